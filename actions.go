@@ -0,0 +1,464 @@
+package selenium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLegacyProtocol is returned by commands that exist only in the W3C
+// protocol when the session uses the legacy protocol.
+var ErrLegacyProtocol = errors.New("the command is not supported by the legacy protocol")
+
+// Input source types, for ActionSequence.Type.
+const (
+	KeySource     = "key"
+	PointerSource = "pointer"
+	WheelSource   = "wheel"
+	NoneSource    = "none"
+)
+
+// Pointer move origins, for PointerMoveAction. A WebElement may also be used
+// as the origin, in which case the offsets are relative to the center of the
+// element.
+const (
+	ViewportOrigin = "viewport"
+	PointerOrigin  = "pointer"
+)
+
+// Action is a single action of an input source, as defined by the W3C
+// specification. It is created by the KeyDownAction, PointerMoveAction, etc.
+// functions.
+type Action map[string]interface{}
+
+// ActionSequence is the list of actions of one input source, which is
+// identified by its ID across calls to PerformActions. The actions of all
+// the sequences passed to PerformActions are performed in ticks: the first
+// action of each sequence in the first tick, the second in the second, and
+// so on. A PauseAction keeps a source idle during a tick.
+type ActionSequence struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Actions    []Action          `json:"actions"`
+}
+
+// KeySequence returns the action sequence of the keyboard with the given ID.
+func KeySequence(id string, actions ...Action) ActionSequence {
+	return ActionSequence{Type: KeySource, ID: id, Actions: actions}
+}
+
+// Pointer types, for PointerSequence and PointerInput.
+const (
+	MousePointer = "mouse"
+	PenPointer   = "pen"
+	TouchPointer = "touch"
+)
+
+// PointerSequence returns the action sequence of the pointer with the given
+// ID. The pointer type is one of MousePointer, PenPointer or TouchPointer.
+func PointerSequence(id, pointerType string, actions ...Action) ActionSequence {
+	return ActionSequence{
+		Type:       PointerSource,
+		ID:         id,
+		Parameters: map[string]string{"pointerType": pointerType},
+		Actions:    actions,
+	}
+}
+
+// PointerInput is a pointer input source: a mouse, a pen or a finger on a
+// touch screen. Each finger of a multi-touch gesture is a separate input.
+type PointerInput struct {
+	ID string
+	// Type is one of MousePointer, PenPointer or TouchPointer.
+	Type string
+}
+
+// Sequence returns the action sequence of the input.
+func (p PointerInput) Sequence(actions ...Action) ActionSequence {
+	return PointerSequence(p.ID, p.Type, actions...)
+}
+
+// PointerProperties describe the contact of a pen or touch pointer. Zero
+// values are left for the remote end to choose.
+type PointerProperties struct {
+	// Width and Height are the size of the contact geometry, in pixels.
+	Width, Height float64
+	// Pressure is the normalized pressure, between 0 and 1.
+	Pressure float64
+}
+
+// WithProperties returns a copy of the pointerDown, pointerUp or pointerMove
+// action with the given contact properties.
+func (a Action) WithProperties(props PointerProperties) Action {
+	c := make(Action, len(a)+3)
+	for k, v := range a {
+		c[k] = v
+	}
+	if props.Width != 0 {
+		c["width"] = props.Width
+	}
+	if props.Height != 0 {
+		c["height"] = props.Height
+	}
+	if props.Pressure != 0 {
+		c["pressure"] = props.Pressure
+	}
+	return c
+}
+
+// WithDuration returns a copy of the pointerMove or scroll action that takes
+// the given duration, rounded to the nearest millisecond, instead of being
+// immediate.
+func (a Action) WithDuration(d time.Duration) Action {
+	c := make(Action, len(a))
+	for k, v := range a {
+		c[k] = v
+	}
+	c["duration"] = milliseconds(d)
+	return c
+}
+
+// milliseconds returns d rounded to the nearest millisecond, as an action
+// duration.
+func milliseconds(d time.Duration) int {
+	return int(d.Round(time.Millisecond) / time.Millisecond)
+}
+
+// WheelSequence returns the action sequence of the wheel with the given ID.
+func WheelSequence(id string, actions ...Action) ActionSequence {
+	return ActionSequence{Type: WheelSource, ID: id, Actions: actions}
+}
+
+// PauseAction returns an action that keeps the input source idle for the
+// given duration, rounded to the nearest millisecond. A zero duration only
+// lets the tick pass.
+func PauseAction(d time.Duration) Action {
+	return Action{"type": "pause", "duration": milliseconds(d)}
+}
+
+// KeyDownAction returns an action that presses the key, which is a single
+// character or one of the special keys such as ShiftKey.
+func KeyDownAction(key string) Action {
+	return Action{"type": "keyDown", "value": key}
+}
+
+// KeyUpAction returns an action that releases the key.
+func KeyUpAction(key string) Action {
+	return Action{"type": "keyUp", "value": key}
+}
+
+// PointerMoveAction returns an action that moves the pointer to the offset
+// (x, y) from the origin, which is ViewportOrigin, PointerOrigin or a
+// WebElement. The move is immediate; see WithDuration.
+func PointerMoveAction(origin interface{}, x, y int) Action {
+	return Action{"type": "pointerMove", "duration": 0, "origin": origin, "x": x, "y": y}
+}
+
+// PointerDownAction returns an action that presses the button of the
+// pointer: one of LeftButton, MiddleButton or RightButton.
+func PointerDownAction(button MouseButton) Action {
+	return Action{"type": "pointerDown", "button": button}
+}
+
+// PointerUpAction returns an action that releases the button of the pointer.
+func PointerUpAction(button MouseButton) Action {
+	return Action{"type": "pointerUp", "button": button}
+}
+
+// ScrollAction returns a wheel action that scrolls by (deltaX, deltaY)
+// pixels at the offset (x, y) from the origin, which is ViewportOrigin or a
+// WebElement. With an element as the origin, the element is first scrolled
+// into view.
+func ScrollAction(origin interface{}, x, y, deltaX, deltaY int) Action {
+	return Action{"type": "scroll", "duration": 0, "origin": origin,
+		"x": x, "y": y, "deltaX": deltaX, "deltaY": deltaY}
+}
+
+func (wd *remoteWD) PerformActions(sequences []ActionSequence) error {
+	if !wd.w3cCompatible {
+		return ErrLegacyProtocol
+	}
+	// The remote end requires arrays, not nulls, for empty lists.
+	payload := make([]ActionSequence, len(sequences))
+	for i, seq := range sequences {
+		if seq.Actions == nil {
+			seq.Actions = []Action{}
+		}
+		payload[i] = seq
+	}
+	return wd.voidCommand("/session/%s/actions", map[string]interface{}{
+		"actions": payload,
+	})
+}
+
+func (wd *remoteWD) ReleaseActions() error {
+	if !wd.w3cCompatible {
+		return ErrLegacyProtocol
+	}
+	_, err := wd.execute("DELETE", wd.requestURL("/session/%s/actions", wd.id), nil)
+	return err
+}
+
+// Actions is a chain of keyboard and mouse actions, built by calling its
+// methods in the order in which the actions should happen and performed by
+// Perform. Each action takes one tick; the other device pauses meanwhile.
+//
+//	err := wd.Actions().MoveToElement(elem).ClickAndHold().MoveByOffset(10, 0).Release().Perform()
+//
+// Pause and the methods taking a duration slow the chain down, e.g. to give
+// a hover menu time to open:
+//
+//	err := wd.Actions().MoveToElementWithDuration(menu, 500*time.Millisecond).Pause(200*time.Millisecond).Click().Perform()
+type Actions struct {
+	wd    *remoteWD
+	steps []actionStep
+}
+
+type actionStep struct {
+	source string // KeySource, PointerSource or NoneSource for pauses.
+	action Action
+}
+
+const (
+	defaultKeyboardID = "default keyboard"
+	defaultMouseID    = "default mouse"
+	defaultWheelID    = "default wheel"
+	defaultTouchID    = "default touch"
+	defaultNoneID     = "default none"
+)
+
+func (wd *remoteWD) Actions() *Actions {
+	return &Actions{wd: wd}
+}
+
+func (a *Actions) add(source string, action Action) *Actions {
+	a.steps = append(a.steps, actionStep{source, action})
+	return a
+}
+
+// KeyDown presses the key, which is a single character or one of the special
+// keys such as ShiftKey.
+func (a *Actions) KeyDown(key string) *Actions {
+	return a.add(KeySource, KeyDownAction(key))
+}
+
+// KeyUp releases the key.
+func (a *Actions) KeyUp(key string) *Actions {
+	return a.add(KeySource, KeyUpAction(key))
+}
+
+// SendKeys presses and releases each of the keys in turn.
+func (a *Actions) SendKeys(keys string) *Actions {
+	for _, key := range keys {
+		a.KeyDown(string(key)).KeyUp(string(key))
+	}
+	return a
+}
+
+// MoveToElement moves the mouse to the center of the element.
+func (a *Actions) MoveToElement(elem WebElement) *Actions {
+	return a.add(PointerSource, PointerMoveAction(elem, 0, 0))
+}
+
+// MoveByOffset moves the mouse by the offset from its current position.
+func (a *Actions) MoveByOffset(x, y int) *Actions {
+	return a.add(PointerSource, PointerMoveAction(PointerOrigin, x, y))
+}
+
+// MoveToElementWithDuration is like MoveToElement, but moves the mouse
+// gradually over the given duration.
+func (a *Actions) MoveToElementWithDuration(elem WebElement, d time.Duration) *Actions {
+	return a.add(PointerSource, PointerMoveAction(elem, 0, 0).WithDuration(d))
+}
+
+// MoveByOffsetWithDuration is like MoveByOffset, but moves the mouse
+// gradually over the given duration.
+func (a *Actions) MoveByOffsetWithDuration(x, y int, d time.Duration) *Actions {
+	return a.add(PointerSource, PointerMoveAction(PointerOrigin, x, y).WithDuration(d))
+}
+
+// Pause keeps all devices idle for the given duration.
+func (a *Actions) Pause(d time.Duration) *Actions {
+	return a.add(NoneSource, PauseAction(d))
+}
+
+// ClickAndHold presses the left mouse button.
+func (a *Actions) ClickAndHold() *Actions {
+	return a.add(PointerSource, PointerDownAction(LeftButton))
+}
+
+// Release releases the left mouse button.
+func (a *Actions) Release() *Actions {
+	return a.add(PointerSource, PointerUpAction(LeftButton))
+}
+
+// Click presses and releases the left mouse button.
+func (a *Actions) Click() *Actions {
+	return a.ClickAndHold().Release()
+}
+
+// Sequences returns the action sequences of the chain, as performed by
+// Perform on sessions using the W3C protocol. Sequences are only returned
+// for the devices that the chain uses, all with the same number of ticks; a
+// chain of pauses only is returned as a sequence of the NoneSource type.
+func (a *Actions) Sequences() []ActionSequence {
+	var keys, mouse []Action
+	usesKeys, usesMouse := false, false
+	for _, step := range a.steps {
+		usesKeys = usesKeys || step.source == KeySource
+		usesMouse = usesMouse || step.source == PointerSource
+	}
+	for _, step := range a.steps {
+		switch step.source {
+		case KeySource:
+			keys = append(keys, step.action)
+			if usesMouse {
+				mouse = append(mouse, PauseAction(0))
+			}
+		case PointerSource:
+			mouse = append(mouse, step.action)
+			if usesKeys {
+				keys = append(keys, PauseAction(0))
+			}
+		default:
+			if usesKeys || !usesMouse {
+				keys = append(keys, step.action)
+			}
+			if usesMouse {
+				mouse = append(mouse, step.action)
+			}
+		}
+	}
+	var sequences []ActionSequence
+	switch {
+	case usesKeys:
+		sequences = append(sequences, KeySequence(defaultKeyboardID, keys...))
+	case !usesMouse && len(keys) > 0:
+		sequences = append(sequences, ActionSequence{Type: NoneSource, ID: defaultNoneID, Actions: keys})
+	}
+	if usesMouse {
+		sequences = append(sequences, PointerSequence(defaultMouseID, MousePointer, mouse...))
+	}
+	return sequences
+}
+
+// Perform performs the chain with a single request. On sessions using the
+// legacy protocol, each action is performed with a separate request instead.
+func (a *Actions) Perform() error {
+	if len(a.steps) == 0 {
+		return nil
+	}
+	if a.wd == nil {
+		return errors.New("the actions were not created with WebDriver.Actions")
+	}
+	if a.wd.w3cCompatible {
+		return a.wd.PerformActions(a.Sequences())
+	}
+	for i, step := range a.steps {
+		if err := a.wd.legacyAction(step.action); err != nil {
+			return fmt.Errorf("performing action %d (%s): %v", i+1, step.action["type"], err)
+		}
+	}
+	return nil
+}
+
+// legacyAction performs a single action with the legacy protocol.
+func (wd *remoteWD) legacyAction(action Action) error {
+	switch action["type"] {
+	case "pause":
+		time.Sleep(time.Duration(action["duration"].(int)) * time.Millisecond)
+		return nil
+	case "keyDown":
+		return wd.voidCommand("/session/%s/keys", wd.processKeyString(action["value"].(string)))
+	case "keyUp":
+		// The legacy protocol types other keys in full when they are sent, and
+		// toggles the special keys, such as modifiers, each time.
+		key := action["value"].(string)
+		if r := []rune(key); len(r) != 1 || r[0] < 0xE000 || r[0] > 0xF8FF {
+			return nil
+		}
+		return wd.voidCommand("/session/%s/keys", wd.processKeyString(key))
+	case "pointerDown":
+		return wd.voidCommand("/session/%s/buttondown", map[string]interface{}{"button": action["button"]})
+	case "pointerUp":
+		return wd.voidCommand("/session/%s/buttonup", map[string]interface{}{"button": action["button"]})
+	case "pointerMove":
+		switch origin := action["origin"].(type) {
+		case WebElement:
+			id, err := elementReference(origin)
+			if err != nil {
+				return err
+			}
+			// Without offsets, the legacy protocol moves to the center of the
+			// element.
+			params := map[string]interface{}{"element": id}
+			if x, y := action["x"].(int), action["y"].(int); x != 0 || y != 0 {
+				size, err := origin.Size()
+				if err != nil {
+					return err
+				}
+				params["xoffset"] = size.Width/2 + x
+				params["yoffset"] = size.Height/2 + y
+			}
+			return wd.legacyMove(params, action)
+		case string:
+			if origin != PointerOrigin {
+				return fmt.Errorf("moving relative to the %s: %v", origin, ErrLegacyProtocol)
+			}
+			return wd.legacyMove(map[string]interface{}{
+				"xoffset": action["x"],
+				"yoffset": action["y"],
+			}, action)
+		}
+	}
+	return fmt.Errorf("unsupported action %v: %v", action["type"], ErrLegacyProtocol)
+}
+
+// legacyMove moves the mouse with the legacy protocol. The legacy protocol
+// moves the mouse at once, so the duration of the move, if any, is waited
+// out afterwards to preserve the timing of the chain.
+func (wd *remoteWD) legacyMove(params map[string]interface{}, action Action) error {
+	if err := wd.voidCommand("/session/%s/moveto", params); err != nil {
+		return err
+	}
+	time.Sleep(time.Duration(action["duration"].(int)) * time.Millisecond)
+	return nil
+}
+
+// elementReference returns the ID of the element, as serialized by its
+// MarshalJSON method.
+func elementReference(elem WebElement) (string, error) {
+	data, err := json.Marshal(elem)
+	if err != nil {
+		return "", err
+	}
+	var ref map[string]string
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return "", err
+	}
+	if id := ref[webElementIdentifier]; id != "" {
+		return id, nil
+	}
+	if id := ref["ELEMENT"]; id != "" {
+		return id, nil
+	}
+	return "", ErrInvalidElement
+}
+
+func (wd *remoteWD) Tap(elem WebElement) error {
+	if !wd.w3cCompatible {
+		id, err := elementReference(elem)
+		if err != nil {
+			return err
+		}
+		return wd.voidCommand("/session/%s/touch/click", map[string]string{"element": id})
+	}
+	finger := PointerInput{ID: defaultTouchID, Type: TouchPointer}
+	return wd.PerformActions([]ActionSequence{finger.Sequence(
+		PointerMoveAction(elem, 0, 0),
+		PointerDownAction(LeftButton),
+		PointerUpAction(LeftButton),
+	)})
+}
@@ -0,0 +1,535 @@
+package selenium
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPerformActions(t *testing.T) {
+	var requests []string
+	var body []byte
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		if r.URL.Path != "/session/123/actions" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		requests = append(requests, r.Method)
+		body, _ = ioutil.ReadAll(r.Body)
+		fmt.Fprint(w, `{"value":null}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "e1"}
+
+	// Shift-click the element.
+	err := wd.PerformActions([]ActionSequence{
+		KeySequence("keyboard",
+			KeyDownAction(ShiftKey),
+			PauseAction(0),
+			PauseAction(0),
+			PauseAction(0),
+			KeyUpAction(ShiftKey)),
+		PointerSequence("mouse", "mouse",
+			PauseAction(0),
+			PointerMoveAction(elem, 0, 0),
+			PointerDownAction(LeftButton),
+			PointerUpAction(LeftButton)),
+		{Type: NoneSource, ID: "idle"},
+	})
+	if err != nil {
+		t.Fatalf("PerformActions() returned error: %v", err)
+	}
+	want := `{"actions":[` +
+		`{"type":"key","id":"keyboard","actions":[` +
+		`{"type":"keyDown","value":"` + ShiftKey + `"},` +
+		`{"duration":0,"type":"pause"},` +
+		`{"duration":0,"type":"pause"},` +
+		`{"duration":0,"type":"pause"},` +
+		`{"type":"keyUp","value":"` + ShiftKey + `"}]},` +
+		`{"type":"pointer","id":"mouse","parameters":{"pointerType":"mouse"},"actions":[` +
+		`{"duration":0,"type":"pause"},` +
+		`{"duration":0,"origin":{"ELEMENT":"e1","` + webElementIdentifier + `":"e1"},"type":"pointerMove","x":0,"y":0},` +
+		`{"button":0,"type":"pointerDown"},` +
+		`{"button":0,"type":"pointerUp"}]},` +
+		`{"type":"none","id":"idle","actions":[]}]}`
+	var got, wantValue interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("the request body %s is not valid JSON: %v", body, err)
+	}
+	json.Unmarshal([]byte(want), &wantValue)
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(wantValue)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("PerformActions() sent\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+
+	if err := wd.ReleaseActions(); err != nil {
+		t.Fatalf("ReleaseActions() returned error: %v", err)
+	}
+	if fmt.Sprint(requests) != "[POST DELETE]" {
+		t.Errorf("requests = %v, want [POST DELETE]", requests)
+	}
+}
+
+func TestPauseActionDuration(t *testing.T) {
+	for _, tc := range []struct {
+		d    time.Duration
+		want int
+	}{
+		{0, 0},
+		{200 * time.Millisecond, 200},
+		{1500 * time.Microsecond, 2},
+		{time.Second, 1000},
+	} {
+		if got := PauseAction(tc.d)["duration"]; got != tc.want {
+			t.Errorf("PauseAction(%v) has duration %v, want %d", tc.d, got, tc.want)
+		}
+	}
+}
+
+func TestActionsLegacyProtocol(t *testing.T) {
+	wd := &remoteWD{id: "123", urlPrefix: "http://127.0.0.1:0"}
+	if err := wd.PerformActions([]ActionSequence{KeySequence("keyboard", KeyDownAction("a"))}); err != ErrLegacyProtocol {
+		t.Errorf("PerformActions() on a legacy session returned error %v, want ErrLegacyProtocol", err)
+	}
+	if err := wd.ReleaseActions(); err != ErrLegacyProtocol {
+		t.Errorf("ReleaseActions() on a legacy session returned error %v, want ErrLegacyProtocol", err)
+	}
+}
+
+func TestActionsBuilder(t *testing.T) {
+	var bodies [][]byte
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		if r.URL.Path != "/session/123/actions" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		fmt.Fprint(w, `{"value":null}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "e1"}
+
+	err := wd.Actions().KeyDown(ShiftKey).MoveToElement(elem).ClickAndHold().MoveByOffset(10, 0).Release().KeyUp(ShiftKey).Perform()
+	if err != nil {
+		t.Fatalf("Perform() returned error: %v", err)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("Perform() sent %d requests, want 1", len(bodies))
+	}
+	var payload struct {
+		Actions []struct {
+			Type, ID string
+			Actions  []map[string]interface{}
+		}
+	}
+	if err := json.Unmarshal(bodies[0], &payload); err != nil {
+		t.Fatalf("the request body %s is not valid JSON: %v", bodies[0], err)
+	}
+	if len(payload.Actions) != 2 {
+		t.Fatalf("Perform() sent %d sequences, want 2", len(payload.Actions))
+	}
+	var types []string
+	for _, seq := range payload.Actions {
+		var seqTypes []string
+		for _, a := range seq.Actions {
+			seqTypes = append(seqTypes, a["type"].(string))
+		}
+		types = append(types, seq.Type+":"+fmt.Sprint(seqTypes))
+	}
+	want := []string{
+		"key:[keyDown pause pause pause pause keyUp]",
+		"pointer:[pause pointerMove pointerDown pointerMove pointerUp pause]",
+	}
+	if fmt.Sprint(types) != fmt.Sprint(want) {
+		t.Errorf("Perform() sent sequences\n%v\nwant\n%v", types, want)
+	}
+	if move := payload.Actions[1].Actions[3]; move["origin"] != PointerOrigin || move["x"] != 10.0 {
+		t.Errorf("MoveByOffset(10, 0) sent %v, want a move by 10 from the pointer", move)
+	}
+
+	// Long chains are sent as a whole, with both devices having as many ticks
+	// as the chain has actions.
+	bodies = nil
+	a := wd.Actions()
+	for i := 0; i < 20; i++ {
+		a.SendKeys("x").Click()
+	}
+	if err := a.Perform(); err != nil {
+		t.Fatalf("Perform() returned error: %v", err)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("Perform() sent %d requests, want 1", len(bodies))
+	}
+	for _, seq := range a.Sequences() {
+		if len(seq.Actions) != 80 {
+			t.Errorf("the %s sequence has %d ticks, want 80", seq.Type, len(seq.Actions))
+		}
+	}
+}
+
+func TestActionsBuilderLegacy(t *testing.T) {
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, strings.TrimPrefix(r.URL.Path, "/session/123")+" "+string(body))
+		fmt.Fprint(w, `{"status":0,"value":null}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL}
+	elem := &remoteWE{parent: wd, id: "e1"}
+	if err := wd.Actions().MoveToElement(elem).ClickAndHold().MoveByOffset(10, 0).Release().SendKeys("a").Perform(); err != nil {
+		t.Fatalf("Perform() returned error: %v", err)
+	}
+	want := []string{
+		`/moveto {"element":"e1"}`,
+		`/buttondown {"button":0}`,
+		`/moveto {"xoffset":10,"yoffset":0}`,
+		`/buttonup {"button":0}`,
+		`/keys {"value":["a"]}`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Perform() sent\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+
+	if err := wd.Actions().add(PointerSource, PointerMoveAction(ViewportOrigin, 1, 1)).Perform(); err == nil {
+		t.Errorf("Perform() of a move relative to the viewport on a legacy session returned nil error")
+	}
+}
+
+func TestWheelScroll(t *testing.T) {
+	var bodies []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, r.URL.Path+" "+string(body))
+		fmt.Fprint(w, `{"value":null}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "e1"}
+	if err := wd.ScrollToElement(elem); err != nil {
+		t.Fatalf("ScrollToElement() returned error: %v", err)
+	}
+	if err := wd.ScrollByAmount(10, 20, 0, 300); err != nil {
+		t.Fatalf("ScrollByAmount() returned error: %v", err)
+	}
+	want := []string{
+		`/session/123/actions {"actions":[{"type":"wheel","id":"default wheel","actions":[` +
+			`{"deltaX":0,"deltaY":0,"duration":0,"origin":{"ELEMENT":"e1","` + webElementIdentifier + `":"e1"},"type":"scroll","x":0,"y":0}]}]}`,
+		`/session/123/actions {"actions":[{"type":"wheel","id":"default wheel","actions":[` +
+			`{"deltaX":0,"deltaY":300,"duration":0,"origin":"viewport","type":"scroll","x":10,"y":20}]}]}`,
+	}
+	if strings.Join(bodies, "\n") != strings.Join(want, "\n") {
+		t.Errorf("the wheel actions sent\n%s\nwant\n%s", strings.Join(bodies, "\n"), strings.Join(want, "\n"))
+	}
+
+	bodies = nil
+	wd.w3cCompatible = false
+	if err := wd.ScrollToElement(elem); err != ErrLegacyProtocol {
+		t.Errorf("ScrollToElement() on a legacy session returned error %v, want ErrLegacyProtocol", err)
+	}
+	if err := wd.ScrollByAmount(0, 0, 0, 100); err != ErrLegacyProtocol {
+		t.Errorf("ScrollByAmount() on a legacy session returned error %v, want ErrLegacyProtocol", err)
+	}
+	if len(bodies) != 0 {
+		t.Errorf("wheel actions on a legacy session sent %q, want no requests", bodies)
+	}
+}
+
+func TestTap(t *testing.T) {
+	var bodies []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, strings.TrimPrefix(r.URL.Path, "/session/123")+" "+string(body))
+		fmt.Fprint(w, `{"status":0,"value":null}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "e1"}
+	if err := wd.Tap(elem); err != nil {
+		t.Fatalf("Tap() returned error: %v", err)
+	}
+	wd.w3cCompatible = false
+	if err := wd.Tap(elem); err != nil {
+		t.Fatalf("Tap() on a legacy session returned error: %v", err)
+	}
+	want := []string{
+		`/actions {"actions":[{"type":"pointer","id":"default touch","parameters":{"pointerType":"touch"},"actions":[` +
+			`{"duration":0,"origin":{"ELEMENT":"e1","` + webElementIdentifier + `":"e1"},"type":"pointerMove","x":0,"y":0},` +
+			`{"button":0,"type":"pointerDown"},` +
+			`{"button":0,"type":"pointerUp"}]}]}`,
+		`/touch/click {"element":"e1"}`,
+	}
+	if strings.Join(bodies, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Tap() sent\n%s\nwant\n%s", strings.Join(bodies, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPointerProperties(t *testing.T) {
+	pen := PointerInput{ID: "pen", Type: PenPointer}
+	down := PointerDownAction(LeftButton)
+	seq := pen.Sequence(down.WithProperties(PointerProperties{Width: 2, Height: 3, Pressure: 0.5}))
+	got, err := json.Marshal(seq)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	want := `{"type":"pointer","id":"pen","parameters":{"pointerType":"pen"},"actions":[` +
+		`{"button":0,"height":3,"pressure":0.5,"type":"pointerDown","width":2}]}`
+	if string(got) != want {
+		t.Errorf("the pen sequence is\n%s\nwant\n%s", got, want)
+	}
+	if _, ok := down["width"]; ok {
+		t.Errorf("WithProperties() modified the original action")
+	}
+	if got := PointerUpAction(LeftButton).WithProperties(PointerProperties{}); len(got) != 2 {
+		t.Errorf("WithProperties() with zero properties returned %v, want only the type and button", got)
+	}
+}
+
+func TestActionsBuilderTiming(t *testing.T) {
+	var body []byte
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ = ioutil.ReadAll(r.Body)
+		fmt.Fprint(w, `{"value":null}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "e1"}
+
+	// The keyboard has three actions and the mouse two, with pauses between.
+	a := wd.Actions().
+		KeyDown("a").Pause(200*time.Millisecond).KeyUp("a").
+		MoveToElementWithDuration(elem, 500*time.Millisecond).
+		SendKeys("b").
+		MoveByOffsetWithDuration(5, 5, 1500*time.Microsecond)
+	if err := a.Perform(); err != nil {
+		t.Fatalf("Perform() returned error: %v", err)
+	}
+	var payload struct{ Actions []ActionSequence }
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("the request body %s is not valid JSON: %v", body, err)
+	}
+	if len(payload.Actions) != 2 {
+		t.Fatalf("Perform() sent %d sequences, want 2", len(payload.Actions))
+	}
+	var got []string
+	for _, seq := range payload.Actions {
+		if len(seq.Actions) != 7 {
+			t.Errorf("the %s sequence has %d ticks, want 7", seq.Type, len(seq.Actions))
+		}
+		var ticks []string
+		for _, a := range seq.Actions {
+			ticks = append(ticks, fmt.Sprintf("%v/%v", a["type"], a["duration"]))
+		}
+		got = append(got, seq.Type+": "+strings.Join(ticks, " "))
+	}
+	want := []string{
+		"key: keyDown/<nil> pause/200 keyUp/<nil> pause/0 keyDown/<nil> keyUp/<nil> pause/0",
+		"pointer: pause/0 pause/200 pause/0 pointerMove/500 pause/0 pause/0 pointerMove/2",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Perform() sent\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// A chain of pauses only idles without a device.
+	seqs := wd.Actions().Pause(time.Second).Sequences()
+	if len(seqs) != 1 || seqs[0].Type != NoneSource || len(seqs[0].Actions) != 1 || seqs[0].Actions[0]["duration"] != 1000 {
+		t.Errorf("Sequences() of a pause = %v, want a single none sequence pausing 1000ms", seqs)
+	}
+}
+
+func TestClickButton(t *testing.T) {
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, strings.TrimPrefix(r.URL.Path, "/session/123")+" "+string(body))
+		fmt.Fprint(w, `{"status":0,"value":null}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "e1"}
+	origin := `{"ELEMENT":"e1","` + webElementIdentifier + `":"e1"}`
+	if err := wd.Click(MiddleButton); err != nil {
+		t.Fatalf("Click(MiddleButton) returned error: %v", err)
+	}
+	if err := elem.RightClick(); err != nil {
+		t.Fatalf("RightClick() returned error: %v", err)
+	}
+	if err := elem.DoubleClick(); err != nil {
+		t.Fatalf("DoubleClick() returned error: %v", err)
+	}
+	wd.w3cCompatible = false
+	if err := wd.Click(MiddleButton); err != nil {
+		t.Fatalf("Click(MiddleButton) on a legacy session returned error: %v", err)
+	}
+	if err := elem.RightClick(); err != nil {
+		t.Fatalf("RightClick() on a legacy session returned error: %v", err)
+	}
+	if err := elem.DoubleClick(); err != nil {
+		t.Fatalf("DoubleClick() on a legacy session returned error: %v", err)
+	}
+	want := []string{
+		`/actions {"actions":[{"type":"pointer","id":"default mouse","parameters":{"pointerType":"mouse"},"actions":[` +
+			`{"button":1,"type":"pointerDown"},{"button":1,"type":"pointerUp"}]}]}`,
+		`/actions {"actions":[{"type":"pointer","id":"default mouse","parameters":{"pointerType":"mouse"},"actions":[` +
+			`{"duration":0,"origin":` + origin + `,"type":"pointerMove","x":0,"y":0},` +
+			`{"button":2,"type":"pointerDown"},{"button":2,"type":"pointerUp"}]}]}`,
+		`/actions {"actions":[{"type":"pointer","id":"default mouse","parameters":{"pointerType":"mouse"},"actions":[` +
+			`{"duration":0,"origin":` + origin + `,"type":"pointerMove","x":0,"y":0},` +
+			`{"button":0,"type":"pointerDown"},{"button":0,"type":"pointerUp"},` +
+			`{"button":0,"type":"pointerDown"},{"button":0,"type":"pointerUp"}]}]}`,
+		`/click {"button":1}`,
+		`/moveto {"element":"e1"}`,
+		`/click {"button":2}`,
+		`/moveto {"element":"e1"}`,
+		`/doubleclick {}`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("the clicks sent\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestHover(t *testing.T) {
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		path := strings.TrimPrefix(r.URL.Path, "/session/123")
+		requests = append(requests, strings.TrimSpace(path+" "+string(body)))
+		if path == "/element/e1/size" {
+			fmt.Fprint(w, `{"status":0,"value":{"width":100,"height":40}}`)
+			return
+		}
+		fmt.Fprint(w, `{"status":0,"value":null}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "e1"}
+	origin := `{"ELEMENT":"e1","` + webElementIdentifier + `":"e1"}`
+	if err := elem.Hover(); err != nil {
+		t.Fatalf("Hover() returned error: %v", err)
+	}
+	if err := elem.HoverAt(5, -3); err != nil {
+		t.Fatalf("HoverAt(5, -3) returned error: %v", err)
+	}
+	wd.w3cCompatible = false
+	if err := elem.Hover(); err != nil {
+		t.Fatalf("Hover() on a legacy session returned error: %v", err)
+	}
+	if err := elem.HoverAt(5, -3); err != nil {
+		t.Fatalf("HoverAt(5, -3) on a legacy session returned error: %v", err)
+	}
+	want := []string{
+		`/actions {"actions":[{"type":"pointer","id":"default mouse","parameters":{"pointerType":"mouse"},"actions":[` +
+			`{"duration":0,"origin":` + origin + `,"type":"pointerMove","x":0,"y":0}]}]}`,
+		`/actions {"actions":[{"type":"pointer","id":"default mouse","parameters":{"pointerType":"mouse"},"actions":[` +
+			`{"duration":0,"origin":` + origin + `,"type":"pointerMove","x":5,"y":-3}]}]}`,
+		`/moveto {"element":"e1"}`,
+		`/element/e1/size`,
+		`/moveto {"element":"e1","xoffset":55,"yoffset":17}`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("the moves sent\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestClickAt(t *testing.T) {
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		path := strings.TrimPrefix(r.URL.Path, "/session/123")
+		requests = append(requests, strings.TrimSpace(path+" "+string(body)))
+		if path == "/element/e1/rect" {
+			fmt.Fprint(w, `{"status":0,"value":{"x":10,"y":20,"width":100,"height":100}}`)
+			return
+		}
+		fmt.Fprint(w, `{"status":0,"value":null}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "e1"}
+	origin := `{"ELEMENT":"e1","` + webElementIdentifier + `":"e1"}`
+	for _, p := range []Point{{0, 0}, {99, 0}, {0, 99}} {
+		if err := elem.ClickAt(p.X, p.Y); err != nil {
+			t.Fatalf("ClickAt(%d, %d) returned error: %v", p.X, p.Y, err)
+		}
+	}
+	wd.w3cCompatible = false
+	if err := elem.ClickAt(99, 0); err != nil {
+		t.Fatalf("ClickAt(99, 0) on a legacy session returned error: %v", err)
+	}
+	click := func(x, y int) string {
+		return fmt.Sprintf(`/actions {"actions":[{"type":"pointer","id":"default mouse","parameters":{"pointerType":"mouse"},"actions":[`+
+			`{"duration":0,"origin":%s,"type":"pointerMove","x":%d,"y":%d},`+
+			`{"button":0,"type":"pointerDown"},{"button":0,"type":"pointerUp"}]}]}`, origin, x, y)
+	}
+	want := []string{
+		`/element/e1/rect`, click(-50, -50),
+		`/element/e1/rect`, click(49, -50),
+		`/element/e1/rect`, click(-50, 49),
+		`/moveto {"element":"e1","xoffset":99,"yoffset":0}`,
+		`/click {"button":0}`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("the clicks sent\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+
+	// The element is referred to as encoded by the element encoder.
+	requests = nil
+	wd.SetElementEncoder(func(id string) interface{} { return map[string]string{"ELEMENT": "custom-" + id} })
+	if err := elem.ClickAt(1, 2); err != nil {
+		t.Fatalf("ClickAt(1, 2) with an element encoder returned error: %v", err)
+	}
+	if len(requests) == 0 || requests[0] != `/moveto {"element":"custom-e1","xoffset":1,"yoffset":2}` {
+		t.Errorf("ClickAt(1, 2) with an element encoder sent %q, want a move to custom-e1", requests)
+	}
+}
+
+func TestMoveToUsesElementEncoder(t *testing.T) {
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, strings.TrimSpace(strings.TrimPrefix(r.URL.Path, "/session/123")+" "+string(body)))
+		fmt.Fprint(w, `{"status":0,"value":null}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL}
+	wd.SetElementEncoder(func(id string) interface{} { return map[string]string{"ELEMENT": "custom-" + id} })
+	elem := &remoteWE{parent: wd, id: "e1"}
+	if err := elem.MoveTo(3, 4); err != nil {
+		t.Fatalf("MoveTo(3, 4) returned error: %v", err)
+	}
+	if want := `/moveto {"element":"custom-e1","xoffset":3,"yoffset":4}`; len(requests) != 1 || requests[0] != want {
+		t.Errorf("MoveTo(3, 4) sent %q, want %q", requests, want)
+	}
+}
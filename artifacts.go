@@ -0,0 +1,201 @@
+package selenium
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ArtifactSink stores the files produced for a session, such as debug dumps,
+// set with WebDriver.SetArtifactSink. Dir and MemorySink are provided;
+// other implementations can upload artifacts to remote storage.
+//
+// Artifacts are named {testname}/{timestamp}-{kind}-{seq}.{ext}, where
+// testname is set by WebDriver.SetArtifactTestName and seq is unique within
+// the process, so that names do not collide under parallel tests. The
+// artifacts of each session are listed in {testname}/index-{session}.json,
+// which is rewritten each time an artifact is added.
+type ArtifactSink interface {
+	// WriteArtifact stores data under name, a slash-separated relative path.
+	// An existing artifact with the same name is replaced.
+	WriteArtifact(name string, data []byte) error
+}
+
+// ArtifactInfo describes an artifact in a session's index.
+type ArtifactInfo struct {
+	// Name is the name of the artifact within the sink.
+	Name string `json:"name"`
+	// Kind is the kind of the artifact, e.g. "debugdump".
+	Kind string `json:"kind"`
+	// Session is the ID of the session the artifact was produced for.
+	Session string `json:"session"`
+	// Time is when the artifact was produced.
+	Time time.Time `json:"time"`
+	// Command is the most recent command of the session, if command history
+	// is enabled.
+	Command string `json:"command,omitempty"`
+	// URL is the URL of the current page, if known.
+	URL string `json:"url,omitempty"`
+	// Reason is why the artifact was produced, e.g. "test failed".
+	Reason string `json:"reason,omitempty"`
+}
+
+// Dir returns an ArtifactSink that writes artifacts to files under the
+// directory path, creating directories as necessary.
+func Dir(path string) ArtifactSink {
+	return dirSink(path)
+}
+
+type dirSink string
+
+func (d dirSink) WriteArtifact(name string, data []byte) error {
+	p := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, data, 0644)
+}
+
+// MemorySink is an ArtifactSink that keeps artifacts in memory, for tests.
+// The zero value is ready to use.
+type MemorySink struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (m *MemorySink) WriteArtifact(name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.files == nil {
+		m.files = make(map[string][]byte)
+	}
+	m.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// Names returns the names of the stored artifacts, sorted.
+func (m *MemorySink) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Artifact returns the contents of the named artifact.
+func (m *MemorySink) Artifact(name string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[name]
+	return data, ok
+}
+
+// artifactSeq numbers the artifacts produced by the process.
+var artifactSeq uint64
+
+// unsafeNameChars matches the characters replaced in the parts of artifact
+// names.
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._/-]+`)
+
+// artifactDir returns the directory of the session's artifacts within the
+// sink.
+func (wd *remoteWD) artifactDir() string {
+	name := wd.artifactTestName
+	if name == "" {
+		name = wd.id
+	}
+	name = unsafeNameChars.ReplaceAllString(name, "_")
+	var parts []string
+	for _, p := range strings.Split(name, "/") {
+		if p != "" && p != "." && p != ".." {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return "session"
+	}
+	return path.Join(parts...)
+}
+
+func (wd *remoteWD) SetArtifactSink(s ArtifactSink) {
+	wd.artifactSink = s
+}
+
+func (wd *remoteWD) SetArtifactTestName(name string) {
+	wd.artifactTestName = name
+}
+
+// saveArtifact stores data as an artifact of the given kind and file
+// extension, and adds it to the session's index.
+func (wd *remoteWD) saveArtifact(kind, ext string, info ArtifactInfo, data []byte) (string, error) {
+	return wd.saveArtifactTo(wd.artifactSink, kind, ext, info, data)
+}
+
+// saveArtifactTo is like saveArtifact, but stores the artifact and the index
+// in the given sink.
+func (wd *remoteWD) saveArtifactTo(sink ArtifactSink, kind, ext string, info ArtifactInfo, data []byte) (string, error) {
+	if sink == nil {
+		return "", fmt.Errorf("no artifact sink set")
+	}
+	now := time.Now()
+	dir := wd.artifactDir()
+	seq := atomic.AddUint64(&artifactSeq, 1)
+	info.Name = path.Join(dir, fmt.Sprintf("%s-%s-%d.%s", now.UTC().Format("20060102T150405.000"), kind, seq, ext))
+	info.Kind = kind
+	info.Session = wd.id
+	info.Time = now
+	if err := sink.WriteArtifact(info.Name, data); err != nil {
+		return "", err
+	}
+
+	// The index is written under the lock, so that the last one written
+	// lists all the artifacts.
+	wd.artifactsMu.Lock()
+	defer wd.artifactsMu.Unlock()
+	wd.artifacts = append(wd.artifacts, info)
+	index, err := json.MarshalIndent(wd.artifacts, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := sink.WriteArtifact(path.Join(dir, "index-"+wd.id+".json"), index); err != nil {
+		return "", err
+	}
+	return info.Name, nil
+}
+
+// lastCommand describes the most recent command of the session, if command
+// history is enabled.
+func (wd *remoteWD) lastCommand() string {
+	if wd.history == nil {
+		return ""
+	}
+	last := wd.history.last(1)
+	if len(last) == 0 {
+		return ""
+	}
+	return last[0].Method + " " + last[0].Endpoint
+}
+
+func (wd *remoteWD) SaveDebugDump(reason string, opts DumpOptions) (string, error) {
+	info := ArtifactInfo{Command: wd.lastCommand(), Reason: reason}
+	var buf bytes.Buffer
+	url, err := wd.debugDump(&buf, opts)
+	if err != nil {
+		return "", err
+	}
+	info.URL = url
+	return wd.saveArtifact("debugdump", "zip", info, buf.Bytes())
+}
@@ -0,0 +1,144 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestSaveDebugDump(t *testing.T) {
+	s := dumpServer()
+	defer s.Close()
+	sink := new(MemorySink)
+
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+				t.Parallel()
+				wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+				wd.EnableCommandHistory(10)
+				wd.SetArtifactSink(sink)
+				wd.SetArtifactTestName(t.Name())
+				if _, err := wd.Title(); err != nil {
+					t.Fatalf("wd.Title() returned error: %v", err)
+				}
+				for j := 0; j < 2; j++ {
+					if _, err := wd.SaveDebugDump("test failed", DumpOptions{}); err != nil {
+						t.Fatalf("wd.SaveDebugDump() returned error: %v", err)
+					}
+				}
+			})
+		}
+	})
+
+	names := sink.Names()
+	// Two dumps and an index for each of the four subtests.
+	if len(names) != 12 {
+		t.Fatalf("the sink holds %d artifacts, want 12: %q", len(names), names)
+	}
+	dumpName := regexp.MustCompile(`^TestSaveDebugDump/group/case_\d/\d{8}T\d{6}\.\d{3}-debugdump-\d+\.zip$`)
+	var dumps int
+	for _, name := range names {
+		if filepath.Base(name) == "index-123.json" {
+			continue
+		}
+		dumps++
+		if !dumpName.MatchString(name) {
+			t.Errorf("artifact name %q does not follow the naming scheme", name)
+		}
+	}
+	if dumps != 8 {
+		t.Errorf("the sink holds %d dumps, want 8", dumps)
+	}
+
+	data, ok := sink.Artifact("TestSaveDebugDump/group/case_0/index-123.json")
+	if !ok {
+		t.Fatalf("the sink holds no index for case 0: %q", names)
+	}
+	var index []ArtifactInfo
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("json.Unmarshal(index) returned error: %v", err)
+	}
+	if len(index) != 2 {
+		t.Fatalf("the index lists %d artifacts, want 2", len(index))
+	}
+	want := ArtifactInfo{
+		Name:    index[0].Name,
+		Kind:    "debugdump",
+		Session: "123",
+		Time:    index[0].Time,
+		Command: "GET /session/123/title",
+		URL:     "http://example.com/",
+		Reason:  "test failed",
+	}
+	if index[0] != want {
+		t.Errorf("index[0] = %+v, want %+v", index[0], want)
+	}
+	if _, ok := sink.Artifact(index[1].Name); !ok {
+		t.Errorf("the index lists %q, which is not in the sink", index[1].Name)
+	}
+}
+
+func TestDirSink(t *testing.T) {
+	dir := t.TempDir()
+	if err := Dir(dir).WriteArtifact("a/b.txt", []byte("hello")); err != nil {
+		t.Fatalf("WriteArtifact() returned error: %v", err)
+	}
+	got, err := ioutil.ReadFile(filepath.Join(dir, "a", "b.txt"))
+	if err != nil || string(got) != "hello" {
+		t.Errorf("the artifact file holds %q, %v, want %q", got, err, "hello")
+	}
+}
+
+func TestArtifactDir(t *testing.T) {
+	for _, tc := range []struct{ name, id, want string }{
+		{"", "abc", "abc"},
+		{"TestX/sub test", "abc", "TestX/sub_test"},
+		{"../../etc", "abc", "etc"},
+		{"", "", "session"},
+	} {
+		wd := &remoteWD{id: tc.id, artifactTestName: tc.name}
+		if got := wd.artifactDir(); got != tc.want {
+			t.Errorf("artifactDir() with test name %q = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSaveArtifactWithoutSink(t *testing.T) {
+	wd := &remoteWD{id: "123"}
+	if _, err := wd.saveArtifact("x", "txt", ArtifactInfo{}, nil); err == nil {
+		t.Errorf("saveArtifact() without a sink returned nil error")
+	}
+}
+
+func TestSaveArtifactsConcurrently(t *testing.T) {
+	sink := new(MemorySink)
+	wd := &remoteWD{id: "123", artifactSink: sink}
+
+	// Run with -race.
+	const n = 10
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := wd.saveArtifact("note", "txt", ArtifactInfo{}, []byte("x")); err != nil {
+				t.Errorf("saveArtifact() returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, _ := sink.Artifact("123/index-123.json")
+	var index []ArtifactInfo
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("json.Unmarshal(index) returned error: %v", err)
+	}
+	if len(index) != n {
+		t.Errorf("the index lists %d artifacts, want %d", len(index), n)
+	}
+}
@@ -0,0 +1,120 @@
+/* A port of bot.dom.isShown from the Selenium atoms, with opacity ignored,
+   as used by the isDisplayed atom. Run go generate to replace it with the
+   compiled atom of the Selenium version set in displayed.go. */
+function(elem) {
+	function style(e, name) {
+		return window.getComputedStyle(e).getPropertyValue(name);
+	}
+	function tagIs(e, name) {
+		return e.tagName.toUpperCase() === name;
+	}
+	// parentElement returns the parent element in the composed tree, crossing
+	// slots and shadow roots.
+	function parentElement(e) {
+		var p = e.assignedSlot || e.parentNode;
+		while (p && p.nodeType !== 1) {
+			p = p.nodeType === 11 && p.host ? p.host : p.parentNode;
+		}
+		return p;
+	}
+	function displayed(e) {
+		for (; e; e = parentElement(e)) {
+			if (style(e, 'display') === 'none') {
+				return false;
+			}
+		}
+		return true;
+	}
+	function positiveSize(e) {
+		var rect = e.getBoundingClientRect();
+		if (rect.height > 0 && rect.width > 0) {
+			return true;
+		}
+		if (tagIs(e, 'PATH') && (rect.height > 0 || rect.width > 0)) {
+			return parseFloat(style(e, 'stroke-width')) > 0;
+		}
+		return style(e, 'overflow') !== 'hidden' &&
+			Array.prototype.some.call(e.childNodes, function(n) {
+				return n.nodeType === 3 || (n.nodeType === 1 && positiveSize(n));
+			});
+	}
+	// containingBlock returns the nearest ancestor that may clip e.
+	function containingBlock(e) {
+		var position = style(e, 'position');
+		if (position === 'fixed') {
+			return null;
+		}
+		for (var p = parentElement(e); p; p = parentElement(p)) {
+			if (position !== 'absolute' || style(p, 'position') !== 'static' ||
+					p === document.documentElement) {
+				return p;
+			}
+		}
+		return null;
+	}
+	// hiddenByOverflow returns whether e lies entirely within a region of an
+	// ancestor that cannot be scrolled into view: outside an ancestor with
+	// overflow hidden, or before the scroll origin of any ancestor.
+	function hiddenByOverflow(e) {
+		var r = e.getBoundingClientRect();
+		if (style(e, 'position') === 'fixed') {
+			return r.right < 0 || r.bottom < 0 ||
+				r.left > window.innerWidth || r.top > window.innerHeight;
+		}
+		for (var c = containingBlock(e); c; c = containingBlock(c)) {
+			var overflowX = style(c, 'overflow-x'), overflowY = style(c, 'overflow-y');
+			if (overflowX === 'visible' && overflowY === 'visible' && c !== document.documentElement) {
+				continue;
+			}
+			var cr = c.getBoundingClientRect();
+			var left = cr.left - c.scrollLeft, top = cr.top - c.scrollTop;
+			if (c === document.documentElement) {
+				left = -window.pageXOffset;
+				top = -window.pageYOffset;
+			}
+			if (r.right <= left || r.bottom <= top) {
+				return true;
+			}
+			var right = cr.right, bottom = cr.bottom;
+			if (c === document.documentElement) {
+				right = window.innerWidth;
+				bottom = window.innerHeight;
+			}
+			if ((overflowX === 'hidden' && r.left >= right) ||
+					(overflowY === 'hidden' && r.top >= bottom)) {
+				return true;
+			}
+		}
+		return false;
+	}
+	function isShown(e) {
+		if (tagIs(e, 'OPTION') || tagIs(e, 'OPTGROUP')) {
+			var select = e.closest('select');
+			return !!select && isShown(select);
+		}
+		if (tagIs(e, 'MAP') || tagIs(e, 'AREA')) {
+			var map = tagIs(e, 'MAP') ? e : e.closest('map');
+			var image = map && map.name &&
+				document.querySelector('[usemap="#' + CSS.escape(map.name) + '"]');
+			return !!image && isShown(image);
+		}
+		if (tagIs(e, 'INPUT') && e.type.toLowerCase() === 'hidden') {
+			return false;
+		}
+		if (tagIs(e, 'NOSCRIPT')) {
+			return false;
+		}
+		var visibility = style(e, 'visibility');
+		if (visibility === 'hidden' || visibility === 'collapse') {
+			return false;
+		}
+		if (!displayed(e)) {
+			return false;
+		}
+		if (!positiveSize(e)) {
+			return false;
+		}
+		return !hiddenByOverflow(e);
+	}
+	return isShown(elem);
+}
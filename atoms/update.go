@@ -0,0 +1,96 @@
+//go:build ignore
+// +build ignore
+
+// Program update replaces isDisplayed.js with the isDisplayed atom compiled by
+// the Selenium project, as distributed verbatim in the wheel of its Python
+// bindings. The wheel is downloaded from PyPI and checked against the SHA-256
+// digest that PyPI publishes for it. It is run by go generate:
+//
+//	go run atoms/update.go -version 4.25.0
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+)
+
+var (
+	version = flag.String("version", "", "The version of the Selenium Python bindings to take the atom from.")
+	out     = flag.String("out", "atoms/isDisplayed.js", "The path of the file to write.")
+)
+
+// atomPath is the path of the atom in the wheel.
+const atomPath = "selenium/webdriver/remote/isDisplayed.js"
+
+func get(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func main() {
+	flag.Parse()
+	if *version == "" {
+		log.Fatal("-version is required")
+	}
+
+	data, err := get(fmt.Sprintf("https://pypi.org/pypi/selenium/%s/json", *version))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var release struct {
+		URLs []struct {
+			Filename    string
+			PackageType string `json:"packagetype"`
+			URL         string
+			Digests     struct{ SHA256 string }
+		}
+	}
+	if err := json.Unmarshal(data, &release); err != nil {
+		log.Fatalf("decoding the release of selenium %s: %v", *version, err)
+	}
+	for _, u := range release.URLs {
+		if u.PackageType != "bdist_wheel" {
+			continue
+		}
+		wheel, err := get(u.URL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if sum := sha256.Sum256(wheel); hex.EncodeToString(sum[:]) != u.Digests.SHA256 {
+			log.Fatalf("%s does not have the SHA-256 digest %s published by PyPI", u.Filename, u.Digests.SHA256)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(wheel), int64(len(wheel)))
+		if err != nil {
+			log.Fatal(err)
+		}
+		f, err := zr.Open(atomPath)
+		if err != nil {
+			log.Fatalf("%s: %v", u.Filename, err)
+		}
+		atom, err := ioutil.ReadAll(f)
+		if err != nil {
+			log.Fatal(err)
+		}
+		header := fmt.Sprintf("/* The isDisplayed atom of Selenium %s, from %s in %s (sha256 %s).\n   Generated by atoms/update.go; DO NOT EDIT. */\n", *version, atomPath, u.Filename, u.Digests.SHA256)
+		if err := ioutil.WriteFile(*out, append([]byte(header), bytes.TrimSpace(atom)...), 0644); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	log.Fatalf("selenium %s has no wheel", *version)
+}
@@ -0,0 +1,124 @@
+package selenium
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// getAttributeAtom reproduces the "get attribute" atom of the Selenium
+// bindings, which legacy drivers used for the /attribute endpoint: the current
+// property value where the element has one, the content attribute otherwise.
+// Boolean attributes, whose name is passed by the caller as isBoolean, are
+// "true" if either the attribute or the property is set, and null otherwise.
+const getAttributeAtom = `function(elem, name, isBoolean) {
+	var lower = name.toLowerCase();
+	if (lower === 'style') {
+		return elem.style ? elem.style.cssText : null;
+	}
+	var tag = elem.tagName.toUpperCase();
+	if (lower === 'selected' || lower === 'checked') {
+		if (tag === 'OPTION') {
+			return elem.selected ? 'true' : null;
+		}
+		if (tag === 'INPUT' && /^(checkbox|radio)$/i.test(elem.type)) {
+			return elem.checked ? 'true' : null;
+		}
+	}
+	if ((tag === 'A' && lower === 'href') || (tag === 'IMG' && lower === 'src')) {
+		// The property is the resolved URL.
+		var url = elem.getAttribute(lower);
+		return url ? elem[lower] : url;
+	}
+	if (lower === 'spellcheck') {
+		var spellcheck = elem.getAttribute(lower);
+		if (spellcheck !== null && /^(true|false)$/i.test(spellcheck)) {
+			return spellcheck.toLowerCase();
+		}
+		return String(elem.spellcheck);
+	}
+	var property = {'class': 'className', 'readonly': 'readOnly'}[lower] || name;
+	if (isBoolean) {
+		return elem.getAttribute(name) !== null || elem[property] ? 'true' : null;
+	}
+	var value = elem[property];
+	if (value === undefined || value === null || typeof value === 'object' ||
+			typeof value === 'function') {
+		value = elem.getAttribute(name);
+	}
+	return value === undefined || value === null ? null : String(value);
+}`
+
+// getAttributeScript calls getAttributeAtom with the arguments of the script.
+const getAttributeScript = `return (` + getAttributeAtom + `).apply(null, arguments);`
+
+func (elem *remoteWE) GetAttributeOrProperty(name string) (string, error) {
+	if err := elem.checkID(); err != nil {
+		return "", err
+	}
+	isBoolean := booleanAttributes[strings.ToLower(name)]
+	var response []byte
+	var err error
+	if elem.parent.w3cCompatible {
+		// W3C drivers return the content attribute only.
+		response, err = elem.parent.ExecuteScriptRaw(getAttributeScript, []interface{}{elem, name, isBoolean})
+		err = elem.wrapError("attribute/"+name, err)
+	} else {
+		response, err = elem.execute("GET", "/attribute/"+url.PathEscape(name), nil)
+	}
+	if err != nil {
+		return "", err
+	}
+	reply := new(struct{ Value json.RawMessage })
+	if err := json.Unmarshal(response, reply); err != nil {
+		return "", err
+	}
+	// Some legacy drivers return booleans and numbers as such.
+	var value *string
+	if len(reply.Value) != 0 && string(reply.Value) != "null" {
+		s := string(reply.Value)
+		if err := json.Unmarshal(reply.Value, &s); err != nil && reply.Value[0] == '"' {
+			return "", err
+		}
+		value = &s
+	}
+	if isBoolean {
+		// Legacy drivers return "false" for some unset boolean attributes,
+		// and null for others.
+		if value == nil || *value == "false" {
+			return "", nil
+		}
+		return "true", nil
+	}
+	if value == nil {
+		return "", nullValueError("/session/%s/element/%s/attribute/%s", elem.parent.id, elem.id, name)
+	}
+	return *value, nil
+}
+
+// attributesScript returns the attributes of the element passed as the first
+// argument, by their qualified names, e.g. "xlink:href".
+const attributesScript = `var attrs = {};
+for (var i = 0; i < arguments[0].attributes.length; i++) {
+  var a = arguments[0].attributes[i];
+  attrs[a.name] = a.value;
+}
+return attrs;`
+
+func (elem *remoteWE) Attributes() (map[string]string, error) {
+	if err := elem.checkID(); err != nil {
+		return nil, err
+	}
+	response, err := elem.parent.ExecuteScriptRaw(attributesScript, []interface{}{elem})
+	if err != nil {
+		return nil, elem.wrapError("attributes", err)
+	}
+	attrs, err := decodeValue[map[string]string](response, elem.parent.strictDecoding, "/session/%s/execute", elem.parent.id)
+	if err != nil {
+		return nil, err
+	}
+	if attrs == nil {
+		return nil, nullValueError("/session/%s/execute", elem.parent.id)
+	}
+	return attrs, nil
+}
@@ -0,0 +1,116 @@
+package selenium
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// blendedAttributes are the values of the attributes of an input, after
+// typing into it, that legacy drivers return and that getAttributeAtom
+// computes.
+var blendedAttributes = map[string]interface{}{
+	"value":    "typed",
+	"class":    "wide field",
+	"checked":  nil,
+	"disabled": "false",
+	"required": true,
+	"hidden":   "true",
+	"missing":  nil,
+}
+
+func TestGetAttributeOrProperty(t *testing.T) {
+	for _, w3c := range []bool{true, false} {
+		var scripts, attributes int
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", JSONType)
+			path := strings.TrimPrefix(r.URL.Path, "/session/123")
+			var name string
+			switch {
+			case w3c && path == "/execute/sync":
+				scripts++
+				var params struct {
+					Script string
+					Args   []interface{}
+				}
+				json.NewDecoder(r.Body).Decode(&params)
+				if params.Script != getAttributeScript {
+					t.Errorf("unexpected script %q", params.Script)
+				}
+				name = params.Args[1].(string)
+				if isBoolean := params.Args[2].(bool); isBoolean != booleanAttributes[name] {
+					t.Errorf("script argument isBoolean for %q = %t", name, isBoolean)
+				}
+			case !w3c && strings.HasPrefix(path, "/element/e1/attribute/"):
+				attributes++
+				name = strings.TrimPrefix(path, "/element/e1/attribute/")
+			default:
+				t.Errorf("w3c=%t: unexpected request %s %s", w3c, r.Method, r.URL.Path)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": blendedAttributes[name]})
+		}))
+		wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+		elem := &remoteWE{parent: wd, id: "e1"}
+
+		for _, tc := range []struct {
+			name, want string
+		}{
+			{"value", "typed"},
+			{"class", "wide field"},
+			{"checked", ""},
+			{"disabled", ""},
+			{"required", "true"},
+			{"hidden", "true"},
+		} {
+			if got, err := elem.GetAttributeOrProperty(tc.name); err != nil || got != tc.want {
+				t.Errorf("w3c=%t: GetAttributeOrProperty(%q) = %q, %v; want %q, nil", w3c, tc.name, got, err, tc.want)
+			}
+		}
+		if _, err := elem.GetAttributeOrProperty("missing"); err == nil {
+			t.Errorf("w3c=%t: GetAttributeOrProperty() for a missing attribute returned no error", w3c)
+		}
+		if w3c && (scripts == 0 || attributes != 0) || !w3c && (scripts != 0 || attributes == 0) {
+			t.Errorf("w3c=%t: sent %d scripts and %d attribute commands", w3c, scripts, attributes)
+		}
+		s.Close()
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	var value interface{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		var params struct {
+			Script string
+			Args   []map[string]string
+		}
+		json.NewDecoder(r.Body).Decode(&params)
+		if r.URL.Path != "/session/123/execute/sync" || params.Script != attributesScript {
+			t.Errorf("unexpected request %s %s: %q", r.Method, r.URL.Path, params.Script)
+		}
+		if len(params.Args) != 1 || params.Args[0][webElementIdentifier] != "e1" {
+			t.Errorf("script arguments = %v, want the element", params.Args)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"value": value})
+	}))
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "e1"}
+
+	value = map[string]string{"id": "icon", "xlink:href": "#shape"}
+	if got, err := elem.Attributes(); err != nil || !reflect.DeepEqual(got, value) {
+		t.Errorf("Attributes() = %v, %v; want %v, nil", got, err, value)
+	}
+	value = map[string]string{}
+	if got, err := elem.Attributes(); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("Attributes() of an element without attributes = %#v, %v; want an empty map, nil", got, err)
+	}
+	value = nil
+	if _, err := elem.Attributes(); err == nil {
+		t.Errorf("Attributes() returned no error for a null value")
+	}
+}
@@ -0,0 +1,101 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultMaxReauthsPerHour is the number of times per hour that the guard
+// set with WebDriver.SetAuthGuard re-authenticates, unless changed with
+// WebDriver.SetAuthGuardLimits.
+const DefaultMaxReauthsPerHour = 10
+
+// ErrReauthLimit is returned by the commands that find the session logged
+// out after the guard set with WebDriver.SetAuthGuard has re-authenticated as
+// many times in the last hour as its limit allows.
+var ErrReauthLimit = errors.New("selenium: the session is logged out and the re-authentication limit is reached")
+
+// authGuard detects that the session was logged out and re-authenticates.
+type authGuard struct {
+	detect func(url string) bool
+	reauth func(wd WebDriver) error
+
+	// checkEvery, if positive, is the number of commands after which the URL
+	// is checked before a command; commands counts them.
+	checkEvery int
+	commands   int
+	maxPerHour int
+	// reauths holds the times of the re-authentications in the last hour.
+	reauths []time.Time
+	// active is set while the guard checks the URL or re-authenticates, so
+	// that the commands it sends do not trigger it.
+	active bool
+}
+
+func (wd *remoteWD) SetAuthGuard(detect func(url string) bool, reauth func(wd WebDriver) error) {
+	if detect == nil || reauth == nil {
+		wd.authGuard = nil
+		return
+	}
+	wd.authGuard = &authGuard{
+		detect:     detect,
+		reauth:     reauth,
+		maxPerHour: DefaultMaxReauthsPerHour,
+	}
+}
+
+func (wd *remoteWD) SetAuthGuardLimits(checkEvery, maxPerHour int) {
+	if wd.authGuard == nil {
+		return
+	}
+	wd.authGuard.checkEvery = checkEvery
+	wd.authGuard.maxPerHour = maxPerHour
+}
+
+// checkAuth re-authenticates if the current URL shows that the session was
+// logged out, and reports whether it did.
+func (wd *remoteWD) checkAuth() (bool, error) {
+	g := wd.authGuard
+	if g == nil || g.active {
+		return false, nil
+	}
+	g.active = true
+	defer func() { g.active = false }()
+
+	url, err := wd.CurrentURL()
+	if err != nil {
+		return false, err
+	}
+	if !g.detect(url) {
+		return false, nil
+	}
+
+	hourAgo := time.Now().Add(-time.Hour)
+	for len(g.reauths) > 0 && g.reauths[0].Before(hourAgo) {
+		g.reauths = g.reauths[1:]
+	}
+	if len(g.reauths) >= g.maxPerHour {
+		return false, ErrReauthLimit
+	}
+	g.reauths = append(g.reauths, time.Now())
+	if err := g.reauth(wd); err != nil {
+		return false, fmt.Errorf("re-authenticating after being logged out at %s: %v", url, err)
+	}
+	return true, nil
+}
+
+// checkAuthBeforeCommand runs checkAuth before every checkEvery-th command,
+// if enabled.
+func (wd *remoteWD) checkAuthBeforeCommand() error {
+	g := wd.authGuard
+	if g == nil || g.active || g.checkEvery <= 0 {
+		return nil
+	}
+	g.commands++
+	if g.commands%g.checkEvery != 0 {
+		return nil
+	}
+	_, err := wd.checkAuth()
+	return err
+}
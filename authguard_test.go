@@ -0,0 +1,101 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// authSite emulates a remote end browsing a site whose login expires after
+// a number of navigations, after which it redirects every page to /login.
+type authSite struct {
+	expireAfter int
+	navigations int
+	loggedIn    bool
+	current     string
+	visited     []string
+}
+
+func (s *authSite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", JSONType)
+	reply := func(v interface{}) {
+		b, _ := json.Marshal(v)
+		fmt.Fprintf(w, `{"value":%s}`, b)
+	}
+	switch r.Method + " " + r.URL.Path {
+	case "POST /session/123/url":
+		var params struct{ URL string }
+		json.NewDecoder(r.Body).Decode(&params)
+		switch {
+		case params.URL == "/do-login":
+			s.loggedIn, s.navigations, s.current = true, 0, "/home"
+		case s.loggedIn && s.navigations < s.expireAfter:
+			s.navigations++
+			s.current = params.URL
+		default:
+			s.loggedIn, s.current = false, "/login"
+		}
+		s.visited = append(s.visited, s.current)
+		reply(nil)
+	case "GET /session/123/url":
+		reply(s.current)
+	case "GET /session/123/title":
+		reply("Title of " + s.current)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestAuthGuard(t *testing.T) {
+	site := &authSite{expireAfter: 2, loggedIn: true}
+	s := httptest.NewServer(site)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	reauths := 0
+	wd.SetAuthGuard(func(url string) bool {
+		return strings.HasPrefix(url, "/login")
+	}, func(wd WebDriver) error {
+		reauths++
+		return wd.Get("/do-login")
+	})
+
+	for _, page := range []string{"/a", "/b", "/c"} {
+		if err := wd.Get(page); err != nil {
+			t.Fatalf("wd.Get(%q) returned error: %v", page, err)
+		}
+		if site.current != page {
+			t.Fatalf("after wd.Get(%q), the current page is %q", page, site.current)
+		}
+	}
+	if want := "/a /b /login /home /c"; reauths != 1 || strings.Join(site.visited, " ") != want {
+		t.Errorf("the guard re-authenticated %d times, visiting %q; want once, visiting %q", reauths, site.visited, want)
+	}
+
+	// The logout is detected before commands.
+	wd.SetAuthGuardLimits(1, DefaultMaxReauthsPerHour)
+	site.loggedIn, site.current = false, "/login"
+	if title, err := wd.Title(); err != nil || title != "Title of /home" {
+		t.Errorf("wd.Title() after the login expired = %q, %v; want the title of /home", title, err)
+	}
+	if reauths != 2 {
+		t.Errorf("the guard re-authenticated %d times, want 2", reauths)
+	}
+
+	wd.SetAuthGuardLimits(0, 2)
+	site.loggedIn = false
+	if err := wd.Get("/d"); err != ErrReauthLimit {
+		t.Errorf("wd.Get() after reaching the limit returned error %v, want ErrReauthLimit", err)
+	}
+	if reauths != 2 {
+		t.Errorf("the guard re-authenticated %d times, want no more than the limit of 2", reauths)
+	}
+
+	wd.SetAuthGuard(nil, nil)
+	if err := wd.Get("/e"); err != nil || site.current != "/login" {
+		t.Errorf("wd.Get() without guard = %v and went to %q; want nil and /login", err, site.current)
+	}
+}
@@ -0,0 +1,113 @@
+package selenium
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/net/websocket"
+)
+
+// ErrNoBiDi is returned by the methods that use WebDriver BiDi if the session
+// has no BiDi WebSocket URL, because it was not created with the capability
+// set by Capabilities.EnableBiDi or the remote end does not support BiDi.
+var ErrNoBiDi = errors.New("selenium: the session has no WebDriver BiDi WebSocket URL")
+
+// EnableBiDi sets the "webSocketUrl" capability, with which remote ends that
+// support WebDriver BiDi, such as geckodriver, return the URL of the session's
+// BiDi WebSocket when they create the session. It is required by
+// WebDriver.AttachConsole.
+func (c Capabilities) EnableBiDi() {
+	c["webSocketUrl"] = true
+}
+
+// webSocketURL returns the URL of the session's BiDi WebSocket.
+func (wd *remoteWD) webSocketURL() (string, error) {
+	url, _ := wd.negotiated["webSocketUrl"].(string)
+	if url == "" {
+		return "", ErrNoBiDi
+	}
+	return url, nil
+}
+
+// bidiConn is a connection to the BiDi WebSocket of a session. It is not safe
+// for concurrent use, except for Close. The standard library has no WebSocket
+// client, so it uses golang.org/x/net/websocket, from the module that package
+// fakedriver already requires for HTML parsing.
+type bidiConn struct {
+	ws     *websocket.Conn
+	lastID int
+}
+
+// bidiMessage is a message received over a BiDi connection: the reply to the
+// command with the ID, or an event.
+type bidiMessage struct {
+	ID   *int   `json:"id"`
+	Type string `json:"type"`
+	// Method and Params are those of an event.
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	// Error and Message are those of a failed command.
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+func dialBiDi(ctx context.Context, url string) (*bidiConn, error) {
+	config, err := websocket.NewConfig(url, "http://localhost/")
+	if err != nil {
+		return nil, err
+	}
+	ws, err := config.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the BiDi WebSocket at %s: %v", url, err)
+	}
+	return &bidiConn{ws: ws}, nil
+}
+
+// command sends the command and waits for its reply. The events received
+// before the reply are passed to onEvent.
+func (c *bidiConn) command(method string, params interface{}, onEvent func(*bidiMessage)) (json.RawMessage, error) {
+	c.lastID++
+	id := c.lastID
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	if err := websocket.JSON.Send(c.ws, map[string]interface{}{
+		"id":     id,
+		"method": method,
+		"params": params,
+	}); err != nil {
+		return nil, err
+	}
+	for {
+		msg, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case msg.ID == nil:
+			onEvent(msg)
+		case *msg.ID != id:
+			// The reply to an abandoned command.
+		case msg.Type == "error" || msg.Error != "":
+			return nil, fmt.Errorf("BiDi command %s: %s: %s", method, msg.Error, msg.Message)
+		default:
+			return msg.Result, nil
+		}
+	}
+}
+
+// receive returns the next message received.
+func (c *bidiConn) receive() (*bidiMessage, error) {
+	msg := new(bidiMessage)
+	if err := websocket.JSON.Receive(c.ws, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (c *bidiConn) Close() error {
+	return c.ws.Close()
+}
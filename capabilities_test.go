@@ -0,0 +1,134 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/tebeka/selenium/chrome"
+)
+
+// randomJSONValue returns a random value composed of the types produced by
+// decoding JSON into an interface{}.
+func randomJSONValue(r *rand.Rand, depth int) interface{} {
+	n := 6
+	if depth <= 0 {
+		n = 4
+	}
+	switch r.Intn(n) {
+	case 0:
+		return nil
+	case 1:
+		return r.Intn(2) == 0
+	case 2:
+		return r.NormFloat64() * 1000
+	case 3:
+		return fmt.Sprintf("s<%d>\"&\n", r.Intn(100))
+	case 4:
+		l := make([]interface{}, r.Intn(4))
+		for i := range l {
+			l[i] = randomJSONValue(r, depth-1)
+		}
+		return l
+	default:
+		m := make(map[string]interface{})
+		for i := r.Intn(5); i > 0; i-- {
+			m[fmt.Sprintf("k%d", r.Intn(20))] = randomJSONValue(r, depth-1)
+		}
+		return m
+	}
+}
+
+func TestCanonicalJSONIsStable(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		caps := Capabilities{}
+		for j := r.Intn(6); j > 0; j-- {
+			caps[fmt.Sprintf("cap%d", j)] = randomJSONValue(r, 3)
+		}
+		first, err := caps.CanonicalJSON()
+		if err != nil {
+			t.Fatalf("caps.CanonicalJSON() returned error: %v", err)
+		}
+		second, err := caps.CanonicalJSON()
+		if err != nil {
+			t.Fatalf("caps.CanonicalJSON() returned error: %v", err)
+		}
+		if string(first) != string(second) {
+			t.Fatalf("caps.CanonicalJSON() is not stable:\n%s\n%s", first, second)
+		}
+
+		// Canonicalizing the canonical form must be a no-op.
+		var decoded Capabilities
+		if err := json.Unmarshal(first, &decoded); err != nil {
+			t.Fatalf("json.Unmarshal(%s) returned error: %v", first, err)
+		}
+		third, err := decoded.CanonicalJSON()
+		if err != nil {
+			t.Fatalf("decoded.CanonicalJSON() returned error: %v", err)
+		}
+		if string(first) != string(third) {
+			t.Fatalf("canonical form is not a fixed point:\n%s\n%s", first, third)
+		}
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		caps Capabilities
+		want string
+	}{
+		{Capabilities{}, `{}`},
+		{
+			Capabilities{"b": 1.0, "a": []interface{}{3, 1e0, 2.5, -0.0}},
+			`{"a":[3,1,2.5,0],"b":1}`,
+		},
+		{
+			Capabilities{"z": map[string]interface{}{"y": "<&>", "x": nil}},
+			`{"z":{"x":null,"y":"<&>"}}`,
+		},
+		{
+			Capabilities{"big": 1e300, "small": 1.5e-7},
+			`{"big":1e+300,"small":1.5e-07}`,
+		},
+	}
+	for _, tc := range tests {
+		got, err := tc.caps.CanonicalJSON()
+		if err != nil {
+			t.Fatalf("%+v.CanonicalJSON() returned error: %v", tc.caps, err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("%+v.CanonicalJSON() = %s, want %s", tc.caps, got, tc.want)
+		}
+	}
+}
+
+func TestFingerprintMatchesStructAndMapVendorBlocks(t *testing.T) {
+	fromStruct := Capabilities{"browserName": "chrome"}
+	fromStruct.AddChrome(chrome.Capabilities{
+		Args: []string{"--headless", "--no-sandbox"},
+		Path: "/usr/bin/chrome",
+	})
+	fromMap := Capabilities{
+		chrome.CapabilitiesKey: map[string]interface{}{
+			"binary": "/usr/bin/chrome",
+			"args":   []interface{}{"--headless", "--no-sandbox"},
+		},
+		"browserName": "chrome",
+	}
+	if a, b := fromStruct.Fingerprint(), fromMap.Fingerprint(); a != b || a == "" {
+		t.Errorf("Fingerprint() of equivalent capabilities differ: %q != %q", a, b)
+	}
+
+	reordered := Capabilities{
+		"browserName": "chrome",
+		chrome.CapabilitiesKey: map[string]interface{}{
+			"binary": "/usr/bin/chrome",
+			"args":   []interface{}{"--no-sandbox", "--headless"},
+		},
+	}
+	if fromMap.Fingerprint() == reordered.Fingerprint() {
+		t.Error("Fingerprint() ignores the order of array elements")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"encoding/binary"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/tebeka/selenium/internal/zip"
 )
@@ -230,3 +231,82 @@ func NewExtensionWithKey(basePath string, key *rsa.PrivateKey) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+// noisyPrefs are the preferences set by DisableNoisyFeatures.
+var noisyPrefs = map[string]interface{}{
+	// Do not offer to save the passwords typed in forms.
+	"credentials_enable_service": false,
+	// Disable the password manager, which also offers to save passwords on
+	// older versions.
+	"profile.password_manager_enabled": false,
+}
+
+// noisyArgs are the switches set by DisableNoisyFeatures.
+var noisyArgs = []string{
+	// Skip the first run experience, such as the welcome page and the prompt
+	// to sign in.
+	"--no-first-run",
+	// Do not prompt to make Chrome the default browser.
+	"--no-default-browser-check",
+}
+
+// noisyFeatures are the features that DisableNoisyFeatures adds to the
+// --disable-features switch.
+var noisyFeatures = []string{
+	// Do not offer to translate pages in other languages.
+	"Translate",
+}
+
+// DisableNoisyFeatures sets the preferences and switches that keep Chrome
+// from showing native bubbles and prompts, such as the offer to save a
+// password or to translate the page. WebDriver cannot dismiss them, and they
+// may take the focus from the page, e.g. while keys are being sent.
+//
+// Preferences and switches that are already set are kept. The features are
+// added to the --disable-features switch, if any, since Chrome only honors
+// the last one.
+func (c *Capabilities) DisableNoisyFeatures() {
+	if c.Prefs == nil {
+		c.Prefs = make(map[string]interface{})
+	}
+	for k, v := range noisyPrefs {
+		if _, ok := c.Prefs[k]; !ok {
+			c.Prefs[k] = v
+		}
+	}
+
+	for _, arg := range noisyArgs {
+		if !hasArg(c.Args, arg) {
+			c.Args = append(c.Args, arg)
+		}
+	}
+
+	const disableFeatures = "--disable-features="
+	i := len(c.Args) - 1
+	for ; i >= 0; i-- {
+		if strings.HasPrefix(c.Args[i], disableFeatures) {
+			break
+		}
+	}
+	if i < 0 {
+		c.Args = append(c.Args, disableFeatures+strings.Join(noisyFeatures, ","))
+		return
+	}
+	features := strings.Split(strings.TrimPrefix(c.Args[i], disableFeatures), ",")
+	for _, f := range noisyFeatures {
+		if !hasArg(features, f) {
+			features = append(features, f)
+		}
+	}
+	c.Args[i] = disableFeatures + strings.Join(features, ",")
+}
+
+// hasArg reports whether args contains arg.
+func hasArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
@@ -15,3 +15,37 @@ func TestEmptyCapabilities(t *testing.T) {
 		t.Fatalf("json.Marshal(Capabilities{}) = %q, want %q", got, want)
 	}
 }
+
+func TestDisableNoisyFeatures(t *testing.T) {
+	for _, tc := range []struct {
+		caps Capabilities
+		want string
+	}{
+		{
+			caps: Capabilities{},
+			want: `{"args":["--no-first-run","--no-default-browser-check","--disable-features=Translate"],` +
+				`"prefs":{"credentials_enable_service":false,"profile.password_manager_enabled":false}}`,
+		},
+		{
+			// Existing switches and preferences are kept, and the features are
+			// added to the last --disable-features switch.
+			caps: Capabilities{
+				Args:  []string{"--no-sandbox", "--no-first-run", "--disable-features=A", "--disable-features=B"},
+				Prefs: map[string]interface{}{"credentials_enable_service": true},
+			},
+			want: `{"args":["--no-sandbox","--no-first-run","--disable-features=A","--disable-features=B,Translate","--no-default-browser-check"],` +
+				`"prefs":{"credentials_enable_service":true,"profile.password_manager_enabled":false}}`,
+		},
+	} {
+		tc.caps.DisableNoisyFeatures()
+		// Calling it again changes nothing.
+		tc.caps.DisableNoisyFeatures()
+		data, err := json.Marshal(tc.caps)
+		if err != nil {
+			t.Fatalf("json.Marshal() returned error: %v", err)
+		}
+		if got := string(data); got != tc.want {
+			t.Errorf("DisableNoisyFeatures() set the capabilities\n%s\nwant\n%s", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ClickRetryError is returned by WebElement.Click if the click was still
+// intercepted by another element after the attempts set with
+// WebDriver.SetClickRetry. It wraps the error of the last attempt.
+type ClickRetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *ClickRetryError) Error() string {
+	return fmt.Sprintf("click intercepted after %d attempts: %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns the error of the last attempt.
+func (e *ClickRetryError) Unwrap() error {
+	return e.Err
+}
+
+// isClickIntercepted returns true if err indicates that another element, e.g.
+// a toast or a sticky header, would receive the click.
+func isClickIntercepted(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Err == "element click intercepted"
+}
+
+// scrollToCenterScript scrolls the element passed as the first argument to the
+// center of the viewport, away from sticky headers and footers.
+const scrollToCenterScript = `arguments[0].scrollIntoView({block: 'center', inline: 'center'});`
+
+func (wd *remoteWD) SetClickRetry(attempts int, interval time.Duration) {
+	wd.clickRetry = attempts
+	wd.clickRetryInterval = interval
+}
+
+// clickWithRetry sends the click command, and, if the click is intercepted,
+// scrolls the element to the center of the viewport and sends it again, as
+// set with SetClickRetry.
+func (elem *remoteWE) clickWithRetry() error {
+	wd := elem.parent
+	err := elem.voidCommand("/click", nil)
+	attempts := 1
+	for ; attempts < wd.clickRetry && isClickIntercepted(err); attempts++ {
+		time.Sleep(wd.clickRetryInterval)
+		if _, err := wd.ExecuteScriptRaw(scrollToCenterScript, []interface{}{elem}); err != nil {
+			debugLog("scrolling %s to the center failed: %v", elem, err)
+		}
+		err = elem.voidCommand("/click", nil)
+	}
+	if attempts > 1 && isClickIntercepted(err) {
+		return &ClickRetryError{Attempts: attempts, Err: err}
+	}
+	return err
+}
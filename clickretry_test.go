@@ -0,0 +1,93 @@
+package selenium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// clickServer fails the first clicks on the element e1 with the given errors,
+// and counts the clicks and the scripts executed.
+type clickServer struct {
+	failures        []string
+	clicks, scripts int
+}
+
+func (s *clickServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", JSONType)
+	switch r.Method + " " + r.URL.Path {
+	case "POST /session/123/element/e1/click":
+		s.clicks++
+		if s.clicks <= len(s.failures) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"value":{"error":%q,"message":"Other element would receive the click"}}`, s.failures[s.clicks-1])
+			return
+		}
+	case "POST /session/123/execute/sync":
+		s.scripts++
+		var params struct{ Script string }
+		json.NewDecoder(r.Body).Decode(&params)
+		if params.Script != scrollToCenterScript {
+			return
+		}
+	default:
+		return
+	}
+	fmt.Fprint(w, `{"value":null}`)
+}
+
+func TestClickRetry(t *testing.T) {
+	const intercepted = "element click intercepted"
+	for _, tc := range []struct {
+		name     string
+		failures []string
+		clicks   int
+		wantErr  bool
+	}{
+		{"Succeeds", []string{intercepted, intercepted}, 3, false},
+		{"GivesUp", []string{intercepted, intercepted, intercepted, intercepted}, 3, true},
+		{"OtherError", []string{"element not interactable"}, 1, true},
+		{"Stale", []string{"stale element reference"}, 1, true},
+	} {
+		s := &clickServer{failures: tc.failures}
+		server := httptest.NewServer(s)
+		wd := &remoteWD{id: "123", urlPrefix: server.URL, w3cCompatible: true}
+		wd.SetClickRetry(3, time.Millisecond)
+		err := (&remoteWE{parent: wd, id: "e1"}).Click()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: Click() returned %v, want error %t", tc.name, err, tc.wantErr)
+		}
+		if s.clicks != tc.clicks || s.scripts != tc.clicks-1 {
+			t.Errorf("%s: Click() sent %d clicks and %d scripts, want %d and %d", tc.name, s.clicks, s.scripts, tc.clicks, tc.clicks-1)
+		}
+		server.Close()
+	}
+}
+
+func TestClickRetryGivesUpWithTheError(t *testing.T) {
+	s := &clickServer{failures: []string{"element click intercepted", "element click intercepted"}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	wd := &remoteWD{id: "123", urlPrefix: server.URL, w3cCompatible: true}
+	wd.SetClickRetry(2, 0)
+	err := (&remoteWE{parent: wd, id: "e1"}).Click()
+	var retryErr *ClickRetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 2 {
+		t.Fatalf("Click() returned %v, want a *ClickRetryError after 2 attempts", err)
+	}
+	if !isClickIntercepted(err) {
+		t.Errorf("Click() returned %v, want it to wrap the click intercepted error", err)
+	}
+
+	// Without retries, the error is returned as is.
+	s.clicks = 0
+	wd.SetClickRetry(0, 0)
+	err = (&remoteWE{parent: wd, id: "e1"}).Click()
+	if !isClickIntercepted(err) || errors.As(err, &retryErr) || s.clicks != 1 {
+		t.Errorf("Click() without retries returned %v after %d clicks, want the click intercepted error after 1", err, s.clicks)
+	}
+}
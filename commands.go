@@ -0,0 +1,83 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+func (wd *remoteWD) ExecuteCommand(method, path string, params interface{}) (json.RawMessage, error) {
+	var data []byte
+	if method != "POST" && params != nil {
+		// Only POST commands have a body, and the protocol defines no query
+		// parameters.
+		return nil, fmt.Errorf("%s %s: params are only sent with POST requests", method, path)
+	}
+	if method == "POST" {
+		if params == nil {
+			params = make(map[string]interface{})
+		}
+		var err error
+		if data, err = json.Marshal(params); err != nil {
+			return nil, err
+		}
+	}
+	return wd.execute(method, wd.urlPrefix+path, data)
+}
+
+// GetValue sends a GET request for the path, formatted from pathTemplate and
+// args as with fmt.Sprintf, with WebDriver.ExecuteCommand, and returns the
+// value of the response decoded into a T. A null value is an error, unless T
+// is a pointer, slice, map or interface type, whose zero value is nil.
+//
+// It is meant for commands that the WebDriver interface does not cover, such
+// as the vendor extensions of a remote end:
+//
+//	locked, err := selenium.GetValue[bool](wd, "/session/%s/appium/device/is_locked", wd.SessionID())
+func GetValue[T any](wd WebDriver, pathTemplate string, args ...interface{}) (T, error) {
+	response, err := wd.ExecuteCommand("GET", fmt.Sprintf(pathTemplate, args...), nil)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return decodeValue[T](response, wd.StrictDecoding(), pathTemplate, args...)
+}
+
+// PostValue is like GetValue, but sends a POST request with the params
+// encoded as JSON, or an empty object if they are nil.
+func PostValue[T any](wd WebDriver, pathTemplate string, params interface{}, args ...interface{}) (T, error) {
+	response, err := wd.ExecuteCommand("POST", fmt.Sprintf(pathTemplate, args...), params)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return decodeValue[T](response, wd.StrictDecoding(), pathTemplate, args...)
+}
+
+// decodeValue decodes the value of the response to the command sent to the
+// URL formatted from urlTemplate and args, with decodeStrict if strict is set.
+func decodeValue[T any](response []byte, strict bool, urlTemplate string, args ...interface{}) (T, error) {
+	var zero T
+	reply := new(struct{ Value json.RawMessage })
+	if err := json.Unmarshal(response, reply); err != nil {
+		return zero, err
+	}
+	if len(reply.Value) == 0 || string(reply.Value) == "null" {
+		switch reflect.TypeOf(&zero).Elem().Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+			return zero, nil
+		}
+		return zero, nullValueError(urlTemplate, args...)
+	}
+	var v T
+	if !strict {
+		if err := json.Unmarshal(reply.Value, &v); err != nil {
+			return zero, err
+		}
+		return v, nil
+	}
+	if err := decodeStrict(reply.Value, &v); err != nil {
+		return zero, &DecodeError{Command: fmt.Sprintf(urlTemplate, args...), Value: reply.Value, Err: err}
+	}
+	return v, nil
+}
@@ -0,0 +1,75 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetValue(t *testing.T) {
+	var posted map[string]int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /session/123/vendor/name":
+			fmt.Fprint(w, `{"value":"pixel"}`)
+		case "GET /session/123/vendor/null":
+			fmt.Fprint(w, `{"value":null}`)
+		case "GET /session/123/vendor/my file":
+			fmt.Fprintf(w, `{"value":%q}`, r.URL.EscapedPath())
+		case "GET /session/123/vendor/sizes":
+			fmt.Fprint(w, `{"value":[{"width":1,"height":2}]}`)
+		case "POST /session/123/vendor/lock":
+			json.NewDecoder(r.Body).Decode(&posted)
+			fmt.Fprint(w, `{"value":null}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"unknown command","message":"not implemented"}}`)
+		}
+	}))
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	if name, err := GetValue[string](wd, "/session/%s/vendor/name", wd.SessionID()); err != nil || name != "pixel" {
+		t.Errorf("GetValue[string]() = %q, %v; want %q", name, err, "pixel")
+	}
+	if _, err := wd.ExecuteCommand("GET", "/session/123/vendor/name", map[string]int{"index": 1}); err == nil {
+		t.Errorf("ExecuteCommand() of a GET request with params returned nil error")
+	}
+	// Escapes in the path are sent as they are.
+	escaped := "/session/123/vendor/" + url.PathEscape("my file")
+	if raw, err := wd.ExecuteCommand("GET", escaped, nil); err != nil || !strings.Contains(string(raw), "my%20file") {
+		t.Errorf("ExecuteCommand(%q) = %s, %v; want the escaped path", escaped, raw, err)
+	}
+	if path, err := GetValue[string](wd, "/session/%s/vendor/%s", wd.SessionID(), url.PathEscape("my file")); err != nil || !strings.Contains(path, "my%20file") {
+		t.Errorf("GetValue[string]() of an escaped path = %q, %v; want the escaped path", path, err)
+	}
+	sizes, err := GetValue[[]Size](wd, "/session/%s/vendor/sizes", wd.SessionID())
+	if want := []Size{{Width: 1, Height: 2}}; err != nil || !reflect.DeepEqual(sizes, want) {
+		t.Errorf("GetValue[[]Size]() = %v, %v; want %v", sizes, err, want)
+	}
+	if _, err := GetValue[string](wd, "/session/%s/vendor/null", wd.SessionID()); err == nil || !strings.Contains(err.Error(), "/session/123/vendor/null") {
+		t.Errorf("GetValue[string]() of null returned error %v, want one naming the URL", err)
+	}
+	if _, err := GetValue[bool](wd, "/session/%s/vendor/null", wd.SessionID()); err == nil {
+		t.Errorf("GetValue[bool]() of null returned nil error")
+	}
+	if v, err := GetValue[[]string](wd, "/session/%s/vendor/null", wd.SessionID()); err != nil || v != nil {
+		t.Errorf("GetValue[[]string]() of null = %v, %v; want nil, nil", v, err)
+	}
+	if _, err := GetValue[string](wd, "/session/%s/vendor/missing", wd.SessionID()); !isUnknownCommand(err) {
+		t.Errorf("GetValue[string]() of an unknown command returned error %v, want unknown command", err)
+	}
+
+	if _, err := PostValue[interface{}](wd, "/session/%s/vendor/lock", map[string]int{"seconds": 5}, wd.SessionID()); err != nil {
+		t.Fatalf("PostValue() returned error: %v", err)
+	}
+	if posted["seconds"] != 5 {
+		t.Errorf("PostValue() sent %v, want the params", posted)
+	}
+}
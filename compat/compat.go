@@ -0,0 +1,513 @@
+// Package compat preserves the documented behavior of the legacy (JSON Wire)
+// protocol for code written against it, on top of sessions that use the W3C
+// WebDriver protocol.
+//
+// Wrap returns a WebDriver whose methods translate legacy semantics into
+// equivalent W3C calls, as listed in Translations. Each translation is logged
+// through Logf, so that remaining legacy usages can be found and migrated.
+// Behavior that cannot be emulated results in a *NotEmulatedError rather than
+// silently diverging from the legacy behavior.
+//
+// The wrapper should only be used with W3C sessions; on legacy sessions the
+// translations are unnecessary and, for offsets, incorrect.
+package compat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+// Translation describes a behavior that differs between the legacy and W3C
+// protocols, and how the wrapper preserves the legacy behavior.
+type Translation struct {
+	// Method is the affected method, e.g. "WebElement.MoveTo".
+	Method string
+	// Legacy describes the behavior of the method on legacy sessions.
+	Legacy string
+	// W3C describes the behavior of the method on W3C sessions.
+	W3C string
+	// Emulation describes how the wrapper preserves the legacy behavior. It is
+	// empty if the behavior cannot be emulated.
+	Emulation string
+}
+
+// Emulated returns whether the wrapper preserves the legacy behavior.
+func (t Translation) Emulated() bool {
+	return t.Emulation != ""
+}
+
+// Translations is the table of behaviors handled by the wrapper.
+var Translations = []Translation{
+	{
+		Method:    "WebElement.MoveTo",
+		Legacy:    "offsets are relative to the top-left corner of the element",
+		W3C:       "offsets are relative to the center of the element",
+		Emulation: "offsets are converted by subtracting half of the element's width and height, and the mouse is moved with a pointer action whose origin is the element, as with WebElement.HoverAt",
+	},
+	{
+		Method:    "WebDriver.SwitchWindow",
+		Legacy:    "accepts a window name or handle",
+		W3C:       "only accepts a window handle",
+		Emulation: "a name that is not a handle is resolved to the handle of the window whose window.name, or otherwise whose title, matches it",
+	},
+	{
+		Method:    "WebDriver.CloseWindow",
+		Legacy:    "closes the named window",
+		W3C:       "only the current window can be closed",
+		Emulation: "the name is resolved to a handle as for SwitchWindow; that window is switched to and closed, and the original window is switched back to if it was another",
+	},
+	{
+		Method:    "WebDriver.MaximizeWindow",
+		Legacy:    "accepts a window name or handle",
+		W3C:       "only accepts a window handle",
+		Emulation: "the name is resolved to a handle as for SwitchWindow",
+	},
+	{
+		Method:    "WebDriver.MinimizeWindow",
+		Legacy:    "not supported",
+		W3C:       "only accepts a window handle",
+		Emulation: "the name is resolved to a handle as for SwitchWindow",
+	},
+	{
+		Method:    "WebDriver.FullscreenWindow",
+		Legacy:    "not supported",
+		W3C:       "only accepts a window handle",
+		Emulation: "the name is resolved to a handle as for SwitchWindow",
+	},
+	{
+		Method:    "WebDriver.ResizeWindow",
+		Legacy:    "accepts a window name or handle",
+		W3C:       "only accepts a window handle",
+		Emulation: "the name is resolved to a handle as for SwitchWindow",
+	},
+	{
+		Method:    "WebDriver.WindowRect",
+		Legacy:    "accepts a window name or handle",
+		W3C:       "only accepts a window handle",
+		Emulation: "the name is resolved to a handle as for SwitchWindow",
+	},
+	{
+		Method:    "WebDriver.SetWindowRect",
+		Legacy:    "accepts a window name or handle",
+		W3C:       "only accepts a window handle",
+		Emulation: "the name is resolved to a handle as for SwitchWindow",
+	},
+	{
+		Method:    "WebDriver.GetWindowPosition",
+		Legacy:    "accepts a window name or handle",
+		W3C:       "only accepts a window handle",
+		Emulation: "the name is resolved to a handle as for SwitchWindow",
+	},
+	{
+		Method:    "WebDriver.SetWindowPosition",
+		Legacy:    "accepts a window name or handle",
+		W3C:       "only accepts a window handle",
+		Emulation: "the name is resolved to a handle as for SwitchWindow",
+	},
+	{
+		Method:    "WebDriver.SwitchFrame",
+		Legacy:    "a string selects a frame by its name or ID attribute",
+		W3C:       "a string selects a frame by its ID attribute only",
+		Emulation: "if no frame has the ID, the frame or iframe element with the name attribute is located and switched to",
+	},
+	{
+		Method:    "WebDriver.Click",
+		Legacy:    "clicks at the current mouse position via the /click endpoint",
+		W3C:       "the endpoint was removed in favor of actions",
+		Emulation: "the WebDriver performs the click with pointer actions",
+	},
+	{
+		Method: "WebDriver.DoubleClick",
+		Legacy: "double-clicks at the current mouse position via the /doubleclick endpoint",
+		W3C:    "the endpoint was removed in favor of actions",
+	},
+	{
+		Method: "WebDriver.ButtonDown",
+		Legacy: "presses the left mouse button via the /buttondown endpoint",
+		W3C:    "the endpoint was removed in favor of actions",
+	},
+	{
+		Method: "WebDriver.ButtonUp",
+		Legacy: "releases the left mouse button via the /buttonup endpoint",
+		W3C:    "the endpoint was removed in favor of actions",
+	},
+}
+
+// lookup returns the translation for the method.
+func lookup(method string) Translation {
+	for _, t := range Translations {
+		if t.Method == method {
+			return t
+		}
+	}
+	panic(fmt.Sprintf("compat: no translation for %s", method))
+}
+
+// Logf is called for each translation performed by a wrapped WebDriver. The
+// default writes to the standard logger.
+var Logf = log.Printf
+
+func logTranslation(method, detail string) {
+	t := lookup(method)
+	Logf("selenium/compat: %s: %s (%s)", method, t.Emulation, detail)
+}
+
+// NotEmulatedError is returned by a wrapped WebDriver when the remote end
+// rejects a call whose legacy behavior cannot be emulated.
+type NotEmulatedError struct {
+	Translation
+	// Err is the error returned by the remote end.
+	Err error
+}
+
+func (e *NotEmulatedError) Error() string {
+	return fmt.Sprintf("selenium/compat: %s cannot be emulated on W3C sessions: legacy behavior: %s; W3C: %s: %v", e.Method, e.Legacy, e.W3C, e.Err)
+}
+
+// isUnknownCommand returns whether err reports that the remote end does not
+// implement the command.
+func isUnknownCommand(err error) bool {
+	var e *selenium.Error
+	if errors.As(err, &e) {
+		return e.Err == "unknown command" || e.Err == "unknown method"
+	}
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "unknown command")
+}
+
+// notEmulated wraps err, if the remote end does not implement the method.
+func notEmulated(method string, err error) error {
+	if !isUnknownCommand(err) {
+		return err
+	}
+	return &NotEmulatedError{Translation: lookup(method), Err: err}
+}
+
+type driver struct {
+	selenium.WebDriver
+}
+
+// Wrap returns a WebDriver that preserves legacy semantics on top of the W3C
+// session of wd, according to Translations.
+func Wrap(wd selenium.WebDriver) selenium.WebDriver {
+	if d, ok := wd.(*driver); ok {
+		return d
+	}
+	return &driver{wd}
+}
+
+// resolveWindow returns the handle of the window identified by name, which
+// may be a handle, a window.name or a title. The current window is switched
+// back to even if resolving the name fails.
+func (d *driver) resolveWindow(method, name string) (handle string, err error) {
+	if name == "" {
+		return "", nil
+	}
+	handles, err := d.WebDriver.WindowHandles()
+	if err != nil {
+		return "", err
+	}
+	for _, h := range handles {
+		if h == name {
+			return h, nil
+		}
+	}
+
+	current, err := d.WebDriver.CurrentWindowHandle()
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if serr := d.WebDriver.SwitchWindow(current); serr != nil && err == nil {
+			handle, err = "", serr
+		}
+	}()
+	var found, byTitle string
+	for _, h := range handles {
+		if err := d.WebDriver.SwitchWindow(h); err != nil {
+			return "", err
+		}
+		windowName, err := d.WebDriver.ExecuteScript("return window.name;", nil)
+		if err != nil {
+			return "", err
+		}
+		if windowName == name {
+			found = h
+			break
+		}
+		if byTitle == "" {
+			if title, err := d.WebDriver.Title(); err == nil && title == name {
+				byTitle = h
+			}
+		}
+	}
+	switch {
+	case found != "":
+		logTranslation(method, fmt.Sprintf("window name %q is handle %q", name, found))
+		return found, nil
+	case byTitle != "":
+		logTranslation(method, fmt.Sprintf("window title %q is handle %q", name, byTitle))
+		return byTitle, nil
+	}
+	return "", fmt.Errorf("selenium/compat: %s: no window has the handle, name or title %q", method, name)
+}
+
+func (d *driver) SwitchWindow(name string) error {
+	handle, err := d.resolveWindow("WebDriver.SwitchWindow", name)
+	if err != nil {
+		return err
+	}
+	return d.WebDriver.SwitchWindow(handle)
+}
+
+func (d *driver) CloseWindow(name string) ([]string, error) {
+	handle, err := d.resolveWindow("WebDriver.CloseWindow", name)
+	if err != nil {
+		return nil, err
+	}
+	return d.WebDriver.CloseWindow(handle)
+}
+
+func (d *driver) MaximizeWindow(name string) error {
+	handle, err := d.resolveWindow("WebDriver.MaximizeWindow", name)
+	if err != nil {
+		return err
+	}
+	return d.WebDriver.MaximizeWindow(handle)
+}
+
+func (d *driver) MinimizeWindow(name string) error {
+	handle, err := d.resolveWindow("WebDriver.MinimizeWindow", name)
+	if err != nil {
+		return err
+	}
+	return d.WebDriver.MinimizeWindow(handle)
+}
+
+func (d *driver) FullscreenWindow(name string) (*selenium.Rect, error) {
+	handle, err := d.resolveWindow("WebDriver.FullscreenWindow", name)
+	if err != nil {
+		return nil, err
+	}
+	return d.WebDriver.FullscreenWindow(handle)
+}
+
+func (d *driver) ResizeWindow(name string, width, height int) error {
+	handle, err := d.resolveWindow("WebDriver.ResizeWindow", name)
+	if err != nil {
+		return err
+	}
+	return d.WebDriver.ResizeWindow(handle, width, height)
+}
+
+func (d *driver) WindowRect(name string) (*selenium.Rect, error) {
+	handle, err := d.resolveWindow("WebDriver.WindowRect", name)
+	if err != nil {
+		return nil, err
+	}
+	return d.WebDriver.WindowRect(handle)
+}
+
+func (d *driver) SetWindowRect(name string, r selenium.Rect) error {
+	handle, err := d.resolveWindow("WebDriver.SetWindowRect", name)
+	if err != nil {
+		return err
+	}
+	return d.WebDriver.SetWindowRect(handle, r)
+}
+
+func (d *driver) GetWindowPosition(name string) (*selenium.Point, error) {
+	handle, err := d.resolveWindow("WebDriver.GetWindowPosition", name)
+	if err != nil {
+		return nil, err
+	}
+	return d.WebDriver.GetWindowPosition(handle)
+}
+
+func (d *driver) SetWindowPosition(name string, x, y int) error {
+	handle, err := d.resolveWindow("WebDriver.SetWindowPosition", name)
+	if err != nil {
+		return err
+	}
+	return d.WebDriver.SetWindowPosition(handle, x, y)
+}
+
+func (d *driver) SwitchFrame(frame interface{}) error {
+	name, ok := frame.(string)
+	if !ok || name == "" {
+		return d.WebDriver.SwitchFrame(frame)
+	}
+	if _, err := d.WebDriver.FindElement(selenium.ByID, name); err == nil {
+		return d.WebDriver.SwitchFrame(name)
+	}
+	for _, tag := range []string{"iframe", "frame"} {
+		frames, err := d.WebDriver.FindElements(selenium.ByTagName, tag)
+		if err != nil {
+			return err
+		}
+		for _, f := range frames {
+			if n, err := f.GetAttribute("name"); err == nil && n == name {
+				logTranslation("WebDriver.SwitchFrame", fmt.Sprintf("frame name %q", name))
+				return d.WebDriver.SwitchFrame(f)
+			}
+		}
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		// The driver switches to the frame with the index.
+		return d.WebDriver.SwitchFrame(name)
+	}
+	return fmt.Errorf("selenium/compat: WebDriver.SwitchFrame: no frame has the ID or name %q", name)
+}
+
+func (d *driver) Click(button selenium.MouseButton) error {
+	return notEmulated("WebDriver.Click", d.WebDriver.Click(button))
+}
+
+func (d *driver) DoubleClick() error {
+	return notEmulated("WebDriver.DoubleClick", d.WebDriver.DoubleClick())
+}
+
+func (d *driver) ButtonDown() error {
+	return notEmulated("WebDriver.ButtonDown", d.WebDriver.ButtonDown())
+}
+
+func (d *driver) ButtonUp() error {
+	return notEmulated("WebDriver.ButtonUp", d.WebDriver.ButtonUp())
+}
+
+func (d *driver) FindElement(by, value string) (selenium.WebElement, error) {
+	return wrapElement(d.WebDriver.FindElement(by, value))
+}
+
+func (d *driver) FindElements(by, value string) ([]selenium.WebElement, error) {
+	return wrapElements(d.WebDriver.FindElements(by, value))
+}
+
+func (d *driver) Find(loc selenium.Locator) (selenium.WebElement, error) {
+	return wrapElement(d.WebDriver.Find(loc))
+}
+
+func (d *driver) FindAll(loc selenium.Locator) ([]selenium.WebElement, error) {
+	return wrapElements(d.WebDriver.FindAll(loc))
+}
+
+func (d *driver) FindElementRelative(rb *selenium.RelativeBy) (selenium.WebElement, error) {
+	return wrapElement(d.WebDriver.FindElementRelative(rb))
+}
+
+func (d *driver) FindElementsRelative(rb *selenium.RelativeBy) ([]selenium.WebElement, error) {
+	return wrapElements(d.WebDriver.FindElementsRelative(rb))
+}
+
+func (d *driver) FindElementWithTimeout(by, value string, timeout time.Duration) (selenium.WebElement, error) {
+	return wrapElement(d.WebDriver.FindElementWithTimeout(by, value, timeout))
+}
+
+func (d *driver) WaitForElementCount(by, value string, pred func(int) bool, timeout time.Duration) ([]selenium.WebElement, error) {
+	return wrapElements(d.WebDriver.WaitForElementCount(by, value, pred, timeout))
+}
+
+// wrapCondition passes the wrapper, rather than the wrapped driver, to the
+// condition.
+func (d *driver) wrapCondition(condition selenium.Condition) selenium.Condition {
+	return func(selenium.WebDriver) (bool, error) { return condition(d) }
+}
+
+func (d *driver) WaitWithTimeoutAndInterval(condition selenium.Condition, timeout, interval time.Duration) error {
+	return d.WebDriver.WaitWithTimeoutAndInterval(d.wrapCondition(condition), timeout, interval)
+}
+
+func (d *driver) WaitWithTimeout(condition selenium.Condition, timeout time.Duration) error {
+	return d.WebDriver.WaitWithTimeout(d.wrapCondition(condition), timeout)
+}
+
+func (d *driver) Wait(condition selenium.Condition) error {
+	return d.WebDriver.Wait(d.wrapCondition(condition))
+}
+
+func (d *driver) ActiveElement() (selenium.WebElement, error) {
+	return wrapElement(d.WebDriver.ActiveElement())
+}
+
+func (d *driver) DecodeElement(data []byte) (selenium.WebElement, error) {
+	return wrapElement(d.WebDriver.DecodeElement(data))
+}
+
+func (d *driver) DecodeElements(data []byte) ([]selenium.WebElement, error) {
+	return wrapElements(d.WebDriver.DecodeElements(data))
+}
+
+// element preserves the legacy semantics of WebElement methods.
+type element struct {
+	selenium.WebElement
+}
+
+func wrapElement(e selenium.WebElement, err error) (selenium.WebElement, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &element{e}, nil
+}
+
+func wrapElements(elems []selenium.WebElement, err error) ([]selenium.WebElement, error) {
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range elems {
+		elems[i] = &element{e}
+	}
+	return elems, nil
+}
+
+// MarshalJSON encodes the underlying element, so that wrapped elements can be
+// passed as script arguments.
+func (e *element) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.WebElement)
+}
+
+// Driver returns the driver of the element, wrapped.
+func (e *element) Driver() selenium.WebDriver {
+	return Wrap(e.WebElement.Driver())
+}
+
+func (e *element) MoveTo(xOffset, yOffset int) error {
+	size, err := e.WebElement.Size()
+	if err != nil {
+		return err
+	}
+	x, y := xOffset-size.Width/2, yOffset-size.Height/2
+	logTranslation("WebElement.MoveTo", fmt.Sprintf("(%d, %d) from the top-left corner is (%d, %d) from the center", xOffset, yOffset, x, y))
+	// WebElement.MoveTo sends the legacy /moveto endpoint, whose offsets are
+	// from the top-left corner and which W3C remote ends need not implement.
+	return e.WebElement.HoverAt(x, y)
+}
+
+func (e *element) FindElement(by, value string) (selenium.WebElement, error) {
+	return wrapElement(e.WebElement.FindElement(by, value))
+}
+
+func (e *element) FindElements(by, value string) ([]selenium.WebElement, error) {
+	return wrapElements(e.WebElement.FindElements(by, value))
+}
+
+func (e *element) Find(loc selenium.Locator) (selenium.WebElement, error) {
+	return wrapElement(e.WebElement.Find(loc))
+}
+
+func (e *element) FindAll(loc selenium.Locator) ([]selenium.WebElement, error) {
+	return wrapElements(e.WebElement.FindAll(loc))
+}
+
+func (e *element) WaitForElementCount(by, value string, pred func(int) bool, timeout time.Duration) ([]selenium.WebElement, error) {
+	return wrapElements(e.WebElement.WaitForElementCount(by, value, pred, timeout))
+}
+
+func (e *element) ScrollableAncestor() (selenium.WebElement, error) {
+	return wrapElement(e.WebElement.ScrollableAncestor())
+}
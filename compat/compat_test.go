@@ -0,0 +1,413 @@
+package compat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+type window struct {
+	handle, name, title string
+}
+
+// fakeDriver implements the subset of a W3C session used by the wrapper.
+type fakeDriver struct {
+	selenium.WebDriver
+
+	windows []window
+	current string
+	closed  []string
+	resized string
+
+	// frameIDs and frameNames describe the frames of the page.
+	frameIDs   map[string]bool
+	frameNames []string
+	switchedTo interface{}
+
+	legacyErr error
+	// scriptErrIn is the handle of a window in which scripts fail.
+	scriptErrIn string
+	// selectArgs are the arguments of the script that selects options.
+	selectArgs []interface{}
+}
+
+func (d *fakeDriver) WindowHandles() ([]string, error) {
+	var handles []string
+	for _, w := range d.windows {
+		handles = append(handles, w.handle)
+	}
+	return handles, nil
+}
+
+func (d *fakeDriver) CurrentWindowHandle() (string, error) {
+	return d.current, nil
+}
+
+func (d *fakeDriver) window() window {
+	for _, w := range d.windows {
+		if w.handle == d.current {
+			return w
+		}
+	}
+	return window{}
+}
+
+func (d *fakeDriver) SwitchWindow(handle string) error {
+	for _, w := range d.windows {
+		if w.handle == handle {
+			d.current = handle
+			return nil
+		}
+	}
+	return &selenium.Error{Err: "no such window", Message: handle}
+}
+
+func (d *fakeDriver) ExecuteScript(script string, args []interface{}) (interface{}, error) {
+	if strings.Contains(script, "option.selected = selected") {
+		d.selectArgs = args
+		return nil, nil
+	}
+	if script != "return window.name;" {
+		return nil, fmt.Errorf("unexpected script %q", script)
+	}
+	if d.current == d.scriptErrIn {
+		return nil, &selenium.Error{Err: "javascript error", Message: d.current}
+	}
+	return d.window().name, nil
+}
+
+func (d *fakeDriver) Title() (string, error) {
+	return d.window().title, nil
+}
+
+func (d *fakeDriver) CloseWindow(handle string) ([]string, error) {
+	d.closed = append(d.closed, handle)
+	var remaining []string
+	for _, w := range d.windows {
+		if w.handle != handle {
+			remaining = append(remaining, w.handle)
+		}
+	}
+	return remaining, nil
+}
+
+func (d *fakeDriver) ResizeWindow(handle string, width, height int) error {
+	d.resized = handle
+	return nil
+}
+
+func (d *fakeDriver) FindElement(by, value string) (selenium.WebElement, error) {
+	if by == selenium.ByID && d.frameIDs[value] {
+		return &fakeElement{id: value}, nil
+	}
+	return nil, &selenium.Error{Err: "no such element", Message: value}
+}
+
+func (d *fakeDriver) FindElements(by, value string) ([]selenium.WebElement, error) {
+	var elems []selenium.WebElement
+	if by == selenium.ByTagName && value == "iframe" {
+		for _, name := range d.frameNames {
+			elems = append(elems, &fakeElement{name: name, width: 100, height: 50})
+		}
+	}
+	return elems, nil
+}
+
+func (d *fakeDriver) WaitForElementCount(by, value string, pred func(int) bool, timeout time.Duration) ([]selenium.WebElement, error) {
+	return d.FindElements(by, value)
+}
+
+func (d *fakeDriver) SwitchFrame(frame interface{}) error {
+	d.switchedTo = frame
+	return nil
+}
+
+func (d *fakeDriver) DoubleClick() error {
+	return d.legacyErr
+}
+
+type fakeElement struct {
+	selenium.WebElement
+	id, name      string
+	width, height int
+	movedTo       [2]int
+}
+
+func (e *fakeElement) GetAttribute(name string) (string, error) {
+	return e.name, nil
+}
+
+func (e *fakeElement) Size() (*selenium.Size, error) {
+	return &selenium.Size{Width: e.width, Height: e.height}, nil
+}
+
+func (e *fakeElement) HoverAt(x, y int) error {
+	e.movedTo = [2]int{x, y}
+	return nil
+}
+
+func (e *fakeElement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"element-6066-11e4-a52e-4f735466cecf": e.name})
+}
+
+// fakeSelect is a hidden <select> with one option, which cannot be clicked.
+type fakeSelect struct {
+	selenium.WebElement
+	option *fakeOption
+}
+
+func (e *fakeSelect) TagName() (string, error) {
+	return "select", nil
+}
+
+func (e *fakeSelect) GetPropertyRaw(name string) (json.RawMessage, error) {
+	return json.RawMessage("false"), nil
+}
+
+func (e *fakeSelect) FindElements(by, value string) ([]selenium.WebElement, error) {
+	return []selenium.WebElement{e.option}, nil
+}
+
+type fakeOption struct {
+	selenium.WebElement
+	driver *fakeDriver
+}
+
+func (e *fakeOption) IsSelected() (bool, error) {
+	return false, nil
+}
+
+func (e *fakeOption) IsEnabled() (bool, error) {
+	return true, nil
+}
+
+func (e *fakeOption) Click() error {
+	return &selenium.Error{Err: "element not interactable", Message: "option"}
+}
+
+func (e *fakeOption) Driver() selenium.WebDriver {
+	return e.driver
+}
+
+func (e *fakeOption) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"element-6066-11e4-a52e-4f735466cecf": "option"})
+}
+
+// captureLog replaces Logf for the duration of the test.
+func captureLog(t *testing.T) *[]string {
+	var lines []string
+	old := Logf
+	Logf = func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	t.Cleanup(func() { Logf = old })
+	return &lines
+}
+
+func newFakeDriver() *fakeDriver {
+	return &fakeDriver{
+		windows: []window{
+			{handle: "h1", name: "", title: "Main"},
+			{handle: "h2", name: "popup", title: "Popup"},
+			{handle: "h3", name: "", title: "Help"},
+		},
+		current: "h1",
+	}
+}
+
+func TestTranslationsAreComplete(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, tr := range Translations {
+		if seen[tr.Method] {
+			t.Errorf("duplicate translation for %s", tr.Method)
+		}
+		seen[tr.Method] = true
+		if tr.Legacy == "" || tr.W3C == "" {
+			t.Errorf("translation for %s does not describe both behaviors", tr.Method)
+		}
+	}
+}
+
+func TestSwitchWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		want       string
+		wantLogged bool
+	}{
+		{name: "h3", want: "h3"},
+		{name: "popup", want: "h2", wantLogged: true},
+		{name: "Help", want: "h3", wantLogged: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logged := captureLog(t)
+			fd := newFakeDriver()
+			if err := Wrap(fd).SwitchWindow(tc.name); err != nil {
+				t.Fatalf("SwitchWindow(%q) returned error: %v", tc.name, err)
+			}
+			if fd.current != tc.want {
+				t.Errorf("SwitchWindow(%q) switched to %q, want %q", tc.name, fd.current, tc.want)
+			}
+			if got := len(*logged) > 0; got != tc.wantLogged {
+				t.Errorf("SwitchWindow(%q) logged a translation = %t, want %t", tc.name, got, tc.wantLogged)
+			}
+		})
+	}
+
+	fd := newFakeDriver()
+	if err := Wrap(fd).SwitchWindow("missing"); err == nil {
+		t.Errorf(`SwitchWindow("missing") returned nil error`)
+	}
+	if fd.current != "h1" {
+		t.Errorf(`SwitchWindow("missing") left the current window at %q, want "h1"`, fd.current)
+	}
+
+	fd = newFakeDriver()
+	fd.scriptErrIn = "h2"
+	if err := Wrap(fd).SwitchWindow("Help"); err == nil {
+		t.Errorf(`SwitchWindow("Help") returned nil error when a script failed`)
+	}
+	if fd.current != "h1" {
+		t.Errorf(`SwitchWindow("Help") left the current window at %q after an error, want "h1"`, fd.current)
+	}
+}
+
+func TestCloseWindow(t *testing.T) {
+	captureLog(t)
+	fd := newFakeDriver()
+	remaining, err := Wrap(fd).CloseWindow("popup")
+	if err != nil {
+		t.Fatalf(`CloseWindow("popup") returned error: %v`, err)
+	}
+	if len(remaining) != len(fd.windows)-1 {
+		t.Errorf(`CloseWindow("popup") returned the remaining windows %v`, remaining)
+	}
+	if len(fd.closed) != 1 || fd.closed[0] != "h2" {
+		t.Errorf(`CloseWindow("popup") closed %v, want [h2]`, fd.closed)
+	}
+	if fd.current != "h1" {
+		t.Errorf(`CloseWindow("popup") left the current window at %q, want "h1"`, fd.current)
+	}
+}
+
+func TestResizeWindow(t *testing.T) {
+	captureLog(t)
+	fd := newFakeDriver()
+	if err := Wrap(fd).ResizeWindow("Popup", 10, 10); err != nil {
+		t.Fatalf(`ResizeWindow("Popup") returned error: %v`, err)
+	}
+	if fd.resized != "h2" {
+		t.Errorf(`ResizeWindow("Popup") resized %q, want "h2"`, fd.resized)
+	}
+}
+
+func TestSwitchFrameByName(t *testing.T) {
+	captureLog(t)
+	fd := newFakeDriver()
+	fd.frameIDs = map[string]bool{"byid": true}
+	fd.frameNames = []string{"other", "content"}
+	wd := Wrap(fd)
+
+	if err := wd.SwitchFrame("byid"); err != nil {
+		t.Fatalf(`SwitchFrame("byid") returned error: %v`, err)
+	}
+	if fd.switchedTo != "byid" {
+		t.Errorf(`SwitchFrame("byid") switched to %v, want "byid"`, fd.switchedTo)
+	}
+
+	if err := wd.SwitchFrame("content"); err != nil {
+		t.Fatalf(`SwitchFrame("content") returned error: %v`, err)
+	}
+	if e, ok := fd.switchedTo.(*fakeElement); !ok || e.name != "content" {
+		t.Errorf(`SwitchFrame("content") switched to %v, want the frame named "content"`, fd.switchedTo)
+	}
+
+	if err := wd.SwitchFrame("missing"); err == nil {
+		t.Errorf(`SwitchFrame("missing") returned nil error`)
+	}
+}
+
+func TestMoveToConvertsOffsets(t *testing.T) {
+	logged := captureLog(t)
+	fd := newFakeDriver()
+	fd.frameNames = []string{"f"}
+	elems, err := Wrap(fd).FindElements(selenium.ByTagName, "iframe")
+	if err != nil {
+		t.Fatalf("FindElements() returned error: %v", err)
+	}
+	if err := elems[0].MoveTo(10, 5); err != nil {
+		t.Fatalf("MoveTo(10, 5) returned error: %v", err)
+	}
+	// The fake element is 100x50.
+	if got, want := elems[0].(*element).WebElement.(*fakeElement).movedTo, [2]int{-40, -20}; got != want {
+		t.Errorf("MoveTo(10, 5) moved to %v from the center, want %v", got, want)
+	}
+	if len(*logged) != 1 || !strings.Contains((*logged)[0], "WebElement.MoveTo") {
+		t.Errorf("MoveTo(10, 5) logged %q, want one WebElement.MoveTo translation", *logged)
+	}
+
+	b, err := json.Marshal(elems[0])
+	if err != nil {
+		t.Fatalf("json.Marshal(wrapped element) returned error: %v", err)
+	}
+	if !strings.Contains(string(b), `"f"`) {
+		t.Errorf("json.Marshal(wrapped element) = %s, want the underlying element's encoding", b)
+	}
+}
+
+func TestWaitForElementCountWrapsElements(t *testing.T) {
+	fd := newFakeDriver()
+	fd.frameNames = []string{"f", "g"}
+	elems, err := Wrap(fd).WaitForElementCount(selenium.ByTagName, "iframe", selenium.AtLeast(2), time.Second)
+	if err != nil {
+		t.Fatalf("WaitForElementCount() returned error: %v", err)
+	}
+	for i, e := range elems {
+		if _, ok := e.(*element); !ok {
+			t.Errorf("WaitForElementCount() element %d is a %T, want it wrapped", i, e)
+		}
+	}
+}
+
+func TestRemovedEndpointsFailLoudly(t *testing.T) {
+	fd := newFakeDriver()
+	fd.legacyErr = &selenium.Error{Err: "unknown command", Message: "POST /session/1/doubleclick"}
+	err := Wrap(fd).DoubleClick()
+	e, ok := err.(*NotEmulatedError)
+	if !ok {
+		t.Fatalf("DoubleClick() returned error %v, want a *NotEmulatedError", err)
+	}
+	if e.Method != "WebDriver.DoubleClick" || e.Emulated() {
+		t.Errorf("DoubleClick() returned error for %+v, want the non-emulated WebDriver.DoubleClick translation", e.Translation)
+	}
+
+	other := &selenium.Error{Err: "no such window"}
+	fd.legacyErr = other
+	if err := Wrap(fd).DoubleClick(); err != other {
+		t.Errorf("DoubleClick() returned error %v, want the remote error unchanged", err)
+	}
+}
+
+func TestSelectFallsBackToScriptOnWrappedElements(t *testing.T) {
+	fd := newFakeDriver()
+	s, err := selenium.NewSelect(&element{&fakeSelect{option: &fakeOption{driver: fd}}})
+	if err != nil {
+		t.Fatalf("NewSelect() returned error: %v", err)
+	}
+	if err := s.SelectByIndex(0); err != nil {
+		t.Fatalf("SelectByIndex(0) returned error: %v", err)
+	}
+	if len(fd.selectArgs) != 2 {
+		t.Fatalf("SelectByIndex(0) ran the script with arguments %v, want the option and true", fd.selectArgs)
+	}
+	if _, ok := fd.selectArgs[0].(*element); !ok {
+		t.Errorf("SelectByIndex(0) passed a %T to the script, want the wrapped option", fd.selectArgs[0])
+	}
+	if fd.selectArgs[1] != true {
+		t.Errorf("SelectByIndex(0) passed %v to the script, want true", fd.selectArgs[1])
+	}
+}
@@ -0,0 +1,75 @@
+package selenium
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// WithRequestCompression returns an option that gzips the bodies of POST
+// requests of at least minSize bytes, such as large scripts and their
+// arguments, which saves time on slow links to a remote grid. Selenium Grid 4
+// accepts compressed bodies; if the remote end of a session rejects one, the
+// request is sent again uncompressed and compression is disabled for the rest
+// of the session.
+func WithRequestCompression(minSize int) RemoteOption {
+	return func(wd *remoteWD) error {
+		if minSize <= 0 {
+			return fmt.Errorf("invalid minimum size for compression %d: must be positive", minSize)
+		}
+		wd.compressMinSize = minSize
+		return nil
+	}
+}
+
+// gzipBody returns data compressed with gzip.
+func gzipBody(data []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sendRequest sends a request to the remote end, compressing the body if
+// enabled with WithRequestCompression.
+func (wd *remoteWD) sendRequest(ctx context.Context, method, url string, body requestBody) (*http.Response, error) {
+	client := wd.httpClientOf()
+	if wd.compressMinSize <= 0 || wd.compressionRejected || method != "POST" || body.len() < int64(wd.compressMinSize) {
+		return doRequest(ctx, client, method, url, body, false)
+	}
+	response, err := doRequest(ctx, client, method, url, body, true)
+	if err != nil {
+		return nil, err
+	}
+	switch response.StatusCode {
+	case http.StatusUnsupportedMediaType, http.StatusBadRequest:
+	default:
+		return response, nil
+	}
+
+	// A remote end that does not decode the body cannot parse it. The W3C
+	// protocol also answers invalid arguments with 400 Bad Request, so in
+	// that case the compression is only deemed rejected if the uncompressed
+	// request is not.
+	unsupported := response.StatusCode == http.StatusUnsupportedMediaType
+	io.Copy(ioutil.Discard, response.Body)
+	response.Body.Close()
+	debugLog("the remote end answered a compressed request with %s; retrying uncompressed", response.Status)
+	response, err = doRequest(ctx, client, method, url, body, false)
+	if err != nil {
+		return nil, err
+	}
+	if unsupported || response.StatusCode != http.StatusBadRequest {
+		wd.compressionRejected = true
+	}
+	return response, nil
+}
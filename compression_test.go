@@ -0,0 +1,135 @@
+package selenium
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// compressionServer answers script executions. If acceptGzip is false, it
+// rejects compressed bodies with the given status. If bytesPerSecond is
+// positive, it reads request bodies at that rate, as a slow link would.
+type compressionServer struct {
+	acceptGzip     bool
+	rejectStatus   int
+	bytesPerSecond int
+
+	encodings []string
+}
+
+func (s *compressionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", JSONType)
+	wire, _ := ioutil.ReadAll(r.Body)
+	if s.bytesPerSecond > 0 {
+		time.Sleep(time.Duration(len(wire)) * time.Second / time.Duration(s.bytesPerSecond))
+	}
+	encoding := r.Header.Get("Content-Encoding")
+	s.encodings = append(s.encodings, encoding)
+	var body io.Reader = strings.NewReader(string(wire))
+	if encoding == "gzip" {
+		if !s.acceptGzip {
+			w.WriteHeader(s.rejectStatus)
+			fmt.Fprint(w, `{"value":{"error":"invalid argument","message":"cannot parse the body"}}`)
+			return
+		}
+		body, _ = gzip.NewReader(body)
+	}
+	var params struct{ Args []string }
+	if err := json.NewDecoder(body).Decode(&params); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"value":{"error":"invalid argument","message":%q}}`, err.Error())
+		return
+	}
+	if len(params.Args) == 1 && params.Args[0] == "invalid" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"value":{"error":"invalid argument","message":"invalid"}}`)
+		return
+	}
+	fmt.Fprintf(w, `{"value":%d}`, len(params.Args[0]))
+}
+
+func TestRequestCompression(t *testing.T) {
+	large := strings.Repeat("x", 2000)
+	for _, tc := range []struct {
+		name      string
+		server    *compressionServer
+		encodings []string
+	}{
+		{"accepted", &compressionServer{acceptGzip: true}, []string{"gzip", "", "gzip"}},
+		{"unsupported media type", &compressionServer{rejectStatus: http.StatusUnsupportedMediaType}, []string{"gzip", "", "", ""}},
+		{"bad request", &compressionServer{rejectStatus: http.StatusBadRequest}, []string{"gzip", "", "", ""}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := httptest.NewServer(tc.server)
+			defer s.Close()
+
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+			if err := WithRequestCompression(1000)(wd); err != nil {
+				t.Fatalf("WithRequestCompression() returned error: %v", err)
+			}
+			for _, arg := range []string{large, "small", large} {
+				got, err := wd.ExecuteScript("return arguments[0].length", []interface{}{arg})
+				if err != nil {
+					t.Fatalf("ExecuteScript() returned error: %v", err)
+				}
+				if got != float64(len(arg)) {
+					t.Errorf("ExecuteScript() = %v, want %d", got, len(arg))
+				}
+			}
+			// A rejected compressed request is sent again uncompressed, and
+			// later ones are not compressed.
+			if got, want := fmt.Sprintf("%q", tc.server.encodings), fmt.Sprintf("%q", tc.encodings); got != want {
+				t.Errorf("the requests had content encodings %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestRequestCompressionInvalidArgument(t *testing.T) {
+	cs := &compressionServer{acceptGzip: true}
+	s := httptest.NewServer(cs)
+	defer s.Close()
+
+	// A command that is invalid regardless of the compression does not
+	// disable it.
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true, compressMinSize: 1}
+	if _, err := wd.ExecuteScript("return 1", []interface{}{"invalid"}); err == nil {
+		t.Fatalf("ExecuteScript() of an invalid command returned nil error")
+	}
+	if wd.compressionRejected {
+		t.Errorf("an invalid argument error disabled the compression")
+	}
+	if err := WithRequestCompression(0)(wd); err == nil {
+		t.Errorf("WithRequestCompression(0) returned nil error")
+	}
+}
+
+func BenchmarkRequestCompression(b *testing.B) {
+	// A 1MB argument, as large extraction scripts produce, over a link of
+	// 8 Mbit/s.
+	arg := strings.Repeat(`{"selector":"#main .item","attributes":["href","title"]},`, 1<<20/56)
+	s := httptest.NewServer(&compressionServer{acceptGzip: true, bytesPerSecond: 1 << 20})
+	defer s.Close()
+
+	for _, minSize := range []int{0, 1024} {
+		name := "uncompressed"
+		if minSize > 0 {
+			name = "compressed"
+		}
+		b.Run(name, func(b *testing.B) {
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true, compressMinSize: minSize}
+			for i := 0; i < b.N; i++ {
+				if _, err := wd.ExecuteScript("return arguments[0].length", []interface{}{arg}); err != nil {
+					b.Fatalf("ExecuteScript() returned error: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package selenium
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tebeka/selenium/chrome"
+)
+
+// ConnectOption configures ConnectStandalone.
+type ConnectOption func(*connectOptions) error
+
+type connectOptions struct {
+	user, password string
+	tlsConfig      *tls.Config
+	capabilities   Capabilities
+	remoteOptions  []RemoteOption
+}
+
+// ConnectCredentials sets the user name and password with which
+// ConnectStandalone authenticates to the container, e.g. to a browserless
+// instance behind a proxy that requires basic authentication.
+func ConnectCredentials(user, password string) ConnectOption {
+	return func(o *connectOptions) error {
+		o.user, o.password = user, password
+		return nil
+	}
+}
+
+// ConnectTLS makes ConnectStandalone connect over HTTPS, with the given TLS
+// configuration, or the default one if config is nil. The configuration only
+// applies to the returned WebDriver, which sends its requests with a copy of
+// the HTTP client returned by GetHTTPClient; the transport of that client,
+// which the other WebDrivers share, is left unchanged.
+func ConnectTLS(config *tls.Config) ConnectOption {
+	return func(o *connectOptions) error {
+		if config == nil {
+			config = new(tls.Config)
+		}
+		o.tlsConfig = config
+		return nil
+	}
+}
+
+// ConnectCapabilities sets capabilities that override the defaults that
+// ConnectStandalone chooses for the container. Each capability replaces the
+// default one of the same name.
+func ConnectCapabilities(caps Capabilities) ConnectOption {
+	return func(o *connectOptions) error {
+		if o.capabilities == nil {
+			o.capabilities = make(Capabilities)
+		}
+		for k, v := range caps {
+			o.capabilities[k] = v
+		}
+		return nil
+	}
+}
+
+// ConnectRemoteOptions sets the options with which ConnectStandalone creates
+// the WebDriver, as with NewRemoteWithOptions.
+func ConnectRemoteOptions(opts ...RemoteOption) ConnectOption {
+	return func(o *connectOptions) error {
+		o.remoteOptions = append(o.remoteOptions, opts...)
+		return nil
+	}
+}
+
+// standalonePaths are the URL paths under which the remote ends of the common
+// container images serve the WebDriver protocol, in the order in which
+// ConnectStandalone tries them: the root for Selenium 4, which also serves
+// /wd/hub, /wd/hub for Selenium 3, and /webdriver for browserless.
+var standalonePaths = []string{"", "/wd/hub", "/webdriver"}
+
+// The intervals at which ConnectStandalone probes a container that is not
+// ready yet, doubling from the first to the last.
+var (
+	connectFirstRetry = 100 * time.Millisecond
+	connectMaxRetry   = 2 * time.Second
+)
+
+// standaloneImage is the kind of container image of a remote end.
+type standaloneImage int
+
+const (
+	imageUnknown standaloneImage = iota
+	// imageSelenium3 is a Selenium 3 standalone image, e.g.
+	// selenium/standalone-chrome:3.141.59, which serves /wd/hub.
+	imageSelenium3
+	// imageSelenium4 is a Selenium 4 standalone image, which serves the root
+	// path and /wd/hub.
+	imageSelenium4
+	// imageBrowserless is a browserless image, which serves /webdriver.
+	imageBrowserless
+)
+
+// standaloneEndpoint is a remote end found by probeStandalone.
+type standaloneEndpoint struct {
+	urlPrefix string
+	image     standaloneImage
+	// browser is the browser that the remote end offers, if it says.
+	browser string
+}
+
+// standaloneStatus is the value of the reply to the Status command of the
+// remote ends of the container images.
+type standaloneStatus struct {
+	Ready   *bool
+	Message string
+	Build   struct {
+		Version string
+	}
+	// Nodes are the nodes of a Selenium 4 grid.
+	Nodes []struct {
+		Slots []struct {
+			Stereotype map[string]interface{}
+		}
+	}
+}
+
+// ConnectStandalone connects to a container running a standalone remote end,
+// such as selenium/standalone-chrome or browserless, and creates a session.
+// host is the address of the container, e.g. "localhost:4444", optionally
+// with a scheme, and with the path under which the remote end is served if
+// it is not one of the usual ones.
+//
+// ConnectStandalone finds the path at which the remote end serves the
+// WebDriver protocol, which differs across images and versions, and waits
+// with increasing intervals until its status is ready or ctx is done. It
+// then requests capabilities suited to the detected image, such as the
+// browser that a Selenium 4 standalone image offers and the Chrome flags
+// needed in containers, which ConnectCapabilities overrides.
+func ConnectStandalone(ctx context.Context, host string, opts ...ConnectOption) (WebDriver, error) {
+	o := new(connectOptions)
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	base, err := standaloneBaseURL(host, o)
+	if err != nil {
+		return nil, err
+	}
+	client := httpClient.Load()
+	if o.tlsConfig != nil {
+		client = tlsClient(o.tlsConfig)
+	}
+
+	endpoint, err := probeStandalone(ctx, base, client)
+	if err != nil {
+		return nil, err
+	}
+	caps := endpoint.defaultCapabilities()
+	for k, v := range o.capabilities {
+		caps[k] = v
+	}
+	remoteOptions := append([]RemoteOption{func(wd *remoteWD) error {
+		wd.client = client
+		return nil
+	}}, o.remoteOptions...)
+	return NewRemoteWithOptions(caps, endpoint.urlPrefix, remoteOptions...)
+}
+
+// tlsClient returns a copy of the shared HTTP client whose transport uses
+// config.
+func tlsClient(config *tls.Config) *http.Client {
+	client := *httpClient.Load()
+	t, ok := client.Transport.(*http.Transport)
+	if !ok {
+		t = http.DefaultTransport.(*http.Transport)
+	}
+	t = t.Clone()
+	t.TLSClientConfig = config
+	client.Transport = t
+	return &client
+}
+
+// standaloneBaseURL returns the URL of the container at host, with the
+// credentials of the options.
+func standaloneBaseURL(host string, o *connectOptions) (string, error) {
+	if !strings.Contains(host, "://") {
+		if o.tlsConfig != nil {
+			host = "https://" + host
+		} else {
+			host = "http://" + host
+		}
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid container address %q: %v", host, err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid container address %q: no host", host)
+	}
+	if o.user != "" || o.password != "" {
+		u.User = url.UserPassword(o.user, o.password)
+	}
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
+
+// probeStandalone finds the remote end served by the container at base and
+// waits until it is ready.
+func probeStandalone(ctx context.Context, base string, client *http.Client) (*standaloneEndpoint, error) {
+	prefixes := []string{base}
+	if u, err := url.Parse(base); err == nil && u.Path == "" {
+		prefixes = prefixes[:0]
+		for _, p := range standalonePaths {
+			prefixes = append(prefixes, base+p)
+		}
+	}
+
+	retry := connectFirstRetry
+	for {
+		failures := new(MultiError)
+		for _, prefix := range prefixes {
+			status, err := standaloneStatusOf(ctx, prefix, client)
+			if err != nil {
+				failures.Append(filteredURL(prefix), err)
+				continue
+			}
+			if status.Ready != nil && !*status.Ready {
+				failures.Append(filteredURL(prefix), fmt.Errorf("not ready: %s", status.Message))
+				// The other paths are served by the same remote end.
+				break
+			}
+			return newStandaloneEndpoint(prefix, status), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for a remote end at %s: %v: %v", filteredURL(base), ctx.Err(), failures)
+		case <-time.After(retry):
+		}
+		if retry *= 2; retry > connectMaxRetry {
+			retry = connectMaxRetry
+		}
+	}
+}
+
+// standaloneStatusOf sends the Status command to the remote end at prefix
+// with client.
+func standaloneStatusOf(ctx context.Context, prefix string, client *http.Client) (*standaloneStatus, error) {
+	probe := &remoteWD{urlPrefix: prefix, client: client}
+	response, err := probe.executeContext(ctx, "GET", probe.requestURL("/status"), nil)
+	if err != nil {
+		return nil, err
+	}
+	reply := new(struct{ Value *standaloneStatus })
+	if err := json.Unmarshal(response, reply); err != nil {
+		return nil, err
+	}
+	if reply.Value == nil {
+		return nil, nullValueError("/status")
+	}
+	return reply.Value, nil
+}
+
+// newStandaloneEndpoint identifies the image of the remote end at prefix from
+// its status.
+func newStandaloneEndpoint(prefix string, status *standaloneStatus) *standaloneEndpoint {
+	e := &standaloneEndpoint{urlPrefix: prefix}
+	message := strings.ToLower(status.Message)
+	switch {
+	case strings.HasPrefix(status.Build.Version, "3."):
+		e.image = imageSelenium3
+	case len(status.Nodes) > 0 || strings.Contains(message, "selenium grid"):
+		e.image = imageSelenium4
+	case strings.Contains(message, "browserless") || strings.HasSuffix(prefix, "/webdriver"):
+		e.image = imageBrowserless
+	}
+	for _, n := range status.Nodes {
+		for _, s := range n.Slots {
+			if b, ok := s.Stereotype["browserName"].(string); ok && e.browser == "" {
+				e.browser = b
+			}
+		}
+	}
+	return e
+}
+
+// defaultCapabilities returns the capabilities suited to the remote end: the
+// browser it offers, Chrome unless it says otherwise, and for Chrome the
+// flags without which it fails in containers with the default size of
+// /dev/shm, or as root. Browserless images have no display, so Chrome runs
+// headless there.
+func (e *standaloneEndpoint) defaultCapabilities() Capabilities {
+	browser := e.browser
+	if browser == "" {
+		browser = "chrome"
+	}
+	caps := Capabilities{"browserName": browser}
+	if browser == "chrome" {
+		args := []string{"--no-sandbox", "--disable-dev-shm-usage"}
+		if e.image == imageBrowserless {
+			args = append(args, "--headless")
+		}
+		caps.AddChrome(chrome.Capabilities{Args: args})
+	}
+	return caps
+}
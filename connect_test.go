@@ -0,0 +1,232 @@
+package selenium
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tebeka/selenium/chrome"
+)
+
+// containerServer emulates the remote end of a container image, which serves
+// the WebDriver protocol under the prefixes.
+type containerServer struct {
+	t        *testing.T
+	prefixes []string
+	status   string
+	// notReady is the number of Status commands answered with a status that
+	// is not ready.
+	notReady int
+	// user and password, if set, are required.
+	user, password string
+	// capabilities are those requested for the session, and prefix the
+	// prefix under which it was requested.
+	capabilities map[string]interface{}
+	prefix       string
+}
+
+func (s *containerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.user != "" {
+		if user, password, ok := r.BasicAuth(); !ok || user != s.user || password != s.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+	for _, prefix := range s.prefixes {
+		switch r.URL.Path {
+		case prefix + "/status":
+			w.Header().Set("Content-Type", JSONType)
+			if s.notReady > 0 {
+				s.notReady--
+				fmt.Fprint(w, `{"value":{"ready":false,"message":"starting"}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"value":%s}`, s.status)
+			return
+		case prefix + "/session":
+			var payload struct {
+				Capabilities struct {
+					AlwaysMatch map[string]interface{}
+				}
+			}
+			json.NewDecoder(r.Body).Decode(&payload)
+			s.capabilities, s.prefix = payload.Capabilities.AlwaysMatch, prefix
+			w.Header().Set("Content-Type", JSONType)
+			fmt.Fprint(w, `{"value":{"sessionId":"s1","capabilities":{}}}`)
+			return
+		}
+	}
+	// The servers of the images answer unknown paths in various ways.
+	http.NotFound(w, r)
+}
+
+func TestConnectStandalone(t *testing.T) {
+	defer func(first time.Duration) { connectFirstRetry = first }(connectFirstRetry)
+	connectFirstRetry = 5 * time.Millisecond
+
+	chromeArgs := func(args ...string) map[string]interface{} {
+		a := make([]interface{}, len(args))
+		for i, arg := range args {
+			a[i] = arg
+		}
+		return map[string]interface{}{"browserName": "chrome", chrome.CapabilitiesKey: map[string]interface{}{"args": a}}
+	}
+	for _, tc := range []struct {
+		name       string
+		server     *containerServer
+		opts       []ConnectOption
+		wantPrefix string
+		wantCaps   map[string]interface{}
+	}{
+		{
+			name: "Selenium 3",
+			server: &containerServer{
+				prefixes: []string{"/wd/hub"},
+				status:   `{"ready":true,"message":"Server is running","build":{"version":"3.141.59"}}`,
+			},
+			wantPrefix: "/wd/hub",
+			wantCaps:   chromeArgs("--no-sandbox", "--disable-dev-shm-usage"),
+		},
+		{
+			name: "Selenium 4 starting",
+			server: &containerServer{
+				prefixes: []string{"", "/wd/hub"},
+				status:   `{"ready":true,"message":"Selenium Grid ready.","nodes":[{"slots":[{"stereotype":{"browserName":"firefox"}}]}]}`,
+				notReady: 3,
+			},
+			wantPrefix: "",
+			wantCaps:   map[string]interface{}{"browserName": "firefox"},
+		},
+		{
+			name: "browserless with credentials",
+			server: &containerServer{
+				prefixes: []string{"/webdriver"},
+				status:   `{"ready":true,"message":"ok"}`,
+				user:     "alice",
+				password: "secret",
+			},
+			opts:       []ConnectOption{ConnectCredentials("alice", "secret")},
+			wantPrefix: "/webdriver",
+			wantCaps:   chromeArgs("--no-sandbox", "--disable-dev-shm-usage", "--headless"),
+		},
+		{
+			name: "capability overrides",
+			server: &containerServer{
+				prefixes: []string{"/wd/hub"},
+				status:   `{"ready":true,"message":"Server is running","build":{"version":"3.141.59"}}`,
+			},
+			opts:       []ConnectOption{ConnectCapabilities(Capabilities{chrome.CapabilitiesKey: map[string]interface{}{}, "acceptInsecureCerts": true})},
+			wantPrefix: "/wd/hub",
+			wantCaps:   map[string]interface{}{"browserName": "chrome", chrome.CapabilitiesKey: map[string]interface{}{}, "acceptInsecureCerts": true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.server.t = t
+			s := httptest.NewServer(tc.server)
+			defer s.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			wd, err := ConnectStandalone(ctx, strings.TrimPrefix(s.URL, "http://"), tc.opts...)
+			if err != nil {
+				t.Fatalf("ConnectStandalone() returned error: %v", err)
+			}
+			if id := wd.SessionID(); id != "s1" {
+				t.Errorf("ConnectStandalone() created session %q, want s1", id)
+			}
+			if tc.server.prefix != tc.wantPrefix {
+				t.Errorf("ConnectStandalone() created the session under %q, want %q", tc.server.prefix, tc.wantPrefix)
+			}
+			if !reflect.DeepEqual(tc.server.capabilities, tc.wantCaps) {
+				t.Errorf("ConnectStandalone() requested capabilities %v, want %v", tc.server.capabilities, tc.wantCaps)
+			}
+			if tc.server.notReady != 0 {
+				t.Errorf("ConnectStandalone() did not wait until the remote end was ready")
+			}
+			untrackSession(wd.(*remoteWD))
+		})
+	}
+}
+
+func TestConnectStandaloneTimeout(t *testing.T) {
+	defer func(first time.Duration) { connectFirstRetry = first }(connectFirstRetry)
+	connectFirstRetry = 5 * time.Millisecond
+
+	s := httptest.NewServer(http.NotFoundHandler())
+	defer s.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := ConnectStandalone(ctx, s.URL)
+	if err == nil {
+		t.Fatal("ConnectStandalone() without a remote end returned nil error")
+	}
+	for _, path := range []string{"/wd/hub", "/webdriver"} {
+		if !strings.Contains(err.Error(), s.URL+path) {
+			t.Errorf("ConnectStandalone() returned error %q, which does not mention %s", err, s.URL+path)
+		}
+	}
+}
+
+func TestStandaloneBaseURL(t *testing.T) {
+	for _, tc := range []struct {
+		host string
+		opts connectOptions
+		want string
+	}{
+		{"localhost:4444", connectOptions{}, "http://localhost:4444"},
+		{"http://localhost:4444/", connectOptions{}, "http://localhost:4444"},
+		{"grid.example.com/custom/hub", connectOptions{}, "http://grid.example.com/custom/hub"},
+		{"localhost:3000", connectOptions{user: "u", password: "p"}, "http://u:p@localhost:3000"},
+	} {
+		got, err := standaloneBaseURL(tc.host, &tc.opts)
+		if err != nil || got != tc.want {
+			t.Errorf("standaloneBaseURL(%q) = %q, %v; want %q, nil", tc.host, got, err, tc.want)
+		}
+	}
+	if _, err := standaloneBaseURL("http://", &connectOptions{}); err == nil {
+		t.Error(`standaloneBaseURL("http://") returned nil error`)
+	}
+}
+
+func TestConnectStandaloneTLS(t *testing.T) {
+	shared := httpClient.Load().Transport
+	server := &containerServer{
+		t:        t,
+		prefixes: []string{"", "/wd/hub"},
+		status:   `{"ready":true,"message":"Selenium Grid ready.","nodes":[]}`,
+	}
+	s := httptest.NewTLSServer(server)
+	defer s.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(s.Certificate())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	wd, err := ConnectStandalone(ctx, strings.TrimPrefix(s.URL, "https://"), ConnectTLS(&tls.Config{RootCAs: roots}))
+	if err != nil {
+		t.Fatalf("ConnectStandalone() over TLS returned error: %v", err)
+	}
+	untrackSession(wd.(*remoteWD))
+	if server.capabilities == nil {
+		t.Error("ConnectStandalone() over TLS did not create a session")
+	}
+	if httpClient.Load().Transport != shared {
+		t.Error("ConnectStandalone() over TLS replaced the transport of the shared HTTP client")
+	}
+	if _, err := wd.Status(); err != nil {
+		t.Errorf("wd.Status() over TLS returned error: %v", err)
+	}
+
+	// Other WebDrivers do not trust the server's certificate.
+	if _, err := NewRemote(nil, s.URL+"/wd/hub"); err == nil {
+		t.Error("NewRemote() trusted the certificate configured for another WebDriver")
+	}
+}
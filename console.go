@@ -0,0 +1,223 @@
+package selenium
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+var (
+	// BiDiReconnectDelay is the time that AttachConsole waits between the
+	// attempts to reconnect after its connection dropped, multiplied by the
+	// number of attempts that failed. The first attempt is immediate, so as
+	// to lose as few entries as possible.
+	BiDiReconnectDelay = 100 * time.Millisecond
+	// BiDiReconnectAttempts is the number of attempts in a row to reconnect
+	// after which AttachConsole gives up.
+	BiDiReconnectAttempts = 5
+)
+
+// ConsoleEntry is a console message or an uncaught JavaScript error of the
+// page, received with WebDriver.AttachConsole. Its LogMessage has the shape
+// of the browser log entries that chromedriver returns from WebDriver.Log:
+// the Timestamp is in milliseconds since the epoch, the Level is one of
+// "DEBUG", "INFO", "WARNING" and "SEVERE", and the Message is prefixed with
+// the location in the script, e.g. "http://example.com/app.js 12:5 loaded".
+type ConsoleEntry struct {
+	LogMessage
+	// Source is "console" for console API calls and "javascript" for
+	// uncaught errors.
+	Source string
+	// Text is the message without the location.
+	Text string
+}
+
+// bidiLogEntry is the parameter of the "log.entryAdded" event.
+type bidiLogEntry struct {
+	Type       string  `json:"type"`
+	Level      string  `json:"level"`
+	Text       string  `json:"text"`
+	Timestamp  float64 `json:"timestamp"`
+	StackTrace *struct {
+		CallFrames []struct {
+			URL          string `json:"url"`
+			LineNumber   int    `json:"lineNumber"`
+			ColumnNumber int    `json:"columnNumber"`
+		} `json:"callFrames"`
+	} `json:"stackTrace"`
+}
+
+// bidiLogLevels maps the BiDi log levels to those of the legacy log.
+var bidiLogLevels = map[string]string{
+	"debug": "DEBUG",
+	"info":  "INFO",
+	"warn":  "WARNING",
+	"error": "SEVERE",
+}
+
+func (e *bidiLogEntry) consoleEntry() ConsoleEntry {
+	level, ok := bidiLogLevels[e.Level]
+	if !ok {
+		level = "INFO"
+	}
+	message := e.Text
+	if st := e.StackTrace; st != nil && len(st.CallFrames) > 0 {
+		// BiDi line and column numbers are zero-based, those of the log are
+		// not.
+		f := st.CallFrames[0]
+		message = fmt.Sprintf("%s %d:%d %s", f.URL, f.LineNumber+1, f.ColumnNumber+1, e.Text)
+	}
+	return ConsoleEntry{
+		LogMessage: LogMessage{
+			Timestamp: int(e.Timestamp),
+			Level:     level,
+			Message:   message,
+		},
+		Source: e.Type,
+		Text:   e.Text,
+	}
+}
+
+// consoleAttachment streams the console entries of a session until it is
+// detached.
+type consoleAttachment struct {
+	url     string
+	entries chan ConsoleEntry
+	// cancel detaches the attachment, and done is closed once it is.
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (wd *remoteWD) AttachConsole(ctx context.Context) (<-chan ConsoleEntry, error) {
+	url, err := wd.webSocketURL()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dialBiDi(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	a := &consoleAttachment{
+		url:     url,
+		entries: make(chan ConsoleEntry),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	// Events received before the subscription is confirmed are dropped
+	// rather than blocking until the caller reads the channel.
+	if err := subscribeLog(ctx, conn, func(*bidiMessage) {}); err != nil {
+		conn.Close()
+		cancel()
+		return nil, err
+	}
+	wd.consoles = append(wd.consoles, a)
+	go a.run(ctx, conn)
+	return a.entries, nil
+}
+
+// run streams the entries received over conn, and over new connections if
+// it drops, until ctx is done or it fails to reconnect.
+func (a *consoleAttachment) run(ctx context.Context, conn *bidiConn) {
+	defer close(a.done)
+	defer close(a.entries)
+	for {
+		a.stream(ctx, conn)
+		if ctx.Err() != nil {
+			return
+		}
+		if conn = a.reconnect(ctx); conn == nil {
+			return
+		}
+	}
+}
+
+// stream sends the entries received over conn to the channel until the
+// connection fails or ctx is done, and then closes it.
+func (a *consoleAttachment) stream(ctx context.Context, conn *bidiConn) {
+	defer conn.Close()
+	defer closeWhenDone(ctx, conn)()
+	for {
+		msg, err := conn.receive()
+		if err != nil {
+			return
+		}
+		a.handle(ctx, msg)
+	}
+}
+
+// subscribeLog subscribes to the log entries over conn. The events received
+// before the subscription is confirmed are passed to onEvent.
+func subscribeLog(ctx context.Context, conn *bidiConn, onEvent func(*bidiMessage)) error {
+	defer closeWhenDone(ctx, conn)()
+	_, err := conn.command("session.subscribe", map[string]interface{}{
+		"events": []string{"log.entryAdded"},
+	}, onEvent)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// closeWhenDone closes conn if ctx is done before the returned function is
+// called, which interrupts a pending receive.
+func closeWhenDone(ctx context.Context, conn *bidiConn) (stop func()) {
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stopped:
+		}
+	}()
+	return func() { close(stopped) }
+}
+
+// handle sends the entry of a "log.entryAdded" event to the channel.
+func (a *consoleAttachment) handle(ctx context.Context, msg *bidiMessage) {
+	if msg.Method != "log.entryAdded" {
+		return
+	}
+	var e bidiLogEntry
+	if err := json.Unmarshal(msg.Params, &e); err != nil {
+		return
+	}
+	select {
+	case a.entries <- e.consoleEntry():
+	case <-ctx.Done():
+	}
+}
+
+// reconnect connects to the BiDi WebSocket again and subscribes to the log
+// entries, as the subscription may have ended with the connection. It
+// returns nil if ctx is done or all attempts fail.
+func (a *consoleAttachment) reconnect(ctx context.Context) *bidiConn {
+	for attempt := 1; attempt <= BiDiReconnectAttempts; attempt++ {
+		select {
+		case <-time.After(time.Duration(attempt-1) * BiDiReconnectDelay):
+		case <-ctx.Done():
+			return nil
+		}
+		conn, err := dialBiDi(ctx, a.url)
+		if err != nil {
+			continue
+		}
+		if err := subscribeLog(ctx, conn, func(msg *bidiMessage) { a.handle(ctx, msg) }); err != nil {
+			conn.Close()
+			continue
+		}
+		return conn
+	}
+	return nil
+}
+
+// detachConsoles detaches the console attachments of the session and waits
+// until their channels are closed.
+func (wd *remoteWD) detachConsoles() {
+	for _, a := range wd.consoles {
+		a.cancel()
+		<-a.done
+	}
+	wd.consoles = nil
+}
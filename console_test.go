@@ -0,0 +1,244 @@
+package selenium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// bidiServer is a mock BiDi remote end that emits numbered console entries
+// to the subscribed connection, if any.
+type bidiServer struct {
+	*httptest.Server
+
+	mu sync.Mutex
+	// conn is the subscribed connection, and connections counts them.
+	conn        *websocket.Conn
+	connections int
+	subscribed  chan struct{}
+	// delivered are the entries sent to a subscribed connection, and missed
+	// those emitted while there was none.
+	delivered, missed []int
+	// dropAt is the number of the entry after which the connection drops,
+	// at dropped, and resubscribed is when the next subscription started.
+	dropAt                int
+	dropped, resubscribed time.Time
+}
+
+func newBiDiServer(t *testing.T) *bidiServer {
+	s := &bidiServer{subscribed: make(chan struct{}, 1), dropAt: -1}
+	mux := http.NewServeMux()
+	mux.Handle("/session/123/bidi", websocket.Handler(func(ws *websocket.Conn) {
+		defer func() {
+			s.mu.Lock()
+			if s.conn == ws {
+				s.conn = nil
+			}
+			s.mu.Unlock()
+		}()
+		for {
+			var cmd struct {
+				ID     int
+				Method string
+			}
+			if err := websocket.JSON.Receive(ws, &cmd); err != nil {
+				return
+			}
+			if cmd.Method != "session.subscribe" {
+				t.Errorf("unexpected BiDi command %q", cmd.Method)
+				continue
+			}
+			s.mu.Lock()
+			websocket.JSON.Send(ws, map[string]interface{}{"id": cmd.ID, "type": "success", "result": map[string]interface{}{}})
+			s.conn = ws
+			s.connections++
+			if !s.dropped.IsZero() && s.resubscribed.IsZero() {
+				s.resubscribed = time.Now()
+			}
+			s.mu.Unlock()
+			select {
+			case s.subscribed <- struct{}{}:
+			default:
+			}
+		}
+	}))
+	mux.HandleFunc("/session/123", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", JSONType)
+		fmt.Fprint(w, `{"value":null}`)
+	})
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+func (s *bidiServer) webDriver() *remoteWD {
+	return &remoteWD{
+		id:            "123",
+		urlPrefix:     s.URL,
+		w3cCompatible: true,
+		negotiated:    Capabilities{"webSocketUrl": "ws" + strings.TrimPrefix(s.URL, "http") + "/session/123/bidi"},
+	}
+}
+
+// emit sends the entry n to the subscribed connection, and then drops it if
+// n is dropAt.
+func (s *bidiServer) emit(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		s.missed = append(s.missed, n)
+		return
+	}
+	err := websocket.JSON.Send(s.conn, map[string]interface{}{
+		"type":   "event",
+		"method": "log.entryAdded",
+		"params": map[string]interface{}{
+			"type":      "console",
+			"level":     "warn",
+			"text":      fmt.Sprintf("entry %d", n),
+			"timestamp": 1700000000000 + n,
+			"stackTrace": map[string]interface{}{
+				"callFrames": []map[string]interface{}{{"url": "http://example.com/app.js", "lineNumber": 11, "columnNumber": 4}},
+			},
+		},
+	})
+	if err != nil {
+		s.missed = append(s.missed, n)
+		return
+	}
+	s.delivered = append(s.delivered, n)
+	if n == s.dropAt {
+		s.conn.Close()
+		s.conn = nil
+		s.dropped = time.Now()
+	}
+}
+
+func TestAttachConsoleReconnects(t *testing.T) {
+	defer func(delay time.Duration) { BiDiReconnectDelay = delay }(BiDiReconnectDelay)
+	BiDiReconnectDelay = 10 * time.Millisecond
+
+	s := newBiDiServer(t)
+	defer s.Close()
+	s.dropAt = 100
+	wd := s.webDriver()
+
+	entries, err := wd.AttachConsole(context.Background())
+	if err != nil {
+		t.Fatalf("AttachConsole() returned error: %v", err)
+	}
+	<-s.subscribed
+
+	const total = 300
+	go func() {
+		for n := 0; n < total; n++ {
+			s.emit(n)
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	var received []int
+	var first ConsoleEntry
+	timeout := time.After(10 * time.Second)
+	for len(received) == 0 || received[len(received)-1] != total-1 {
+		select {
+		case e, ok := <-entries:
+			if !ok {
+				t.Fatalf("the channel was closed after %d entries", len(received))
+			}
+			if len(received) == 0 {
+				first = e
+			}
+			var n int
+			fmt.Sscanf(e.Text, "entry %d", &n)
+			received = append(received, n)
+		case <-timeout:
+			t.Fatalf("timed out after receiving %d entries", len(received))
+		}
+	}
+
+	want := ConsoleEntry{
+		LogMessage: LogMessage{Timestamp: 1700000000000, Level: "WARNING", Message: "http://example.com/app.js 12:5 entry 0"},
+		Source:     "console",
+		Text:       "entry 0",
+	}
+	if first != want {
+		t.Errorf("the first entry is %+v, want %+v", first, want)
+	}
+
+	s.mu.Lock()
+	delivered, missed, connections := s.delivered, s.missed, s.connections
+	window := s.resubscribed.Sub(s.dropped)
+	s.mu.Unlock()
+	if connections != 2 {
+		t.Errorf("the console was subscribed over %d connections, want 2", connections)
+	}
+	if fmt.Sprint(received) != fmt.Sprint(delivered) {
+		t.Errorf("received entries %v, want those sent while subscribed, %v", received, delivered)
+	}
+	if len(missed) > 0 {
+		// Only the entries emitted while reconnecting are lost.
+		if first, last := missed[0], missed[len(missed)-1]; first != s.dropAt+1 || last-first+1 != len(missed) {
+			t.Errorf("lost entries %v, want only those emitted right after the drop", missed)
+		}
+	}
+	t.Logf("lost %d of %d entries during the %v reconnect window", len(missed), total, window)
+
+	if err := wd.Quit(); err != nil {
+		t.Fatalf("Quit() returned error: %v", err)
+	}
+	select {
+	case _, ok := <-entries:
+		if ok {
+			t.Error("received an entry after Quit")
+		}
+	case <-time.After(time.Second):
+		t.Error("the channel was not closed by Quit")
+	}
+}
+
+func TestAttachConsoleContext(t *testing.T) {
+	s := newBiDiServer(t)
+	defer s.Close()
+	wd := s.webDriver()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	entries, err := wd.AttachConsole(ctx)
+	if err != nil {
+		t.Fatalf("AttachConsole() returned error: %v", err)
+	}
+	cancel()
+	select {
+	case _, ok := <-entries:
+		if ok {
+			t.Error("received an unexpected entry")
+		}
+	case <-time.After(time.Second):
+		t.Error("the channel was not closed when the context was canceled")
+	}
+}
+
+func TestAttachConsoleWithoutBiDi(t *testing.T) {
+	wd := &remoteWD{id: "123", w3cCompatible: true, negotiated: Capabilities{"browserName": "firefox"}}
+	if _, err := wd.AttachConsole(context.Background()); !errors.Is(err, ErrNoBiDi) {
+		t.Errorf("AttachConsole() on a session without a WebSocket URL returned error %v, want ErrNoBiDi", err)
+	}
+}
+
+func TestEnableBiDi(t *testing.T) {
+	caps := Capabilities{}
+	caps.EnableBiDi()
+	if caps["webSocketUrl"] != true {
+		t.Errorf(`EnableBiDi() set the "webSocketUrl" capability to %v, want true`, caps["webSocketUrl"])
+	}
+}
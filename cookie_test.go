@@ -0,0 +1,212 @@
+package selenium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// cookieServer emulates the cookie endpoints of either a legacy server, which
+// does not implement "Get Named Cookie", or a W3C server.
+type cookieServer struct {
+	w3c          bool
+	namedQueries []string
+}
+
+const cookieList = `[{"name":"a b","value":"1","path":"/","domain":"localhost","secure":false},{"name":"c","value":"2","path":"/","domain":"localhost","secure":false,"expiry":1500000000}]`
+
+func (s *cookieServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", JSONType)
+	switch {
+	case r.URL.Path == "/session/123/cookie":
+		fmt.Fprintf(w, `{"sessionId":"123","status":0,"value":%s}`, cookieList)
+	case strings.HasPrefix(r.URL.Path, "/session/123/cookie/"):
+		name := strings.TrimPrefix(r.URL.Path, "/session/123/cookie/")
+		s.namedQueries = append(s.namedQueries, r.URL.EscapedPath())
+		if !s.w3c {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"sessionId":"123","status":9,"value":{"message":"Unrecognized command: GET /session/123/cookie/`+name+`"}}`)
+			return
+		}
+		switch name {
+		case "a b":
+			fmt.Fprint(w, `{"value":{"name":"a b","value":"1","path":"/","domain":"localhost","secure":false}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"no such cookie","message":"missing","stacktrace":""}}`)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestGetCookie(t *testing.T) {
+	for _, w3c := range []bool{false, true} {
+		t.Run(fmt.Sprintf("W3C=%t", w3c), func(t *testing.T) {
+			cs := &cookieServer{w3c: w3c}
+			s := httptest.NewServer(cs)
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+
+			for i := 0; i < 2; i++ {
+				c, err := wd.GetCookie("a b")
+				if err != nil {
+					t.Fatalf(`wd.GetCookie("a b") returned error: %v`, err)
+				}
+				if c.Name != "a b" || c.Value != "1" {
+					t.Fatalf(`wd.GetCookie("a b") = %+v, want the cookie named "a b"`, c)
+				}
+			}
+
+			_, err := wd.GetCookie("missing")
+			e, ok := err.(*Error)
+			if !ok || e.Err != "no such cookie" {
+				t.Fatalf(`wd.GetCookie("missing") returned error %#v, want a "no such cookie" *Error`, err)
+			}
+
+			wantQueries := 3
+			if !w3c {
+				// The unsupported endpoint is only tried once per session.
+				wantQueries = 1
+			}
+			if len(cs.namedQueries) != wantQueries {
+				t.Errorf("the named cookie endpoint was queried %d times, want %d", len(cs.namedQueries), wantQueries)
+			}
+			if got, want := cs.namedQueries[0], "/session/123/cookie/a%20b"; got != want {
+				t.Errorf("the named cookie endpoint was queried with path %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// cookieJar emulates the cookie endpoints of a W3C remote end with a jar of
+// cookies. Deleting a cookie whose name is in failing fails. The cookies whose
+// name and path, separated by a space, are in httpOnly are reported as
+// HttpOnly.
+type cookieJar struct {
+	cookies  []Cookie
+	failing  map[string]bool
+	httpOnly map[string]bool
+	deleted  []string
+}
+
+func (j *cookieJar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", JSONType)
+	reply := func(v interface{}) {
+		b, _ := json.Marshal(v)
+		fmt.Fprintf(w, `{"value":%s}`, b)
+	}
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/session/123/cookie":
+		var cookies []cookie
+		for _, c := range j.cookies {
+			cookies = append(cookies, cookie{Name: c.Name, Value: c.Value, Path: c.Path, Domain: c.Domain,
+				HTTPOnly: j.httpOnly[c.Name+" "+c.Path]})
+		}
+		reply(cookies)
+	case r.Method == "POST" && r.URL.Path == "/session/123/cookie":
+		var params struct{ Cookie Cookie }
+		json.NewDecoder(r.Body).Decode(&params)
+		j.cookies = append(j.cookies, params.Cookie)
+		reply(nil)
+	case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/session/123/cookie/"):
+		name := strings.TrimPrefix(r.URL.Path, "/session/123/cookie/")
+		j.deleted = append(j.deleted, r.URL.EscapedPath())
+		if j.failing[name] {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"value":{"error":"unable to set cookie","message":"locked","stacktrace":""}}`)
+			return
+		}
+		var remaining []Cookie
+		for _, c := range j.cookies {
+			if c.Name != name {
+				remaining = append(remaining, c)
+			}
+		}
+		j.cookies = remaining
+		reply(nil)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestDeleteCookiesMatching(t *testing.T) {
+	jar := &cookieJar{
+		cookies: []Cookie{
+			{Name: "auth token", Value: "1", Path: "/", Domain: "example.com"},
+			{Name: "session", Value: "2", Path: "/app", Domain: ".example.com"},
+			{Name: "session", Value: "3", Path: "/admin", Domain: ".example.com"},
+			{Name: "consent", Value: "yes", Path: "/", Domain: "example.com"},
+			{Name: "tracker", Value: "4", Path: "/", Domain: "ads.example.net"},
+		},
+		failing: map[string]bool{"locked": true},
+	}
+	s := httptest.NewServer(jar)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	// Only one of the cookies named "session" matches.
+	n, err := wd.DeleteCookiesMatching(func(c Cookie) bool {
+		return c.Name == "auth token" || c.Path == "/app"
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("wd.DeleteCookiesMatching() = %d, %v; want 2, nil", n, err)
+	}
+	if want := []string{"/session/123/cookie/auth%20token", "/session/123/cookie/session"}; !reflect.DeepEqual(jar.deleted, want) {
+		t.Errorf("wd.DeleteCookiesMatching() deleted %q, want %q", jar.deleted, want)
+	}
+	var left []string
+	for _, c := range jar.cookies {
+		left = append(left, c.Name+" "+c.Path)
+	}
+	sort.Strings(left)
+	if want := []string{"consent /", "session /admin", "tracker /"}; !reflect.DeepEqual(left, want) {
+		t.Errorf("after wd.DeleteCookiesMatching(), the jar holds %q, want %q", left, want)
+	}
+
+	n, err = wd.DeleteCookiesMatching(CookieDomainSuffix(".example.net"))
+	if err != nil || n != 1 || len(jar.cookies) != 2 {
+		t.Errorf("wd.DeleteCookiesMatching(CookieDomainSuffix()) = %d, %v and left %v; want 1, nil and 2 cookies", n, err, jar.cookies)
+	}
+
+	jar.cookies = append(jar.cookies, Cookie{Name: "locked", Path: "/"})
+	n, err = wd.DeleteCookiesMatching(CookieNameMatches(regexp.MustCompile(`^(locked|consent)$`)))
+	var multi *MultiError
+	if n != 1 || !errors.As(err, &multi) || len(multi.Items) != 1 || multi.Items[0].Label != `deleting cookie "locked"` {
+		t.Errorf("wd.DeleteCookiesMatching() with a failing cookie = %d, %v; want 1 and a MultiError naming it", n, err)
+	}
+
+	// An HttpOnly cookie could not be added back as it was.
+	jar.cookies = []Cookie{{Name: "sid", Path: "/a"}, {Name: "sid", Path: "/b"}, {Name: "other", Path: "/"}}
+	jar.httpOnly = map[string]bool{"sid /b": true}
+	jar.deleted = nil
+	n, err = wd.DeleteCookiesMatching(func(c Cookie) bool { return c.Path != "/b" })
+	if n != 1 || !errors.As(err, &multi) || len(multi.Items) != 1 || multi.Items[0].Label != `deleting cookie "sid"` {
+		t.Errorf("wd.DeleteCookiesMatching() sharing the name of an HttpOnly cookie = %d, %v; want 1 and a MultiError naming it", n, err)
+	}
+	if want := []string{"/session/123/cookie/other"}; !reflect.DeepEqual(jar.deleted, want) {
+		t.Errorf("wd.DeleteCookiesMatching() deleted %q, want %q", jar.deleted, want)
+	}
+}
+
+func TestCookieDomainSuffix(t *testing.T) {
+	match := CookieDomainSuffix("example.com")
+	for domain, want := range map[string]bool{
+		"example.com":      true,
+		".example.com":     true,
+		"auth.Example.com": true,
+		"badexample.com":   false,
+		"example.com.evil": false,
+	} {
+		if got := match(Cookie{Domain: domain}); got != want {
+			t.Errorf("CookieDomainSuffix(%q) on domain %q = %t, want %t", "example.com", domain, got, want)
+		}
+	}
+}
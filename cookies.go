@@ -0,0 +1,75 @@
+package selenium
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CookieNameMatches returns a predicate for WebDriver.DeleteCookiesMatching
+// that matches the cookies whose name matches re.
+func CookieNameMatches(re *regexp.Regexp) func(Cookie) bool {
+	return func(c Cookie) bool {
+		return re.MatchString(c.Name)
+	}
+}
+
+// CookieDomainSuffix returns a predicate for WebDriver.DeleteCookiesMatching
+// that matches the cookies of the domain and its subdomains. For example,
+// "example.com" matches the cookies of "example.com", ".example.com" and
+// "auth.example.com", but not those of "badexample.com".
+func CookieDomainSuffix(domain string) func(Cookie) bool {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	return func(c Cookie) bool {
+		d := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+		return d == domain || strings.HasSuffix(d, "."+domain)
+	}
+}
+
+func (wd *remoteWD) DeleteCookiesMatching(pred func(Cookie) bool) (int, error) {
+	cookies, err := wd.cookies()
+	if err != nil {
+		return 0, err
+	}
+
+	// Cookies can only be deleted by name, which deletes all the cookies with
+	// that name, so those that share the name of a matching cookie without
+	// matching are added back. Those with attributes that Cookie does not
+	// hold cannot be, so their names are left alone.
+	var names []string
+	matching := make(map[string]int)
+	kept := make(map[string][]Cookie)
+	unrestorable := make(map[string]string)
+	for _, c := range cookies {
+		if pred(c.sanitize()) {
+			if matching[c.Name] == 0 {
+				names = append(names, c.Name)
+			}
+			matching[c.Name]++
+		} else {
+			kept[c.Name] = append(kept[c.Name], c.sanitize())
+			if _, ok := unrestorable[c.Name]; !ok && (c.HTTPOnly || c.SameSite != "") {
+				unrestorable[c.Name] = c.Path
+			}
+		}
+	}
+
+	removed := 0
+	failures := new(MultiError)
+	for _, name := range names {
+		if path, ok := unrestorable[name]; ok {
+			failures.Append(fmt.Sprintf("deleting cookie %q", name), fmt.Errorf("the cookie with path %q shares its name but does not match, and could not be added back with its HttpOnly or SameSite attribute", path))
+			continue
+		}
+		if err := wd.DeleteCookie(name); err != nil {
+			failures.Append(fmt.Sprintf("deleting cookie %q", name), err)
+			continue
+		}
+		removed += matching[name]
+		for _, c := range kept[name] {
+			c := c
+			failures.Append(fmt.Sprintf("restoring cookie %q with path %q", c.Name, c.Path), wd.AddCookie(&c))
+		}
+	}
+	return removed, failures.ErrorOrNil()
+}
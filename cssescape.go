@@ -0,0 +1,56 @@
+package selenium
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// CSSEscape escapes s for use as a CSS identifier, such as an ID or class name
+// in a selector, following the CSS.escape algorithm of the CSSOM
+// specification: https://drafts.csswg.org/cssom/#serialize-an-identifier
+func CSSEscape(s string) string {
+	var b bytes.Buffer
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == 0:
+			b.WriteRune('\uFFFD')
+		case (r >= 0x1 && r <= 0x1F) || r == 0x7F,
+			i == 0 && r >= '0' && r <= '9',
+			i == 1 && r >= '0' && r <= '9' && runes[0] == '-':
+			fmt.Fprintf(&b, `\%x `, r)
+		case i == 0 && r == '-' && len(runes) == 1:
+			b.WriteString(`\-`)
+		case r >= 0x80 || r == '-' || r == '_' ||
+			(r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z'):
+			b.WriteRune(r)
+		default:
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// CSSAttrValue returns s as a double-quoted CSS string, for use as the value
+// in an attribute selector, e.g. "input[name=" + CSSAttrValue(name) + "]".
+// See https://drafts.csswg.org/cssom/#serialize-a-string
+func CSSAttrValue(s string) string {
+	var b bytes.Buffer
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == 0:
+			b.WriteRune('\uFFFD')
+		case (r >= 0x1 && r <= 0x1F) || r == 0x7F:
+			fmt.Fprintf(&b, `\%x `, r)
+		case r == '"' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
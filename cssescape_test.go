@@ -0,0 +1,46 @@
+package selenium
+
+import "testing"
+
+func TestCSSEscape(t *testing.T) {
+	// Expected values are the results of CSS.escape in browsers.
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"abc", "abc"},
+		{"a b", `a\ b`},
+		{"1a", `\31 a`},
+		{"-1a", `-\31 a`},
+		{"-", `\-`},
+		{"--", "--"},
+		{"-a", "-a"},
+		{"_x", "_x"},
+		{`a"]b`, `a\"\]b`},
+		{"a\nb", `a\a b`},
+		{"a\x00b", "a�b"},
+		{"a\x7Fb", `a\7f b`},
+		{"ünï©ode", "ünï©ode"},
+		{"#.:[]", `\#\.\:\[\]`},
+	}
+	for _, tc := range tests {
+		if got := CSSEscape(tc.in); got != tc.want {
+			t.Errorf("CSSEscape(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestCSSAttrValue(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", `""`},
+		{"abc", `"abc"`},
+		{`a"]b`, `"a\"]b"`},
+		{`a\b`, `"a\\b"`},
+		{"a\nb", `"a\a b"`},
+		{"a\x00b", "\"a�b\""},
+		{"1 2", `"1 2"`},
+	}
+	for _, tc := range tests {
+		if got := CSSAttrValue(tc.in); got != tc.want {
+			t.Errorf("CSSAttrValue(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
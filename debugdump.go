@@ -0,0 +1,170 @@
+package selenium
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DumpOptions configures WebDriver.DebugDump.
+type DumpOptions struct {
+	// RedactCookies replaces the values of cookies with a placeholder.
+	RedactCookies bool
+	// Commands is the maximum number of recent commands to include. Zero
+	// includes all commands in the command history.
+	Commands int
+	// LogTypes are the logs to include. If nil, the Browser and Driver logs
+	// are included. Logs that are not available are skipped.
+	LogTypes []LogType
+}
+
+// dumpWriter writes the sections of a debug dump to a Zip file, recording
+// the sections that could not be collected.
+type dumpWriter struct {
+	zw     *zip.Writer
+	errors bytes.Buffer
+	err    error
+}
+
+func (d *dumpWriter) write(name string, data []byte) {
+	if d.err != nil {
+		return
+	}
+	f, err := d.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		d.err = err
+		return
+	}
+	_, d.err = f.Write(data)
+}
+
+func (d *dumpWriter) writeJSON(name string, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		d.fail(name, err)
+		return
+	}
+	d.write(name, data)
+}
+
+// fail records that the section could not be collected.
+func (d *dumpWriter) fail(section string, err error) {
+	fmt.Fprintf(&d.errors, "%s: %v\n", section, err)
+}
+
+func (wd *remoteWD) DebugDump(w io.Writer, opts DumpOptions) error {
+	_, err := wd.debugDump(w, opts)
+	return err
+}
+
+// debugDump writes the dump to w, and returns the URL of the current page, if
+// it could be fetched.
+func (wd *remoteWD) debugDump(w io.Writer, opts DumpOptions) (string, error) {
+	d := &dumpWriter{zw: zip.NewWriter(w)}
+
+	// Take the command history before the dump sends commands of its own, so
+	// that it holds the exchanges that led to the failure.
+	var records []CommandRecord
+	if wd.history != nil {
+		records = wd.history.records()
+		if opts.Commands > 0 && len(records) > opts.Commands {
+			records = records[len(records)-opts.Commands:]
+		}
+	}
+
+	d.writeJSON("capabilities.json", map[string]interface{}{
+		"requested":  wd.capabilities,
+		"negotiated": wd.negotiated,
+	})
+	if fp := wd.capabilities.Fingerprint(); fp == "" {
+		d.fail("fingerprint.txt", fmt.Errorf("the capabilities cannot be encoded"))
+	} else {
+		d.write("fingerprint.txt", []byte(fp+"\n"))
+	}
+
+	page := map[string]string{"session": wd.id}
+	if u, err := wd.CurrentURL(); err != nil {
+		d.fail("page.json: url", err)
+	} else {
+		page["url"] = u
+	}
+	if t, err := wd.Title(); err != nil {
+		d.fail("page.json: title", err)
+	} else {
+		page["title"] = t
+	}
+	d.writeJSON("page.json", page)
+
+	if src, err := wd.PageSource(); err != nil {
+		d.fail("source.html", err)
+	} else {
+		d.write("source.html", []byte(src))
+	}
+
+	if png, err := wd.Screenshot(); err != nil {
+		d.fail("screenshot.png", err)
+	} else {
+		d.write("screenshot.png", png)
+	}
+
+	if cookies, err := wd.GetCookies(); err != nil {
+		d.fail("cookies.json", err)
+	} else {
+		if opts.RedactCookies {
+			for i := range cookies {
+				cookies[i].Value = "<redacted>"
+			}
+		}
+		d.writeJSON("cookies.json", cookies)
+	}
+
+	logTypes := opts.LogTypes
+	if logTypes == nil {
+		logTypes = []LogType{Browser, Driver}
+	}
+	for _, typ := range logTypes {
+		name := fmt.Sprintf("logs/%s.json", typ)
+		if msgs, err := wd.Log(typ); err != nil {
+			d.fail(name, err)
+		} else {
+			d.writeJSON(name, msgs)
+		}
+	}
+
+	if wd.history != nil {
+		d.writeJSON("commands.json", records)
+	}
+
+	versions := map[string]interface{}{"client": Version}
+	for _, key := range []string{"browserName", "browserVersion", "version", "platformName", "platform"} {
+		if v, ok := wd.negotiated[key]; ok {
+			versions[key] = v
+		}
+	}
+	for _, key := range []string{"chrome", "moz:geckodriverVersion"} {
+		if v, ok := wd.negotiated[key]; ok {
+			versions["driver"] = v
+		}
+	}
+	if status, err := wd.Status(); err != nil {
+		d.fail("versions.json: status", err)
+	} else {
+		versions["server"] = status
+	}
+	d.writeJSON("versions.json", versions)
+
+	if d.errors.Len() > 0 {
+		d.write("errors.txt", d.errors.Bytes())
+	}
+	if d.err != nil {
+		return "", d.err
+	}
+	return page["url"], d.zw.Close()
+}
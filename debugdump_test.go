@@ -0,0 +1,118 @@
+package selenium
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// dumpServer emulates a W3C session with a page, for which the driver log is
+// not available.
+func dumpServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		strValues := map[string]string{
+			"/session/123/url":        "http://example.com/",
+			"/session/123/title":      "Example",
+			"/session/123/source":     "<html></html>",
+			"/session/123/screenshot": "iVBORw0KGgo=",
+		}
+		if v, ok := strValues[r.URL.Path]; ok {
+			json.NewEncoder(w).Encode(map[string]string{"value": v})
+			return
+		}
+		switch r.URL.Path {
+		case "/session/123/element/e/value":
+			fmt.Fprint(w, `{"value":null}`)
+		case "/session/123/cookie":
+			fmt.Fprint(w, `{"value":[{"name":"sid","value":"s3cret","path":"/","domain":"example.com"}]}`)
+		case "/session/123/log":
+			body, _ := ioutil.ReadAll(r.Body)
+			if strings.Contains(string(body), "driver") {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"value":{"error":"unknown error","message":"no driver log","stacktrace":""}}`)
+				return
+			}
+			fmt.Fprint(w, `{"value":[{"timestamp":1,"level":"INFO","message":"hello"}]}`)
+		case "/status":
+			fmt.Fprint(w, `{"value":{"ready":true,"message":"ok"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestDebugDump(t *testing.T) {
+	s := dumpServer()
+	defer s.Close()
+	wd := &remoteWD{
+		id:            "123",
+		urlPrefix:     s.URL,
+		w3cCompatible: true,
+		capabilities:  Capabilities{"browserName": "chrome"},
+		negotiated:    Capabilities{"browserName": "chrome", "browserVersion": "99"},
+	}
+	wd.EnableCommandHistory(10)
+
+	elem := &remoteWE{parent: wd, id: "e"}
+	if err := elem.SendKeys("hunter2"); err != nil {
+		t.Fatalf("elem.SendKeys() returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := wd.DebugDump(&buf, DumpOptions{RedactCookies: true}); err != nil {
+		t.Fatalf("wd.DebugDump() returned error: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() returned error: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening %q returned error: %v", f.Name, err)
+		}
+		data, _ := ioutil.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(data)
+	}
+
+	for _, name := range []string{"capabilities.json", "fingerprint.txt", "page.json", "source.html", "screenshot.png", "cookies.json", "logs/browser.json", "commands.json", "versions.json", "errors.txt"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("the dump does not contain %q", name)
+		}
+	}
+	if _, ok := files["logs/driver.json"]; ok {
+		t.Errorf("the dump contains the unavailable driver log")
+	}
+	if !strings.Contains(files["errors.txt"], "logs/driver.json") {
+		t.Errorf("errors.txt = %q, want it to list the driver log", files["errors.txt"])
+	}
+	if strings.Contains(files["cookies.json"], "s3cret") {
+		t.Errorf("cookies.json contains the cookie value despite RedactCookies")
+	}
+	if !strings.Contains(files["page.json"], "http://example.com/") {
+		t.Errorf("page.json = %q, want the current URL", files["page.json"])
+	}
+	if !strings.Contains(files["versions.json"], `"99"`) {
+		t.Errorf("versions.json = %q, want the browser version", files["versions.json"])
+	}
+	if ex := files["commands.json"]; strings.Contains(ex, "hunter2") || !strings.Contains(ex, "/element/e/value") {
+		t.Errorf("commands.json = %q, want the SendKeys exchange with its keys masked", ex)
+	}
+	var records []CommandRecord
+	if err := json.Unmarshal([]byte(files["commands.json"]), &records); err != nil {
+		t.Fatalf("decoding commands.json returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("commands.json has %d records, want only the SendKeys exchange and none of the dump's own commands: %+v", len(records), records)
+	}
+}
@@ -0,0 +1,124 @@
+package selenium
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// DecodeError is returned in strict decoding mode, set with
+// WebDriver.SetStrictDecoding, for a response whose value does not have the
+// shape that the client expects, e.g. because a new version of the driver
+// renamed or nested its fields.
+type DecodeError struct {
+	// Command is the path of the command, e.g. "/session/123/element/456/rect".
+	Command string
+	// Value is the raw value of the response.
+	Value json.RawMessage
+	// Err describes the mismatch.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("unexpected value of the response to %s: %v: %s", e.Command, e.Err, e.Value)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// errZeroValue is the error of a DecodeError for a value that has fields
+// set, none of which the client decoded.
+var errZeroValue = errors.New("no field of the value was decoded")
+
+func (wd *remoteWD) SetStrictDecoding(strict bool) {
+	wd.strictDecoding = strict
+}
+
+func (wd *remoteWD) StrictDecoding() bool {
+	return wd.strictDecoding
+}
+
+// decodeStrict decodes the JSON value raw into v, which must be a pointer,
+// failing for fields of objects that have no counterpart in the structs of v,
+// and if v is left with its zero value although raw has non-zero fields.
+func decodeStrict(raw json.RawMessage, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if reflect.ValueOf(v).Elem().IsZero() && hasNonZeroLeaf(raw) {
+		return errZeroValue
+	}
+	return nil
+}
+
+// hasNonZeroLeaf reports whether the JSON value raw contains a non-empty
+// string, true or a non-zero number.
+func hasNonZeroLeaf(raw json.RawMessage) bool {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return false
+	}
+	var walk func(v interface{}) bool
+	walk = func(v interface{}) bool {
+		switch v := v.(type) {
+		case string:
+			return v != ""
+		case bool:
+			return v
+		case float64:
+			return v != 0
+		case []interface{}:
+			for _, e := range v {
+				if walk(e) {
+					return true
+				}
+			}
+		case map[string]interface{}:
+			for _, e := range v {
+				if walk(e) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return walk(v)
+}
+
+// decodeReply decodes the response to the command sent to the URL formatted
+// from urlTemplate and args into reply, which must point to a struct with a
+// Value field, after checking the shape of the value with checkShape if
+// strict is set.
+func decodeReply(response []byte, reply interface{}, strict bool, urlTemplate string, args ...interface{}) error {
+	if strict {
+		value := reflect.ValueOf(reply).Elem().FieldByName("Value").Addr().Interface()
+		if err := checkShape(response, value, urlTemplate, args...); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(response, reply)
+}
+
+// checkShape returns a DecodeError if the value of the response to the
+// command sent to the URL formatted from urlTemplate and args does not decode
+// strictly into the type that v points to. v itself is left unchanged, as
+// callers may decode the value into fields set already.
+func checkShape(response []byte, v interface{}, urlTemplate string, args ...interface{}) error {
+	reply := new(struct{ Value json.RawMessage })
+	if err := json.Unmarshal(response, reply); err != nil {
+		return err
+	}
+	if len(reply.Value) == 0 || string(reply.Value) == "null" {
+		return nil
+	}
+	fresh := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	if err := decodeStrict(reply.Value, fresh); err != nil {
+		return &DecodeError{Command: fmt.Sprintf(urlTemplate, args...), Value: reply.Value, Err: err}
+	}
+	return nil
+}
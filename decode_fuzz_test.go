@@ -0,0 +1,131 @@
+package selenium
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// decodeSeeds are replies that remote ends have been seen to send, or that
+// exercise the decoders' handling of missing and malformed values.
+var decodeSeeds = []string{
+	``,
+	`null`,
+	`{}`,
+	`[]`,
+	`{"value":null}`,
+	`{"value":{}}`,
+	`{"value":[]}`,
+	`{"value":[null]}`,
+	`{"value":[{}]}`,
+	`{"value":""}`,
+	`{"value":0}`,
+	`{"value":{"sessionId":null}}`,
+	`{"value":{"sessionId":"","capabilities":null}}`,
+	`{"sessionId":null,"status":0,"value":null}`,
+	`{"sessionId":"123","status":0,"value":null}`,
+	`{"sessionId":"123","status":0,"value":{"ELEMENT":null}}`,
+	`{"status":13,"value":{"message":null}}`,
+	`{"value":{"error":null,"message":null}}`,
+	`{"value":{"element-6066-11e4-a52e-4f735466cecf":null}}`,
+	`{"value":{"x":null,"y":null,"width":null,"height":null}}`,
+	`{"value":{"ready":null,"build":null,"os":null}}`,
+	`{"value":{"StorageEstimate":null,"Error":null}}`,
+	`{"value":`,
+	`{"value":{"sessionId":"123","capabilit`,
+	`{"value":[{"element-6066-11e4-a52e-4f735466cecf":"a"},`,
+}
+
+// exerciseDecoders calls the public commands whose replies are decoded
+// against a remote end that replies to every request with body, and reports
+// the commands that panic.
+func exerciseDecoders(t *testing.T, body []byte) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		w.Write(body)
+	}))
+	defer s.Close()
+
+	for _, w3c := range []bool{true, false} {
+		newWD := func() *remoteWD {
+			return &remoteWD{
+				id:            "123",
+				urlPrefix:     s.URL,
+				w3cCompatible: w3c,
+				capabilities:  Capabilities{"browserName": "chrome"},
+			}
+		}
+		wd := newWD()
+		elem := &remoteWE{parent: wd, id: "456"}
+		commands := []struct {
+			name string
+			f    func() error
+		}{
+			{"Status", func() error { _, err := wd.Status(); return err }},
+			{"NewSession", func() error { _, err := newWD().NewSession(); return err }},
+			{"Capabilities", func() error { _, err := wd.Capabilities(); return err }},
+			{"CurrentWindowHandle", func() error { _, err := wd.CurrentWindowHandle(); return err }},
+			{"WindowHandles", func() error { _, err := wd.WindowHandles(); return err }},
+			{"CurrentURL", func() error { _, err := wd.CurrentURL(); return err }},
+			{"Title", func() error { _, err := wd.Title(); return err }},
+			{"PageSource", func() error { _, err := wd.PageSource(); return err }},
+			{"CloseAndSwitch", func() error { _, err := newWD().CloseAndSwitch(); return err }},
+			{"FindElement", func() error { _, err := wd.FindElement(ByID, "x"); return err }},
+			{"FindElements", func() error { _, err := wd.FindElements(ByID, "x"); return err }},
+			{"ActiveElement", func() error { _, err := wd.ActiveElement(); return err }},
+			{"DecodeElement", func() error { _, err := wd.DecodeElement(body); return err }},
+			{"DecodeElements", func() error { _, err := wd.DecodeElements(body); return err }},
+			{"GetCookies", func() error { _, err := wd.GetCookies(); return err }},
+			{"GetCookie", func() error { _, err := wd.GetCookie("c"); return err }},
+			{"Screenshot", func() error { _, err := wd.Screenshot(); return err }},
+			{"Log", func() error { _, err := wd.Log(Browser); return err }},
+			{"LocalStorage.Keys", func() error { _, err := wd.LocalStorage().Keys(); return err }},
+			{"StorageEstimate", func() error { _, err := wd.StorageEstimate(); return err }},
+			{"AlertText", func() error { _, err := wd.AlertText(); return err }},
+			{"ExecuteScript", func() error { _, err := wd.ExecuteScript("return 1", nil); return err }},
+			{"ExecuteScriptAsync", func() error { _, err := wd.ExecuteScriptAsync("return 1", nil); return err }},
+			{"DebugDump", func() error { return newWD().DebugDump(new(bytes.Buffer), DumpOptions{}) }},
+			{"WebElement.FindElement", func() error { _, err := elem.FindElement(ByID, "x"); return err }},
+			{"WebElement.FindElements", func() error { _, err := elem.FindElements(ByID, "x"); return err }},
+			{"WebElement.FindElementRaw", func() error { _, err := elem.FindElementRaw(ByID, "x"); return err }},
+			{"WebElement.TagName", func() error { _, err := elem.TagName(); return err }},
+			{"WebElement.Text", func() error { _, err := elem.Text(); return err }},
+			{"WebElement.IsSelected", func() error { _, err := elem.IsSelected(); return err }},
+			{"WebElement.IsEnabled", func() error { _, err := elem.IsEnabled(); return err }},
+			{"WebElement.IsDisplayed", func() error { _, err := elem.IsDisplayed(); return err }},
+			{"WebElement.GetAttribute", func() error { _, err := elem.GetAttribute("a"); return err }},
+			{"WebElement.Location", func() error { _, err := elem.Location(); return err }},
+			{"WebElement.LocationInView", func() error { _, err := elem.LocationInView(); return err }},
+			{"WebElement.Size", func() error { _, err := elem.Size(); return err }},
+			{"WebElement.CSSProperty", func() error { _, err := elem.CSSProperty("color"); return err }},
+			{"WebElement.Describe", func() error { elem.InvalidateCache(); _, err := elem.Describe(); return err }},
+			{"WebElement.ScrollableAncestor", func() error { _, err := elem.ScrollableAncestor(); return err }},
+		}
+		for _, c := range commands {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Errorf("w3c=%t: %s panicked on reply %q: %v", w3c, c.name, body, r)
+					}
+				}()
+				c.f()
+			}()
+		}
+	}
+}
+
+func TestDecodeSeeds(t *testing.T) {
+	for _, seed := range decodeSeeds {
+		exerciseDecoders(t, []byte(seed))
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, seed := range decodeSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		exerciseDecoders(t, body)
+	})
+}
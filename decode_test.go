@@ -0,0 +1,112 @@
+package selenium
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStrictDecoding(t *testing.T) {
+	var reply string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		w.Write([]byte(reply))
+	}))
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "456"}
+
+	for _, tc := range []struct {
+		name, reply string
+		want        Rect
+		// mismatch is set for replies that strict decoding rejects.
+		mismatch bool
+	}{
+		{name: "expected", reply: `{"value":{"x":1.5,"y":2,"width":3,"height":4}}`, want: Rect{1.5, 2, 3, 4}},
+		{name: "zero", reply: `{"value":{"x":0,"y":0,"width":0,"height":0}}`},
+		{name: "renamed fields", reply: `{"value":{"left":1.5,"top":2,"w":3,"h":4}}`, mismatch: true},
+		{name: "extra field", reply: `{"value":{"x":1.5,"y":2,"width":3,"height":4,"depth":5}}`, want: Rect{1.5, 2, 3, 4}, mismatch: true},
+		{name: "extra nesting", reply: `{"value":{"rect":{"x":1.5,"y":2,"width":3,"height":4}}}`, mismatch: true},
+	} {
+		reply = tc.reply
+		wantValue := json.RawMessage(tc.reply[len(`{"value":`) : len(tc.reply)-1])
+		for _, strict := range []bool{false, true} {
+			wd.SetStrictDecoding(strict)
+			check := func(command string, got Rect, err error) {
+				t.Helper()
+				var de *DecodeError
+				switch {
+				case strict && tc.mismatch:
+					if !errors.As(err, &de) || string(de.Value) != string(wantValue) {
+						t.Errorf("%s: strict %s returned %+v, %v; want a DecodeError carrying %s", tc.name, command, got, err, wantValue)
+					}
+				case err != nil || got != tc.want:
+					t.Errorf("%s: %s with strict decoding %t returned %+v, %v; want %+v, nil", tc.name, command, strict, got, err, tc.want)
+				}
+			}
+
+			got, err := GetValue[Rect](wd, "/session/%s/element/456/rect", wd.id)
+			check("GetValue()", got, err)
+			r, err := elem.Rect()
+			if r == nil {
+				r = &Rect{}
+			}
+			check("elem.Rect()", *r, err)
+			r, err = wd.WindowRect("")
+			if r == nil {
+				r = &Rect{}
+			}
+			check("WindowRect()", *r, err)
+			// Wrappers such as those of package compat embed the driver.
+			got, err = GetValue[Rect](struct{ WebDriver }{wd}, "/session/%s/element/456/rect", wd.id)
+			check("GetValue() on a wrapped driver", got, err)
+		}
+	}
+}
+
+func TestHasNonZeroLeaf(t *testing.T) {
+	for _, tc := range []struct {
+		raw  string
+		want bool
+	}{
+		{`null`, false},
+		{`""`, false},
+		{`0`, false},
+		{`false`, false},
+		{`[]`, false},
+		{`{}`, false},
+		{`{"a":[0,null,{"b":""}],"c":false}`, false},
+		{`"x"`, true},
+		{`true`, true},
+		{`-0.5`, true},
+		{`{"a":[0,null,{"b":"x"}]}`, true},
+	} {
+		if got := hasNonZeroLeaf(json.RawMessage(tc.raw)); got != tc.want {
+			t.Errorf("hasNonZeroLeaf(%s) = %t, want %t", tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestNullValueErrorQuotesNames(t *testing.T) {
+	var path string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		path = r.URL.Path
+		w.Write([]byte(`{"status":0,"value":null}`))
+	}))
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL}
+	elem := &remoteWE{parent: wd, id: "456"}
+
+	// The name is part of the URL, not of its template.
+	const name = "data-100%"
+	_, err := elem.GetAttribute(name)
+	if want := "server returned null for /session/123/element/456/attribute/data-100%25"; err == nil || err.Error() != want {
+		t.Errorf("elem.GetAttribute(%q) returned error %v, want %q", name, err, want)
+	}
+	if want := "/session/123/element/456/attribute/" + name; path != want {
+		t.Errorf("elem.GetAttribute(%q) requested %q, want %q", name, path, want)
+	}
+}
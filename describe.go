@@ -0,0 +1,62 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ElementInfo holds commonly queried properties of an element. It is returned
+// by WebElement.Describe.
+type ElementInfo struct {
+	// TagName is the element's lower-case tag name, e.g. "select".
+	TagName string
+	// ID, Name and Type are the values of the element's "id", "name" and "type"
+	// attributes, or empty if the attribute is not present.
+	ID, Name, Type string
+	// Class is the value of the element's "class" attribute.
+	Class string
+	// X and Y are the coordinates of the top-left corner of the element
+	// relative to the document, and Width and Height its dimensions.
+	X, Y, Width, Height float64
+}
+
+// describeScript returns the properties of ElementInfo for the element passed
+// as the first argument.
+const describeScript = `
+var elem = arguments[0];
+var r = elem.getBoundingClientRect();
+return {
+	tagName: elem.tagName.toLowerCase(),
+	id: elem.getAttribute('id') || '',
+	name: elem.getAttribute('name') || '',
+	type: elem.getAttribute('type') || '',
+	class: elem.getAttribute('class') || '',
+	x: r.left + window.pageXOffset,
+	y: r.top + window.pageYOffset,
+	width: r.width,
+	height: r.height
+};
+`
+
+func (elem *remoteWE) Describe() (*ElementInfo, error) {
+	if err := elem.checkID(); err != nil {
+		return nil, err
+	}
+	raw, err := elem.parent.ExecuteScriptRaw(describeScript, []interface{}{elem})
+	if err != nil {
+		return nil, elem.noteError(err)
+	}
+	reply := new(struct{ Value *ElementInfo })
+	if err := json.Unmarshal(raw, reply); err != nil {
+		return nil, err
+	}
+	if reply.Value == nil {
+		return nil, fmt.Errorf("nil return value")
+	}
+	elem.tagName = reply.Value.TagName
+	return reply.Value, nil
+}
+
+func (elem *remoteWE) InvalidateCache() {
+	elem.tagName = ""
+}
@@ -0,0 +1,171 @@
+package selenium
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// selectServer emulates a W3C server presenting a <select> element with a
+// number of options. It counts the requests it receives.
+type selectServer struct {
+	options  int
+	requests int64
+	// stale causes element commands to return a stale element error.
+	stale bool
+}
+
+func (s *selectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&s.requests, 1)
+	w.Header().Set("Content-Type", JSONType)
+	path := strings.TrimPrefix(r.URL.Path, "/session/123")
+	if s.stale && path != "/elements" {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"value":{"error":"stale element reference","message":"gone","stacktrace":""}}`)
+		return
+	}
+	switch {
+	case path == "/elements":
+		var elems []string
+		for i := 0; i < s.options; i++ {
+			elems = append(elems, fmt.Sprintf(`{%q:"option-%d"}`, webElementIdentifier, i))
+		}
+		fmt.Fprintf(w, `{"value":[%s]}`, strings.Join(elems, ","))
+	case path == "/execute/sync":
+		// Both the describe script and the file dialog guard script are
+		// executed here; the guard only checks for a boolean true.
+		fmt.Fprint(w, `{"value":{"tagName":"option","id":"","name":"","type":"","class":"choice","x":10,"y":20,"width":100,"height":16}}`)
+	case strings.HasSuffix(path, "/name"):
+		fmt.Fprint(w, `{"value":"option"}`)
+	case strings.HasSuffix(path, "/rect"):
+		fmt.Fprint(w, `{"value":{"x":10,"y":20,"width":100,"height":16}}`)
+	case strings.HasSuffix(path, "/click"):
+		fmt.Fprint(w, `{"value":null}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestDescribeCache(t *testing.T) {
+	ss := &selectServer{options: 1}
+	s := httptest.NewServer(ss)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	elems, err := wd.FindElements(ByTagName, "option")
+	if err != nil {
+		t.Fatalf("wd.FindElements() returned error: %v", err)
+	}
+	elem := elems[0]
+
+	info, err := elem.Describe()
+	if err != nil {
+		t.Fatalf("elem.Describe() returned error: %v", err)
+	}
+	want := ElementInfo{TagName: "option", Class: "choice", X: 10, Y: 20, Width: 100, Height: 16}
+	if *info != want {
+		t.Fatalf("elem.Describe() = %+v, want %+v", *info, want)
+	}
+
+	before := atomic.LoadInt64(&ss.requests)
+	if name, err := elem.TagName(); err != nil || name != "option" {
+		t.Fatalf("elem.TagName() = %q, %v, want %q, nil", name, err, "option")
+	}
+	if n := atomic.LoadInt64(&ss.requests) - before; n != 0 {
+		t.Errorf("elem.TagName() after Describe made %d requests, want 0", n)
+	}
+
+	// The rectangle and the attributes may change, so they are not cached.
+	before = atomic.LoadInt64(&ss.requests)
+	if _, err := elem.Describe(); err != nil {
+		t.Fatalf("elem.Describe() returned error: %v", err)
+	}
+	if size, err := elem.Size(); err != nil || *size != (Size{100, 16}) {
+		t.Fatalf("elem.Size() = %v, %v, want {100 16}, nil", size, err)
+	}
+	if n := atomic.LoadInt64(&ss.requests) - before; n != 2 {
+		t.Errorf("elem.Describe() and elem.Size() made %d requests, want 2", n)
+	}
+
+	// The file dialog guard needs no script for an element known not to be
+	// an input.
+	wd.fileDialogGuard = true
+	before = atomic.LoadInt64(&ss.requests)
+	if err := elem.Click(); err != nil {
+		t.Fatalf("elem.Click() returned error: %v", err)
+	}
+	if n := atomic.LoadInt64(&ss.requests) - before; n != 1 {
+		t.Errorf("elem.Click() with the file dialog guard made %d requests, want 1", n)
+	}
+	wd.fileDialogGuard = false
+
+	elem.InvalidateCache()
+	before = atomic.LoadInt64(&ss.requests)
+	if _, err := elem.TagName(); err != nil {
+		t.Fatalf("elem.TagName() returned error: %v", err)
+	}
+	if n := atomic.LoadInt64(&ss.requests) - before; n != 1 {
+		t.Errorf("elem.TagName() after InvalidateCache made %d requests, want 1", n)
+	}
+
+	// A stale element error from any command invalidates the cache.
+	if _, err := elem.Describe(); err != nil {
+		t.Fatalf("elem.Describe() returned error: %v", err)
+	}
+	ss.stale = true
+	if err := elem.Click(); !isStaleElement(err) {
+		t.Fatalf("elem.Click() returned error %v, want a stale element error", err)
+	}
+	if _, err := elem.TagName(); !isStaleElement(err) {
+		t.Errorf("elem.TagName() after a stale element error returned error %v, want a stale element error", err)
+	}
+}
+
+// BenchmarkSelectWorkflow reports the number of requests made by a workflow
+// that inspects and clicks every option of a <select> element with the file
+// dialog guard enabled, with and without the tag name cache.
+func BenchmarkSelectWorkflow(b *testing.B) {
+	for _, cached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cached=%t", cached), func(b *testing.B) {
+			ss := &selectServer{options: 20}
+			s := httptest.NewServer(ss)
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true, fileDialogGuard: true}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				options, err := wd.FindElements(ByTagName, "option")
+				if err != nil {
+					b.Fatal(err)
+				}
+				for _, option := range options {
+					if _, err := option.Describe(); err != nil {
+						b.Fatal(err)
+					}
+					if !cached {
+						option.InvalidateCache()
+					}
+					if _, err := option.TagName(); err != nil {
+						b.Fatal(err)
+					}
+					if _, err := option.Location(); err != nil {
+						b.Fatal(err)
+					}
+					if _, err := option.Size(); err != nil {
+						b.Fatal(err)
+					}
+					if !cached {
+						option.InvalidateCache()
+					}
+					if err := option.Click(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+			b.ReportMetric(float64(atomic.LoadInt64(&ss.requests))/float64(b.N), "requests/op")
+		})
+	}
+}
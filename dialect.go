@@ -0,0 +1,312 @@
+package selenium
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// DialectWarning describes a call whose behavior is known to differ between
+// the legacy (JSON Wire) protocol and the W3C WebDriver protocol.
+type DialectWarning struct {
+	// ID is a short, stable identifier of the difference, e.g.
+	// "sendkeys-newline".
+	ID string
+	// Command is the name of the method that was called, e.g. "SendKeys".
+	Command string
+	// W3C is true if the session uses the W3C protocol.
+	W3C bool
+	// Description explains the difference.
+	Description string
+	// Reference points to documentation of the difference.
+	Reference string
+}
+
+func (w DialectWarning) String() string {
+	dialect := "legacy"
+	if w.W3C {
+		dialect = "W3C"
+	}
+	return fmt.Sprintf("%s (%s, %s dialect): %s See %s", w.ID, w.Command, dialect, w.Description, w.Reference)
+}
+
+// DialectWarningHandler is called with each dialect warning emitted by a
+// WebDriver with dialect warnings enabled. The default handler writes the
+// warning to the standard logger.
+var DialectWarningHandler = func(w DialectWarning) {
+	log.Printf("selenium: dialect warning: %s", w)
+}
+
+// Which dialects a difference applies to.
+const (
+	dialectLegacy = 1 << iota
+	dialectW3C
+	dialectBoth = dialectLegacy | dialectW3C
+)
+
+const w3cSpecURL = "https://www.w3.org/TR/webdriver/"
+
+// dialectDifference is an entry in the catalogue of known differences between
+// the protocols.
+type dialectDifference struct {
+	id, command string
+	dialects    int
+	description string
+	reference   string
+	// applies reports whether a call with the given arguments is affected. A
+	// nil function matches every call.
+	applies func(args []interface{}) bool
+}
+
+// booleanAttributes are HTML attributes whose presence, not value, is
+// significant.
+var booleanAttributes = map[string]bool{
+	"allowfullscreen": true, "async": true, "autofocus": true,
+	"autoplay": true, "checked": true, "controls": true, "default": true,
+	"defer": true, "disabled": true, "formnovalidate": true, "hidden": true,
+	"inert": true, "ismap": true, "itemscope": true, "loop": true,
+	"multiple": true, "muted": true, "nomodule": true, "novalidate": true,
+	"open": true, "playsinline": true, "readonly": true, "required": true,
+	"reversed": true, "selected": true,
+}
+
+func argContains(substr string) func([]interface{}) bool {
+	return func(args []interface{}) bool {
+		if len(args) == 0 {
+			return false
+		}
+		s, ok := args[0].(string)
+		return ok && strings.Contains(s, substr)
+	}
+}
+
+func argEquals(values ...interface{}) func([]interface{}) bool {
+	return func(args []interface{}) bool {
+		if len(args) == 0 {
+			return false
+		}
+		for _, v := range values {
+			if args[0] == v {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func argIsString(args []interface{}) bool {
+	if len(args) == 0 {
+		return false
+	}
+	s, ok := args[0].(string)
+	return ok && s != ""
+}
+
+// dialectDifferences is the catalogue of known differences.
+var dialectDifferences = []dialectDifference{
+	{
+		id: "sendkeys-newline", command: "SendKeys", dialects: dialectBoth,
+		description: `A "\n" in the keys is typed as a newline character by some drivers and as the Enter key by others; use EnterKey or ReturnKey explicitly.`,
+		reference:   w3cSpecURL + "#element-send-keys",
+		applies:     argContains("\n"),
+	},
+	{
+		id: "sendkeys-return-enter", command: "SendKeys", dialects: dialectW3C,
+		description: "ReturnKey and EnterKey are distinct keys in the W3C key table and may produce different key events, whereas legacy drivers treated them identically.",
+		reference:   w3cSpecURL + "#keyboard-actions",
+		applies:     argContains(ReturnKey),
+	},
+	{
+		id: "sendkeys-caret-position", command: "SendKeys", dialects: dialectW3C,
+		description: "W3C drivers move the caret to the end of an input's existing value before typing, whereas legacy drivers typed at the current caret position.",
+		reference:   w3cSpecURL + "#element-send-keys",
+	},
+	{
+		id: "click-interactability", command: "Click", dialects: dialectW3C,
+		description: `W3C drivers scroll the element into view and return "element not interactable" or "element click intercepted" errors if it is obscured, whereas legacy drivers clicked at the element's coordinates regardless.`,
+		reference:   w3cSpecURL + "#element-click",
+	},
+	{
+		id: "getattribute-boolean", command: "GetAttribute", dialects: dialectW3C,
+		description: `For boolean attributes, W3C drivers return "true" if the attribute is present and null otherwise, whereas legacy drivers returned "true" or "false"; GetAttributeOrProperty returns "true" or the empty string on both.`,
+		reference:   w3cSpecURL + "#get-element-attribute",
+		applies: func(args []interface{}) bool {
+			if len(args) == 0 {
+				return false
+			}
+			s, ok := args[0].(string)
+			return ok && booleanAttributes[strings.ToLower(s)]
+		},
+	},
+	{
+		id: "getattribute-property", command: "GetAttribute", dialects: dialectW3C,
+		description: "W3C drivers return the attribute from the markup, whereas legacy drivers returned the current property value (e.g. the current value of an input rather than its initial value); use GetAttributeOrProperty for the legacy behavior.",
+		reference:   w3cSpecURL + "#get-element-attribute",
+		applies:     argEquals("value", "checked", "selected", "href", "src", "class", "style"),
+	},
+	{
+		id: "find-by-id-emulated", command: "FindElement", dialects: dialectW3C,
+		description: `The W3C protocol has no "id" locator strategy; it is emulated with a CSS selector.`,
+		reference:   w3cSpecURL + "#locator-strategies",
+		applies:     argEquals(ByID),
+	},
+	{
+		id: "find-by-name-emulated", command: "FindElement", dialects: dialectW3C,
+		description: `The W3C protocol has no "name" locator strategy; it is emulated with a CSS selector that only matches <input> elements.`,
+		reference:   w3cSpecURL + "#locator-strategies",
+		applies:     argEquals(ByName),
+	},
+	{
+		id: "switchframe-by-name", command: "SwitchFrame", dialects: dialectW3C,
+		description: "The W3C protocol cannot switch to a frame by name; the frame is located by its ID attribute instead, and frames identified only by name are not found.",
+		reference:   w3cSpecURL + "#switch-to-frame",
+		applies:     argIsString,
+	},
+	{
+		id: "window-by-name-emulated", command: "MaximizeWindow", dialects: dialectW3C,
+		description: "The W3C protocol can only modify the current window; modifying another window is emulated by switching to it and back.",
+		reference:   w3cSpecURL + "#maximize-window",
+		applies:     argIsString,
+	},
+	{
+		id: "window-by-name-emulated", command: "MinimizeWindow", dialects: dialectW3C,
+		description: "The W3C protocol can only modify the current window; modifying another window is emulated by switching to it and back.",
+		reference:   w3cSpecURL + "#minimize-window",
+		applies:     argIsString,
+	},
+	{
+		id: "window-by-name-emulated", command: "FullscreenWindow", dialects: dialectW3C,
+		description: "The W3C protocol can only modify the current window; modifying another window is emulated by switching to it and back.",
+		reference:   w3cSpecURL + "#fullscreen-window",
+		applies:     argIsString,
+	},
+	{
+		id: "window-by-name-emulated", command: "ResizeWindow", dialects: dialectW3C,
+		description: "The W3C protocol can only modify the current window; modifying another window is emulated by switching to it and back.",
+		reference:   w3cSpecURL + "#set-window-rect",
+		applies:     argIsString,
+	},
+	{
+		id: "window-by-name-emulated", command: "WindowRect", dialects: dialectW3C,
+		description: "The W3C protocol can only query the current window; querying another window is emulated by switching to it and back.",
+		reference:   w3cSpecURL + "#get-window-rect",
+		applies:     argIsString,
+	},
+	{
+		id: "window-by-name-emulated", command: "SetWindowRect", dialects: dialectW3C,
+		description: "The W3C protocol can only modify the current window; modifying another window is emulated by switching to it and back.",
+		reference:   w3cSpecURL + "#set-window-rect",
+		applies:     argIsString,
+	},
+	{
+		id: "window-by-name-emulated", command: "GetWindowPosition", dialects: dialectW3C,
+		description: "The W3C protocol can only query the current window; querying another window is emulated by switching to it and back.",
+		reference:   w3cSpecURL + "#get-window-rect",
+		applies:     argIsString,
+	},
+	{
+		id: "window-by-name-emulated", command: "SetWindowPosition", dialects: dialectW3C,
+		description: "The W3C protocol can only modify the current window; modifying another window is emulated by switching to it and back.",
+		reference:   w3cSpecURL + "#set-window-rect",
+		applies:     argIsString,
+	},
+	{
+		id: "sendmodifier-emulated", command: "SendModifier", dialects: dialectW3C,
+		description: "The W3C protocol has no modifier endpoint; it is emulated with key actions, whose state persists until released.",
+		reference:   w3cSpecURL + "#actions",
+	},
+	{
+		id: "keyup-toggles", command: "KeyUp", dialects: dialectLegacy,
+		description: "The legacy protocol has no key-up command; sending the keys again toggles modifier keys, which releases them only if they are currently pressed.",
+		reference:   w3cSpecURL + "#actions",
+	},
+	{
+		id: "location-in-view-no-scroll", command: "LocationInView", dialects: dialectW3C,
+		description: "The W3C protocol has no location-in-view endpoint; the element's rectangle is returned without scrolling it into view.",
+		reference:   w3cSpecURL + "#get-element-rect",
+	},
+	{
+		id: "legacy-mouse-endpoint", command: "MoveTo", dialects: dialectW3C,
+		description: "This command uses a legacy-only mouse endpoint that W3C drivers may not implement; use actions instead.",
+		reference:   w3cSpecURL + "#actions",
+	},
+	{
+		id: "legacy-mouse-endpoint", command: "DoubleClick", dialects: dialectW3C,
+		description: "This command uses a legacy-only mouse endpoint that W3C drivers may not implement; use WebElement.DoubleClick or actions instead.",
+		reference:   w3cSpecURL + "#actions",
+	},
+	{
+		id: "legacy-mouse-endpoint", command: "ButtonDown", dialects: dialectW3C,
+		description: "This command uses a legacy-only mouse endpoint that W3C drivers may not implement; use actions instead.",
+		reference:   w3cSpecURL + "#actions",
+	},
+	{
+		id: "legacy-mouse-endpoint", command: "ButtonUp", dialects: dialectW3C,
+		description: "This command uses a legacy-only mouse endpoint that W3C drivers may not implement; use actions instead.",
+		reference:   w3cSpecURL + "#actions",
+	},
+	{
+		id: "submit-legacy-only", command: "Submit", dialects: dialectW3C,
+		description: "The W3C protocol has no element submit endpoint; Submit is emulated with a script that clicks the default button of the form, or fires a submit event and submits the form if it has none.",
+		reference:   w3cSpecURL + "#elements",
+	},
+}
+
+// detectDialectDifferences returns the warnings for a call to command with the
+// given arguments.
+func detectDialectDifferences(w3c bool, command string, args ...interface{}) []DialectWarning {
+	dialect := dialectLegacy
+	if w3c {
+		dialect = dialectW3C
+	}
+	var warnings []DialectWarning
+	for _, d := range dialectDifferences {
+		if d.command != command || d.dialects&dialect == 0 {
+			continue
+		}
+		if d.applies != nil && !d.applies(args) {
+			continue
+		}
+		warnings = append(warnings, DialectWarning{
+			ID:          d.id,
+			Command:     command,
+			W3C:         w3c,
+			Description: d.description,
+			Reference:   d.reference,
+		})
+	}
+	return warnings
+}
+
+func (wd *remoteWD) SetDialectWarnings(enabled bool) {
+	wd.dialectWarnings = enabled
+}
+
+// checkDialect emits warnings for a call to command, if dialect warnings are
+// enabled. Each difference is reported at most once per command for the
+// lifetime of the WebDriver.
+func (wd *remoteWD) checkDialect(command string, args ...interface{}) {
+	if !wd.dialectWarnings {
+		return
+	}
+	for _, w := range detectDialectDifferences(wd.w3cCompatible, command, args...) {
+		if wd.firstDialectWarning(w.ID + "/" + w.Command) {
+			DialectWarningHandler(w)
+		}
+	}
+}
+
+// firstDialectWarning records the warning with the given key and returns
+// true if it was not emitted before.
+func (wd *remoteWD) firstDialectWarning(key string) bool {
+	wd.dialectMu.Lock()
+	defer wd.dialectMu.Unlock()
+	if wd.dialectWarned[key] {
+		return false
+	}
+	if wd.dialectWarned == nil {
+		wd.dialectWarned = make(map[string]bool)
+	}
+	wd.dialectWarned[key] = true
+	return true
+}
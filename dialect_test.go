@@ -0,0 +1,110 @@
+package selenium
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestDetectDialectDifferences(t *testing.T) {
+	tests := []struct {
+		w3c     bool
+		command string
+		args    []interface{}
+		want    []string
+	}{
+		{true, "SendKeys", []interface{}{"a\nb"}, []string{"sendkeys-newline", "sendkeys-caret-position"}},
+		{false, "SendKeys", []interface{}{"a\nb"}, []string{"sendkeys-newline"}},
+		{true, "SendKeys", []interface{}{"a" + ReturnKey}, []string{"sendkeys-return-enter", "sendkeys-caret-position"}},
+		{false, "SendKeys", []interface{}{"abc"}, nil},
+		{true, "Click", nil, []string{"click-interactability"}},
+		{false, "Click", nil, nil},
+		{true, "GetAttribute", []interface{}{"Disabled"}, []string{"getattribute-boolean"}},
+		{true, "GetAttribute", []interface{}{"checked"}, []string{"getattribute-boolean", "getattribute-property"}},
+		{true, "GetAttribute", []interface{}{"value"}, []string{"getattribute-property"}},
+		{true, "GetAttribute", []interface{}{"data-foo"}, nil},
+		{true, "FindElement", []interface{}{ByID}, []string{"find-by-id-emulated"}},
+		{true, "FindElement", []interface{}{ByName}, []string{"find-by-name-emulated"}},
+		{true, "FindElement", []interface{}{ByCSSSelector}, nil},
+		{false, "FindElement", []interface{}{ByID}, nil},
+		{true, "SwitchFrame", []interface{}{"frameName"}, []string{"switchframe-by-name"}},
+		{true, "SwitchFrame", []interface{}{nil}, nil},
+		{true, "MaximizeWindow", []interface{}{"other"}, []string{"window-by-name-emulated"}},
+		{true, "MaximizeWindow", []interface{}{""}, nil},
+		{true, "ResizeWindow", []interface{}{"other"}, []string{"window-by-name-emulated"}},
+		{true, "MinimizeWindow", []interface{}{"other"}, []string{"window-by-name-emulated"}},
+		{true, "FullscreenWindow", []interface{}{"other"}, []string{"window-by-name-emulated"}},
+		{true, "WindowRect", []interface{}{"other"}, []string{"window-by-name-emulated"}},
+		{true, "SendModifier", []interface{}{ShiftKey}, []string{"sendmodifier-emulated"}},
+		{false, "KeyUp", []interface{}{ShiftKey}, []string{"keyup-toggles"}},
+		{true, "KeyUp", []interface{}{ShiftKey}, nil},
+		{true, "LocationInView", nil, []string{"location-in-view-no-scroll"}},
+		{true, "MoveTo", []interface{}{0, 0}, []string{"legacy-mouse-endpoint"}},
+		{true, "DoubleClick", nil, []string{"legacy-mouse-endpoint"}},
+		{true, "ButtonDown", nil, []string{"legacy-mouse-endpoint"}},
+		{true, "ButtonUp", nil, []string{"legacy-mouse-endpoint"}},
+		{true, "Submit", nil, []string{"submit-legacy-only"}},
+	}
+	detected := make(map[string]bool)
+	for _, tc := range tests {
+		var got []string
+		for _, w := range detectDialectDifferences(tc.w3c, tc.command, tc.args...) {
+			got = append(got, w.ID)
+			detected[w.ID] = true
+			if w.Description == "" || w.Reference == "" {
+				t.Errorf("warning %q for %s is not documented", w.ID, tc.command)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("detectDialectDifferences(%t, %q, %v) = %v, want %v", tc.w3c, tc.command, tc.args, got, tc.want)
+		}
+	}
+	if len(detected) < 10 {
+		t.Errorf("detected %d distinct differences, want at least 10", len(detected))
+	}
+	for _, d := range dialectDifferences {
+		if !detected[d.id] {
+			t.Errorf("difference %q is not exercised by this test", d.id)
+		}
+	}
+}
+
+func TestCheckDialectReportsOnce(t *testing.T) {
+	old := DialectWarningHandler
+	defer func() { DialectWarningHandler = old }()
+	var got []DialectWarning
+	DialectWarningHandler = func(w DialectWarning) { got = append(got, w) }
+
+	wd := &remoteWD{w3cCompatible: true}
+	wd.checkDialect("Click")
+	if len(got) != 0 {
+		t.Fatalf("checkDialect() with warnings disabled emitted %v", got)
+	}
+	wd.SetDialectWarnings(true)
+	wd.checkDialect("Click")
+	wd.checkDialect("Click")
+	if len(got) != 1 || got[0].ID != "click-interactability" {
+		t.Fatalf("checkDialect() emitted %v, want a single click-interactability warning", got)
+	}
+
+	// Commands may be checked concurrently; run with -race.
+	var mu sync.Mutex
+	DialectWarningHandler = func(w DialectWarning) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, w)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wd.checkDialect("Click")
+			wd.checkDialect("SwitchFrame", "name")
+		}()
+	}
+	wg.Wait()
+	if len(got) != 2 {
+		t.Errorf("concurrent checkDialect() calls emitted %v, want one SwitchFrame warning after the Click one", got)
+	}
+}
@@ -0,0 +1,58 @@
+package selenium
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+// isDisplayedAtom implements the WebDriver "element displayedness" algorithm
+// that the specification delegates to the Selenium atoms. It is embedded from
+// atoms/isDisplayed.js, a function expression that is applied to the element,
+// as the isDisplayed.js atom distributed with the other bindings is.
+//
+// go generate replaces the file with that atom, taken verbatim from the
+// Python bindings of the Selenium version given below, and records the
+// version and the checksum of its source in the file. Until then, the file
+// holds a port of bot.dom.isShown with opacity ignored, as its header says.
+//
+//go:generate go run atoms/update.go -version 4.25.0
+//go:embed atoms/isDisplayed.js
+var isDisplayedAtom string
+
+// isDisplayedScript calls isDisplayedAtom on the element passed as the first
+// argument.
+var isDisplayedScript = `return (` + isDisplayedAtom + `).apply(null, arguments);`
+
+func (elem *remoteWE) IsDisplayed() (bool, error) {
+	if !elem.parent.displayedUnsupported {
+		displayed, err := elem.boolCommand("/displayed")
+		if !isUnknownCommand(err) {
+			return displayed, err
+		}
+		// The /displayed endpoint is an extension to the W3C specification,
+		// which remote ends are not required to implement. Remember that for
+		// the rest of the session.
+		elem.parent.displayedUnsupported = true
+	}
+	return elem.displayedByAtom()
+}
+
+// displayedByAtom determines whether the element is displayed using
+// isDisplayedAtom.
+func (elem *remoteWE) displayedByAtom() (bool, error) {
+	if err := elem.checkID(); err != nil {
+		return false, err
+	}
+	response, err := elem.parent.ExecuteScriptRaw(isDisplayedScript, []interface{}{elem})
+	if err != nil {
+		return false, elem.wrapError("displayed", err)
+	}
+	reply := new(struct{ Value *bool })
+	if err := json.Unmarshal(response, reply); err != nil {
+		return false, err
+	}
+	if reply.Value == nil {
+		return false, nullValueError("/session/%s/execute", elem.parent.id)
+	}
+	return *reply.Value, nil
+}
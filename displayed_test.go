@@ -0,0 +1,88 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsDisplayedFallback(t *testing.T) {
+	var displayedRequests, scriptRequests int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch r.URL.Path {
+		case "/session/123/element/e1/displayed":
+			displayedRequests++
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"unknown command","message":"not implemented"}}`)
+		case "/session/123/execute/sync":
+			scriptRequests++
+			body, _ := ioutil.ReadAll(r.Body)
+			var params struct {
+				Script string
+				Args   []map[string]string
+			}
+			json.Unmarshal(body, &params)
+			if params.Script != isDisplayedScript {
+				t.Errorf("the fallback executed %q, want the displayedness atom", params.Script)
+			}
+			if len(params.Args) != 1 || params.Args[0][webElementIdentifier] != "e1" {
+				t.Errorf("the fallback passed arguments %v, want the element", params.Args)
+			}
+			fmt.Fprint(w, `{"value":true}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "e1"}
+	for i := 0; i < 2; i++ {
+		if displayed, err := elem.IsDisplayed(); err != nil || !displayed {
+			t.Fatalf("IsDisplayed() = %t, %v; want true, nil", displayed, err)
+		}
+	}
+	if displayedRequests != 1 || scriptRequests != 2 {
+		t.Errorf("IsDisplayed() made %d /displayed and %d script requests, want 1 and 2", displayedRequests, scriptRequests)
+	}
+
+	// A new session may be served by a different remote end.
+	wd.SwitchSession("123")
+	elem.IsDisplayed()
+	if displayedRequests != 2 {
+		t.Errorf("IsDisplayed() after SwitchSession() made %d /displayed requests in total, want 2", displayedRequests)
+	}
+}
+
+func TestIsDisplayedNative(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch r.URL.Path {
+		case "/session/123/element/e1/displayed":
+			fmt.Fprint(w, `{"value":false}`)
+		case "/session/123/element/e2/displayed":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"stale element reference","message":"gone"}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	if displayed, err := (&remoteWE{parent: wd, id: "e1"}).IsDisplayed(); err != nil || displayed {
+		t.Errorf("IsDisplayed() = %t, %v; want false, nil", displayed, err)
+	}
+	if _, err := (&remoteWE{parent: wd, id: "e2"}).IsDisplayed(); !isStaleElement(err) {
+		t.Errorf("IsDisplayed() of a stale element returned error %v, want stale element reference", err)
+	}
+	if wd.displayedUnsupported {
+		t.Errorf("errors other than unknown command disabled the /displayed endpoint")
+	}
+}
@@ -0,0 +1,49 @@
+package selenium
+
+// dragAndDropScript simulates an HTML5 drag and drop of the element passed as
+// the first argument onto the element passed as the second argument, by
+// dispatching the drag events that the browser would fire with a shared
+// DataTransfer.
+const dragAndDropScript = `
+var source = arguments[0], target = arguments[1];
+var data = typeof DataTransfer === 'function' ? new DataTransfer() : null;
+function center(elem) {
+	var r = elem.getBoundingClientRect();
+	return {x: r.left + r.width / 2, y: r.top + r.height / 2};
+}
+function fire(elem, type, point) {
+	var event;
+	try {
+		event = new DragEvent(type, {bubbles: true, cancelable: true,
+			clientX: point.x, clientY: point.y, dataTransfer: data});
+	} catch (e) {
+		event = document.createEvent('CustomEvent');
+		event.initCustomEvent(type, true, true, null);
+		event.dataTransfer = data;
+	}
+	return elem.dispatchEvent(event);
+}
+var from = center(source), to = center(target);
+if (!fire(source, 'dragstart', from)) {
+	return;
+}
+fire(target, 'dragenter', to);
+if (!fire(target, 'dragover', to)) {
+	// A cancelled dragover event means the target accepts the drop.
+	fire(target, 'drop', to);
+}
+fire(source, 'dragend', to);
+`
+
+func (wd *remoteWD) DragAndDrop(source, target WebElement) error {
+	return wd.Actions().MoveToElement(source).ClickAndHold().MoveToElement(target).Release().Perform()
+}
+
+func (wd *remoteWD) DragAndDropByOffset(source WebElement, x, y int) error {
+	return wd.Actions().MoveToElement(source).ClickAndHold().MoveByOffset(x, y).Release().Perform()
+}
+
+func (wd *remoteWD) DragAndDropViaScript(source, target WebElement) error {
+	_, err := wd.ExecuteScript(dragAndDropScript, []interface{}{source, target})
+	return err
+}
@@ -0,0 +1,76 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDragAndDrop(t *testing.T) {
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		path := strings.TrimPrefix(r.URL.Path, "/session/123")
+		if path == "/actions" {
+			// Summarize the pointer actions.
+			var payload struct {
+				Actions []struct {
+					Actions []map[string]interface{}
+				}
+			}
+			json.Unmarshal(body, &payload)
+			var steps []string
+			for _, a := range payload.Actions[0].Actions {
+				step := a["type"].(string)
+				switch origin := a["origin"].(type) {
+				case map[string]interface{}:
+					step += fmt.Sprintf("(%v)", origin[webElementIdentifier])
+				case string:
+					step += fmt.Sprintf("(%s %v,%v)", origin, a["x"], a["y"])
+				}
+				steps = append(steps, step)
+			}
+			requests = append(requests, strings.Join(steps, " "))
+		} else {
+			requests = append(requests, path+" "+string(body))
+		}
+		fmt.Fprint(w, `{"status":0,"value":null}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	source, target := &remoteWE{parent: wd, id: "a"}, &remoteWE{parent: wd, id: "b"}
+	if err := wd.DragAndDrop(source, target); err != nil {
+		t.Fatalf("DragAndDrop() returned error: %v", err)
+	}
+	if err := wd.DragAndDropByOffset(source, 5, 40); err != nil {
+		t.Fatalf("DragAndDropByOffset() returned error: %v", err)
+	}
+	want := []string{
+		"pointerMove(a) pointerDown pointerMove(b) pointerUp",
+		"pointerMove(a) pointerDown pointerMove(pointer 5,40) pointerUp",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("W3C drag and drop sent\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+
+	requests = nil
+	wd.w3cCompatible = false
+	if err := wd.DragAndDrop(source, target); err != nil {
+		t.Fatalf("DragAndDrop() returned error: %v", err)
+	}
+	want = []string{
+		`/moveto {"element":"a"}`,
+		`/buttondown {"button":0}`,
+		`/moveto {"element":"b"}`,
+		`/buttonup {"button":0}`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("legacy drag and drop sent\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,120 @@
+package selenium
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DropFileMIMETypes overrides the MIME types that DropFiles assigns to files,
+// keyed by lower-case file extension including the leading dot, e.g. ".log".
+// Extensions not present here are looked up with mime.TypeByExtension.
+var DropFileMIMETypes = map[string]string{}
+
+// dropChunkSize is the number of file bytes sent per script invocation. The
+// base64 encoding of a chunk is a third larger; this keeps script arguments
+// well below the size limits of common drivers.
+const dropChunkSize = 512 * 1024
+
+const dropStartScript = `window.__seleniumDrop = [];`
+
+const dropChunkScript = `
+var files = window.__seleniumDrop, i = arguments[0];
+if (!files[i]) {
+	files[i] = {name: arguments[1], type: arguments[2], chunks: []};
+}
+if (arguments[3]) {
+	files[i].chunks.push(arguments[3]);
+}
+`
+
+const dropFinishScript = `
+var target = arguments[0];
+var dt = new DataTransfer();
+window.__seleniumDrop.forEach(function(f) {
+	var parts = f.chunks.map(function(chunk) {
+		var bin = atob(chunk), bytes = new Uint8Array(bin.length);
+		for (var i = 0; i < bin.length; i++) {
+			bytes[i] = bin.charCodeAt(i);
+		}
+		return bytes;
+	});
+	dt.items.add(new File(parts, f.name, {type: f.type}));
+});
+delete window.__seleniumDrop;
+var rect = target.getBoundingClientRect();
+var x = rect.left + rect.width / 2, y = rect.top + rect.height / 2;
+['dragenter', 'dragover', 'drop'].forEach(function(type) {
+	var e = new DragEvent(type, {
+		bubbles: true, cancelable: true, composed: true,
+		clientX: x, clientY: y, dataTransfer: dt
+	});
+	target.dispatchEvent(e);
+});
+`
+
+// dropMIMEType returns the MIME type to use for the file at path.
+func dropMIMEType(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if t, ok := DropFileMIMETypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+func (elem *remoteWE) DropFiles(paths ...string) error {
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no files to drop")
+	}
+	wd := elem.parent
+	if _, err := wd.ExecuteScript(dropStartScript, nil); err != nil {
+		return err
+	}
+	for i, path := range paths {
+		if err := elem.sendDropFile(i, path); err != nil {
+			return err
+		}
+	}
+	_, err := wd.ExecuteScript(dropFinishScript, []interface{}{elem})
+	return err
+}
+
+// sendDropFile transfers the contents of the file at path to the page in
+// base64-encoded chunks.
+func (elem *remoteWE) sendDropFile(i int, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	name, typ := filepath.Base(path), dropMIMEType(path)
+	buf := make([]byte, dropChunkSize)
+	sent := false
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 || !sent {
+			chunk := base64.StdEncoding.EncodeToString(buf[:n])
+			if _, err := elem.parent.ExecuteScript(dropChunkScript, []interface{}{i, name, typ, chunk}); err != nil {
+				return fmt.Errorf("error sending %q: %v", path, err)
+			}
+			sent = true
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package selenium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEmptyElementIDIsRejected(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.Error(w, "unexpected request", http.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	iface := reflect.TypeOf((*WebElement)(nil)).Elem()
+	for _, w3c := range []bool{true, false} {
+		wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+		elem := reflect.ValueOf(WebElement(&remoteWE{parent: wd}))
+		for i := 0; i < iface.NumMethod(); i++ {
+			m := iface.Method(i)
+			typ := m.Type
+			if typ.NumOut() == 0 || typ.Out(typ.NumOut()-1) != errorType {
+				// The method cannot fail, but must not send a request either.
+				elem.MethodByName(m.Name).Call(zeroArgs(typ))
+				continue
+			}
+			var out []reflect.Value
+			if typ.IsVariadic() {
+				out = elem.MethodByName(m.Name).CallSlice(zeroArgs(typ))
+			} else {
+				out = elem.MethodByName(m.Name).Call(zeroArgs(typ))
+			}
+			err, _ := out[len(out)-1].Interface().(error)
+			if !errors.Is(err, ErrInvalidElement) {
+				t.Errorf("w3c=%t: %s() on an element with no ID returned error %v, want ErrInvalidElement", w3c, m.Name, err)
+			}
+		}
+	}
+}
+
+// zeroArgs returns the zero value of each argument of a method of type typ.
+func zeroArgs(typ reflect.Type) []reflect.Value {
+	args := make([]reflect.Value, typ.NumIn())
+	for i := range args {
+		args[i] = reflect.Zero(typ.In(i))
+	}
+	return args
+}
+
+func TestDecodeEmptyElement(t *testing.T) {
+	for _, tc := range []struct {
+		w3c  bool
+		data string
+	}{
+		{false, `{"value":{"ELEMENT":""}}`},
+		{false, `{"value":{}}`},
+		{true, `{"value":{"element-6066-11e4-a52e-4f735466cecf":""}}`},
+	} {
+		wd := &remoteWD{w3cCompatible: tc.w3c}
+		if elem, err := wd.DecodeElement([]byte(tc.data)); err == nil {
+			t.Errorf("w3c=%t: DecodeElement(%s) = %v, want an error", tc.w3c, tc.data, elem)
+		}
+		list := `{"value":[` + tc.data[len(`{"value":`):len(tc.data)-1] + `]}`
+		if elems, err := wd.DecodeElements([]byte(list)); err == nil {
+			t.Errorf("w3c=%t: DecodeElements(%s) = %v, want an error", tc.w3c, list, elems)
+		}
+	}
+}
+
+func TestElementString(t *testing.T) {
+	elem := &remoteWE{id: "abc123"}
+	if got, want := elem.String(), "element[abc123]"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestActiveElement(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		w3c      bool
+		postOnly bool
+		reply    string
+	}{
+		{"W3C", true, false, `{"value":{"element-6066-11e4-a52e-4f735466cecf":"e1"}}`},
+		{"legacy", false, true, `{"sessionId":"123","status":0,"value":{"ELEMENT":"e1"}}`},
+		// ChromeDriver in legacy mode may claim W3C compliance.
+		{"legacy reply to W3C", true, true, `{"value":{"ELEMENT":"e1"}}`},
+		{"W3C reply to legacy", false, false, `{"sessionId":"123","status":0,"value":{"element-6066-11e4-a52e-4f735466cecf":"e1"}}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var methods []string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				methods = append(methods, r.Method)
+				w.Header().Set("Content-Type", JSONType)
+				if r.URL.Path != "/session/123/element/active" || tc.postOnly && r.Method != "POST" {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					fmt.Fprint(w, `{"value":{"error":"unknown method","message":"not allowed","stacktrace":""}}`)
+					return
+				}
+				fmt.Fprint(w, tc.reply)
+			}))
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: tc.w3c}
+
+			elem, err := wd.ActiveElement()
+			if err != nil {
+				t.Fatalf("ActiveElement() returned error: %v", err)
+			}
+			if id := elem.(*remoteWE).id; id != "e1" {
+				t.Errorf("ActiveElement() returned element %q, want e1", id)
+			}
+			want := []string{"GET"}
+			if tc.postOnly {
+				want = []string{"GET", "POST"}
+			}
+			if !reflect.DeepEqual(methods, want) {
+				t.Errorf("ActiveElement() sent %v requests, want %v", methods, want)
+			}
+		})
+	}
+}
+
+func TestGetProperty(t *testing.T) {
+	for _, w3c := range []bool{true, false} {
+		t.Run(fmt.Sprintf("w3c=%t", w3c), func(t *testing.T) {
+			// The page has an input, "q", and a checkbox, "chuk", whose
+			// properties reflect the keys typed and the clicks.
+			var (
+				value   string
+				checked bool
+			)
+			property := func(id, name string) interface{} {
+				switch {
+				case id == "q" && name == "value":
+					return value
+				case id == "q" && name == "maxLength":
+					return -1
+				case id == "chuk" && name == "checked":
+					return checked
+				}
+				return nil
+			}
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", JSONType)
+				var result interface{}
+				switch path := r.URL.Path; {
+				case r.Method == "POST" && path == "/session/123/element/q/value":
+					var keys struct {
+						Text  string
+						Value []string
+					}
+					if err := json.NewDecoder(r.Body).Decode(&keys); err != nil {
+						t.Errorf("decoding the keys sent: %v", err)
+					}
+					if keys.Text == "" {
+						keys.Text = strings.Join(keys.Value, "")
+					}
+					value += keys.Text
+				case r.Method == "POST" && path == "/session/123/element/chuk/click":
+					checked = !checked
+				case w3c && r.Method == "GET" && strings.HasPrefix(path, "/session/123/element/"):
+					parts := strings.Split(path, "/")
+					if len(parts) != 7 || parts[5] != "property" {
+						t.Errorf("unexpected request GET %s", path)
+						break
+					}
+					result = property(parts[4], parts[6])
+				case !w3c && r.Method == "POST" && path == "/session/123/execute":
+					var params struct {
+						Script string
+						Args   []json.RawMessage
+					}
+					if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+						t.Errorf("decoding the script: %v", err)
+					}
+					var elem map[string]string
+					var name string
+					if len(params.Args) != 2 || json.Unmarshal(params.Args[0], &elem) != nil || json.Unmarshal(params.Args[1], &name) != nil {
+						t.Errorf("unexpected script arguments %s", params.Args)
+						break
+					}
+					result = property(elem["ELEMENT"], name)
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, path)
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"sessionId": "123", "status": 0, "value": result})
+			}))
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+			input := &remoteWE{parent: wd, id: "q"}
+			checkbox := &remoteWE{parent: wd, id: "chuk"}
+
+			if got, err := checkbox.GetProperty("checked"); err != nil || got != "false" {
+				t.Errorf(`checkbox.GetProperty("checked") = %q, %v; want "false", nil`, got, err)
+			}
+			if err := checkbox.Click(); err != nil {
+				t.Fatalf("checkbox.Click() returned error: %v", err)
+			}
+			if got, err := checkbox.GetProperty("checked"); err != nil || got != "true" {
+				t.Errorf(`checkbox.GetProperty("checked") after clicking = %q, %v; want "true", nil`, got, err)
+			}
+
+			if err := input.SendKeys("golang"); err != nil {
+				t.Fatalf("input.SendKeys() returned error: %v", err)
+			}
+			if got, err := input.GetProperty("value"); err != nil || got != "golang" {
+				t.Errorf(`input.GetProperty("value") after typing = %q, %v; want "golang", nil`, got, err)
+			}
+			if got, err := input.GetPropertyRaw("value"); err != nil || string(got) != `"golang"` {
+				t.Errorf(`input.GetPropertyRaw("value") after typing = %s, %v; want "golang", nil`, got, err)
+			}
+			if got, err := input.GetProperty("maxLength"); err != nil || got != "-1" {
+				t.Errorf(`input.GetProperty("maxLength") = %q, %v; want "-1", nil`, got, err)
+			}
+			if got, err := input.GetProperty("noSuchProperty"); err != nil || got != "" {
+				t.Errorf(`input.GetProperty("noSuchProperty") = %q, %v; want "", nil`, got, err)
+			}
+			if got, err := input.GetPropertyRaw("noSuchProperty"); err != nil || string(got) != "null" {
+				t.Errorf(`input.GetPropertyRaw("noSuchProperty") = %s, %v; want null, nil`, got, err)
+			}
+		})
+	}
+}
+
+func TestElementRect(t *testing.T) {
+	// The values are chosen so that formatting them with less than 17
+	// significant digits, or parsing them as float32, would change them.
+	want := Rect{X: 10.123456789012345, Y: 0.30000000000000004, Width: 99.99999999999999, Height: 1e-7}
+	for _, w3c := range []bool{true, false} {
+		t.Run(fmt.Sprintf("w3c=%t", w3c), func(t *testing.T) {
+			var paths []string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				var value interface{}
+				switch r.URL.Path {
+				case "/session/123/element/e1/rect":
+					value = want
+				case "/session/123/element/e1/location":
+					value = map[string]float64{"x": want.X, "y": want.Y}
+				case "/session/123/element/e1/size":
+					value = map[string]float64{"width": want.Width, "height": want.Height}
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				w.Header().Set("Content-Type", JSONType)
+				json.NewEncoder(w).Encode(map[string]interface{}{"sessionId": "123", "status": 0, "value": value})
+			}))
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+			elem := &remoteWE{parent: wd, id: "e1"}
+
+			rect, err := elem.Rect()
+			if err != nil {
+				t.Fatalf("Rect() returned error: %v", err)
+			}
+			if *rect != want {
+				t.Errorf("Rect() = %+v, want %+v", *rect, want)
+			}
+			wantPaths := []string{"/session/123/element/e1/rect"}
+			if !w3c {
+				wantPaths = []string{"/session/123/element/e1/location", "/session/123/element/e1/size"}
+			}
+			if !reflect.DeepEqual(paths, wantPaths) {
+				t.Errorf("Rect() requested %v, want %v", paths, wantPaths)
+			}
+
+			paths = nil
+			if p, err := elem.Location(); err != nil || *p != (Point{10, 0}) {
+				t.Errorf("Location() = %v, %v; want {10 0}, nil", p, err)
+			}
+			if size, err := elem.Size(); err != nil || *size != (Size{99, 0}) {
+				t.Errorf("Size() = %v, %v; want {99 0}, nil", size, err)
+			}
+			// Legacy sessions only send the command for the part needed.
+			wantPaths = []string{"/session/123/element/e1/rect", "/session/123/element/e1/rect"}
+			if !w3c {
+				wantPaths = []string{"/session/123/element/e1/location", "/session/123/element/e1/size"}
+			}
+			if !reflect.DeepEqual(paths, wantPaths) {
+				t.Errorf("Location() and Size() requested %v, want %v", paths, wantPaths)
+			}
+		})
+	}
+}
+
+func TestComputedRoleAndLabel(t *testing.T) {
+	// The element is <button aria-label="Close dialog">X</button>.
+	for _, supported := range []bool{true, false} {
+		t.Run(fmt.Sprintf("supported=%t", supported), func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", JSONType)
+				if !supported {
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprint(w, `{"value":{"error":"unknown command","message":"unknown command: session/123/element/close/computedrole","stacktrace":""}}`)
+					return
+				}
+				switch r.URL.Path {
+				case "/session/123/element/close/computedrole":
+					fmt.Fprint(w, `{"value":"button"}`)
+				case "/session/123/element/close/computedlabel":
+					fmt.Fprint(w, `{"value":"Close dialog"}`)
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+			button := &remoteWE{parent: wd, id: "close"}
+
+			role, roleErr := button.ComputedRole()
+			label, labelErr := button.ComputedLabel()
+			if supported {
+				if roleErr != nil || role != "button" {
+					t.Errorf(`ComputedRole() = %q, %v; want "button", nil`, role, roleErr)
+				}
+				if labelErr != nil || label != "Close dialog" {
+					t.Errorf(`ComputedLabel() = %q, %v; want "Close dialog", nil`, label, labelErr)
+				}
+				return
+			}
+			for name, err := range map[string]error{"ComputedRole": roleErr, "ComputedLabel": labelErr} {
+				var unsupported *UnsupportedCommandError
+				if !errors.As(err, &unsupported) || unsupported.Command != name {
+					t.Errorf("%s() on a remote end without the command returned error %v, want an *UnsupportedCommandError", name, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package selenium
+
+import "encoding/json"
+
+func (wd *remoteWD) SetElementDecoder(decode func(raw json.RawMessage) (id string, ok bool)) {
+	wd.elementDecoder = decode
+}
+
+func (wd *remoteWD) SetElementEncoder(encode func(id string) interface{}) {
+	wd.elementEncoder = encode
+}
+
+// decodeCustomElement decodes the element in the reply data with the decoder
+// set by SetElementDecoder. It returns false if no decoder is set or the
+// decoder does not recognize the element.
+func (wd *remoteWD) decodeCustomElement(data []byte) (*remoteWE, bool) {
+	if wd.elementDecoder == nil {
+		return nil, false
+	}
+	reply := new(struct{ Value json.RawMessage })
+	if err := json.Unmarshal(data, reply); err != nil {
+		return nil, false
+	}
+	id, ok := wd.elementDecoder(reply.Value)
+	if !ok || id == "" {
+		return nil, false
+	}
+	return &remoteWE{parent: wd, id: id}, true
+}
+
+// decodeCustomElements decodes the elements in the reply data with the
+// decoder set by SetElementDecoder. It returns false if no decoder is set or
+// the decoder does not recognize every element.
+func (wd *remoteWD) decodeCustomElements(data []byte) ([]WebElement, bool) {
+	if wd.elementDecoder == nil {
+		return nil, false
+	}
+	reply := new(struct{ Value []json.RawMessage })
+	if err := json.Unmarshal(data, reply); err != nil {
+		return nil, false
+	}
+	elems := make([]WebElement, len(reply.Value))
+	for i, raw := range reply.Value {
+		id, ok := wd.elementDecoder(raw)
+		if !ok || id == "" {
+			return nil, false
+		}
+		elems[i] = &remoteWE{parent: wd, id: id}
+	}
+	return elems, true
+}
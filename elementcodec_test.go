@@ -0,0 +1,94 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCustomElementKey(t *testing.T) {
+	var scriptArgs string
+	var clicked []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch r.URL.Path {
+		case "/session/123/element":
+			fmt.Fprint(w, `{"value":{"element-id":"x1"}}`)
+		case "/session/123/elements":
+			fmt.Fprint(w, `{"value":[{"element-id":"x1"},{"element-id":"x2"}]}`)
+		case "/session/123/element/x1/click", "/session/123/element/x2/click":
+			clicked = append(clicked, r.URL.Path)
+			fmt.Fprint(w, `{"value":null}`)
+		case "/session/123/execute/sync":
+			body, _ := ioutil.ReadAll(r.Body)
+			var params struct{ Args json.RawMessage }
+			json.Unmarshal(body, &params)
+			scriptArgs = string(params.Args)
+			fmt.Fprint(w, `{"value":"ok"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	wd.SetElementDecoder(func(raw json.RawMessage) (string, bool) {
+		var v struct {
+			ID string `json:"element-id"`
+		}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return "", false
+		}
+		return v.ID, v.ID != ""
+	})
+	wd.SetElementEncoder(func(id string) interface{} {
+		return map[string]string{"element-id": id}
+	})
+
+	elem, err := wd.FindElement(ByCSSSelector, "button")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	if err := elem.Click(); err != nil {
+		t.Fatalf("Click() returned error: %v", err)
+	}
+	elems, err := wd.FindElements(ByCSSSelector, "button")
+	if err != nil {
+		t.Fatalf("FindElements() returned error: %v", err)
+	}
+	if len(elems) != 2 {
+		t.Fatalf("FindElements() returned %d elements, want 2", len(elems))
+	}
+	if err := elems[1].Click(); err != nil {
+		t.Fatalf("Click() returned error: %v", err)
+	}
+	want := []string{"/session/123/element/x1/click", "/session/123/element/x2/click"}
+	if fmt.Sprint(clicked) != fmt.Sprint(want) {
+		t.Errorf("clicked %v, want %v", clicked, want)
+	}
+
+	if _, err := wd.ExecuteScript("arguments[0].focus()", []interface{}{elem}); err != nil {
+		t.Fatalf("ExecuteScript() returned error: %v", err)
+	}
+	if want := `[{"element-id":"x1"}]`; scriptArgs != want {
+		t.Errorf("ExecuteScript() sent arguments %s, want %s", scriptArgs, want)
+	}
+}
+
+func TestCustomElementDecoderFallsBack(t *testing.T) {
+	wd := &remoteWD{w3cCompatible: true}
+	wd.SetElementDecoder(func(raw json.RawMessage) (string, bool) {
+		return "", false
+	})
+	elem, err := wd.DecodeElement([]byte(`{"value":{"element-6066-11e4-a52e-4f735466cecf":"w3c"}}`))
+	if err != nil {
+		t.Fatalf("DecodeElement() returned error: %v", err)
+	}
+	if id := elem.(*remoteWE).id; id != "w3c" {
+		t.Errorf("DecodeElement() returned element %q, want %q", id, "w3c")
+	}
+}
@@ -0,0 +1,86 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AtLeast returns a predicate for WaitForElementCount that holds for counts
+// of at least n.
+func AtLeast(n int) func(int) bool {
+	return func(count int) bool { return count >= n }
+}
+
+// Exactly returns a predicate for WaitForElementCount that holds for a count
+// of n.
+func Exactly(n int) func(int) bool {
+	return func(count int) bool { return count == n }
+}
+
+// Between returns a predicate for WaitForElementCount that holds for counts
+// from lo to hi, inclusive.
+func Between(lo, hi int) func(int) bool {
+	return func(count int) bool { return count >= lo && count <= hi }
+}
+
+// ErrStaleParent is wrapped by the error that WebElement.WaitForElementCount
+// returns if the element within which the elements are counted is no longer
+// attached to the DOM, e.g. because the table was re-rendered.
+var ErrStaleParent = errors.New("the element within which elements are counted is stale")
+
+// ElementCountError is returned by WaitForElementCount if the count of
+// elements did not satisfy the predicate in time.
+type ElementCountError struct {
+	Locator Locator
+	// Count is the last count of elements observed.
+	Count   int
+	Timeout time.Duration
+}
+
+func (e *ElementCountError) Error() string {
+	return fmt.Sprintf("waiting for the count of elements %s: timeout after %v with %d element(s)", e.Locator, e.Timeout, e.Count)
+}
+
+// waitForElementCount polls find until pred holds for the number of elements
+// it returns, and returns them.
+func (wd *remoteWD) waitForElementCount(loc Locator, find func() ([]WebElement, error), pred func(int) bool, timeout time.Duration) ([]WebElement, error) {
+	var (
+		elems   []WebElement
+		findErr error
+	)
+	err := wd.WaitWithTimeout(func(WebDriver) (bool, error) {
+		elems, findErr = find()
+		if findErr != nil {
+			return false, findErr
+		}
+		return pred(len(elems)), nil
+	}, timeout)
+	if findErr != nil {
+		return nil, findErr
+	}
+	if err != nil {
+		return nil, &ElementCountError{Locator: loc, Count: len(elems), Timeout: timeout}
+	}
+	return elems, nil
+}
+
+func (wd *remoteWD) WaitForElementCount(by, value string, pred func(int) bool, timeout time.Duration) ([]WebElement, error) {
+	return wd.waitForElementCount(Locator{by, value}, func() ([]WebElement, error) {
+		return wd.FindElements(by, value)
+	}, pred, timeout)
+}
+
+func (elem *remoteWE) WaitForElementCount(by, value string, pred func(int) bool, timeout time.Duration) ([]WebElement, error) {
+	if err := elem.checkID(); err != nil {
+		return nil, err
+	}
+	loc := Locator{by, value}
+	return elem.parent.waitForElementCount(loc, func() ([]WebElement, error) {
+		elems, err := elem.FindElements(by, value)
+		if isStaleElement(err) {
+			return nil, &ElementError{Locator: loc, Element: elem.provenance(), Err: ErrStaleParent}
+		}
+		return elems, err
+	}, pred, timeout)
+}
@@ -0,0 +1,69 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitForElementCount(t *testing.T) {
+	polls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch r.URL.Path {
+		case "/session/123/elements":
+			// One more row appears with each poll, up to 5.
+			polls++
+			var refs []string
+			for i := 0; i < polls && i < 5; i++ {
+				refs = append(refs, fmt.Sprintf(`{"element-6066-11e4-a52e-4f735466cecf":"row%d"}`, i))
+			}
+			fmt.Fprintf(w, `{"value":[%s]}`, strings.Join(refs, ","))
+		case "/session/123/element/table/elements":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"stale element reference","message":"detached","stacktrace":""}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true, knownTimeouts: implicitTimeout}
+
+	rows, err := wd.WaitForElementCount(ByCSSSelector, "tr", Exactly(3), 5*time.Second)
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("WaitForElementCount(Exactly(3)) = %d rows, %v; want 3", len(rows), err)
+	}
+
+	_, err = wd.WaitForElementCount(ByCSSSelector, "tr", AtLeast(10), 150*time.Millisecond)
+	var countErr *ElementCountError
+	if !errors.As(err, &countErr) || countErr.Count != 5 {
+		t.Errorf("WaitForElementCount(AtLeast(10)) returned error %v, want an *ElementCountError with the count 5", err)
+	}
+
+	table := &remoteWE{parent: wd, id: "table"}
+	if _, err := table.WaitForElementCount(ByCSSSelector, "tr", Between(1, 5), time.Second); !errors.Is(err, ErrStaleParent) {
+		t.Errorf("WaitForElementCount() within a stale element returned error %v, want ErrStaleParent", err)
+	}
+
+	for _, tc := range []struct {
+		pred  func(int) bool
+		count int
+		want  bool
+	}{
+		{AtLeast(3), 2, false},
+		{AtLeast(3), 3, true},
+		{Exactly(0), 0, true},
+		{Exactly(2), 3, false},
+		{Between(2, 4), 1, false},
+		{Between(2, 4), 4, true},
+		{Between(2, 4), 5, false},
+	} {
+		if got := tc.pred(tc.count); got != tc.want {
+			t.Errorf("predicate(%d) = %t, want %t", tc.count, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package selenium
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ElementError is returned by the commands of an element that fail, and by
+// failed searches of the document. It identifies the element by the locators
+// it was found with, and wraps the error returned by the remote end, which can
+// be retrieved with errors.As.
+type ElementError struct {
+	// Op is the command that failed, e.g. "click".
+	Op string
+	// Locator is the locator of a failed search, if the command was
+	// FindElement or FindElements.
+	Locator Locator
+	// Element describes the element, e.g.
+	// `element[css ".submit"] within element[css ".modal"] (id 3f2a)`. It is
+	// empty for a search of the document.
+	Element string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ElementError) Error() string {
+	if e.Locator.By != "" && e.Element == "" {
+		return fmt.Sprintf("find %s: %v", e.Locator, e.Err)
+	}
+	if e.Locator.By != "" {
+		return fmt.Sprintf("find %s within %s: %v", e.Locator, e.Element, e.Err)
+	}
+	return fmt.Sprintf("%s on %s: %v", e.Op, e.Element, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// unwrapElementError returns the error wrapped by err, if err is an
+// *ElementError, and err otherwise.
+func unwrapElementError(err error) error {
+	if e, ok := err.(*ElementError); ok {
+		return e.Err
+	}
+	return err
+}
+
+// provenance describes the element and the elements it was found within by
+// the locators they were found with, e.g.
+// `element[css ".submit"] within element[css ".modal"] (id 3f2a)`.
+func (elem *remoteWE) provenance() string {
+	var b bytes.Buffer
+	for e := elem; e != nil; e = e.within {
+		if e != elem {
+			b.WriteString(" within ")
+		}
+		b.WriteString("element")
+		if e.locator.By != "" {
+			fmt.Fprintf(&b, "[%s]", e.locator)
+		}
+	}
+	fmt.Fprintf(&b, " (id %s)", elem.id)
+	return b.String()
+}
+
+// wrapError invalidates cached data if err reports that the element is stale,
+// and returns err wrapped in an *ElementError.
+func (elem *remoteWE) wrapError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	elem.noteError(err)
+	return &ElementError{Op: op, Element: elem.provenance(), Err: err}
+}
+
+// setProvenance records on the elements that they were found with loc,
+// within the element parent, if not nil.
+func setProvenance(loc Locator, parent *remoteWE, elems ...WebElement) {
+	for _, e := range elems {
+		if elem, ok := e.(*remoteWE); ok {
+			elem.locator = loc
+			elem.within = parent
+		}
+	}
+}
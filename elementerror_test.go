@@ -0,0 +1,134 @@
+package selenium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// provenanceServer finds a modal (e1) in the page, a button (e2) in the
+// modal, and fails to click the button or find anything else in the page or
+// in the modal.
+func provenanceServer(t *testing.T, w3c bool, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		w.Header().Set("Content-Type", JSONType)
+		element := func(id string) {
+			if w3c {
+				fmt.Fprintf(w, `{"value":{"element-6066-11e4-a52e-4f735466cecf":%q}}`, id)
+			} else {
+				fmt.Fprintf(w, `{"sessionId":"123","status":0,"value":{"ELEMENT":%q}}`, id)
+			}
+		}
+		fail := func(code string, status int, message string) {
+			if w3c {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `{"value":{"error":%q,"message":%q}}`, code, message)
+			} else {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintf(w, `{"sessionId":"123","status":%d,"value":{"message":%q}}`, status, message)
+			}
+		}
+		switch r.URL.Path {
+		case "/session/123/element", "/session/123/elements":
+			var q struct{ Value string }
+			if err := json.NewDecoder(r.Body).Decode(&q); err != nil || q.Value != ".modal" {
+				fail("no such element", 7, "not found")
+				return
+			}
+			element("e1")
+		case "/session/123/element/e1/element":
+			var q struct{ Value string }
+			if err := json.NewDecoder(r.Body).Decode(&q); err != nil || q.Value != ".submit" {
+				fail("no such element", 7, "not found")
+				return
+			}
+			element("e2")
+		case "/session/123/element/e2/click":
+			fail("invalid element state", 12, "covered")
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+}
+
+func TestElementErrorProvenance(t *testing.T) {
+	for _, w3c := range []bool{true, false} {
+		requests := 0
+		s := provenanceServer(t, w3c, &requests)
+		wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+
+		modal, err := wd.FindElement(ByCSSSelector, ".modal")
+		if err != nil {
+			t.Fatalf("w3c=%t: FindElement() returned error: %v", w3c, err)
+		}
+		button, err := modal.Find(CSS(".submit"))
+		if err != nil {
+			t.Fatalf("w3c=%t: Find() returned error: %v", w3c, err)
+		}
+
+		before := requests
+		err = button.Click()
+		want := `click on element[css ".submit"] within element[css ".modal"] (id e2): invalid element state: covered`
+		if err == nil || err.Error() != want {
+			t.Errorf("w3c=%t: Click() returned error %v, want %s", w3c, err, want)
+		}
+		if requests != before+1 {
+			t.Errorf("w3c=%t: Click() sent %d requests, want 1", w3c, requests-before)
+		}
+		var ee *ElementError
+		if !errors.As(err, &ee) || ee.Op != "click" {
+			t.Errorf("w3c=%t: Click() returned error %v, want an *ElementError for click", w3c, err)
+		}
+		if w3c {
+			var e *Error
+			if !errors.As(err, &e) || e.Err != "invalid element state" {
+				t.Errorf("Click() returned error %v, want the remote *Error to be retrievable", err)
+			}
+		}
+
+		_, err = modal.FindElement(ByCSSSelector, ".missing")
+		want = `find css ".missing" within element[css ".modal"] (id e1): no such element: not found`
+		if err == nil || err.Error() != want {
+			t.Errorf("w3c=%t: FindElement() returned error %v, want %s", w3c, err, want)
+		}
+
+		_, err = wd.FindElement(ByCSSSelector, ".missing")
+		want = `find css ".missing": no such element: not found`
+		if err == nil || err.Error() != want {
+			t.Errorf("w3c=%t: wd.FindElement() returned error %v, want %s", w3c, err, want)
+		}
+		if !errors.As(err, &ee) || ee.Locator != CSS(".missing") || ee.Element != "" {
+			t.Errorf("w3c=%t: wd.FindElement() returned error %v, want an *ElementError for the search of the document", w3c, err)
+		}
+		if !isNoSuchElement(err) {
+			t.Errorf("w3c=%t: wd.FindElement() returned error %v, want it classified as no such element", w3c, err)
+		}
+		if w3c {
+			var e *Error
+			if !errors.As(err, &e) || e.Err != "no such element" {
+				t.Errorf("wd.FindElement() returned error %v, want the remote *Error to be retrievable", err)
+			}
+		}
+		_, err = wd.FindElements(ByCSSSelector, ".missing")
+		want = `find css ".missing": no such element: not found`
+		if err == nil || err.Error() != want {
+			t.Errorf("w3c=%t: wd.FindElements() returned error %v, want %s", w3c, err, want)
+		}
+		s.Close()
+	}
+}
+
+func TestElementErrorUnknownProvenance(t *testing.T) {
+	elem := &remoteWE{id: "e3"}
+	err := elem.wrapError("text", errors.New("boom"))
+	if got, want := err.Error(), "text on element (id e3): boom"; got != want {
+		t.Errorf("wrapError() = %q, want %q", got, want)
+	}
+	if elem.wrapError("text", nil) != nil {
+		t.Errorf("wrapError(nil) returned a non-nil error")
+	}
+}
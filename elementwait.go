@@ -0,0 +1,201 @@
+package selenium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WaitOption configures WebElement.WaitUntilVisible,
+// WebElement.WaitUntilClickable and WebDriver.WaitUntilGone.
+type WaitOption func(*waitOptions) error
+
+type waitOptions struct {
+	interval time.Duration
+	ctx      context.Context
+}
+
+// WaitInterval sets the interval between polls, DefaultWaitInterval by
+// default.
+func WaitInterval(interval time.Duration) WaitOption {
+	return func(o *waitOptions) error {
+		if interval <= 0 {
+			return fmt.Errorf("invalid wait interval %v", interval)
+		}
+		o.interval = interval
+		return nil
+	}
+}
+
+// WaitContext makes the wait stop when ctx is done, e.g. at the deadline of a
+// test, before the timeout elapses.
+func WaitContext(ctx context.Context) WaitOption {
+	return func(o *waitOptions) error {
+		o.ctx = ctx
+		return nil
+	}
+}
+
+// ApplyWaitOptions returns the interval between polls and the context set by
+// opts, or DefaultWaitInterval and context.Background if they are not set.
+// It is meant for other implementations of the element waits, such as the
+// one of package fakedriver.
+func ApplyWaitOptions(opts ...WaitOption) (time.Duration, context.Context, error) {
+	o := &waitOptions{interval: DefaultWaitInterval, ctx: context.Background()}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return 0, nil, err
+		}
+	}
+	return o.interval, o.ctx, nil
+}
+
+// ElementWaitError is returned by the element waits if the condition did not
+// hold in time, or the context of the wait was done first.
+type ElementWaitError struct {
+	// Element describes the element, or the locator of the elements, that
+	// was waited for.
+	Element string
+	// Condition is the condition waited for, e.g. "visible".
+	Condition string
+	// State is the last state of the element observed, e.g. "not displayed"
+	// or "stale".
+	State   string
+	Timeout time.Duration
+	// Err is the error of the context of the wait, if it was done before the
+	// timeout elapsed.
+	Err error
+}
+
+func (e *ElementWaitError) Error() string {
+	reason := fmt.Sprintf("timeout after %v", e.Timeout)
+	if e.Err != nil {
+		reason = e.Err.Error()
+	}
+	return fmt.Sprintf("waiting for %s to be %s: %s; last state: %s", e.Element, e.Condition, reason, e.State)
+}
+
+// Unwrap returns the error of the context of the wait, if any.
+func (e *ElementWaitError) Unwrap() error {
+	return e.Err
+}
+
+// errWaitTimeout is returned by pollUntil if the timeout elapses.
+var errWaitTimeout = errors.New("timeout")
+
+// pollUntil calls check until it returns true or an error, every interval of
+// the options, until the timeout elapses or the context of the options is
+// done.
+func (wd *remoteWD) pollUntil(timeout time.Duration, opts []WaitOption, check func() (bool, error)) error {
+	interval, ctx, err := ApplyWaitOptions(opts...)
+	if err != nil {
+		return err
+	}
+	restore := wd.suspendImplicitWait()
+	defer restore()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+		if !time.Now().Before(deadline) {
+			return errWaitTimeout
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// waitForElement polls check, which returns whether the condition holds and
+// the state of the element, and returns an *ElementWaitError describing the
+// element as desc if the wait ends before it holds.
+func (wd *remoteWD) waitForElement(desc, condition string, timeout time.Duration, opts []WaitOption, check func() (bool, string, error)) error {
+	var state string
+	err := wd.pollUntil(timeout, opts, func() (bool, error) {
+		var done bool
+		var err error
+		done, state, err = check()
+		return done, err
+	})
+	switch {
+	case err == errWaitTimeout:
+		return &ElementWaitError{Element: desc, Condition: condition, State: state, Timeout: timeout}
+	case err == context.Canceled || err == context.DeadlineExceeded:
+		return &ElementWaitError{Element: desc, Condition: condition, State: state, Timeout: timeout, Err: err}
+	}
+	return err
+}
+
+func (elem *remoteWE) WaitUntilVisible(timeout time.Duration, opts ...WaitOption) error {
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	return elem.parent.waitForElement(elem.provenance(), "visible", timeout, opts, func() (bool, string, error) {
+		displayed, err := elem.IsDisplayed()
+		switch {
+		case isStaleElement(err):
+			// The element may be found again with SetStaleRetry.
+			return false, "stale", nil
+		case err != nil:
+			return false, "", err
+		case !displayed:
+			return false, "not displayed", nil
+		}
+		return true, "displayed", nil
+	})
+}
+
+func (elem *remoteWE) WaitUntilClickable(timeout time.Duration, opts ...WaitOption) error {
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	return elem.parent.waitForElement(elem.provenance(), "clickable", timeout, opts, func() (bool, string, error) {
+		displayed, err := elem.IsDisplayed()
+		if err == nil && !displayed {
+			return false, "not displayed", nil
+		}
+		var enabled bool
+		if err == nil {
+			enabled, err = elem.IsEnabled()
+		}
+		switch {
+		case isStaleElement(err):
+			return false, "stale", nil
+		case err != nil:
+			return false, "", err
+		case !enabled:
+			return false, "displayed but disabled", nil
+		}
+		return true, "displayed and enabled", nil
+	})
+}
+
+func (wd *remoteWD) WaitUntilGone(by, value string, timeout time.Duration, opts ...WaitOption) error {
+	return wd.waitForElement(Locator{by, value}.String(), "gone", timeout, opts, func() (bool, string, error) {
+		elems, err := wd.FindElements(by, value)
+		if err != nil {
+			return false, "", err
+		}
+		shown := 0
+		for _, e := range elems {
+			displayed, err := e.IsDisplayed()
+			if isStaleElement(err) {
+				// The element was removed since it was found.
+				continue
+			}
+			if err != nil {
+				return false, "", err
+			}
+			if displayed {
+				shown++
+			}
+		}
+		return shown == 0, fmt.Sprintf("%d displayed element(s)", shown), nil
+	})
+}
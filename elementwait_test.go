@@ -0,0 +1,145 @@
+package selenium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitServer serves the element e1, whose state is read from the states in
+// turn, one per poll: "shown", "hidden", "disabled", "stale", or "absent" for
+// searches that find nothing. The last state is repeated. Polls start with a
+// search for WaitUntilGone, and with the displayed command otherwise.
+type waitServer struct {
+	*httptest.Server
+	states []string
+	polls  int
+	state  string
+}
+
+func newWaitServer(t *testing.T, gone bool, states ...string) *waitServer {
+	s := &waitServer{states: states}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		path := strings.TrimPrefix(r.URL.Path, "/session/123")
+		if gone == (path == "/elements") && path != "/element/e1/enabled" {
+			s.state = s.states[len(s.states)-1]
+			if s.polls < len(s.states) {
+				s.state = s.states[s.polls]
+			}
+			s.polls++
+		}
+		switch {
+		case path == "/elements" && s.state == "absent":
+			fmt.Fprint(w, `{"value":[]}`)
+		case path == "/elements":
+			fmt.Fprintf(w, `{"value":[{%q:"e1"}]}`, webElementIdentifier)
+		case s.state == "stale":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"stale element reference","message":"gone"}}`)
+		case path == "/element/e1/displayed":
+			fmt.Fprintf(w, `{"value":%t}`, s.state != "hidden")
+		case path == "/element/e1/enabled":
+			fmt.Fprintf(w, `{"value":%t}`, s.state != "disabled")
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	return s
+}
+
+func (s *waitServer) elem() *remoteWE {
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true, knownTimeouts: implicitTimeout}
+	return &remoteWE{parent: wd, id: "e1", locator: Locator{ByCSSSelector, ".dialog"}}
+}
+
+func TestWaitUntilVisible(t *testing.T) {
+	s := newWaitServer(t, false, "hidden", "stale", "shown")
+	defer s.Close()
+	if err := s.elem().WaitUntilVisible(time.Second, WaitInterval(time.Millisecond)); err != nil {
+		t.Fatalf("WaitUntilVisible() returned error: %v", err)
+	}
+	if s.polls != 3 {
+		t.Errorf("WaitUntilVisible() polled %d times, want 3", s.polls)
+	}
+}
+
+func TestWaitUntilClickable(t *testing.T) {
+	s := newWaitServer(t, false, "hidden", "disabled", "shown")
+	defer s.Close()
+	if err := s.elem().WaitUntilClickable(time.Second, WaitInterval(time.Millisecond)); err != nil {
+		t.Fatalf("WaitUntilClickable() returned error: %v", err)
+	}
+	if s.polls != 3 {
+		t.Errorf("WaitUntilClickable() polled %d times, want 3", s.polls)
+	}
+}
+
+func TestElementWaitTimeout(t *testing.T) {
+	s := newWaitServer(t, false, "hidden", "disabled")
+	defer s.Close()
+	err := s.elem().WaitUntilClickable(20*time.Millisecond, WaitInterval(time.Millisecond))
+	var e *ElementWaitError
+	if !errors.As(err, &e) {
+		t.Fatalf("WaitUntilClickable() returned %v, want an *ElementWaitError", err)
+	}
+	if e.State != "displayed but disabled" || e.Condition != "clickable" || e.Err != nil {
+		t.Errorf("WaitUntilClickable() returned %+v, want the last state and no context error", e)
+	}
+	for _, want := range []string{`element[css ".dialog"] (id e1)`, "timeout after 20ms", "displayed but disabled"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("WaitUntilClickable() returned %q, want it to contain %q", err, want)
+		}
+	}
+
+	s.states, s.polls = []string{"hidden"}, 0
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = s.elem().WaitUntilVisible(time.Minute, WaitContext(ctx))
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.As(err, &e) || e.State != "not displayed" {
+		t.Errorf("WaitUntilVisible() with an expiring context returned %v, want an *ElementWaitError wrapping context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("WaitUntilVisible() with an expiring context returned after %v", elapsed)
+	}
+
+	if err := s.elem().WaitUntilVisible(time.Second, WaitInterval(0)); err == nil || errors.As(err, &e) {
+		t.Errorf("WaitUntilVisible() with a zero interval returned %v, want an option error", err)
+	}
+}
+
+func TestWaitUntilGone(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		states []string
+		polls  int
+	}{
+		{"Removed", []string{"shown", "absent"}, 2},
+		{"Hidden", []string{"shown", "shown", "hidden"}, 3},
+		{"Stale", []string{"shown", "stale"}, 2},
+	} {
+		s := newWaitServer(t, true, tc.states...)
+		wd := s.elem().parent
+		if err := wd.WaitUntilGone(ByCSSSelector, ".spinner", time.Second, WaitInterval(time.Millisecond)); err != nil {
+			t.Errorf("%s: WaitUntilGone() returned error: %v", tc.name, err)
+		}
+		if s.polls != tc.polls {
+			t.Errorf("%s: WaitUntilGone() polled %d times, want %d", tc.name, s.polls, tc.polls)
+		}
+		s.Close()
+	}
+
+	s := newWaitServer(t, true, "shown")
+	defer s.Close()
+	err := s.elem().parent.WaitUntilGone(ByCSSSelector, ".spinner", 10*time.Millisecond, WaitInterval(time.Millisecond))
+	var e *ElementWaitError
+	if !errors.As(err, &e) || e.State != "1 displayed element(s)" || !strings.Contains(err.Error(), ".spinner") {
+		t.Errorf("WaitUntilGone() returned %v, want an *ElementWaitError with 1 displayed element", err)
+	}
+}
@@ -0,0 +1,101 @@
+package selenium
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// VendorPrefix marks a vendor-specific value of a string enumeration, such as
+// a log type or locator strategy that only some remote ends support. The
+// validated constructors, and the methods that validate their arguments,
+// remove the prefix and pass the rest of the value through unchanged.
+const VendorPrefix = "vendor:"
+
+var (
+	logTypes = []string{
+		string(ServerLog), Browser, Client, Driver, Performance, Profiler,
+	}
+	locatorStrategies = []string{
+		ByID, ByXPATH, ByLinkText, ByPartialLinkText, ByName, ByTagName, ByClassName, ByCSSSelector,
+	}
+)
+
+// normalizeEnum validates the value of a string enumeration. It ignores the
+// case of the value and surrounding white space or slashes. Values prefixed
+// with VendorPrefix are accepted as they are, without the prefix.
+func normalizeEnum(kind, value string, accepted []string) (string, error) {
+	if strings.HasPrefix(value, VendorPrefix) {
+		return strings.TrimPrefix(value, VendorPrefix), nil
+	}
+	normalized := strings.ToLower(strings.Trim(value, " \t/"))
+	for _, v := range accepted {
+		if normalized == v {
+			return v, nil
+		}
+	}
+	quoted := make([]string, len(accepted))
+	for i, v := range accepted {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return "", fmt.Errorf("invalid %s %q: accepted values are %s, or a vendor-specific value prefixed with %q",
+		kind, value, strings.Join(quoted, ", "), VendorPrefix)
+}
+
+// LogTypeOf returns the log type named by s, which is one of the standard log
+// types, in any case, or a vendor-specific log type prefixed with
+// VendorPrefix.
+func LogTypeOf(s string) (LogType, error) {
+	v, err := normalizeEnum("log type", s, logTypes)
+	return LogType(v), err
+}
+
+// LocatorStrategyOf returns the locator strategy named by s, which is one of
+// the By constants, in any case, or a vendor-specific strategy prefixed with
+// VendorPrefix.
+func LocatorStrategyOf(s string) (string, error) {
+	return normalizeEnum("locator strategy", s, locatorStrategies)
+}
+
+// warnedStrategies records the unknown locator strategies that were warned
+// about.
+var warnedStrategies sync.Map
+
+// locatorStrategy returns the locator strategy named by by, as
+// LocatorStrategyOf does. Unknown strategies, such as those of Appium, e.g.
+// "accessibility id", which predate VendorPrefix, are returned as they are,
+// with a warning logged once per strategy.
+func locatorStrategy(by string) string {
+	strategy, err := LocatorStrategyOf(by)
+	if err == nil {
+		return strategy
+	}
+	if _, warned := warnedStrategies.LoadOrStore(by, true); !warned {
+		log.Printf("selenium: %v; the strategy is sent as it is", err)
+	}
+	return by
+}
+
+// String implements the fmt.Stringer interface.
+func (b MouseButton) String() string {
+	switch b {
+	case LeftButton:
+		return "LeftButton"
+	case MiddleButton:
+		return "MiddleButton"
+	case RightButton:
+		return "RightButton"
+	}
+	return fmt.Sprintf("MouseButton(%d)", int(b))
+}
+
+// checkMouseButton validates a mouse button passed to Click.
+func checkMouseButton(button MouseButton) error {
+	switch button {
+	case LeftButton, MiddleButton, RightButton:
+		return nil
+	}
+	return fmt.Errorf("invalid mouse button %d: accepted values are %d (LeftButton), %d (MiddleButton) and %d (RightButton)",
+		int(button), int(LeftButton), int(MiddleButton), int(RightButton))
+}
@@ -0,0 +1,168 @@
+package selenium
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogTypeOf(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    LogType
+		wantErr bool
+	}{
+		{in: "browser", want: Browser},
+		{in: "Browser", want: Browser},
+		{in: "DRIVER", want: Driver},
+		{in: "client", want: Client},
+		{in: "server/", want: ServerLog},
+		{in: " performance ", want: Performance},
+		{in: "profiler", want: Profiler},
+		{in: "vendor:bugreport", want: "bugreport"},
+		{in: "vendor:Mixed/Case", want: "Mixed/Case"},
+		{in: "brwoser", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "vendor", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := LogTypeOf(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("LogTypeOf(%q) = %q, want an error", tc.in, got)
+			} else if !strings.Contains(err.Error(), `"browser", "client"`) {
+				t.Errorf("LogTypeOf(%q) returned error %q, want it to list the accepted values", tc.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("LogTypeOf(%q) = %q, %v; want %q, nil", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func TestLocatorStrategyOf(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "css selector", want: ByCSSSelector},
+		{in: "CSS Selector", want: ByCSSSelector},
+		{in: "xpath", want: ByXPATH},
+		{in: "XPath", want: ByXPATH},
+		{in: "link text", want: ByLinkText},
+		{in: "Partial Link Text", want: ByPartialLinkText},
+		{in: "id", want: ByID},
+		{in: "name", want: ByName},
+		{in: "tag name", want: ByTagName},
+		{in: "class name/", want: ByClassName},
+		{in: "vendor:-android uiautomator", want: "-android uiautomator"},
+		{in: "css", wantErr: true},
+		{in: "linktext", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := LocatorStrategyOf(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("LocatorStrategyOf(%q) = %q, want an error", tc.in, got)
+			} else if !strings.Contains(err.Error(), `"css selector"`) {
+				t.Errorf("LocatorStrategyOf(%q) returned error %q, want it to list the accepted values", tc.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("LocatorStrategyOf(%q) = %q, %v; want %q, nil", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func TestCheckMouseButton(t *testing.T) {
+	for _, tc := range []struct {
+		button  MouseButton
+		wantErr bool
+	}{
+		{LeftButton, false},
+		{MiddleButton, false},
+		{RightButton, false},
+		{-1, true},
+		{3, true},
+	} {
+		err := checkMouseButton(tc.button)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("checkMouseButton(%d) returned error %v, want error: %t", tc.button, err, tc.wantErr)
+		}
+		if err != nil && !strings.Contains(err.Error(), "RightButton") {
+			t.Errorf("checkMouseButton(%d) returned error %q, want it to list the accepted values", tc.button, err)
+		}
+	}
+}
+
+func TestInvalidEnumsAreRejectedLocally(t *testing.T) {
+	var paths []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", JSONType)
+		w.Write([]byte(`{"value":[]}`))
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	if _, err := wd.Log("brwoser"); err == nil {
+		t.Errorf("Log() with an invalid type returned nil error")
+	}
+	if err := wd.Click(7); err == nil {
+		t.Errorf("Click(7) returned nil error")
+	}
+	if len(paths) != 0 {
+		t.Errorf("invalid arguments sent requests to %q, want none", paths)
+	}
+
+	// Normalized and vendor-specific values are sent as such.
+	wd.FindElements("CSS Selector", "a")
+	wd.Log("vendor:bugreport")
+	if want := []string{"/session/123/elements", "/session/123/log"}; strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Errorf("valid arguments sent requests to %q, want %q", paths, want)
+	}
+}
+
+func TestUnknownLocatorStrategiesArePassedThrough(t *testing.T) {
+	var strategies []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params struct{ Using string }
+		json.NewDecoder(r.Body).Decode(&params)
+		strategies = append(strategies, params.Using)
+		w.Header().Set("Content-Type", JSONType)
+		w.Write([]byte(`{"value":[]}`))
+	}))
+	defer s.Close()
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "e1"}
+	for i := 0; i < 2; i++ {
+		if _, err := wd.FindElements("accessibility id", "Login"); err != nil {
+			t.Fatalf("FindElements() with an Appium strategy returned error: %v", err)
+		}
+		if _, err := elem.FindElements("-android uiautomator", "new UiSelector()"); err != nil {
+			t.Fatalf("elem.FindElements() with an Appium strategy returned error: %v", err)
+		}
+	}
+	want := "accessibility id,-android uiautomator,accessibility id,-android uiautomator"
+	if got := strings.Join(strategies, ","); got != want {
+		t.Errorf("the strategies sent were %s, want %s", got, want)
+	}
+	for _, strategy := range []string{"accessibility id", "-android uiautomator"} {
+		if n := strings.Count(logged.String(), strategy); n != 1 {
+			t.Errorf("the log mentions %q %d times, want a single warning:\n%s", strategy, n, logged.String())
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package selenium
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SessionEventType is the kind of a SessionEvent.
+type SessionEventType int
+
+const (
+	// SessionCreated is emitted when a session has been created.
+	SessionCreated SessionEventType = iota
+	// SessionDialectDetected is emitted after SessionCreated, once the
+	// protocol spoken by the remote end is known.
+	SessionDialectDetected
+	// SessionQuit is emitted when a session has been ended with Quit or
+	// QuitContext, or abandoned because the remote end did not answer in time.
+	SessionQuit
+	// SessionCrashed is emitted the first time a command fails because the
+	// remote end no longer knows the session, e.g. because the browser
+	// crashed or the session timed out on a grid.
+	SessionCrashed
+)
+
+// String implements the fmt.Stringer interface.
+func (t SessionEventType) String() string {
+	switch t {
+	case SessionCreated:
+		return "created"
+	case SessionDialectDetected:
+		return "dialect detected"
+	case SessionQuit:
+		return "quit"
+	case SessionCrashed:
+		return "crashed"
+	}
+	return fmt.Sprintf("SessionEventType(%d)", int(t))
+}
+
+// SessionEvent describes a change in the lifecycle of a session.
+type SessionEvent struct {
+	Type      SessionEventType
+	SessionID string
+	// Fingerprint is the fingerprint of the capabilities requested for the
+	// session. See Capabilities.Fingerprint.
+	Fingerprint string
+	URLPrefix   string
+	Time        time.Time
+	// W3C is set if the session uses the W3C protocol rather than the legacy
+	// (JSON Wire) protocol. It is only meaningful from SessionDialectDetected
+	// on.
+	W3C bool
+	// Err is the error that revealed a crash, for SessionCrashed, and the
+	// reason the session was abandoned, for a SessionQuit event of a session
+	// that did not end in time.
+	Err error
+}
+
+// SessionEventBuffer is the number of events buffered for each handler
+// registered with OnSessionEvent. Events emitted while the buffer of a handler
+// is full are dropped for that handler and counted by DroppedSessionEvents.
+var SessionEventBuffer = 64
+
+var droppedSessionEvents uint64
+
+// DroppedSessionEvents returns the number of events that were not delivered
+// because a handler did not keep up.
+func DroppedSessionEvents() uint64 {
+	return atomic.LoadUint64(&droppedSessionEvents)
+}
+
+// sessionEventHandler delivers events to a handler from its own goroutine.
+type sessionEventHandler struct {
+	f      func(SessionEvent)
+	events chan SessionEvent
+	// done is closed when the goroutine of the handler exits.
+	done chan struct{}
+}
+
+// sessionEventHandlers is a set of registered handlers.
+type sessionEventHandlers struct {
+	mu       sync.Mutex
+	handlers map[*sessionEventHandler]bool
+}
+
+// add registers f and returns the function that unregisters it.
+func (hs *sessionEventHandlers) add(f func(SessionEvent)) (remove func()) {
+	h := &sessionEventHandler{f: f, events: make(chan SessionEvent, SessionEventBuffer), done: make(chan struct{})}
+	go func() {
+		defer close(h.done)
+		for e := range h.events {
+			h.f(e)
+		}
+	}()
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if hs.handlers == nil {
+		hs.handlers = make(map[*sessionEventHandler]bool)
+	}
+	hs.handlers[h] = true
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			hs.mu.Lock()
+			defer hs.mu.Unlock()
+			if hs.handlers[h] {
+				delete(hs.handlers, h)
+				close(h.events)
+			}
+		})
+	}
+}
+
+// emit queues e for each handler without blocking.
+func (hs *sessionEventHandlers) emit(e SessionEvent) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	for h := range hs.handlers {
+		select {
+		case h.events <- e:
+		default:
+			atomic.AddUint64(&droppedSessionEvents, 1)
+		}
+	}
+}
+
+// closeAll unregisters all handlers. Events already queued are still
+// delivered, after which the goroutines of the handlers exit.
+func (hs *sessionEventHandlers) closeAll() {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	for h := range hs.handlers {
+		close(h.events)
+	}
+	hs.handlers = nil
+}
+
+// globalSessionEventHandlers are called for the events of all sessions.
+var globalSessionEventHandlers sessionEventHandlers
+
+// OnSessionEvent registers f to be called with the lifecycle events of all
+// sessions, and returns a function that unregisters it. The events are
+// delivered in order from a goroutine dedicated to f, so a slow handler does
+// not delay commands; see SessionEventBuffer for what happens if it falls
+// behind. Events already queued when f is unregistered are still delivered.
+func OnSessionEvent(f func(SessionEvent)) (remove func()) {
+	return globalSessionEventHandlers.add(f)
+}
+
+// SessionEventHandler returns an option that registers f to be called with
+// the lifecycle events of the WebDriver's session, including its creation.
+// See OnSessionEvent for how the events are delivered. The handler is
+// unregistered once the session has been quit.
+func SessionEventHandler(f func(SessionEvent)) RemoteOption {
+	return func(wd *remoteWD) error {
+		wd.OnSessionEvent(f)
+		return nil
+	}
+}
+
+func (wd *remoteWD) OnSessionEvent(f func(SessionEvent)) (remove func()) {
+	return wd.sessionEventHandlers.add(f)
+}
+
+// emitSessionEvent emits an event of the current session to the handlers of
+// the WebDriver and to the global handlers.
+func (wd *remoteWD) emitSessionEvent(t SessionEventType, err error) {
+	e := SessionEvent{
+		Type:        t,
+		SessionID:   wd.id,
+		Fingerprint: wd.capabilities.Fingerprint(),
+		URLPrefix:   wd.urlPrefix,
+		Time:        time.Now(),
+		W3C:         wd.w3cCompatible,
+		Err:         err,
+	}
+	wd.sessionEventHandlers.emit(e)
+	globalSessionEventHandlers.emit(e)
+}
+
+// noteCommandError emits SessionCrashed the first time a command of the
+// session fails because the remote end no longer knows the session. A crashed
+// session is no longer tracked, as it cannot be ended with Quit.
+func (wd *remoteWD) noteCommandError(err error) {
+	if wd.crashReported || wd.lastWindowClosed || wd.id == "" || !isInvalidSession(err) {
+		return
+	}
+	wd.crashReported = true
+	untrackSession(wd)
+	wd.emitSessionEvent(SessionCrashed, err)
+}
@@ -0,0 +1,171 @@
+package selenium
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSessionEvents(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/session":
+			fmt.Fprint(w, `{"value":{"sessionId":"s1","capabilities":{}}}`)
+		case r.URL.Path == "/session/s1/url":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"invalid session id","message":"session deleted because of page crash"}}`)
+		case r.Method == "DELETE" && r.URL.Path == "/session/s1":
+			fmt.Fprint(w, `{"value":null}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+
+	events := make(chan SessionEvent, 10)
+	global := make(chan SessionEvent, 10)
+	remove := OnSessionEvent(func(e SessionEvent) {
+		if e.URLPrefix == s.URL {
+			global <- e
+		}
+	})
+	defer remove()
+
+	caps := Capabilities{"browserName": "chrome"}
+	wd, err := NewRemoteWithOptions(caps, s.URL, SessionEventHandler(func(e SessionEvent) { events <- e }))
+	if err != nil {
+		t.Fatalf("NewRemoteWithOptions() returned error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := wd.CurrentURL(); !isInvalidSession(err) {
+			t.Fatalf("CurrentURL() returned error %v, want invalid session id", err)
+		}
+	}
+	if err := wd.Quit(); err != nil {
+		t.Fatalf("Quit() returned error: %v", err)
+	}
+
+	want := []SessionEventType{SessionCreated, SessionDialectDetected, SessionCrashed, SessionQuit}
+	for _, ch := range []chan SessionEvent{events, global} {
+		for _, typ := range want {
+			select {
+			case e := <-ch:
+				if e.Type != typ {
+					t.Fatalf("got a %s event, want %s", e.Type, typ)
+				}
+				if e.SessionID != "s1" || e.Fingerprint != caps.Fingerprint() || !e.W3C || e.Time.IsZero() {
+					t.Errorf("the %s event is %+v, want session s1 with the fingerprint of %v", e.Type, e, caps)
+				}
+				if (e.Err != nil) != (typ == SessionCrashed) {
+					t.Errorf("the %s event has error %v", e.Type, e.Err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("no %s event was delivered", typ)
+			}
+		}
+	}
+}
+
+func TestSessionEventBackpressure(t *testing.T) {
+	defer func(n int) { SessionEventBuffer = n }(SessionEventBuffer)
+	SessionEventBuffer = 2
+
+	var handlers sessionEventHandlers
+	started := make(chan bool, 10)
+	block := make(chan struct{})
+	delivered := make(chan SessionEvent, 10)
+	remove := handlers.add(func(e SessionEvent) {
+		started <- true
+		<-block
+		delivered <- e
+	})
+
+	dropped := DroppedSessionEvents()
+	handlers.emit(SessionEvent{SessionID: "0"})
+	<-started
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i < 10; i++ {
+			handlers.emit(SessionEvent{SessionID: fmt.Sprint(i)})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("emitting events to a blocked handler did not return")
+	}
+
+	// The handler holds one event and the buffer two more; the rest are
+	// dropped.
+	close(block)
+	remove()
+	var got []string
+	for i := 0; i < 3; i++ {
+		select {
+		case e := <-delivered:
+			got = append(got, e.SessionID)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d events were delivered, want 3", len(got))
+		}
+	}
+	if fmt.Sprint(got) != "[0 1 2]" {
+		t.Errorf("delivered events %v, want [0 1 2]", got)
+	}
+	if n := DroppedSessionEvents() - dropped; n != 7 {
+		t.Errorf("DroppedSessionEvents() increased by %d, want 7", n)
+	}
+	remove()
+}
+
+func TestSessionEventHandlersExitAfterQuit(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/session":
+			fmt.Fprint(w, `{"value":{"sessionId":"s1","capabilities":{}}}`)
+		case r.Method == "DELETE" && r.URL.Path == "/session/s1":
+			fmt.Fprint(w, `{"value":null}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+
+	quit := make(chan SessionEvent, 10)
+	wd, err := NewRemoteWithOptions(Capabilities{"browserName": "chrome"}, s.URL, SessionEventHandler(func(e SessionEvent) {
+		if e.Type == SessionQuit {
+			quit <- e
+		}
+	}))
+	if err != nil {
+		t.Fatalf("NewRemoteWithOptions() returned error: %v", err)
+	}
+	var handlers []*sessionEventHandler
+	hs := &wd.(*remoteWD).sessionEventHandlers
+	hs.mu.Lock()
+	for h := range hs.handlers {
+		handlers = append(handlers, h)
+	}
+	hs.mu.Unlock()
+	if len(handlers) != 1 {
+		t.Fatalf("the WebDriver has %d handlers, want 1", len(handlers))
+	}
+
+	if err := wd.Quit(); err != nil {
+		t.Fatalf("Quit() returned error: %v", err)
+	}
+	select {
+	case <-quit:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the SessionQuit event was not delivered")
+	}
+	select {
+	case <-handlers[0].done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the goroutine of the handler did not exit after Quit")
+	}
+}
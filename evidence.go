@@ -0,0 +1,177 @@
+package selenium
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"path"
+	"strings"
+	"time"
+)
+
+// EvidenceStep is a step run with WebDriver.Step while evidence mode is
+// enabled, with the evidence captured before and after it.
+type EvidenceStep struct {
+	// Name is the name of the step.
+	Name string `json:"name"`
+	// Start and End are when the step started and ended.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Err is the error returned by the step, if any.
+	Err string `json:"error,omitempty"`
+	// Before and After are the evidence captured before and after the step.
+	Before *Evidence `json:"before,omitempty"`
+	After  *Evidence `json:"after,omitempty"`
+	// Steps are the steps nested in the step.
+	Steps []*EvidenceStep `json:"steps,omitempty"`
+}
+
+// Evidence is the state of the browser captured at a point of a step.
+type Evidence struct {
+	// URL is the URL of the current page.
+	URL string `json:"url,omitempty"`
+	// Screenshot is the name of the screenshot artifact.
+	Screenshot string `json:"screenshot,omitempty"`
+	// Errors lists what could not be captured.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// evidenceJournal records the steps of a session in evidence mode.
+type evidenceJournal struct {
+	sink  ArtifactSink
+	steps []*EvidenceStep
+	// open holds the steps being run, innermost last.
+	open []*EvidenceStep
+}
+
+func (wd *remoteWD) EnableEvidence(sink ArtifactSink) {
+	if sink == nil {
+		wd.evidence = nil
+		return
+	}
+	wd.evidence = &evidenceJournal{sink: sink}
+}
+
+func (wd *remoteWD) Step(name string, fn func() error) error {
+	if wd.evidence == nil {
+		return fn()
+	}
+	return wd.evidenceStep(name, fn)
+}
+
+// evidenceStep runs fn as a step, capturing evidence before and after it.
+func (wd *remoteWD) evidenceStep(name string, fn func() error) error {
+	j := wd.evidence
+	step := &EvidenceStep{Name: name, Start: time.Now()}
+	if n := len(j.open); n > 0 {
+		parent := j.open[n-1]
+		parent.Steps = append(parent.Steps, step)
+	} else {
+		j.steps = append(j.steps, step)
+	}
+	j.open = append(j.open, step)
+	defer func() {
+		j.open = j.open[:len(j.open)-1]
+	}()
+
+	var names []string
+	for _, s := range j.open {
+		names = append(names, s.Name)
+	}
+	stepPath := strings.Join(names, " > ")
+
+	step.Before = wd.captureEvidence(j.sink, "step "+stepPath+": before")
+	err := fn()
+	step.After = wd.captureEvidence(j.sink, "step "+stepPath+": after")
+	step.End = time.Now()
+	if err != nil {
+		step.Err = err.Error()
+	}
+	// The journal is written when a top-level step ends, with its nested
+	// steps, rather than after each of them.
+	if len(j.open) == 1 {
+		if writeErr := wd.writeEvidenceJournal(); err == nil {
+			err = writeErr
+		}
+	}
+	return err
+}
+
+// captureEvidence captures the URL and a screenshot of the current page.
+// Failures are recorded in the evidence rather than failing the step.
+func (wd *remoteWD) captureEvidence(sink ArtifactSink, reason string) *Evidence {
+	e := new(Evidence)
+	url, err := wd.CurrentURL()
+	if err != nil {
+		e.Errors = append(e.Errors, "url: "+err.Error())
+	}
+	e.URL = url
+	screenshot, err := wd.Screenshot()
+	if err == nil {
+		info := ArtifactInfo{Command: wd.lastCommand(), URL: url, Reason: reason}
+		e.Screenshot, err = wd.saveArtifactTo(sink, "evidence", "png", info, screenshot)
+	}
+	if err != nil {
+		e.Errors = append(e.Errors, "screenshot: "+err.Error())
+	}
+	return e
+}
+
+func (wd *remoteWD) EvidenceSteps() []*EvidenceStep {
+	if wd.evidence == nil {
+		return nil
+	}
+	return wd.evidence.steps
+}
+
+// evidenceReport renders the step tree of the journal, with the screenshots
+// as thumbnails linking to them. The report is stored in the same directory
+// as the screenshots.
+var evidenceReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"base": path.Base,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Evidence for {{.Session}}</title>
+<style>
+body { font-family: sans-serif; }
+li { margin: 0.5em 0; }
+.error { color: #b00020; }
+.evidence { display: inline-block; margin-right: 1em; vertical-align: top; font-size: small; }
+.evidence img { display: block; max-width: 240px; border: 1px solid #ccc; }
+</style>
+</head>
+<body>
+<h1>Evidence for {{.Session}}</h1>
+{{template "steps" .Steps}}
+</body>
+</html>
+{{define "steps"}}{{if .}}<ol>
+{{range .}}<li><strong>{{.Name}}</strong> ({{.End.Sub .Start}}){{if .Err}} <span class="error">{{.Err}}</span>{{end}}
+<div>{{with .Before}}{{template "evidence" .}}{{end}}{{with .After}}{{template "evidence" .}}{{end}}</div>
+{{template "steps" .Steps}}</li>
+{{end}}</ol>{{end}}{{end}}
+{{define "evidence"}}<div class="evidence">{{if .Screenshot}}<a href="{{base .Screenshot}}"><img src="{{base .Screenshot}}" alt="screenshot"></a>{{end}}{{.URL}}{{range .Errors}}<div class="error">{{.}}</div>{{end}}</div>{{end}}`))
+
+// writeEvidenceJournal stores the journal of the session's steps, as JSON
+// and as an HTML report, in the evidence sink.
+func (wd *remoteWD) writeEvidenceJournal() error {
+	j := wd.evidence
+	dir := wd.artifactDir()
+	data, err := json.MarshalIndent(j.steps, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := j.sink.WriteArtifact(path.Join(dir, "evidence-"+wd.id+".json"), data); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := evidenceReport.Execute(&buf, struct {
+		Session string
+		Steps   []*EvidenceStep
+	}{wd.id, j.steps}); err != nil {
+		return err
+	}
+	return j.sink.WriteArtifact(path.Join(dir, "evidence-"+wd.id+".html"), buf.Bytes())
+}
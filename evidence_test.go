@@ -0,0 +1,106 @@
+package selenium
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+)
+
+// countingSink counts the writes of each artifact to its ArtifactSink.
+type countingSink struct {
+	ArtifactSink
+	writes map[string]int
+}
+
+func (s *countingSink) WriteArtifact(name string, data []byte) error {
+	s.writes[name]++
+	return s.ArtifactSink.WriteArtifact(name, data)
+}
+
+func TestStepEvidence(t *testing.T) {
+	s := dumpServer()
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	calls := 0
+	if err := wd.Step("untracked", func() error { calls++; return nil }); err != nil || calls != 1 {
+		t.Fatalf("Step() without evidence mode returned %v after %d calls, want nil after 1", err, calls)
+	}
+	if wd.EvidenceSteps() != nil {
+		t.Errorf("EvidenceSteps() without evidence mode = %v, want nil", wd.EvidenceSteps())
+	}
+
+	sink := new(MemorySink)
+	counter := &countingSink{ArtifactSink: sink, writes: make(map[string]int)}
+	wd.SetArtifactTestName("TestStepEvidence")
+	wd.EnableEvidence(counter)
+	errSubmit := errors.New("submit failed")
+	err := wd.Step("checkout", func() error {
+		if err := wd.Step("fill address", func() error { return nil }); err != nil {
+			return err
+		}
+		return wd.Step("submit", func() error { return errSubmit })
+	})
+	if err != errSubmit {
+		t.Fatalf("Step() returned error %v, want that of the failed nested step", err)
+	}
+
+	steps := wd.EvidenceSteps()
+	if len(steps) != 1 || len(steps[0].Steps) != 2 {
+		t.Fatalf("EvidenceSteps() = %+v, want a step with two nested steps", steps)
+	}
+	submit := steps[0].Steps[1]
+	if submit.Name != "submit" || submit.Err != "submit failed" || steps[0].Err != "submit failed" {
+		t.Errorf("the submit step is %+v, want it to have failed", submit)
+	}
+	for _, step := range []*EvidenceStep{steps[0], steps[0].Steps[0], submit} {
+		for _, e := range []*Evidence{step.Before, step.After} {
+			if e == nil || e.URL != "http://example.com/" || len(e.Errors) > 0 {
+				t.Fatalf("the evidence of step %q is %+v, want the URL and no errors", step.Name, e)
+			}
+			if _, ok := sink.Artifact(e.Screenshot); !ok {
+				t.Errorf("the screenshot %q of step %q is not in the sink", e.Screenshot, step.Name)
+			}
+		}
+	}
+
+	data, ok := sink.Artifact("TestStepEvidence/evidence-123.json")
+	if !ok {
+		t.Fatalf("the sink holds no journal: %q", sink.Names())
+	}
+	for _, name := range []string{"TestStepEvidence/evidence-123.json", "TestStepEvidence/evidence-123.html"} {
+		if n := counter.writes[name]; n != 1 {
+			t.Errorf("%s was written %d times, want once, when the top-level step ended", name, n)
+		}
+	}
+	var journal []*EvidenceStep
+	if err := json.Unmarshal(data, &journal); err != nil || len(journal) != 1 || journal[0].Steps[1].After.Screenshot != submit.After.Screenshot {
+		t.Errorf("the journal is %s, want the step tree (%v)", data, err)
+	}
+	var index []ArtifactInfo
+	data, _ = sink.Artifact("TestStepEvidence/index-123.json")
+	if err := json.Unmarshal(data, &index); err != nil || len(index) != 6 || index[5].Reason != "step checkout: after" {
+		t.Errorf("the artifact index is %s, want the 6 screenshots (%v)", data, err)
+	}
+
+	report, ok := sink.Artifact("TestStepEvidence/evidence-123.html")
+	if !ok {
+		t.Fatalf("the sink holds no report: %q", sink.Names())
+	}
+	html := string(report)
+	for _, want := range []string{
+		"<strong>checkout</strong>",
+		"<strong>fill address</strong>",
+		`<span class="error">submit failed</span>`,
+		`<img src="` + path.Base(submit.Before.Screenshot) + `"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("the report does not contain %s:\n%s", want, html)
+		}
+	}
+	if strings.Index(html, "fill address") > strings.Index(html, ">submit<") {
+		t.Errorf("the report does not list the steps in order:\n%s", html)
+	}
+}
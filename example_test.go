@@ -0,0 +1,132 @@
+package selenium_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+// This example shows how a command-line program that is interrupted with
+// Ctrl-C ends its browser sessions without hanging on a wedged driver.
+func ExampleQuitAllTrackedContext() {
+	selenium.TrackSessions(true)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	wd, err := selenium.NewRemote(selenium.Capabilities{"browserName": "firefox"}, "http://localhost:4444/wd/hub")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// Scrape until interrupted. The WebDriver is only used by this goroutine,
+	// as it is not safe for concurrent use.
+	for _, u := range []string{"https://example.com/a", "https://example.com/b"} {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := wd.Get(u); err != nil {
+			fmt.Println(err)
+			break
+		}
+	}
+
+	// Give the sessions at most 5 seconds to end. Sessions that do not end in
+	// time are abandoned.
+	quitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := selenium.QuitAllTrackedContext(quitCtx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// This example shows how to find a row of a virtualized list, which only
+// renders the rows in view: the rows are found, the list is scrolled if the
+// row is not among them, and the rows are found again.
+func ExampleWebDriver_ScrollContainer() {
+	wd, err := selenium.NewRemote(selenium.Capabilities{"browserName": "firefox"}, "http://localhost:4444/wd/hub")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer wd.Quit()
+
+	if err := wd.Get("https://example.com/contacts"); err != nil {
+		fmt.Println(err)
+		return
+	}
+	first, err := wd.FindElement(selenium.ByCSSSelector, ".contact-row")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// The list is the nearest ancestor of the rows that scrolls.
+	list, err := first.ScrollableAncestor()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for page := 0; page < 50; page++ {
+		rows, err := list.FindElements(selenium.ByCSSSelector, ".contact-row")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, row := range rows {
+			text, err := row.Text()
+			if err != nil {
+				// The row was removed from the DOM while being read.
+				continue
+			}
+			if text == "Ada Lovelace" {
+				if err := row.ScrollIntoViewWithin(list); err != nil {
+					fmt.Println(err)
+					return
+				}
+				fmt.Println("found on page", page)
+				return
+			}
+		}
+		// Scroll by roughly a screenful; the rows found so far are stale
+		// once the list renders the next ones.
+		if err := wd.ScrollContainer(list, 0, 400); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	fmt.Println("not found")
+}
+
+// lockDevice locks the screen of the device of an Appium session for the
+// given number of seconds, with a vendor endpoint that the WebDriver
+// interface does not cover.
+func lockDevice(wd selenium.WebDriver, seconds int) error {
+	_, err := selenium.PostValue[interface{}](wd, "/session/%s/appium/device/lock", map[string]int{"seconds": seconds}, wd.SessionID())
+	return err
+}
+
+// This example shows how to wrap vendor endpoints of Appium.
+func ExamplePostValue() {
+	wd, err := selenium.NewRemote(selenium.Capabilities{"platformName": "Android"}, "http://localhost:4723")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer wd.Quit()
+
+	if err := lockDevice(wd, 5); err != nil {
+		fmt.Println(err)
+		return
+	}
+	locked, err := selenium.GetValue[bool](wd, "/session/%s/appium/device/is_locked", wd.SessionID())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("locked:", locked)
+}
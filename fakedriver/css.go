@@ -0,0 +1,326 @@
+package fakedriver
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// selector is a parsed CSS selector: a sequence of compound selectors joined
+// by combinators.
+type selector struct {
+	parts []compound
+	// combinators[i] joins parts[i] and parts[i+1], and is ' ' for the
+	// descendant combinator or '>' for the child combinator.
+	combinators []byte
+}
+
+// compound is a compound selector, e.g. "input#q.search[name=q]".
+type compound struct {
+	tag     string // Empty or "*" for any element.
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+type attrSelector struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+// parseSelector parses the subset of CSS selectors supported by the fake:
+// type selectors, the universal selector, ID and class selectors, [attr] and
+// [attr=value] attribute selectors, and the descendant and child
+// combinators.
+func parseSelector(s string) (*selector, error) {
+	p := &selectorParser{s: s}
+	sel, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("fakedriver: unsupported CSS selector %q: %v", s, err)
+	}
+	return sel, nil
+}
+
+type selectorParser struct {
+	s   string
+	pos int
+}
+
+func (p *selectorParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *selectorParser) peek() byte {
+	return p.s[p.pos]
+}
+
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for !p.eof() && strings.IndexByte(" \t\r\n\f", p.peek()) >= 0 {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *selectorParser) parse() (*selector, error) {
+	sel := new(selector)
+	p.skipSpace()
+	if p.eof() {
+		return nil, fmt.Errorf("empty selector")
+	}
+	for {
+		c, err := p.compound()
+		if err != nil {
+			return nil, err
+		}
+		sel.parts = append(sel.parts, c)
+
+		space := p.skipSpace()
+		if p.eof() {
+			return sel, nil
+		}
+		switch ch := p.peek(); ch {
+		case '>':
+			p.pos++
+			p.skipSpace()
+			sel.combinators = append(sel.combinators, '>')
+		case '+', '~':
+			return nil, fmt.Errorf("the %q combinator is not supported", string(ch))
+		case ',':
+			return nil, fmt.Errorf("selector lists are not supported")
+		default:
+			if !space {
+				return nil, fmt.Errorf("unexpected %q at offset %d", string(ch), p.pos)
+			}
+			sel.combinators = append(sel.combinators, ' ')
+		}
+		if p.eof() {
+			return nil, fmt.Errorf("missing selector after combinator")
+		}
+	}
+}
+
+func (p *selectorParser) compound() (compound, error) {
+	var c compound
+	if p.peek() == '*' {
+		p.pos++
+		c.tag = "*"
+	} else if isIdentChar(p.peek()) {
+		c.tag = strings.ToLower(p.ident())
+	}
+	for !p.eof() {
+		switch ch := p.peek(); ch {
+		case '#':
+			p.pos++
+			id := p.ident()
+			if id == "" {
+				return c, fmt.Errorf("missing ID after '#'")
+			}
+			c.id = id
+		case '.':
+			p.pos++
+			class := p.ident()
+			if class == "" {
+				return c, fmt.Errorf("missing class name after '.'")
+			}
+			c.classes = append(c.classes, class)
+		case '[':
+			p.pos++
+			a, err := p.attr()
+			if err != nil {
+				return c, err
+			}
+			c.attrs = append(c.attrs, a)
+		case ':':
+			return c, fmt.Errorf("pseudo-classes and pseudo-elements are not supported")
+		default:
+			if c.tag == "" && c.id == "" && c.classes == nil && c.attrs == nil {
+				return c, fmt.Errorf("unexpected %q at offset %d", string(ch), p.pos)
+			}
+			return c, nil
+		}
+	}
+	return c, nil
+}
+
+func (p *selectorParser) attr() (attrSelector, error) {
+	var a attrSelector
+	p.skipSpace()
+	a.name = strings.ToLower(p.ident())
+	if a.name == "" {
+		return a, fmt.Errorf("missing attribute name after '['")
+	}
+	p.skipSpace()
+	if p.eof() {
+		return a, fmt.Errorf("unterminated attribute selector")
+	}
+	switch ch := p.peek(); ch {
+	case ']':
+		p.pos++
+		return a, nil
+	case '=':
+		p.pos++
+	case '~', '|', '^', '$', '*':
+		return a, fmt.Errorf("the %q attribute operator is not supported", string(ch)+"=")
+	default:
+		return a, fmt.Errorf("unexpected %q in attribute selector", string(ch))
+	}
+	p.skipSpace()
+	if p.eof() {
+		return a, fmt.Errorf("missing attribute value")
+	}
+	a.hasValue = true
+	if q := p.peek(); q == '"' || q == '\'' {
+		v, err := p.quoted(q)
+		if err != nil {
+			return a, err
+		}
+		a.value = v
+	} else {
+		a.value = p.ident()
+		if a.value == "" {
+			return a, fmt.Errorf("missing attribute value")
+		}
+	}
+	p.skipSpace()
+	if p.eof() || p.peek() != ']' {
+		return a, fmt.Errorf("unterminated attribute selector")
+	}
+	p.pos++
+	return a, nil
+}
+
+func isIdentChar(ch byte) bool {
+	return ch == '-' || ch == '_' || ch == '\\' || ch >= 0x80 ||
+		(ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+// ident parses an identifier, resolving escapes.
+func (p *selectorParser) ident() string {
+	var b bytes.Buffer
+	for !p.eof() && isIdentChar(p.peek()) {
+		if p.peek() == '\\' {
+			p.escape(&b)
+			continue
+		}
+		b.WriteByte(p.peek())
+		p.pos++
+	}
+	return b.String()
+}
+
+// quoted parses a string delimited by q, resolving escapes.
+func (p *selectorParser) quoted(q byte) (string, error) {
+	var b bytes.Buffer
+	p.pos++
+	for !p.eof() {
+		switch ch := p.peek(); ch {
+		case q:
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			p.escape(&b)
+		default:
+			b.WriteByte(ch)
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// escape parses an escape sequence: a backslash followed by up to six hex
+// digits and an optional space, or by any other character.
+func (p *selectorParser) escape(b *bytes.Buffer) {
+	p.pos++
+	if p.eof() {
+		return
+	}
+	start := p.pos
+	for p.pos < len(p.s) && p.pos-start < 6 && strings.IndexByte("0123456789abcdefABCDEF", p.peek()) >= 0 {
+		p.pos++
+	}
+	if p.pos == start {
+		b.WriteByte(p.peek())
+		p.pos++
+		return
+	}
+	r, _ := strconv.ParseUint(p.s[start:p.pos], 16, 32)
+	b.WriteRune(rune(r))
+	if !p.eof() && p.peek() == ' ' {
+		p.pos++
+	}
+}
+
+// matches returns whether the element n matches the selector.
+func (s *selector) matches(n *html.Node) bool {
+	return s.matchAt(n, len(s.parts)-1)
+}
+
+func (s *selector) matchAt(n *html.Node, i int) bool {
+	if !s.parts[i].matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	if s.combinators[i-1] == '>' {
+		p := parentElement(n)
+		return p != nil && s.matchAt(p, i-1)
+	}
+	for p := parentElement(n); p != nil; p = parentElement(p) {
+		if s.matchAt(p, i-1) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *compound) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if c.tag != "" && c.tag != "*" && c.tag != n.Data {
+		return false
+	}
+	if c.id != "" {
+		if id, ok := attr(n, "id"); !ok || id != c.id {
+			return false
+		}
+	}
+	if len(c.classes) > 0 {
+		class, _ := attr(n, "class")
+		classes := strings.Fields(class)
+		for _, want := range c.classes {
+			found := false
+			for _, got := range classes {
+				if got == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	for _, a := range c.attrs {
+		v, ok := attr(n, a.name)
+		if !ok || (a.hasValue && v != a.value) {
+			return false
+		}
+	}
+	return true
+}
+
+func parentElement(n *html.Node) *html.Node {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode {
+			return p
+		}
+	}
+	return nil
+}
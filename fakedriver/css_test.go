@@ -0,0 +1,100 @@
+package fakedriver
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+const cssPage = `<html><body>
+<div id="main" class="page wide">
+  <form name="search">
+    <input id="q" name="q" type="text" class="search big">
+    <label><input type="checkbox" data-testid="opt-in"></label>
+  </form>
+  <ul><li class="item">One</li><li class="item sel">Two</li></ul>
+</div>
+<p class="item">Three</p>
+</body></html>`
+
+func TestSupportedSelectors(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(cssPage))
+	if err != nil {
+		t.Fatalf("html.Parse() returned error: %v", err)
+	}
+	tests := []struct {
+		sel  string
+		want []string // The ID, data-testid or text of each matching element.
+	}{
+		{"input", []string{"q", "opt-in"}},
+		{"#q", []string{"q"}},
+		{"INPUT#q", []string{"q"}},
+		{".item", []string{"One", "Two", "Three"}},
+		{"li.item.sel", []string{"Two"}},
+		{"[data-testid]", []string{"opt-in"}},
+		{`[data-testid="opt-in"]`, []string{"opt-in"}},
+		{"[type=checkbox]", []string{"opt-in"}},
+		{`input[name='q'].search`, []string{"q"}},
+		{"#main li", []string{"One", "Two"}},
+		{"div > p", nil},
+		{"body > p", []string{"Three"}},
+		{"form > input", []string{"q"}},
+		{"form input", []string{"q", "opt-in"}},
+		{"div ul>li.sel", []string{"Two"}},
+		{"* > .sel", []string{"Two"}},
+		{`#\6d ain ul li`, []string{"One", "Two"}},
+	}
+	for _, tc := range tests {
+		sel, err := parseSelector(tc.sel)
+		if err != nil {
+			t.Errorf("parseSelector(%q) returned error: %v", tc.sel, err)
+			continue
+		}
+		var got []string
+		for _, n := range descendants(doc) {
+			if !sel.matches(n) {
+				continue
+			}
+			if id, ok := attr(n, "id"); ok {
+				got = append(got, id)
+			} else if id, ok := attr(n, "data-testid"); ok {
+				got = append(got, id)
+			} else {
+				got = append(got, textContent(n))
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%q matched %q, want %q", tc.sel, got, tc.want)
+		}
+	}
+}
+
+func TestUnsupportedSelectors(t *testing.T) {
+	tests := []struct {
+		sel, reason string
+	}{
+		{"", "empty selector"},
+		{"a:hover", "pseudo-classes"},
+		{"li::before", "pseudo-classes"},
+		{"h1 + p", `"+" combinator`},
+		{"h1 ~ p", `"~" combinator`},
+		{"a, b", "selector lists"},
+		{"[class~=x]", `"~=" attribute operator`},
+		{"[href^=http]", `"^=" attribute operator`},
+		{"[href", "unterminated attribute selector"},
+		{`[title="x]`, "unterminated string"},
+		{"div >", "missing selector after combinator"},
+		{"#", "missing ID"},
+	}
+	for _, tc := range tests {
+		_, err := parseSelector(tc.sel)
+		if err == nil {
+			t.Errorf("parseSelector(%q) returned nil error", tc.sel)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.reason) {
+			t.Errorf("parseSelector(%q) returned error %q, want it to mention %q", tc.sel, err, tc.reason)
+		}
+	}
+}
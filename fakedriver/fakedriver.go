@@ -0,0 +1,736 @@
+// Package fakedriver provides an in-memory implementation of the
+// selenium.WebDriver and selenium.WebElement interfaces over a small model of
+// an HTML document, for fast unit tests of page objects and wait logic that
+// do not need a browser.
+//
+// The fake does not execute JavaScript or lay out the page. Finding elements
+// supports the ID, name, tag name, class name, link text and CSS selector
+// strategies; CSS selectors are limited to type selectors, the universal
+// selector, #id, .class, [attr] and [attr=value], combined with the
+// descendant (space) and child (>) combinators. Other selectors, and XPath,
+// return an "invalid selector" error.
+//
+// Clicking a checkbox or radio button toggles its checked state, clicking a
+// link navigates to the page registered for its URL, and handlers registered
+// with OnClick can script further page transitions. SendKeys appends to the
+// value of an element and Clear empties it. Elements of a page that has been
+// replaced return "stale element reference" errors.
+//
+// The fake has a virtual clock: functions scheduled with After run only when
+// the test calls Advance, so that delayed page changes can be tested without
+// sleeping. The waits, such as WaitWithTimeout, FindElementWithTimeout and
+// WaitUntilVisible, advance the virtual clock by their interval between
+// polls, and time out when their timeout has elapsed on it.
+//
+// Methods of the interfaces that the fake does not implement return an
+// "unsupported operation" error saying so, or, if they cannot fail, do
+// nothing.
+package fakedriver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tebeka/selenium"
+	"golang.org/x/net/html"
+)
+
+// Driver is a fake selenium.WebDriver.
+type Driver struct {
+	pages   map[string]string
+	url     string
+	doc     *html.Node
+	history []string
+	pos     int
+
+	active *html.Node
+	clicks map[*html.Node]int
+
+	handlers []clickHandler
+
+	now       time.Time
+	scheduled []scheduledFunc
+	seq       int
+}
+
+type clickHandler struct {
+	sel *selector
+	f   func(d *Driver) error
+}
+
+type scheduledFunc struct {
+	at  time.Time
+	seq int
+	f   func(d *Driver)
+}
+
+// New returns a fake driver showing the HTML document page at "about:blank",
+// which is registered as the page at that URL, so that navigating back to it
+// shows it again.
+func New(page string) (*Driver, error) {
+	d := &Driver{
+		pages:  make(map[string]string),
+		clicks: make(map[*html.Node]int),
+		now:    time.Unix(0, 0).UTC(),
+	}
+	if err := d.SetPage(page); err != nil {
+		return nil, err
+	}
+	d.url = "about:blank"
+	d.pages[d.url] = page
+	d.history = []string{d.url}
+	return d, nil
+}
+
+// AddPage registers the HTML document page at the URL u, to be loaded by Get
+// and by clicks on links to u.
+func (d *Driver) AddPage(u, page string) {
+	d.pages[u] = page
+}
+
+// SetPage replaces the current document with page, without navigating.
+// Elements of the previous document become stale.
+func (d *Driver) SetPage(page string) error {
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return err
+	}
+	d.doc = doc
+	d.active = nil
+	return nil
+}
+
+// OnClick registers f to be called when an element matching the CSS selector
+// sel is clicked, after the default action of the click. If several handlers
+// match, they are called in the order they were registered.
+func (d *Driver) OnClick(sel string, f func(d *Driver) error) error {
+	s, err := parseSelector(sel)
+	if err != nil {
+		return err
+	}
+	d.handlers = append(d.handlers, clickHandler{sel: s, f: f})
+	return nil
+}
+
+// Now returns the time of the virtual clock.
+func (d *Driver) Now() time.Time {
+	return d.now
+}
+
+// After schedules f to be called when the virtual clock has advanced by dur.
+func (d *Driver) After(dur time.Duration, f func(d *Driver)) {
+	d.seq++
+	d.scheduled = append(d.scheduled, scheduledFunc{at: d.now.Add(dur), seq: d.seq, f: f})
+}
+
+// Advance advances the virtual clock by dur, calling the functions scheduled
+// with After that become due, in order.
+func (d *Driver) Advance(dur time.Duration) {
+	end := d.now.Add(dur)
+	for {
+		sort.Slice(d.scheduled, func(i, j int) bool {
+			a, b := d.scheduled[i], d.scheduled[j]
+			return a.at.Before(b.at) || (a.at.Equal(b.at) && a.seq < b.seq)
+		})
+		if len(d.scheduled) == 0 || d.scheduled[0].at.After(end) {
+			break
+		}
+		next := d.scheduled[0]
+		d.scheduled = d.scheduled[1:]
+		d.now = next.at
+		next.f(d)
+	}
+	d.now = end
+}
+
+// Clicks returns the number of times elem has been clicked.
+func (d *Driver) Clicks(elem selenium.WebElement) int {
+	e, ok := elem.(*Element)
+	if !ok {
+		return 0
+	}
+	return d.clicks[e.node]
+}
+
+// load navigates to u, without changing the history.
+func (d *Driver) load(u string) error {
+	page, ok := d.pages[u]
+	if !ok {
+		return &selenium.Error{Err: "unknown error", Message: fmt.Sprintf("fakedriver: no page registered for %q", u)}
+	}
+	if err := d.SetPage(page); err != nil {
+		return err
+	}
+	d.url = u
+	return nil
+}
+
+func (d *Driver) Get(u string) error {
+	if err := d.load(u); err != nil {
+		return err
+	}
+	d.history = append(d.history[:d.pos+1], u)
+	d.pos++
+	return nil
+}
+
+func (d *Driver) Back() error {
+	if d.pos == 0 {
+		return nil
+	}
+	if err := d.load(d.history[d.pos-1]); err != nil {
+		return err
+	}
+	d.pos--
+	return nil
+}
+
+func (d *Driver) Forward() error {
+	if d.pos == len(d.history)-1 {
+		return nil
+	}
+	if err := d.load(d.history[d.pos+1]); err != nil {
+		return err
+	}
+	d.pos++
+	return nil
+}
+
+func (d *Driver) Refresh() error {
+	if _, ok := d.pages[d.url]; !ok {
+		return nil
+	}
+	return d.load(d.url)
+}
+
+func (d *Driver) CurrentURL() (string, error) {
+	return d.url, nil
+}
+
+func (d *Driver) Title() (string, error) {
+	for _, n := range descendants(d.doc) {
+		if n.Data == "title" {
+			return textContent(n), nil
+		}
+	}
+	return "", nil
+}
+
+func (d *Driver) PageSource() (string, error) {
+	var buf bytes.Buffer
+	if err := html.Render(&buf, d.doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (d *Driver) SessionID() string {
+	return "fakedriver"
+}
+
+func (d *Driver) SessionId() string {
+	return d.SessionID()
+}
+
+func (d *Driver) Quit() error {
+	return nil
+}
+
+func (d *Driver) QuitContext(context.Context) error {
+	return nil
+}
+
+func (d *Driver) SetImplicitWaitTimeout(time.Duration) error {
+	return nil
+}
+
+func (d *Driver) SetPageLoadTimeout(time.Duration) error {
+	return nil
+}
+
+func (d *Driver) SetAsyncScriptTimeout(time.Duration) error {
+	return nil
+}
+
+// Screenshot returns a 1x1 PNG image.
+func (d *Driver) Screenshot() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// errNoScripts is returned by the methods that execute scripts.
+var errNoScripts = &selenium.Error{Err: "unsupported operation", Message: "fakedriver: scripts are not supported"}
+
+func (d *Driver) ExecuteScript(string, []interface{}) (interface{}, error) {
+	return nil, errNoScripts
+}
+
+func (d *Driver) ExecuteScriptAsync(string, []interface{}) (interface{}, error) {
+	return nil, errNoScripts
+}
+
+func (d *Driver) ExecuteScriptRaw(string, []interface{}) ([]byte, error) {
+	return nil, errNoScripts
+}
+
+func (d *Driver) ExecuteScriptAsyncRaw(string, []interface{}) ([]byte, error) {
+	return nil, errNoScripts
+}
+
+func (d *Driver) FindElement(by, value string) (selenium.WebElement, error) {
+	return d.findOne(d.doc, by, value)
+}
+
+func (d *Driver) FindElements(by, value string) ([]selenium.WebElement, error) {
+	return d.findAll(d.doc, by, value)
+}
+
+func (d *Driver) Find(loc selenium.Locator) (selenium.WebElement, error) {
+	return d.FindElement(loc.By, loc.Value)
+}
+
+func (d *Driver) FindAll(loc selenium.Locator) ([]selenium.WebElement, error) {
+	return d.FindElements(loc.By, loc.Value)
+}
+
+// ActiveElement returns the element last clicked or typed into, or the body
+// of the document.
+func (d *Driver) ActiveElement() (selenium.WebElement, error) {
+	if d.active != nil && d.attached(d.active) {
+		return &Element{d: d, node: d.active}, nil
+	}
+	return d.findOne(d.doc, selenium.ByTagName, "body")
+}
+
+// matcher returns a function that reports whether an element matches the
+// locator.
+func matcher(by, value string) (func(n *html.Node) bool, error) {
+	hasAttr := func(name, value string) func(n *html.Node) bool {
+		return func(n *html.Node) bool {
+			v, ok := attr(n, name)
+			return ok && v == value
+		}
+	}
+	switch by {
+	case selenium.ByID:
+		return hasAttr("id", value), nil
+	case selenium.ByName:
+		return hasAttr("name", value), nil
+	case selenium.ByTagName:
+		tag := strings.ToLower(value)
+		return func(n *html.Node) bool { return n.Data == tag }, nil
+	case selenium.ByClassName:
+		return func(n *html.Node) bool {
+			class, _ := attr(n, "class")
+			for _, c := range strings.Fields(class) {
+				if c == value {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case selenium.ByLinkText:
+		return func(n *html.Node) bool { return n.Data == "a" && textContent(n) == value }, nil
+	case selenium.ByPartialLinkText:
+		return func(n *html.Node) bool { return n.Data == "a" && strings.Contains(textContent(n), value) }, nil
+	case selenium.ByCSSSelector:
+		sel, err := parseSelector(value)
+		if err != nil {
+			return nil, &selenium.Error{Err: "invalid selector", Message: err.Error()}
+		}
+		return sel.matches, nil
+	}
+	return nil, &selenium.Error{Err: "invalid selector", Message: fmt.Sprintf("fakedriver: the %q locator strategy is not supported", by)}
+}
+
+func (d *Driver) findAll(root *html.Node, by, value string) ([]selenium.WebElement, error) {
+	match, err := matcher(by, value)
+	if err != nil {
+		return nil, err
+	}
+	var elems []selenium.WebElement
+	for _, n := range descendants(root) {
+		if match(n) {
+			elems = append(elems, &Element{d: d, node: n})
+		}
+	}
+	return elems, nil
+}
+
+func (d *Driver) findOne(root *html.Node, by, value string) (selenium.WebElement, error) {
+	elems, err := d.findAll(root, by, value)
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) == 0 {
+		return nil, &selenium.Error{Err: "no such element", Message: fmt.Sprintf("fakedriver: no element matches %s %q", by, value)}
+	}
+	return elems[0], nil
+}
+
+// attached returns whether n is part of the current document.
+func (d *Driver) attached(n *html.Node) bool {
+	for ; n != nil; n = n.Parent {
+		if n == d.doc {
+			return true
+		}
+	}
+	return false
+}
+
+// Element is a fake selenium.WebElement.
+type Element struct {
+	d    *Driver
+	node *html.Node
+}
+
+func (e *Element) String() string {
+	return fmt.Sprintf("fakedriver element <%s>", e.node.Data)
+}
+
+// check returns a "stale element reference" error if the element is not part
+// of the current document.
+func (e *Element) check() error {
+	if !e.d.attached(e.node) {
+		return &selenium.Error{Err: "stale element reference", Message: fmt.Sprintf("fakedriver: %s is not attached to the current document", e)}
+	}
+	return nil
+}
+
+// IsStale returns true if the element is not attached to the current
+// document.
+func (e *Element) IsStale() (bool, error) {
+	return !e.d.attached(e.node), nil
+}
+
+func (e *Element) Click() error {
+	if err := e.check(); err != nil {
+		return err
+	}
+	d, n := e.d, e.node
+	d.clicks[n]++
+	d.active = n
+	if _, disabled := attr(n, "disabled"); !disabled {
+		typ, _ := attr(n, "type")
+		switch {
+		case n.Data == "input" && strings.EqualFold(typ, "checkbox"):
+			if _, checked := attr(n, "checked"); checked {
+				removeAttr(n, "checked")
+			} else {
+				setAttr(n, "checked", "")
+			}
+		case n.Data == "input" && strings.EqualFold(typ, "radio"):
+			name, _ := attr(n, "name")
+			for _, other := range descendants(d.doc) {
+				if other.Data != "input" || !hasAttrValue(other, "type", "radio") {
+					continue
+				}
+				if otherName, _ := attr(other, "name"); otherName == name {
+					removeAttr(other, "checked")
+				}
+			}
+			setAttr(n, "checked", "")
+		case n.Data == "option":
+			setAttr(n, "selected", "")
+		case n.Data == "a":
+			if href, ok := attr(n, "href"); ok {
+				if err := d.followLink(href); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, h := range d.handlers {
+		if h.sel.matches(n) {
+			if err := h.f(d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// followLink navigates to the page registered for href, resolved against
+// the current URL, if there is one.
+func (d *Driver) followLink(href string) error {
+	u := href
+	if base, err := url.Parse(d.url); err == nil {
+		if ref, err := base.Parse(href); err == nil {
+			u = ref.String()
+		}
+	}
+	if _, ok := d.pages[u]; !ok {
+		return nil
+	}
+	return d.Get(u)
+}
+
+func (e *Element) SendKeys(keys string) error {
+	if err := e.check(); err != nil {
+		return err
+	}
+	e.d.active = e.node
+	if e.node.Data == "textarea" {
+		setText(e.node, textContent(e.node)+keys)
+		return nil
+	}
+	v, _ := attr(e.node, "value")
+	setAttr(e.node, "value", v+keys)
+	return nil
+}
+
+// SendKeysLiteral is SendKeys, since the fake driver uploads no files.
+func (e *Element) SendKeysLiteral(keys string) error {
+	return e.SendKeys(keys)
+}
+
+func (e *Element) Clear() error {
+	if err := e.check(); err != nil {
+		return err
+	}
+	if e.node.Data == "textarea" {
+		setText(e.node, "")
+		return nil
+	}
+	setAttr(e.node, "value", "")
+	return nil
+}
+
+func (e *Element) TagName() (string, error) {
+	if err := e.check(); err != nil {
+		return "", err
+	}
+	return e.node.Data, nil
+}
+
+// Text returns the text content of the element, with runs of whitespace
+// collapsed.
+func (e *Element) Text() (string, error) {
+	if err := e.check(); err != nil {
+		return "", err
+	}
+	return textContent(e.node), nil
+}
+
+func (e *Element) GetAttribute(name string) (string, error) {
+	if err := e.check(); err != nil {
+		return "", err
+	}
+	v, _ := attr(e.node, strings.ToLower(name))
+	return v, nil
+}
+
+func (e *Element) Attributes() (map[string]string, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	attrs := make(map[string]string, len(e.node.Attr))
+	for _, a := range e.node.Attr {
+		name := a.Key
+		if a.Namespace != "" {
+			name = a.Namespace + ":" + a.Key
+		}
+		attrs[name] = a.Val
+	}
+	return attrs, nil
+}
+
+func (e *Element) IsSelected() (bool, error) {
+	if err := e.check(); err != nil {
+		return false, err
+	}
+	_, checked := attr(e.node, "checked")
+	_, selected := attr(e.node, "selected")
+	return checked || selected, nil
+}
+
+func (e *Element) IsEnabled() (bool, error) {
+	if err := e.check(); err != nil {
+		return false, err
+	}
+	_, disabled := attr(e.node, "disabled")
+	return !disabled, nil
+}
+
+// IsDisplayed returns false if the element or one of its ancestors has the
+// hidden attribute or an inline display: none style.
+func (e *Element) IsDisplayed() (bool, error) {
+	if err := e.check(); err != nil {
+		return false, err
+	}
+	for n := e.node; n != nil; n = parentElement(n) {
+		if _, hidden := attr(n, "hidden"); hidden {
+			return false, nil
+		}
+		style, _ := attr(n, "style")
+		if strings.Contains(strings.Replace(strings.ToLower(style), " ", "", -1), "display:none") {
+			return false, nil
+		}
+		if n.Data == "input" && hasAttrValue(n, "type", "hidden") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Location returns the origin, as the fake does not lay out the page.
+func (e *Element) Location() (*selenium.Point, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return &selenium.Point{}, nil
+}
+
+// LocationInView returns the origin, as the fake does not lay out the page.
+func (e *Element) LocationInView() (*selenium.Point, error) {
+	return e.Location()
+}
+
+// Size returns a zero size, as the fake does not lay out the page.
+func (e *Element) Size() (*selenium.Size, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return &selenium.Size{}, nil
+}
+
+// Rect returns an empty rectangle at the origin, as the fake does not lay out
+// the page.
+func (e *Element) Rect() (*selenium.Rect, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return &selenium.Rect{}, nil
+}
+
+func (e *Element) MoveTo(xOffset, yOffset int) error {
+	return e.check()
+}
+
+func (e *Element) FindElement(by, value string) (selenium.WebElement, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return e.d.findOne(e.node, by, value)
+}
+
+func (e *Element) FindElements(by, value string) ([]selenium.WebElement, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return e.d.findAll(e.node, by, value)
+}
+
+func (e *Element) Find(loc selenium.Locator) (selenium.WebElement, error) {
+	return e.FindElement(loc.By, loc.Value)
+}
+
+func (e *Element) FindAll(loc selenium.Locator) ([]selenium.WebElement, error) {
+	return e.FindElements(loc.By, loc.Value)
+}
+
+func (e *Element) Describe() (*selenium.ElementInfo, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	info := &selenium.ElementInfo{TagName: e.node.Data}
+	info.ID, _ = attr(e.node, "id")
+	info.Name, _ = attr(e.node, "name")
+	info.Type, _ = attr(e.node, "type")
+	info.Class, _ = attr(e.node, "class")
+	return info, nil
+}
+
+func (e *Element) InvalidateCache() {}
+
+func (e *Element) Driver() selenium.WebDriver {
+	return e.d
+}
+
+// descendants returns the elements below n, in document order.
+func descendants(n *html.Node) []*html.Node {
+	var elems []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode {
+				elems = append(elems, c)
+			}
+			walk(c)
+		}
+	}
+	walk(n)
+	return elems
+}
+
+// textContent returns the text below n, excluding scripts and styles, with
+// runs of whitespace collapsed.
+func textContent(n *html.Node) string {
+	var buf bytes.Buffer
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			buf.WriteString(n.Data)
+			buf.WriteByte(' ')
+		case n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style"):
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// setText replaces the children of n with the text s.
+func setText(n *html.Node, s string) {
+	for c := n.FirstChild; c != nil; c = n.FirstChild {
+		n.RemoveChild(c)
+	}
+	n.AppendChild(&html.Node{Type: html.TextNode, Data: s})
+}
+
+func attr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func hasAttrValue(n *html.Node, name, value string) bool {
+	v, ok := attr(n, name)
+	return ok && strings.EqualFold(v, value)
+}
+
+func setAttr(n *html.Node, name, value string) {
+	for i, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: name, Val: value})
+}
+
+func removeAttr(n *html.Node, name string) {
+	for i, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			n.Attr = append(n.Attr[:i], n.Attr[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package fakedriver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+// The fake must be usable wherever the interfaces are.
+var (
+	_ selenium.WebDriver  = (*Driver)(nil)
+	_ selenium.WebElement = (*Element)(nil)
+)
+
+const loginPage = `<html><head><title>Login</title></head><body>
+<form>
+  <input name="user" type="text">
+  <textarea id="note">hi</textarea>
+  <input id="remember" type="checkbox">
+  <input type="radio" name="plan" value="free" checked>
+  <input type="radio" name="plan" value="pro">
+  <button id="submit" disabled>Log in</button>
+  <button id="go">Go</button>
+</form>
+<a href="/help">Help</a>
+<div id="banner" style="display: none">Welcome</div>
+</body></html>`
+
+func TestFormInteraction(t *testing.T) {
+	d, err := New(loginPage)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	if title, _ := d.Title(); title != "Login" {
+		t.Errorf("Title() = %q, want %q", title, "Login")
+	}
+
+	user, err := d.FindElement(selenium.ByName, "user")
+	if err != nil {
+		t.Fatalf("FindElement(ByName) returned error: %v", err)
+	}
+	if err := user.SendKeys("alice"); err != nil {
+		t.Fatalf("SendKeys() returned error: %v", err)
+	}
+	user.SendKeys("!")
+	if v, _ := user.GetAttribute("value"); v != "alice!" {
+		t.Errorf("value after SendKeys = %q, want %q", v, "alice!")
+	}
+	user.Clear()
+	if v, _ := user.GetAttribute("value"); v != "" {
+		t.Errorf("value after Clear = %q, want empty", v)
+	}
+	if attrs, err := user.Attributes(); err != nil || len(attrs) != 3 || attrs["name"] != "user" || attrs["type"] != "text" {
+		t.Errorf("Attributes() = %v, %v; want name, type and value", attrs, err)
+	}
+
+	note, _ := d.Find(selenium.ID("note"))
+	note.SendKeys(" there")
+	if text, _ := note.Text(); text != "hi there" {
+		t.Errorf("textarea text = %q, want %q", text, "hi there")
+	}
+
+	remember, _ := d.FindElement(selenium.ByCSSSelector, "#remember")
+	for i, want := range []bool{true, false} {
+		remember.Click()
+		if got, _ := remember.IsSelected(); got != want {
+			t.Errorf("IsSelected() after %d clicks = %t, want %t", i+1, got, want)
+		}
+	}
+	if n := d.Clicks(remember); n != 2 {
+		t.Errorf("Clicks() = %d, want 2", n)
+	}
+
+	pro, _ := d.FindElement(selenium.ByCSSSelector, "[value=pro]")
+	pro.Click()
+	radios, _ := d.FindElements(selenium.ByCSSSelector, "input[name=plan]")
+	for i, want := range []bool{false, true} {
+		if got, _ := radios[i].IsSelected(); got != want {
+			t.Errorf("radio %d IsSelected() = %t, want %t", i, got, want)
+		}
+	}
+
+	submit, _ := d.FindElement(selenium.ByID, "submit")
+	if enabled, _ := submit.IsEnabled(); enabled {
+		t.Errorf("IsEnabled() of a disabled button = true")
+	}
+	banner, _ := d.FindElement(selenium.ByID, "banner")
+	if displayed, _ := banner.IsDisplayed(); displayed {
+		t.Errorf("IsDisplayed() of a display: none element = true")
+	}
+	if active, _ := d.ActiveElement(); active.(*Element).node != pro.(*Element).node {
+		t.Errorf("ActiveElement() = %v, want the last clicked element", active)
+	}
+
+	if _, err := d.FindElement(selenium.ByID, "missing"); remoteError(err) != "no such element" {
+		t.Errorf("FindElement(missing) returned error %v, want no such element", err)
+	}
+	if _, err := d.FindElement(selenium.ByXPATH, "//a"); remoteError(err) != "invalid selector" {
+		t.Errorf("FindElement(ByXPATH) returned error %v, want invalid selector", err)
+	}
+	if _, err := d.FindElement(selenium.ByCSSSelector, "a:first-child"); remoteError(err) != "invalid selector" {
+		t.Errorf("FindElement(pseudo-class) returned error %v, want invalid selector", err)
+	}
+	if _, err := d.ExecuteScript("return 1", nil); err == nil {
+		t.Errorf("ExecuteScript() returned nil error")
+	}
+	if png, err := d.Screenshot(); err != nil || len(png) == 0 {
+		t.Errorf("Screenshot() = %d bytes, %v, want a placeholder image", len(png), err)
+	}
+}
+
+func TestPageTransitions(t *testing.T) {
+	d, err := New(loginPage)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	d.AddPage("http://example.com/login", loginPage)
+	d.AddPage("http://example.com/help", `<title>Help</title><p>Read the docs.</p>`)
+	if err := d.Get("http://example.com/login"); err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+
+	// Clicking "Go" shows a spinner, which is replaced by the result a second
+	// later.
+	if err := d.OnClick("#go", func(d *Driver) error {
+		d.After(time.Second, func(d *Driver) {
+			d.SetPage(`<title>Done</title><p id="result">OK</p>`)
+		})
+		return d.SetPage(`<title>Working</title><p id="spinner">...</p>`)
+	}); err != nil {
+		t.Fatalf("OnClick() returned error: %v", err)
+	}
+	gone, _ := d.FindElement(selenium.ByID, "go")
+	if stale, err := gone.IsStale(); err != nil || stale {
+		t.Errorf("IsStale() before clicking = %t, %v; want false, nil", stale, err)
+	}
+	if err := gone.Click(); err != nil {
+		t.Fatalf("Click() returned error: %v", err)
+	}
+	if _, err := gone.Text(); remoteError(err) != "stale element reference" {
+		t.Errorf("Text() of a replaced element returned error %v, want stale element reference", err)
+	}
+	if stale, err := gone.IsStale(); err != nil || !stale {
+		t.Errorf("IsStale() of a replaced element = %t, %v; want true, nil", stale, err)
+	}
+	if _, err := d.FindElement(selenium.ByID, "spinner"); err != nil {
+		t.Errorf("FindElement(spinner) returned error: %v", err)
+	}
+	d.Advance(999 * time.Millisecond)
+	if _, err := d.FindElement(selenium.ByID, "result"); err == nil {
+		t.Errorf("the result appeared before the virtual clock reached it")
+	}
+	d.Advance(time.Millisecond)
+	if _, err := d.FindElement(selenium.ByID, "result"); err != nil {
+		t.Errorf("FindElement(result) after advancing the clock returned error: %v", err)
+	}
+	if got, want := d.Now(), time.Unix(1, 0).UTC(); !got.Equal(want) {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+
+	// Links navigate to registered pages, relative to the current URL.
+	d.Refresh()
+	link, _ := d.FindElement(selenium.ByLinkText, "Help")
+	if err := link.Click(); err != nil {
+		t.Fatalf("Click() on a link returned error: %v", err)
+	}
+	if u, _ := d.CurrentURL(); u != "http://example.com/help" {
+		t.Errorf("CurrentURL() after clicking a link = %q, want the help page", u)
+	}
+	d.Back()
+	if title, _ := d.Title(); title != "Login" {
+		t.Errorf("Title() after Back() = %q, want %q", title, "Login")
+	}
+	d.Forward()
+	if title, _ := d.Title(); title != "Help" {
+		t.Errorf("Title() after Forward() = %q, want %q", title, "Help")
+	}
+	if err := d.Get("http://example.com/missing"); err == nil {
+		t.Errorf("Get() of an unregistered page returned nil error")
+	}
+}
+
+func TestBackToStartPage(t *testing.T) {
+	d, err := New(`<title>Start</title>`)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	d.AddPage("http://example.com/help", `<title>Help</title>`)
+	if err := d.Get("http://example.com/help"); err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	if err := d.Back(); err != nil {
+		t.Fatalf("Back() to the start page returned error: %v", err)
+	}
+	if u, _ := d.CurrentURL(); u != "about:blank" {
+		t.Errorf("CurrentURL() after Back() = %q, want about:blank", u)
+	}
+	if title, _ := d.Title(); title != "Start" {
+		t.Errorf("Title() after Back() = %q, want %q", title, "Start")
+	}
+}
+
+func TestElementScopedFind(t *testing.T) {
+	d, err := New(`<div id="a"><p>1</p></div><div id="b"><p>2</p><p>3</p></div>`)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	b, _ := d.FindElement(selenium.ByID, "b")
+	ps, err := b.FindAll(selenium.CSS("p"))
+	if err != nil {
+		t.Fatalf("FindAll() returned error: %v", err)
+	}
+	var texts []string
+	for _, p := range ps {
+		text, _ := p.Text()
+		texts = append(texts, text)
+	}
+	if len(texts) != 2 || texts[0] != "2" || texts[1] != "3" {
+		t.Errorf("FindAll(p) within #b found %q, want [2 3]", texts)
+	}
+	if _, err := b.FindElement(selenium.ByCSSSelector, "#b"); err == nil {
+		t.Errorf("FindElement() within an element matched the element itself")
+	}
+}
+
+func TestWaits(t *testing.T) {
+	d, err := New(`<p id="spinner">...</p><button id="save" disabled>Save</button><ul></ul>`)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	d.After(2*time.Second, func(d *Driver) {
+		d.SetPage(`<p id="result">OK</p><ul><li>1</li><li>2</li></ul>`)
+	})
+	start := d.Now()
+	if err := d.WaitUntilGone(selenium.ByID, "spinner", 5*time.Second, selenium.WaitInterval(500*time.Millisecond)); err != nil {
+		t.Fatalf("WaitUntilGone() returned error: %v", err)
+	}
+	if got := d.Now().Sub(start); got != 2*time.Second {
+		t.Errorf("WaitUntilGone() advanced the clock by %v, want 2s", got)
+	}
+	if _, err := d.FindElementWithTimeout(selenium.ByID, "result", time.Second); err != nil {
+		t.Errorf("FindElementWithTimeout() returned error: %v", err)
+	}
+	if elems, err := d.WaitForElementCount(selenium.ByTagName, "li", selenium.Exactly(2), time.Second); err != nil || len(elems) != 2 {
+		t.Errorf("WaitForElementCount() = %d elements, %v; want 2, nil", len(elems), err)
+	}
+
+	// Timeouts elapse on the virtual clock.
+	start = d.Now()
+	err = d.WaitWithTimeout(func(selenium.WebDriver) (bool, error) { return false, nil }, 3*time.Second)
+	if err == nil {
+		t.Errorf("WaitWithTimeout() of a condition that never holds returned nil error")
+	}
+	if got := d.Now().Sub(start); got < 3*time.Second || got > 3*time.Second+selenium.DefaultWaitInterval {
+		t.Errorf("WaitWithTimeout() advanced the clock by %v, want about 3s", got)
+	}
+	var countErr *selenium.ElementCountError
+	if _, err := d.WaitForElementCount(selenium.ByTagName, "li", selenium.AtLeast(3), time.Second); !errors.As(err, &countErr) || countErr.Count != 2 {
+		t.Errorf("WaitForElementCount(AtLeast(3)) returned error %v, want an *ElementCountError with 2 elements", err)
+	}
+
+	// The element waits report the last state of the element.
+	d.SetPage(`<button id="save" disabled>Save</button>`)
+	save, _ := d.FindElement(selenium.ByID, "save")
+	d.After(time.Second, func(d *Driver) {
+		removeAttr(save.(*Element).node, "disabled")
+	})
+	if err := save.WaitUntilVisible(time.Second); err != nil {
+		t.Errorf("WaitUntilVisible() returned error: %v", err)
+	}
+	if err := save.WaitUntilClickable(2 * time.Second); err != nil {
+		t.Errorf("WaitUntilClickable() returned error: %v", err)
+	}
+	d.SetPage(`<p>Saved</p>`)
+	var waitErr *selenium.ElementWaitError
+	if err := save.WaitUntilVisible(time.Second); !errors.As(err, &waitErr) || waitErr.State != "stale" {
+		t.Errorf("WaitUntilVisible() of a removed element returned error %v, want an *ElementWaitError in the stale state", err)
+	}
+}
+
+func TestNotImplemented(t *testing.T) {
+	d, err := New(`<form><input id="q"></form>`)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	q, _ := d.FindElement(selenium.ByID, "q")
+	for name, err := range map[string]error{
+		"Driver.PrintPage":     func() error { _, err := d.PrintPage(nil); return err }(),
+		"Element.Submit":       q.Submit(),
+		"Driver.LocalStorage":  d.LocalStorage().SetItem("k", "v"),
+		"Driver.Actions":       d.Actions().Pause(time.Second).Perform(),
+		"Driver.SwitchFrame":   d.SwitchFrame(nil),
+		"Element.CSSProperty":  func() error { _, err := q.CSSProperty("color"); return err }(),
+		"Driver.DeleteCookie":  d.DeleteCookie("session"),
+		"Element.Screenshot":   func() error { _, err := q.Screenshot(false); return err }(),
+		"Driver.SetWindowRect": d.SetWindowRect("", selenium.Rect{}),
+	} {
+		if err == nil {
+			t.Errorf("%s returned nil error", name)
+		}
+	}
+	if remove := d.OnNavigation(func(string) {}); remove == nil {
+		t.Errorf("OnNavigation() returned a nil remove function")
+	}
+}
@@ -0,0 +1,556 @@
+package fakedriver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+// This file holds the methods of the interfaces that the fake does not
+// implement. Those that can fail return an "unsupported operation" error
+// naming the method; the others do nothing and return zero values.
+
+// notImplemented returns the error of the methods that the fake does not
+// implement.
+func notImplemented(method string) error {
+	return &selenium.Error{Err: "unsupported operation", Message: fmt.Sprintf("fakedriver: %s is not implemented by fakedriver", method)}
+}
+
+// unimplementedStorage is the selenium.WebStorage returned by
+// Driver.LocalStorage and Driver.SessionStorage.
+type unimplementedStorage struct{}
+
+func (unimplementedStorage) Keys() ([]string, error) {
+	return nil, notImplemented("WebStorage.Keys")
+}
+
+func (unimplementedStorage) Item(key string) (string, bool, error) {
+	return "", false, notImplemented("WebStorage.Item")
+}
+
+func (unimplementedStorage) SetItem(key, value string) error {
+	return notImplemented("WebStorage.SetItem")
+}
+
+func (unimplementedStorage) RemoveItem(key string) error {
+	return notImplemented("WebStorage.RemoveItem")
+}
+
+func (unimplementedStorage) Clear() error {
+	return notImplemented("WebStorage.Clear")
+}
+
+func (unimplementedStorage) Usage() (int64, error) {
+	return 0, notImplemented("WebStorage.Usage")
+}
+
+func (d *Driver) Status() (*selenium.Status, error) {
+	return nil, notImplemented("WebDriver.Status")
+}
+
+func (d *Driver) NewSession() (string, error) {
+	return "", notImplemented("WebDriver.NewSession")
+}
+
+func (d *Driver) OnSessionEvent(f func(selenium.SessionEvent)) (remove func()) {
+	return func() {}
+}
+
+func (d *Driver) OnNavigation(f func(newURL string)) (remove func()) {
+	return func() {}
+}
+
+func (d *Driver) SessionStats() selenium.SessionStats {
+	return selenium.SessionStats{}
+}
+
+func (d *Driver) SwitchSession(sessionID string) error {
+	return notImplemented("WebDriver.SwitchSession")
+}
+
+func (d *Driver) Capabilities() (selenium.Capabilities, error) {
+	return nil, notImplemented("WebDriver.Capabilities")
+}
+
+func (d *Driver) ChromeInfo() (*selenium.ChromeSessionInfo, bool) {
+	return nil, false
+}
+
+func (d *Driver) FirefoxInfo() (*selenium.FirefoxSessionInfo, bool) {
+	return nil, false
+}
+
+func (d *Driver) SessionRequestPayload() (attempt int, payload []byte) {
+	return 0, nil
+}
+
+func (d *Driver) SetTimeouts(timeouts selenium.Timeouts) error {
+	return notImplemented("WebDriver.SetTimeouts")
+}
+
+func (d *Driver) GetTimeouts() (selenium.Timeouts, error) {
+	return selenium.Timeouts{}, notImplemented("WebDriver.GetTimeouts")
+}
+
+func (d *Driver) StrictTimeouts(enabled bool) {
+}
+
+func (d *Driver) SetFileDialogGuard(enabled bool) {
+}
+
+func (d *Driver) SetFileDetector(detect selenium.FileDetector) {
+}
+
+func (d *Driver) UploadFile(localPath string) (remotePath string, err error) {
+	return "", notImplemented("WebDriver.UploadFile")
+}
+
+func (d *Driver) SetPointerPrecision(p selenium.PointerPrecision) {
+}
+
+func (d *Driver) SetDialectWarnings(enabled bool) {
+}
+
+func (d *Driver) SetElementDecoder(decode func(raw json.RawMessage) (id string, ok bool)) {
+}
+
+func (d *Driver) SetElementEncoder(encode func(id string) interface{}) {
+}
+
+func (d *Driver) SetStrictDecoding(strict bool) {
+}
+
+func (d *Driver) StrictDecoding() bool {
+	return false
+}
+
+func (d *Driver) SetStaleRetry(attempts int, commands ...string) {
+}
+
+func (d *Driver) SetClickRetry(attempts int, interval time.Duration) {
+}
+
+func (d *Driver) SetRelativeXPathCheck(enabled bool) {
+}
+
+func (d *Driver) EnableCommandHistory(n int) {
+}
+
+func (d *Driver) CommandHistory() []selenium.CommandRecord {
+	return nil
+}
+
+func (d *Driver) DebugDump(w io.Writer, opts selenium.DumpOptions) error {
+	return notImplemented("WebDriver.DebugDump")
+}
+
+func (d *Driver) SetArtifactSink(s selenium.ArtifactSink) {
+}
+
+func (d *Driver) SetArtifactTestName(name string) {
+}
+
+func (d *Driver) SaveDebugDump(reason string, opts selenium.DumpOptions) (string, error) {
+	return "", notImplemented("WebDriver.SaveDebugDump")
+}
+
+func (d *Driver) EnableEvidence(sink selenium.ArtifactSink) {
+}
+
+func (d *Driver) Step(name string, fn func() error) error {
+	return notImplemented("WebDriver.Step")
+}
+
+func (d *Driver) EvidenceSteps() []*selenium.EvidenceStep {
+	return nil
+}
+
+func (d *Driver) AvailableEngines() ([]string, error) {
+	return nil, notImplemented("WebDriver.AvailableEngines")
+}
+
+func (d *Driver) ActiveEngine() (string, error) {
+	return "", notImplemented("WebDriver.ActiveEngine")
+}
+
+func (d *Driver) IsEngineActivated() (bool, error) {
+	return false, notImplemented("WebDriver.IsEngineActivated")
+}
+
+func (d *Driver) DeactivateEngine() error {
+	return notImplemented("WebDriver.DeactivateEngine")
+}
+
+func (d *Driver) ActivateEngine(engine string) error {
+	return notImplemented("WebDriver.ActivateEngine")
+}
+
+func (d *Driver) CurrentWindowHandle() (string, error) {
+	return "", notImplemented("WebDriver.CurrentWindowHandle")
+}
+
+func (d *Driver) WindowHandles() ([]string, error) {
+	return nil, notImplemented("WebDriver.WindowHandles")
+}
+
+func (d *Driver) SetAuthGuard(detect func(url string) bool, reauth func(wd selenium.WebDriver) error) {
+}
+
+func (d *Driver) SetAuthGuardLimits(checkEvery, maxPerHour int) {
+}
+
+func (d *Driver) Close() error {
+	return notImplemented("WebDriver.Close")
+}
+
+func (d *Driver) CloseAndSwitch() (remaining []string, err error) {
+	return nil, notImplemented("WebDriver.CloseAndSwitch")
+}
+
+func (d *Driver) SetSwitchOnClose(enabled bool) {
+}
+
+func (d *Driver) SwitchFrame(frame interface{}) error {
+	return notImplemented("WebDriver.SwitchFrame")
+}
+
+func (d *Driver) SwitchToDefaultContent() error {
+	return notImplemented("WebDriver.SwitchToDefaultContent")
+}
+
+func (d *Driver) SwitchFrameParent() error {
+	return notImplemented("WebDriver.SwitchFrameParent")
+}
+
+func (d *Driver) FramePath() []interface{} {
+	return nil
+}
+
+func (d *Driver) NewWindow(typ string) (handle, windowType string, err error) {
+	return "", "", notImplemented("WebDriver.NewWindow")
+}
+
+func (d *Driver) Windows() ([]selenium.WindowInfo, error) {
+	return nil, notImplemented("WebDriver.Windows")
+}
+
+func (d *Driver) WindowsSorted(by selenium.WindowSortKey) ([]selenium.WindowInfo, error) {
+	return nil, notImplemented("WebDriver.WindowsSorted")
+}
+
+func (d *Driver) SwitchWindow(name string) error {
+	return notImplemented("WebDriver.SwitchWindow")
+}
+
+func (d *Driver) CloseWindow(name string) (remaining []string, err error) {
+	return nil, notImplemented("WebDriver.CloseWindow")
+}
+
+func (d *Driver) MaximizeWindow(name string) error {
+	return notImplemented("WebDriver.MaximizeWindow")
+}
+
+func (d *Driver) MinimizeWindow(name string) error {
+	return notImplemented("WebDriver.MinimizeWindow")
+}
+
+func (d *Driver) FullscreenWindow(name string) (*selenium.Rect, error) {
+	return nil, notImplemented("WebDriver.FullscreenWindow")
+}
+
+func (d *Driver) ResizeWindow(name string, width, height int) error {
+	return notImplemented("WebDriver.ResizeWindow")
+}
+
+func (d *Driver) WindowRect(handle string) (*selenium.Rect, error) {
+	return nil, notImplemented("WebDriver.WindowRect")
+}
+
+func (d *Driver) SetWindowRect(handle string, r selenium.Rect) error {
+	return notImplemented("WebDriver.SetWindowRect")
+}
+
+func (d *Driver) GetWindowPosition(name string) (*selenium.Point, error) {
+	return nil, notImplemented("WebDriver.GetWindowPosition")
+}
+
+func (d *Driver) SetWindowPosition(name string, x, y int) error {
+	return notImplemented("WebDriver.SetWindowPosition")
+}
+
+func (d *Driver) RenderHTML(html string) error {
+	return notImplemented("WebDriver.RenderHTML")
+}
+
+func (d *Driver) ServeDir(dir string) (baseURL string, stop func(), err error) {
+	return "", nil, notImplemented("WebDriver.ServeDir")
+}
+
+func (d *Driver) WaitForNetworkIdle(idleFor, timeout time.Duration, ignore []string) error {
+	return notImplemented("WebDriver.WaitForNetworkIdle")
+}
+
+func (d *Driver) SubresourceResponses() ([]selenium.ResourceResponse, error) {
+	return nil, notImplemented("WebDriver.SubresourceResponses")
+}
+
+func (d *Driver) FindElementRelative(rb *selenium.RelativeBy) (selenium.WebElement, error) {
+	return nil, notImplemented("WebDriver.FindElementRelative")
+}
+
+func (d *Driver) FindElementsRelative(rb *selenium.RelativeBy) ([]selenium.WebElement, error) {
+	return nil, notImplemented("WebDriver.FindElementsRelative")
+}
+
+func (d *Driver) DecodeElement([]byte) (selenium.WebElement, error) {
+	return nil, notImplemented("WebDriver.DecodeElement")
+}
+
+func (d *Driver) DecodeElements([]byte) ([]selenium.WebElement, error) {
+	return nil, notImplemented("WebDriver.DecodeElements")
+}
+
+func (d *Driver) GetCookies() ([]selenium.Cookie, error) {
+	return nil, notImplemented("WebDriver.GetCookies")
+}
+
+func (d *Driver) GetCookie(name string) (selenium.Cookie, error) {
+	return selenium.Cookie{}, notImplemented("WebDriver.GetCookie")
+}
+
+func (d *Driver) AddCookie(cookie *selenium.Cookie) error {
+	return notImplemented("WebDriver.AddCookie")
+}
+
+func (d *Driver) DeleteAllCookies() error {
+	return notImplemented("WebDriver.DeleteAllCookies")
+}
+
+func (d *Driver) DeleteCookie(name string) error {
+	return notImplemented("WebDriver.DeleteCookie")
+}
+
+func (d *Driver) DeleteCookiesMatching(pred func(selenium.Cookie) bool) (int, error) {
+	return 0, notImplemented("WebDriver.DeleteCookiesMatching")
+}
+
+func (d *Driver) Click(button selenium.MouseButton) error {
+	return notImplemented("WebDriver.Click")
+}
+
+func (d *Driver) DoubleClick() error {
+	return notImplemented("WebDriver.DoubleClick")
+}
+
+func (d *Driver) ButtonDown() error {
+	return notImplemented("WebDriver.ButtonDown")
+}
+
+func (d *Driver) ButtonUp() error {
+	return notImplemented("WebDriver.ButtonUp")
+}
+
+func (d *Driver) SendModifier(modifier string, isDown bool) error {
+	return notImplemented("WebDriver.SendModifier")
+}
+
+func (d *Driver) ScrollContainer(container selenium.WebElement, dx, dy int) error {
+	return notImplemented("WebDriver.ScrollContainer")
+}
+
+func (d *Driver) ScrollToElement(elem selenium.WebElement) error {
+	return notImplemented("WebDriver.ScrollToElement")
+}
+
+func (d *Driver) ScrollByAmount(x, y, deltaX, deltaY int) error {
+	return notImplemented("WebDriver.ScrollByAmount")
+}
+
+func (d *Driver) KeyDown(keys string) error {
+	return notImplemented("WebDriver.KeyDown")
+}
+
+func (d *Driver) KeyUp(keys string) error {
+	return notImplemented("WebDriver.KeyUp")
+}
+
+func (d *Driver) PerformActions(sequences []selenium.ActionSequence) error {
+	return notImplemented("WebDriver.PerformActions")
+}
+
+func (d *Driver) ReleaseActions() error {
+	return notImplemented("WebDriver.ReleaseActions")
+}
+
+func (d *Driver) Actions() *selenium.Actions {
+	// The chain can be built, but performing it fails.
+	return new(selenium.Actions)
+}
+
+func (d *Driver) Tap(elem selenium.WebElement) error {
+	return notImplemented("WebDriver.Tap")
+}
+
+func (d *Driver) DragAndDrop(source, target selenium.WebElement) error {
+	return notImplemented("WebDriver.DragAndDrop")
+}
+
+func (d *Driver) DragAndDropByOffset(source selenium.WebElement, x, y int) error {
+	return notImplemented("WebDriver.DragAndDropByOffset")
+}
+
+func (d *Driver) DragAndDropViaScript(source, target selenium.WebElement) error {
+	return notImplemented("WebDriver.DragAndDropViaScript")
+}
+
+func (d *Driver) ScreenshotTo(w io.Writer) error {
+	return notImplemented("WebDriver.ScreenshotTo")
+}
+
+func (d *Driver) SaveScreenshot(path string) error {
+	return notImplemented("WebDriver.SaveScreenshot")
+}
+
+func (d *Driver) PrintPage(opts *selenium.PrintOptions) ([]byte, error) {
+	return nil, notImplemented("WebDriver.PrintPage")
+}
+
+func (d *Driver) FullPageScreenshot() ([]byte, error) {
+	return nil, notImplemented("WebDriver.FullPageScreenshot")
+}
+
+func (d *Driver) SetPageZoom(factor float64) error {
+	return notImplemented("WebDriver.SetPageZoom")
+}
+
+func (d *Driver) PageZoom() (float64, selenium.ZoomMethod, error) {
+	return 0, 0, notImplemented("WebDriver.PageZoom")
+}
+
+func (d *Driver) Log(typ selenium.LogType) ([]selenium.LogMessage, error) {
+	return nil, notImplemented("WebDriver.Log")
+}
+
+func (d *Driver) AttachConsole(ctx context.Context) (<-chan selenium.ConsoleEntry, error) {
+	return nil, notImplemented("WebDriver.AttachConsole")
+}
+
+func (d *Driver) LocalStorage() selenium.WebStorage {
+	return unimplementedStorage{}
+}
+
+func (d *Driver) SessionStorage() selenium.WebStorage {
+	return unimplementedStorage{}
+}
+
+func (d *Driver) StorageEstimate() (*selenium.StorageEstimate, error) {
+	return nil, notImplemented("WebDriver.StorageEstimate")
+}
+
+func (d *Driver) IndexedDB(dbName string) (*selenium.IDBHandle, error) {
+	return nil, notImplemented("WebDriver.IndexedDB")
+}
+
+func (d *Driver) DismissAlert() error {
+	return notImplemented("WebDriver.DismissAlert")
+}
+
+func (d *Driver) AcceptAlert() error {
+	return notImplemented("WebDriver.AcceptAlert")
+}
+
+func (d *Driver) AlertText() (string, error) {
+	return "", notImplemented("WebDriver.AlertText")
+}
+
+func (d *Driver) SetAlertText(text string) error {
+	return notImplemented("WebDriver.SetAlertText")
+}
+
+func (d *Driver) ExecuteCommand(method, path string, params interface{}) (json.RawMessage, error) {
+	return nil, notImplemented("WebDriver.ExecuteCommand")
+}
+
+func (d *Driver) AddInitScript(script string) error {
+	return notImplemented("WebDriver.AddInitScript")
+}
+
+func (d *Driver) ApplyStealth(opts *selenium.StealthOptions) error {
+	return notImplemented("WebDriver.ApplyStealth")
+}
+
+func (e *Element) ClickAt(xOffset, yOffset int) error {
+	return notImplemented("WebElement.ClickAt")
+}
+
+func (e *Element) Submit() error {
+	return notImplemented("WebElement.Submit")
+}
+
+func (e *Element) RightClick() error {
+	return notImplemented("WebElement.RightClick")
+}
+
+func (e *Element) DoubleClick() error {
+	return notImplemented("WebElement.DoubleClick")
+}
+
+func (e *Element) MiddleClick() error {
+	return notImplemented("WebElement.MiddleClick")
+}
+
+func (e *Element) DropFiles(paths ...string) error {
+	return notImplemented("WebElement.DropFiles")
+}
+
+func (e *Element) Hover() error {
+	return notImplemented("WebElement.Hover")
+}
+
+func (e *Element) HoverAt(xOffset, yOffset int) error {
+	return notImplemented("WebElement.HoverAt")
+}
+
+func (e *Element) ScrollableAncestor() (selenium.WebElement, error) {
+	return nil, notImplemented("WebElement.ScrollableAncestor")
+}
+
+func (e *Element) ScrollIntoViewWithin(container selenium.WebElement) error {
+	return notImplemented("WebElement.ScrollIntoViewWithin")
+}
+
+func (e *Element) FindElementRaw(by, value string) (json.RawMessage, error) {
+	return nil, notImplemented("WebElement.FindElementRaw")
+}
+
+func (e *Element) GetProperty(name string) (string, error) {
+	return "", notImplemented("WebElement.GetProperty")
+}
+
+func (e *Element) GetPropertyRaw(name string) (json.RawMessage, error) {
+	return nil, notImplemented("WebElement.GetPropertyRaw")
+}
+
+func (e *Element) GetAttributeOrProperty(name string) (string, error) {
+	return "", notImplemented("WebElement.GetAttributeOrProperty")
+}
+
+func (e *Element) CSSProperty(name string) (string, error) {
+	return "", notImplemented("WebElement.CSSProperty")
+}
+
+func (e *Element) ComputedRole() (string, error) {
+	return "", notImplemented("WebElement.ComputedRole")
+}
+
+func (e *Element) ComputedLabel() (string, error) {
+	return "", notImplemented("WebElement.ComputedLabel")
+}
+
+func (e *Element) Screenshot(scroll bool) ([]byte, error) {
+	return nil, notImplemented("WebElement.Screenshot")
+}
@@ -0,0 +1,198 @@
+package fakedriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tebeka/selenium"
+)
+
+// errWaitTimeout is returned by poll if the timeout elapses.
+var errWaitTimeout = errors.New("timeout")
+
+// poll calls check until it returns true or an error, advancing the virtual
+// clock by interval between calls, until the timeout elapses on the virtual
+// clock or ctx is done.
+func (d *Driver) poll(timeout, interval time.Duration, ctx context.Context, check func() (bool, error)) error {
+	deadline := d.now.Add(timeout)
+	for {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+		if !d.now.Before(deadline) {
+			return errWaitTimeout
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		d.Advance(interval)
+	}
+}
+
+// WaitWithTimeoutAndInterval polls the condition, advancing the virtual clock
+// by interval between polls, until it holds or the timeout elapses on the
+// virtual clock.
+func (d *Driver) WaitWithTimeoutAndInterval(condition selenium.Condition, timeout, interval time.Duration) error {
+	start := d.now
+	err := d.poll(timeout, interval, context.Background(), func() (bool, error) {
+		return condition(d)
+	})
+	if err == errWaitTimeout {
+		return fmt.Errorf("timeout after %v", d.now.Sub(start))
+	}
+	return err
+}
+
+func (d *Driver) WaitWithTimeout(condition selenium.Condition, timeout time.Duration) error {
+	return d.WaitWithTimeoutAndInterval(condition, timeout, selenium.DefaultWaitInterval)
+}
+
+func (d *Driver) Wait(condition selenium.Condition) error {
+	return d.WaitWithTimeoutAndInterval(condition, selenium.DefaultWaitTimeout, selenium.DefaultWaitInterval)
+}
+
+func (d *Driver) FindElementWithTimeout(by, value string, timeout time.Duration) (selenium.WebElement, error) {
+	var elem selenium.WebElement
+	err := d.WaitWithTimeout(func(selenium.WebDriver) (bool, error) {
+		var err error
+		elem, err = d.FindElement(by, value)
+		if remoteError(err) == "no such element" {
+			return false, nil
+		}
+		return err == nil, err
+	}, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for element %s: %v", selenium.Locator{By: by, Value: value}, err)
+	}
+	return elem, nil
+}
+
+// waitForElementCount polls find until pred holds for the number of elements
+// it returns, and returns them.
+func (d *Driver) waitForElementCount(loc selenium.Locator, find func() ([]selenium.WebElement, error), pred func(int) bool, timeout time.Duration) ([]selenium.WebElement, error) {
+	var elems []selenium.WebElement
+	err := d.poll(timeout, selenium.DefaultWaitInterval, context.Background(), func() (bool, error) {
+		var err error
+		elems, err = find()
+		return err == nil && pred(len(elems)), err
+	})
+	if err == errWaitTimeout {
+		return nil, &selenium.ElementCountError{Locator: loc, Count: len(elems), Timeout: timeout}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return elems, nil
+}
+
+func (d *Driver) WaitForElementCount(by, value string, pred func(int) bool, timeout time.Duration) ([]selenium.WebElement, error) {
+	return d.waitForElementCount(selenium.Locator{By: by, Value: value}, func() ([]selenium.WebElement, error) {
+		return d.FindElements(by, value)
+	}, pred, timeout)
+}
+
+func (e *Element) WaitForElementCount(by, value string, pred func(int) bool, timeout time.Duration) ([]selenium.WebElement, error) {
+	loc := selenium.Locator{By: by, Value: value}
+	return e.d.waitForElementCount(loc, func() ([]selenium.WebElement, error) {
+		elems, err := e.FindElements(by, value)
+		if remoteError(err) == "stale element reference" {
+			return nil, &selenium.ElementError{Locator: loc, Element: e.String(), Err: selenium.ErrStaleParent}
+		}
+		return elems, err
+	}, pred, timeout)
+}
+
+// waitFor polls check, which returns whether the condition holds and the
+// state of the element, as WebElement.WaitUntilVisible does, on the virtual
+// clock.
+func (d *Driver) waitFor(desc, condition string, timeout time.Duration, opts []selenium.WaitOption, check func() (bool, string, error)) error {
+	interval, ctx, err := selenium.ApplyWaitOptions(opts...)
+	if err != nil {
+		return err
+	}
+	var state string
+	err = d.poll(timeout, interval, ctx, func() (bool, error) {
+		var done bool
+		var err error
+		done, state, err = check()
+		return done, err
+	})
+	switch {
+	case err == errWaitTimeout:
+		return &selenium.ElementWaitError{Element: desc, Condition: condition, State: state, Timeout: timeout}
+	case err == context.Canceled || err == context.DeadlineExceeded:
+		return &selenium.ElementWaitError{Element: desc, Condition: condition, State: state, Timeout: timeout, Err: err}
+	}
+	return err
+}
+
+// WaitUntilVisible polls IsDisplayed on the virtual clock. Unlike with a
+// remote session, an element that is stale remains so.
+func (e *Element) WaitUntilVisible(timeout time.Duration, opts ...selenium.WaitOption) error {
+	return e.d.waitFor(e.String(), "visible", timeout, opts, func() (bool, string, error) {
+		displayed, err := e.IsDisplayed()
+		switch {
+		case remoteError(err) == "stale element reference":
+			return false, "stale", nil
+		case err != nil:
+			return false, "", err
+		case !displayed:
+			return false, "not displayed", nil
+		}
+		return true, "displayed", nil
+	})
+}
+
+func (e *Element) WaitUntilClickable(timeout time.Duration, opts ...selenium.WaitOption) error {
+	return e.d.waitFor(e.String(), "clickable", timeout, opts, func() (bool, string, error) {
+		displayed, err := e.IsDisplayed()
+		if err == nil && !displayed {
+			return false, "not displayed", nil
+		}
+		var enabled bool
+		if err == nil {
+			enabled, err = e.IsEnabled()
+		}
+		switch {
+		case remoteError(err) == "stale element reference":
+			return false, "stale", nil
+		case err != nil:
+			return false, "", err
+		case !enabled:
+			return false, "displayed but disabled", nil
+		}
+		return true, "displayed and enabled", nil
+	})
+}
+
+func (d *Driver) WaitUntilGone(by, value string, timeout time.Duration, opts ...selenium.WaitOption) error {
+	return d.waitFor(selenium.Locator{By: by, Value: value}.String(), "gone", timeout, opts, func() (bool, string, error) {
+		elems, err := d.FindElements(by, value)
+		if err != nil {
+			return false, "", err
+		}
+		shown := 0
+		for _, e := range elems {
+			displayed, err := e.IsDisplayed()
+			if err != nil {
+				return false, "", err
+			}
+			if displayed {
+				shown++
+			}
+		}
+		return shown == 0, fmt.Sprintf("%d displayed element(s)", shown), nil
+	})
+}
+
+// remoteError returns the error code of err, if it is a *selenium.Error.
+func remoteError(err error) string {
+	var e *selenium.Error
+	if errors.As(err, &e) {
+		return e.Err
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package selenium
+
+import (
+	"errors"
+	"log"
+	"strings"
+)
+
+// ErrWouldOpenFileDialog is returned by WebElement.Click when the file dialog
+// guard is enabled and the element is an <input type="file">. Clicking such an
+// element opens a native file dialog that WebDriver cannot dismiss. To select
+// files, call SendKeys on the element with the path of the file instead.
+var ErrWouldOpenFileDialog = errors.New("clicking an <input type=file> element would open a native file dialog; use SendKeys with the file path instead")
+
+// fileDialogGuardScript installs, once per document, a capturing click
+// listener that prevents the default action (opening the native file dialog)
+// of trusted clicks on file inputs. Programmatic uses of the files API, such as
+// building DataTransfer objects or assigning input.files, are unaffected. The
+// listener is kept in window.__seleniumFileDialogGuard, so that it can be
+// removed. The script returns whether the element passed as its argument is a
+// file input.
+const fileDialogGuardScript = `
+var elem = arguments[0];
+if (!window.__seleniumFileDialogGuard) {
+	window.__seleniumFileDialogGuard = function(e) {
+		var t = e.target;
+		if (e.isTrusted && t && t.tagName && t.tagName.toLowerCase() === 'input' &&
+				(t.type || '').toLowerCase() === 'file') {
+			e.preventDefault();
+		}
+	};
+	document.addEventListener('click', window.__seleniumFileDialogGuard, true);
+}
+return !!elem && elem.tagName.toLowerCase() === 'input' &&
+	(elem.type || '').toLowerCase() === 'file';
+`
+
+// removeFileDialogGuardScript removes the listener installed by
+// fileDialogGuardScript from the current document.
+const removeFileDialogGuardScript = `
+if (window.__seleniumFileDialogGuard) {
+	document.removeEventListener('click', window.__seleniumFileDialogGuard, true);
+	delete window.__seleniumFileDialogGuard;
+}
+`
+
+func (wd *remoteWD) SetFileDialogGuard(enabled bool) {
+	if wd.fileDialogGuard && !enabled && wd.id != "" {
+		// Best effort: the guard is disabled on the client side either way.
+		if _, err := wd.ExecuteScript(removeFileDialogGuardScript, nil); err != nil {
+			log.Printf("selenium: removing the file dialog guard from the page: %v", err)
+		}
+	}
+	wd.fileDialogGuard = enabled
+}
+
+// checkFileDialogGuard returns ErrWouldOpenFileDialog if the guard is enabled
+// and elem is a file input. Installing the guard listener is piggybacked on
+// the same script, since there is no way to run a script on every new
+// document.
+func (elem *remoteWE) checkFileDialogGuard() error {
+	wd := elem.parent
+	if !wd.fileDialogGuard {
+		return nil
+	}
+	// Only inputs can be file inputs, and the tag name of an element cannot
+	// change, unlike its type.
+	if elem.tagName != "" && !strings.EqualFold(elem.tagName, "input") {
+		return nil
+	}
+	isFileInput, err := wd.ExecuteScript(fileDialogGuardScript, []interface{}{elem})
+	if err != nil {
+		return elem.noteError(err)
+	}
+	if b, ok := isFileInput.(bool); ok && b {
+		return ErrWouldOpenFileDialog
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetFileDialogGuardRemovesListener(t *testing.T) {
+	var scripts []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		var params struct{ Script string }
+		json.Unmarshal(body, &params)
+		scripts = append(scripts, params.Script)
+		fmt.Fprint(w, `{"value":true}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	wd.SetFileDialogGuard(false)
+	if len(scripts) != 0 {
+		t.Fatalf("disabling the guard while disabled sent %d scripts, want none", len(scripts))
+	}
+	wd.SetFileDialogGuard(true)
+	if err := (&remoteWE{parent: wd, id: "e1"}).checkFileDialogGuard(); err != ErrWouldOpenFileDialog {
+		t.Fatalf("checkFileDialogGuard() returned error %v, want %v", err, ErrWouldOpenFileDialog)
+	}
+	wd.SetFileDialogGuard(false)
+	if len(scripts) != 2 || scripts[1] != removeFileDialogGuardScript {
+		t.Errorf("disabling the guard sent %q, want the script that removes the listener", scripts)
+	}
+}
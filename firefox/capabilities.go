@@ -59,6 +59,37 @@ func (c *Capabilities) SetProfile(basePath string) error {
 	return nil
 }
 
+// noisyPrefs are the preferences set by DisableNoisyFeatures.
+var noisyPrefs = map[string]interface{}{
+	// Do not offer to save the passwords typed in forms.
+	"signon.rememberSignons": false,
+	// Do not offer to translate pages in other languages.
+	"browser.translations.enable": false,
+	// Do not prompt to make Firefox the default browser.
+	"browser.shell.checkDefaultBrowser": false,
+	// Skip the welcome page shown on the first run.
+	"browser.aboutwelcome.enabled": false,
+	// Skip the page about the new version shown after an update.
+	"browser.startup.homepage_override.mstone": "ignore",
+	// Do not show the notification bar about data collection.
+	"datareporting.policy.dataSubmissionPolicyBypassNotification": true,
+}
+
+// DisableNoisyFeatures sets the preferences that keep Firefox from showing
+// native prompts and panels, such as the offer to save a password or to
+// translate the page, as chrome.Capabilities.DisableNoisyFeatures does for
+// Chrome. Preferences that are already set are kept.
+func (c *Capabilities) DisableNoisyFeatures() {
+	if c.Prefs == nil {
+		c.Prefs = make(map[string]interface{})
+	}
+	for k, v := range noisyPrefs {
+		if _, ok := c.Prefs[k]; !ok {
+			c.Prefs[k] = v
+		}
+	}
+}
+
 // LogLevel is an enum that defines logging levels for Firefox.
 type LogLevel string
 
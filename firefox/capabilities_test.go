@@ -0,0 +1,25 @@
+package firefox
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDisableNoisyFeatures(t *testing.T) {
+	c := Capabilities{Prefs: map[string]interface{}{"signon.rememberSignons": true}}
+	c.DisableNoisyFeatures()
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	// Existing preferences are kept.
+	want := `{"prefs":{"browser.aboutwelcome.enabled":false,` +
+		`"browser.shell.checkDefaultBrowser":false,` +
+		`"browser.startup.homepage_override.mstone":"ignore",` +
+		`"browser.translations.enable":false,` +
+		`"datareporting.policy.dataSubmissionPolicyBypassNotification":true,` +
+		`"signon.rememberSignons":true}}`
+	if got := string(data); got != want {
+		t.Errorf("DisableNoisyFeatures() set the capabilities\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,147 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSwitchFrame(t *testing.T) {
+	var switched []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		var params struct{ Using, Value string }
+		json.Unmarshal(body, &params)
+		switch r.URL.Path {
+		case "/session/123/frame":
+			switched = append(switched, string(body))
+			fmt.Fprint(w, `{"value":null}`)
+		case "/session/123/elements":
+			if params.Using != ByCSSSelector || params.Value != "frame, iframe" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, `{"value":[{"element-6066-11e4-a52e-4f735466cecf":"f0"},{"element-6066-11e4-a52e-4f735466cecf":"f1"}]}`)
+		case "/session/123/element":
+			if params.Value == `#login` {
+				fmt.Fprint(w, `{"value":{"element-6066-11e4-a52e-4f735466cecf":"login"}}`)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"no such element","message":"missing","stacktrace":""}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	f1 := `{"id":{"ELEMENT":"f1","element-6066-11e4-a52e-4f735466cecf":"f1"}}`
+	for _, tc := range []struct {
+		frame interface{}
+		want  string
+	}{
+		{1, f1},
+		{"1", f1},
+		{5, `{"id":5}`},
+		{"login", `{"id":{"ELEMENT":"login","element-6066-11e4-a52e-4f735466cecf":"login"}}`},
+		{nil, `{"id":null}`},
+		{"", `{"id":null}`},
+	} {
+		switched = nil
+		if err := wd.SwitchFrame(tc.frame); err != nil {
+			t.Errorf("wd.SwitchFrame(%#v) returned error: %v", tc.frame, err)
+			continue
+		}
+		if len(switched) != 1 || switched[0] != tc.want {
+			t.Errorf("wd.SwitchFrame(%#v) sent %q, want [%s]", tc.frame, switched, tc.want)
+		}
+	}
+
+	if err := wd.SwitchFrame("missing"); !isNoSuchElement(err) {
+		t.Errorf(`wd.SwitchFrame("missing") returned error %v, want no such element`, err)
+	}
+}
+
+func TestFramePath(t *testing.T) {
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/session/123/window":
+			fmt.Fprint(w, `{"value":"a"}`)
+		case r.URL.Path == "/session/123/element":
+			fmt.Fprint(w, `{"value":{"element-6066-11e4-a52e-4f735466cecf":"outer"}}`)
+		default:
+			fmt.Fprint(w, `{"value":null}`)
+		}
+	}))
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	if len(wd.FramePath()) != 0 {
+		t.Fatalf("FramePath() of a new session = %v, want none", wd.FramePath())
+	}
+	if err := wd.SwitchFrame("outer"); err != nil {
+		t.Fatalf(`SwitchFrame("outer") returned error: %v`, err)
+	}
+	if err := wd.SwitchFrame(5); err != nil {
+		t.Fatalf("SwitchFrame(5) returned error: %v", err)
+	}
+	path := wd.FramePath()
+	if len(path) != 2 || path[1] != 5 {
+		t.Fatalf("FramePath() = %v, want the outer element and 5", path)
+	}
+	if elem, ok := path[0].(WebElement); !ok || elem.(*remoteWE).id != "outer" {
+		t.Errorf("FramePath()[0] = %v, want the outer element", path[0])
+	}
+
+	// Modifying another window re-enters the frames.
+	requests = nil
+	if err := wd.MaximizeWindow("b"); err != nil {
+		t.Fatalf(`MaximizeWindow("b") returned error: %v`, err)
+	}
+	want := []string{
+		`GET /session/123/window `,
+		`POST /session/123/window {"handle":"b"}`,
+		`POST /session/123/window/maximize {}`,
+		`POST /session/123/window {"handle":"a"}`,
+		`POST /session/123/frame {"id":null}`,
+		`POST /session/123/frame {"id":{"ELEMENT":"outer","element-6066-11e4-a52e-4f735466cecf":"outer"}}`,
+		`POST /session/123/frame {"id":5}`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("MaximizeWindow() sent\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+	if len(wd.FramePath()) != 2 {
+		t.Errorf("after MaximizeWindow(), FramePath() = %v, want the two frames", wd.FramePath())
+	}
+
+	if err := wd.SwitchFrameParent(); err != nil {
+		t.Fatalf("SwitchFrameParent() returned error: %v", err)
+	}
+	if path := wd.FramePath(); len(path) != 1 {
+		t.Errorf("after SwitchFrameParent(), FramePath() = %v, want the outer element", path)
+	}
+	if err := wd.SwitchToDefaultContent(); err != nil {
+		t.Fatalf("SwitchToDefaultContent() returned error: %v", err)
+	}
+	if path := wd.FramePath(); len(path) != 0 {
+		t.Errorf("after SwitchToDefaultContent(), FramePath() = %v, want none", path)
+	}
+
+	wd.SwitchFrame(1)
+	if err := wd.SwitchWindow("b"); err != nil {
+		t.Fatalf(`SwitchWindow("b") returned error: %v`, err)
+	}
+	if path := wd.FramePath(); len(path) != 0 {
+		t.Errorf("after SwitchWindow(), FramePath() = %v, want none", path)
+	}
+}
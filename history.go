@@ -0,0 +1,182 @@
+package selenium
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// maxRecordedBody is the number of bytes of request and response bodies kept
+// in a CommandRecord.
+const maxRecordedBody = 4096
+
+// CommandRecord describes a command sent to the remote end. It is returned by
+// WebDriver.CommandHistory.
+type CommandRecord struct {
+	// Time is when the command was sent.
+	Time time.Time
+	// Method is the HTTP method of the command, e.g. "POST".
+	Method string
+	// Endpoint is the path of the command's URL, e.g.
+	// "/session/1234/element/5678/click".
+	Endpoint string
+	// Request and Response are the bodies of the request and response,
+	// truncated to 4 KiB. Bodies of commands that may carry secrets, such as
+	// typed keys, alert text and cookies, are replaced by "<masked>".
+	Request  string `json:",omitempty"`
+	Response string `json:",omitempty"`
+	// Status is the HTTP status code, or 0 if no response was received.
+	Status int
+	// Duration is the time it took to execute the command.
+	Duration time.Duration
+	// Err is the error returned by the command, if any.
+	Err string `json:",omitempty"`
+}
+
+func (r CommandRecord) String() string {
+	s := fmt.Sprintf("%s %s -> %d in %s", r.Method, r.Endpoint, r.Status, r.Duration)
+	if r.Request != "" {
+		s += "\n  request: " + r.Request
+	}
+	if r.Response != "" {
+		s += "\n  response: " + r.Response
+	}
+	if r.Err != "" {
+		s += "\n  error: " + r.Err
+	}
+	return s
+}
+
+// commandHistory is a bounded ring buffer of the most recent commands. It is
+// safe for concurrent use.
+type commandHistory struct {
+	mu   sync.Mutex
+	buf  []CommandRecord
+	next int
+	full bool
+}
+
+func newCommandHistory(size int) *commandHistory {
+	return &commandHistory{buf: make([]CommandRecord, size)}
+}
+
+func (h *commandHistory) add(r CommandRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.buf) == 0 {
+		return
+	}
+	h.buf[h.next] = r
+	h.next = (h.next + 1) % len(h.buf)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// records returns the recorded commands, oldest first.
+func (h *commandHistory) records() []CommandRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]CommandRecord(nil), h.buf[:h.next]...)
+	}
+	return append(append([]CommandRecord(nil), h.buf[h.next:]...), h.buf[:h.next]...)
+}
+
+// last returns up to the n most recent records, oldest first.
+func (h *commandHistory) last(n int) []CommandRecord {
+	records := h.records()
+	if len(records) > n {
+		records = records[len(records)-n:]
+	}
+	return records
+}
+
+// secretEndpoints match the paths of commands whose request and response
+// bodies may carry secrets, such as typed passwords, alert prompts or cookie
+// values.
+var secretEndpoints = regexp.MustCompile(`/(value|keys|actions|alert/text|alert_text|cookie(/[^/]*)?)$`)
+
+const maskedBody = "<masked>"
+
+// maskBody returns the body of a request to or response from the endpoint, as
+// it should be recorded.
+func maskBody(endpoint string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if secretEndpoints.MatchString(endpoint) {
+		return maskedBody
+	}
+	if len(body) > maxRecordedBody {
+		return string(body[:maxRecordedBody]) + "...(truncated)"
+	}
+	return string(body)
+}
+
+// errorContext is the number of commands, including the failing one, whose
+// records are attached to an *Error.
+const errorContext = 3
+
+// recordCommand adds a command to the session's history, if enabled, and
+// attaches the most recent records to err if it is an *Error.
+func (wd *remoteWD) recordCommand(start time.Time, method, rawURL string, request, response []byte, status int, err error) {
+	if wd.history == nil {
+		return
+	}
+	endpoint := rawURL
+	if u, perr := url.Parse(rawURL); perr == nil {
+		endpoint = u.Path
+	}
+	r := CommandRecord{
+		Time:     start,
+		Method:   method,
+		Endpoint: endpoint,
+		Request:  maskBody(endpoint, request),
+		Response: maskBody(endpoint, response),
+		Status:   status,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		r.Err = err.Error()
+	}
+	wd.history.add(r)
+	if e, ok := err.(*Error); ok {
+		e.history = wd.history.last(errorContext)
+	}
+}
+
+func (wd *remoteWD) EnableCommandHistory(n int) {
+	if n <= 0 {
+		wd.history = nil
+		return
+	}
+	wd.history = newCommandHistory(n)
+}
+
+func (wd *remoteWD) CommandHistory() []CommandRecord {
+	if wd.history == nil {
+		return nil
+	}
+	return wd.history.records()
+}
+
+// Detail returns the error message followed, if command history is enabled
+// for the session, by the records of the failing command and the commands
+// that preceded it.
+func (e *Error) Detail() string {
+	if len(e.history) == 0 {
+		return e.Error()
+	}
+	var buf bytes.Buffer
+	buf.WriteString(e.Error())
+	buf.WriteString("\nrecent commands:")
+	for _, r := range e.history {
+		buf.WriteString("\n")
+		buf.WriteString(r.String())
+	}
+	return buf.String()
+}
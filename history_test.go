@@ -0,0 +1,109 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCommandHistoryWraps(t *testing.T) {
+	h := newCommandHistory(3)
+	for i := 0; i < 5; i++ {
+		h.add(CommandRecord{Method: fmt.Sprint(i)})
+	}
+	var got []string
+	for _, r := range h.records() {
+		got = append(got, r.Method)
+	}
+	if want := []string{"2", "3", "4"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("records() = %v, want %v", got, want)
+	}
+	if got := h.last(2); len(got) != 2 || got[0].Method != "3" || got[1].Method != "4" {
+		t.Errorf("last(2) = %v, want the records of 3 and 4", got)
+	}
+}
+
+func TestCommandHistory(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch r.URL.Path {
+		case "/session/123/element/e/click":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"value":{"error":"element click intercepted","message":"obscured","stacktrace":""}}`)
+		case "/session/123/title":
+			fmt.Fprint(w, `{"value":"Title"}`)
+		default:
+			fmt.Fprint(w, `{"value":null}`)
+		}
+	}))
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	if _, err := wd.Title(); err != nil {
+		t.Fatalf("wd.Title() returned error: %v", err)
+	}
+	if got := wd.CommandHistory(); got != nil {
+		t.Errorf("wd.CommandHistory() without history enabled = %v, want nil", got)
+	}
+
+	wd.EnableCommandHistory(4)
+	elem := &remoteWE{parent: wd, id: "e"}
+	if _, err := wd.Title(); err != nil {
+		t.Fatalf("wd.Title() returned error: %v", err)
+	}
+	if err := elem.SendKeys("hunter2"); err != nil {
+		t.Fatalf("elem.SendKeys() returned error: %v", err)
+	}
+	if err := elem.Clear(); err != nil {
+		t.Fatalf("elem.Clear() returned error: %v", err)
+	}
+	err := elem.Click()
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("elem.Click() returned error %v, want an *Error", err)
+	}
+
+	history := wd.CommandHistory()
+	if len(history) != 4 {
+		t.Fatalf("wd.CommandHistory() returned %d records, want 4", len(history))
+	}
+	for _, r := range history {
+		if strings.Contains(r.Request, "hunter2") {
+			t.Errorf("the record of %s %s contains the typed keys", r.Method, r.Endpoint)
+		}
+	}
+	if r := history[1]; r.Endpoint != "/session/123/element/e/value" || r.Request != maskedBody {
+		t.Errorf("the SendKeys record is %+v, want a masked request to /session/123/element/e/value", r)
+	}
+	if r := history[3]; r.Status != http.StatusBadRequest || !strings.Contains(r.Err, "element click intercepted") {
+		t.Errorf("the Click record is %+v, want the failure", r)
+	}
+
+	detail := e.Detail()
+	for _, want := range []string{"element click intercepted", "/element/e/value", "/element/e/clear", "/element/e/click"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("e.Detail() = %q, want it to contain %q", detail, want)
+		}
+	}
+	if strings.Contains(detail, "/title") {
+		t.Errorf("e.Detail() = %q, want only the failing command and its two predecessors", detail)
+	}
+
+	// Recording is safe for concurrent use.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wd.Title()
+		}()
+	}
+	wg.Wait()
+	if got := len(wd.CommandHistory()); got != 4 {
+		t.Errorf("wd.CommandHistory() returned %d records, want 4", got)
+	}
+}
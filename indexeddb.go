@@ -0,0 +1,215 @@
+package selenium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrIndexedDBBlocked is returned by IDBHandle.DeleteDatabase if the deletion
+// is blocked by connections to the database that the page keeps open.
+var ErrIndexedDBBlocked = errors.New("deletion of IndexedDB database blocked by open connections")
+
+// IDBBinaryCap is the maximum number of bytes of an ArrayBuffer or typed array
+// that IDBHandle.GetAll converts to base64. Larger binary values are replaced
+// by a string describing their size.
+var IDBBinaryCap = 64 * 1024
+
+// IDBHandle provides read access to, and deletion of, an IndexedDB database of
+// the current page's origin. It is returned by WebDriver.IndexedDB.
+type IDBHandle struct {
+	wd   *remoteWD
+	name string
+}
+
+// idbPrelude defines helpers shared by the IndexedDB scripts. The database
+// name is the first script argument and the callback of the asynchronous
+// script is the last.
+const idbPrelude = `
+var dbName = arguments[0], done = arguments[arguments.length - 1];
+function fail(e) {
+	done({error: String(e && (e.message || e.name) || e)});
+}
+function openDB(cb) {
+	var req = indexedDB.open(dbName);
+	var created = false;
+	req.onupgradeneeded = function() {
+		// The database does not exist; do not create it.
+		created = true;
+		req.transaction.abort();
+	};
+	req.onerror = function() {
+		if (created) {
+			done({error: 'no such database: ' + dbName});
+			return;
+		}
+		fail(req.error);
+	};
+	req.onsuccess = function() {
+		var db = req.result;
+		try {
+			cb(db);
+		} catch (e) {
+			db.close();
+			fail(e);
+		}
+	};
+}
+function toBase64(bytes) {
+	var bin = '';
+	for (var i = 0; i < bytes.length; i++) {
+		bin += String.fromCharCode(bytes[i]);
+	}
+	return btoa(bin);
+}
+function toJSON(v, cap) {
+	if (v === null || v === undefined) {
+		return null;
+	}
+	if (v instanceof Date) {
+		return v.toISOString();
+	}
+	if (v instanceof ArrayBuffer || ArrayBuffer.isView(v)) {
+		var bytes = v instanceof ArrayBuffer ? new Uint8Array(v) :
+			new Uint8Array(v.buffer, v.byteOffset, v.byteLength);
+		if (bytes.length > cap) {
+			return '<binary: ' + bytes.length + ' bytes>';
+		}
+		return toBase64(bytes);
+	}
+	if (typeof Blob !== 'undefined' && v instanceof Blob) {
+		return '<blob: ' + v.size + ' bytes>';
+	}
+	if (v instanceof Map) {
+		var m = {};
+		v.forEach(function(val, key) { m[String(key)] = toJSON(val, cap); });
+		return m;
+	}
+	if (v instanceof Set) {
+		var s = [];
+		v.forEach(function(val) { s.push(toJSON(val, cap)); });
+		return s;
+	}
+	if (Array.isArray(v)) {
+		return v.map(function(e) { return toJSON(e, cap); });
+	}
+	if (typeof v === 'object') {
+		var o = {};
+		Object.keys(v).forEach(function(k) { o[k] = toJSON(v[k], cap); });
+		return o;
+	}
+	return v;
+}
+`
+
+const idbObjectStoresScript = idbPrelude + `
+openDB(function(db) {
+	var names = Array.prototype.slice.call(db.objectStoreNames);
+	db.close();
+	done({value: names});
+});
+`
+
+const idbCountScript = idbPrelude + `
+var storeName = arguments[1];
+openDB(function(db) {
+	var req = db.transaction(storeName, 'readonly').objectStore(storeName).count();
+	req.onsuccess = function() { db.close(); done({value: req.result}); };
+	req.onerror = function() { db.close(); fail(req.error); };
+});
+`
+
+const idbGetAllScript = idbPrelude + `
+var storeName = arguments[1], limit = arguments[2], cap = arguments[3];
+openDB(function(db) {
+	var store = db.transaction(storeName, 'readonly').objectStore(storeName);
+	var req = limit > 0 ? store.getAll(null, limit) : store.getAll();
+	req.onsuccess = function() {
+		db.close();
+		done({value: req.result.map(function(v) { return toJSON(v, cap); })});
+	};
+	req.onerror = function() { db.close(); fail(req.error); };
+});
+`
+
+const idbDeleteScript = idbPrelude + `
+var req = indexedDB.deleteDatabase(dbName);
+req.onsuccess = function() { done({value: true}); };
+req.onerror = function() { fail(req.error); };
+req.onblocked = function() { done({blocked: true}); };
+`
+
+func (wd *remoteWD) IndexedDB(dbName string) (*IDBHandle, error) {
+	h := &IDBHandle{wd: wd, name: dbName}
+	// Verify that the database exists.
+	if _, err := h.ObjectStores(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// run executes one of the IndexedDB scripts and decodes its result into v.
+func (h *IDBHandle) run(script string, v interface{}, args ...interface{}) error {
+	raw, err := h.wd.ExecuteScriptAsyncRaw(script, append([]interface{}{h.name}, args...))
+	if err != nil {
+		return err
+	}
+	reply := new(struct {
+		Value *struct {
+			Value   json.RawMessage
+			Error   string
+			Blocked bool
+		}
+	})
+	if err := json.Unmarshal(raw, reply); err != nil {
+		return err
+	}
+	switch {
+	case reply.Value == nil:
+		return fmt.Errorf("nil return value")
+	case reply.Value.Blocked:
+		return ErrIndexedDBBlocked
+	case reply.Value.Error != "":
+		return fmt.Errorf("IndexedDB database %q: %s", h.name, reply.Value.Error)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(reply.Value.Value, v)
+}
+
+// ObjectStores returns the names of the object stores of the database.
+func (h *IDBHandle) ObjectStores() ([]string, error) {
+	var names []string
+	if err := h.run(idbObjectStoresScript, &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// Count returns the number of records in the named object store.
+func (h *IDBHandle) Count(store string) (int, error) {
+	var n int
+	if err := h.run(idbCountScript, &n, store); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// GetAll decodes up to limit values of the named object store, in key order,
+// into out, which should be a pointer to a slice. A limit of zero or less
+// returns all values.
+//
+// Values are converted to JSON as follows: Dates become ISO 8601 strings,
+// ArrayBuffers and typed arrays become base64-encoded strings (or a
+// placeholder string if larger than IDBBinaryCap), Maps become objects, and
+// Sets become arrays.
+func (h *IDBHandle) GetAll(store string, limit int, out interface{}) error {
+	return h.run(idbGetAllScript, out, store, limit, IDBBinaryCap)
+}
+
+// DeleteDatabase deletes the database. ErrIndexedDBBlocked is returned if the
+// page holds open connections to the database that prevent its deletion.
+func (h *IDBHandle) DeleteDatabase() error {
+	return h.run(idbDeleteScript, nil)
+}
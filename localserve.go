@@ -0,0 +1,174 @@
+package selenium
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// PublicBaseURL returns an option that makes the pages served by RenderHTML
+// and ServeDir reachable from a remote end on another host, such as a grid.
+// base is the URL at which that host reaches this one, e.g.
+// "http://ci-runner.internal:8123". The local server then listens on all
+// interfaces, on the port of base if it has one, or else on a free port that
+// replaces the port in the URLs navigated to.
+//
+// Warning: with this option, anyone who can reach this host on that port can
+// read the pages rendered and the files served, as there is no
+// authentication. The URLs contain random tokens, which are only as secret as
+// the network, the logs and the browser history that they pass through. Use
+// it on trusted networks only, and prefer serving test fixtures to serving
+// directories that hold anything else.
+//
+// Without this option, the local server only listens on the loopback
+// interface, which suffices for drivers running on the same host.
+func PublicBaseURL(base string) RemoteOption {
+	return func(wd *remoteWD) error {
+		u, err := url.Parse(base)
+		if err != nil {
+			return fmt.Errorf("invalid public base URL %q: %v", base, err)
+		}
+		if u.Scheme != "http" || u.Host == "" {
+			return fmt.Errorf("invalid public base URL %q: must be an absolute http URL", base)
+		}
+		wd.publicBaseURL = u
+		return nil
+	}
+}
+
+// maxRenderedPages is the number of pages rendered with RenderHTML that the
+// local server keeps serving, e.g. for navigating back to them. Older pages
+// are forgotten, so that sessions that render many pages do not hold them all.
+const maxRenderedPages = 32
+
+// localServer serves the pages rendered with RenderHTML and the directories
+// served with ServeDir to the browser.
+type localServer struct {
+	srv     *httptest.Server
+	baseURL string
+
+	mu    sync.Mutex
+	pages map[string]string
+	// pageKeys are the keys of pages, oldest first.
+	pageKeys []string
+	dirs     map[string]http.Handler
+}
+
+func (s *localServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	if len(parts) < 2 {
+		http.NotFound(w, r)
+		return
+	}
+	s.mu.Lock()
+	page, pageOK := s.pages[parts[1]]
+	dir, dirOK := s.dirs[parts[1]]
+	s.mu.Unlock()
+	switch {
+	case parts[0] == "html" && len(parts) == 2 && pageOK:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprint(w, page)
+	case parts[0] == "dir" && dirOK:
+		http.StripPrefix("/dir/"+parts[1], dir).ServeHTTP(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// newKey returns a path segment not used by any page or directory yet. It is
+// random, so that the URLs of the local server cannot be guessed.
+func (s *localServer) newKey() string {
+	return rand.Text()
+}
+
+// addPage adds a page with the given HTML and returns its key, forgetting the
+// oldest page if there are more than maxRenderedPages.
+func (s *localServer) addPage(html string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := s.newKey()
+	s.pages[key] = html
+	s.pageKeys = append(s.pageKeys, key)
+	if len(s.pageKeys) > maxRenderedPages {
+		delete(s.pages, s.pageKeys[0])
+		s.pageKeys = s.pageKeys[1:]
+	}
+	return key
+}
+
+// startLocalServer starts the WebDriver's local server, if it is not running
+// yet.
+func (wd *remoteWD) startLocalServer() (*localServer, error) {
+	if wd.localServer != nil {
+		return wd.localServer, nil
+	}
+	s := &localServer{pages: make(map[string]string), dirs: make(map[string]http.Handler)}
+	s.srv = httptest.NewUnstartedServer(s)
+	if base := wd.publicBaseURL; base != nil {
+		l, err := net.Listen("tcp", net.JoinHostPort("", base.Port()))
+		if err != nil {
+			s.srv.Close()
+			return nil, fmt.Errorf("listening for the public base URL %s: %v", base, err)
+		}
+		s.srv.Listener.Close()
+		s.srv.Listener = l
+	}
+	s.srv.Start()
+	s.baseURL = s.srv.URL
+	if base := wd.publicBaseURL; base != nil {
+		_, port, _ := net.SplitHostPort(s.srv.Listener.Addr().String())
+		u := *base
+		u.Host = net.JoinHostPort(base.Hostname(), port)
+		s.baseURL = strings.TrimSuffix(u.String(), "/")
+	}
+	wd.localServer = s
+	return s, nil
+}
+
+// stopLocalServer stops the WebDriver's local server, if it is running.
+func (wd *remoteWD) stopLocalServer() {
+	if wd.localServer == nil {
+		return
+	}
+	wd.localServer.srv.Close()
+	wd.localServer = nil
+}
+
+func (wd *remoteWD) RenderHTML(html string) error {
+	s, err := wd.startLocalServer()
+	if err != nil {
+		return err
+	}
+	return wd.Get(s.baseURL + "/html/" + s.addPage(html))
+}
+
+func (wd *remoteWD) ServeDir(dir string) (baseURL string, stop func(), err error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	if !info.IsDir() {
+		return "", nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	s, err := wd.startLocalServer()
+	if err != nil {
+		return "", nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := s.newKey()
+	s.dirs[key] = http.FileServer(http.Dir(dir))
+	stop = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.dirs, key)
+	}
+	return s.baseURL + "/dir/" + key + "/", stop, nil
+}
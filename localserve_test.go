@@ -0,0 +1,160 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// browseServer is a remote end whose browser fetches the pages it is
+// navigated to, and which finds elements by ID in the last page fetched.
+func browseServer(t *testing.T) *httptest.Server {
+	var page string
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch r.URL.Path {
+		case "/session/123/url":
+			var params struct{ URL string }
+			json.NewDecoder(r.Body).Decode(&params)
+			resp, err := http.Get(params.URL)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintf(w, `{"value":{"error":"unknown error","message":%q}}`, err.Error())
+				return
+			}
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			page = string(body)
+			if resp.StatusCode != http.StatusOK {
+				page = ""
+			}
+			fmt.Fprint(w, `{"value":null}`)
+		case "/session/123/element":
+			var params struct{ Using, Value string }
+			json.NewDecoder(r.Body).Decode(&params)
+			id := strings.TrimPrefix(params.Value, "#")
+			if !strings.Contains(page, `id="`+id+`"`) {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"value":{"error":"no such element","message":"not found"}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"value":{%q:%q}}`, webElementIdentifier, id)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestRenderHTML(t *testing.T) {
+	s := browseServer(t)
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	if err := wd.RenderHTML(`<p id="greeting">100% <b>hello</b></p>`); err != nil {
+		t.Fatalf("RenderHTML() returned error: %v", err)
+	}
+	if _, err := wd.FindElement(ByCSSSelector, "#greeting"); err != nil {
+		t.Errorf("FindElement() of the rendered element returned error: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "servedir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "sub", "index.html"), []byte(`<div id="fixture"></div>`), 0644)
+
+	base, stop, err := wd.ServeDir(dir)
+	if err != nil {
+		t.Fatalf("ServeDir() returned error: %v", err)
+	}
+	if !strings.HasPrefix(base, wd.localServer.baseURL) || !strings.HasSuffix(base, "/") {
+		t.Errorf("ServeDir() returned URL %q, want a directory of the local server", base)
+	}
+	if err := wd.Get(base + "sub/index.html"); err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	if _, err := wd.FindElement(ByCSSSelector, "#fixture"); err != nil {
+		t.Errorf("FindElement() of the served element returned error: %v", err)
+	}
+	stop()
+	wd.Get(base + "sub/index.html")
+	if _, err := wd.FindElement(ByCSSSelector, "#fixture"); err == nil {
+		t.Errorf("the directory is still served after stop()")
+	}
+
+	if _, _, err := wd.ServeDir(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("ServeDir() of a missing directory returned nil error")
+	}
+}
+
+func TestLocalServerStoppedOnQuit(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		fmt.Fprint(w, `{"value":null}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	if err := wd.RenderHTML("<p></p>"); err != nil {
+		t.Fatalf("RenderHTML() returned error: %v", err)
+	}
+	base := wd.localServer.baseURL
+	if err := wd.Quit(); err != nil {
+		t.Fatalf("Quit() returned error: %v", err)
+	}
+	if resp, err := http.Get(base + "/"); err == nil {
+		resp.Body.Close()
+		t.Errorf("the local server still answers after Quit()")
+	}
+}
+
+func TestRenderedPagesAreForgotten(t *testing.T) {
+	s := &localServer{pages: make(map[string]string)}
+	first := s.addPage("<p>0</p>")
+	var last string
+	for i := 1; i <= maxRenderedPages; i++ {
+		last = s.addPage(fmt.Sprintf("<p>%d</p>", i))
+	}
+	if len(s.pages) != maxRenderedPages || len(s.pageKeys) != maxRenderedPages {
+		t.Errorf("the local server holds %d pages, want %d", len(s.pages), maxRenderedPages)
+	}
+	if _, ok := s.pages[first]; ok {
+		t.Errorf("the oldest page is still served")
+	}
+	if s.pages[last] != fmt.Sprintf("<p>%d</p>", maxRenderedPages) {
+		t.Errorf("the last page is %q, want the one rendered last", s.pages[last])
+	}
+	if first == last || len(first) < 20 {
+		t.Errorf("the pages have keys %q and %q, want distinct random ones", first, last)
+	}
+}
+
+func TestPublicBaseURL(t *testing.T) {
+	wd := &remoteWD{}
+	if err := PublicBaseURL("http://runner.example:0/")(wd); err != nil {
+		t.Fatalf("PublicBaseURL() returned error: %v", err)
+	}
+	s, err := wd.startLocalServer()
+	if err != nil {
+		t.Fatalf("startLocalServer() returned error: %v", err)
+	}
+	defer wd.stopLocalServer()
+	if !strings.HasPrefix(s.baseURL, "http://runner.example:") || strings.HasSuffix(s.baseURL, ":0") {
+		t.Errorf("the base URL is %q, want the public host with the listening port", s.baseURL)
+	}
+
+	for _, base := range []string{"runner.example", "https://runner.example", "://"} {
+		if err := PublicBaseURL(base)(wd); err == nil {
+			t.Errorf("PublicBaseURL(%q) returned nil error", base)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package selenium
+
+import "fmt"
+
+// Locator describes how to find an element: a locator strategy, one of the
+// By* constants, and a value such as a CSS selector. Found elements record
+// the locator, which is included in their String representation and in the
+// errors returned by their commands.
+type Locator struct {
+	By, Value string
+}
+
+// TestIDAttribute is the attribute matched by the locators returned by
+// TestID.
+var TestIDAttribute = "data-testid"
+
+// CSS returns a locator for the CSS selector sel.
+func CSS(sel string) Locator {
+	return Locator{By: ByCSSSelector, Value: sel}
+}
+
+// XPath returns a locator for the XPath expression expr.
+func XPath(expr string) Locator {
+	return Locator{By: ByXPATH, Value: expr}
+}
+
+// ID returns a locator for the element with the given ID.
+func ID(id string) Locator {
+	return Locator{By: ByID, Value: id}
+}
+
+// TestID returns a locator for the elements whose TestIDAttribute attribute
+// is id.
+func TestID(id string) Locator {
+	return Locator{By: ByCSSSelector, Value: "[" + TestIDAttribute + "=" + CSSAttrValue(id) + "]"}
+}
+
+// locatorNames are the short names of the locator strategies used in
+// Locator.String.
+var locatorNames = map[string]string{
+	ByCSSSelector: "css",
+	ByXPATH:       "xpath",
+}
+
+func (l Locator) String() string {
+	by := l.By
+	if name, ok := locatorNames[by]; ok {
+		by = name
+	}
+	return fmt.Sprintf("%s %q", by, l.Value)
+}
+
+func (wd *remoteWD) Find(loc Locator) (WebElement, error) {
+	return wd.FindElement(loc.By, loc.Value)
+}
+
+func (wd *remoteWD) FindAll(loc Locator) ([]WebElement, error) {
+	return wd.FindElements(loc.By, loc.Value)
+}
+
+func (elem *remoteWE) Find(loc Locator) (WebElement, error) {
+	return elem.FindElement(loc.By, loc.Value)
+}
+
+func (elem *remoteWE) FindAll(loc Locator) ([]WebElement, error) {
+	return elem.FindElements(loc.By, loc.Value)
+}
@@ -0,0 +1,80 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocatorConstructors(t *testing.T) {
+	tests := []struct {
+		loc  Locator
+		want Locator
+		str  string
+	}{
+		{CSS(".foo"), Locator{ByCSSSelector, ".foo"}, `css ".foo"`},
+		{XPath("//a"), Locator{ByXPATH, "//a"}, `xpath "//a"`},
+		{ID("main"), Locator{ByID, "main"}, `id "main"`},
+		{TestID(`sub"mit`), Locator{ByCSSSelector, `[data-testid="sub\"mit"]`}, `css "[data-testid=\"sub\\\"mit\"]"`},
+	}
+	for _, tc := range tests {
+		if tc.loc != tc.want {
+			t.Errorf("locator = %+v, want %+v", tc.loc, tc.want)
+		}
+		if got := tc.loc.String(); got != tc.str {
+			t.Errorf("%+v.String() = %s, want %s", tc.loc, got, tc.str)
+		}
+	}
+}
+
+func TestFindRecordsLocator(t *testing.T) {
+	for _, w3c := range []bool{true, false} {
+		var queries []map[string]string
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q := make(map[string]string)
+			json.NewDecoder(r.Body).Decode(&q)
+			queries = append(queries, q)
+			w.Header().Set("Content-Type", JSONType)
+			ref := `{"element-6066-11e4-a52e-4f735466cecf":"e1"}`
+			if !w3c {
+				ref = `{"ELEMENT":"e1"}`
+			}
+			if r.URL.Path[len(r.URL.Path)-1] == 's' {
+				fmt.Fprintf(w, `{"sessionId":"123","status":0,"value":[%s]}`, ref)
+			} else {
+				fmt.Fprintf(w, `{"sessionId":"123","status":0,"value":%s}`, ref)
+			}
+		}))
+		wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+
+		elem, err := wd.Find(TestID("submit"))
+		if err != nil {
+			t.Fatalf("w3c=%t: Find() returned error: %v", w3c, err)
+		}
+		if got, want := fmt.Sprint(elem), `element[e1 via css "[data-testid=\"submit\"]"]`; got != want {
+			t.Errorf("w3c=%t: Find() returned %s, want %s", w3c, got, want)
+		}
+		if q := queries[0]; q["using"] != ByCSSSelector || q["value"] != `[data-testid="submit"]` {
+			t.Errorf("w3c=%t: Find() sent %v, want a CSS query for the test ID", w3c, q)
+		}
+
+		children, err := elem.FindAll(XPath(".//li"))
+		if err != nil {
+			t.Fatalf("w3c=%t: FindAll() returned error: %v", w3c, err)
+		}
+		if got, want := fmt.Sprint(children[0]), `element[e1 via xpath ".//li"]`; got != want {
+			t.Errorf("w3c=%t: FindAll() returned %s, want %s", w3c, got, want)
+		}
+
+		plain, err := wd.FindElement(ByCSSSelector, "p")
+		if err != nil {
+			t.Fatalf("w3c=%t: FindElement() returned error: %v", w3c, err)
+		}
+		if got, want := fmt.Sprint(plain), `element[e1 via css "p"]`; got != want {
+			t.Errorf("w3c=%t: FindElement() returned %s, want %s", w3c, got, want)
+		}
+		s.Close()
+	}
+}
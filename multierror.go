@@ -0,0 +1,77 @@
+package selenium
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MultiErrorLimit is the number of items that MultiError.Error lists; the
+// others are only counted.
+var MultiErrorLimit = 10
+
+// MultiError is returned by operations on several items, such as the
+// cookies deleted by WebDriver.DeleteCookiesMatching, when some of them fail.
+// Use errors.As to get it, and errors.Is and errors.As on it to check the
+// errors of the items.
+type MultiError struct {
+	// Items are the items that failed, in the order in which they did.
+	Items []MultiErrorItem
+}
+
+// MultiErrorItem is an item of a MultiError.
+type MultiErrorItem struct {
+	// Label describes the item, e.g. `cookie "sid"`.
+	Label string
+	Err   error
+}
+
+// Append adds an item that failed with err. A nil err is ignored.
+func (e *MultiError) Append(label string, err error) {
+	if err == nil {
+		return
+	}
+	e.Items = append(e.Items, MultiErrorItem{Label: label, Err: err})
+}
+
+// ErrorOrNil returns e if any item failed, and nil otherwise, for returning
+// as an error.
+func (e *MultiError) ErrorOrNil() error {
+	if e == nil || len(e.Items) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Error lists the failed items, up to MultiErrorLimit of them, e.g.
+// `2 errors: cookie "a": ...; cookie "b": ...`.
+func (e *MultiError) Error() string {
+	var b strings.Builder
+	if len(e.Items) == 1 {
+		b.WriteString("1 error: ")
+	} else {
+		fmt.Fprintf(&b, "%d errors: ", len(e.Items))
+	}
+	for i, item := range e.Items {
+		if i == MultiErrorLimit {
+			fmt.Fprintf(&b, "; and %d more", len(e.Items)-i)
+			break
+		}
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		if item.Label != "" {
+			b.WriteString(item.Label + ": ")
+		}
+		b.WriteString(item.Err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the items.
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, len(e.Items))
+	for i, item := range e.Items {
+		errs[i] = item.Err
+	}
+	return errs
+}
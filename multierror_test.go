@@ -0,0 +1,47 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMultiError(t *testing.T) {
+	e := new(MultiError)
+	e.Append("ignored", nil)
+	if err := e.ErrorOrNil(); err != nil {
+		t.Fatalf("ErrorOrNil() without failed items = %v, want nil", err)
+	}
+
+	protocolErr := &Error{Err: "no such cookie", Message: "missing"}
+	e.Append(`cookie "a"`, protocolErr)
+	e.Append(`cookie "b"`, &ElementError{Op: "click", Err: ErrSessionClosed})
+	err := e.ErrorOrNil()
+	if want := `2 errors: cookie "a": no such cookie: missing; cookie "b": `; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Error() = %q, want it to start with %q", err, want)
+	}
+
+	wrapped := fmt.Errorf("wrapped: %w", err)
+	var multi *MultiError
+	if !errors.As(wrapped, &multi) || len(multi.Items) != 2 || multi.Items[1].Label != `cookie "b"` {
+		t.Errorf("errors.As() did not find the MultiError in %v", wrapped)
+	}
+	var got *Error
+	if !errors.As(err, &got) || got != protocolErr {
+		t.Errorf("errors.As() did not find the protocol error of an item in %v", err)
+	}
+	if !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("errors.Is(%v, ErrSessionClosed) = false, want true", err)
+	}
+	if errors.Is(err, ErrNoSession) {
+		t.Errorf("errors.Is(%v, ErrNoSession) = true, want false", err)
+	}
+
+	for i := 0; i < MultiErrorLimit+1; i++ {
+		e.Append(fmt.Sprintf("item %d", i), errors.New("failed"))
+	}
+	if s := e.Error(); !strings.HasPrefix(s, "13 errors: ") || !strings.HasSuffix(s, "; and 3 more") || strings.Contains(s, "item 8") {
+		t.Errorf("Error() with more items than the limit = %q, want the first %d and a count of the others", s, MultiErrorLimit)
+	}
+}
@@ -0,0 +1,255 @@
+package selenium
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// navigationMarkerKey is the property of the window object on which the
+// navigation detector plants its marker.
+const navigationMarkerKey = "__seleniumNavigationMarker"
+
+// navigationCheckScript reports whether the marker passed as argument is
+// missing from the window, i.e. whether the document is a new one, and plants
+// it, along with the URL of the document.
+const navigationCheckScript = `var key = arguments[0], token = arguments[1];
+var fresh = window[key] !== token;
+window[key] = token;
+return [fresh, window.location.href];`
+
+// navigationDetector detects the navigations of the current browsing context
+// for the handlers registered with OnNavigation, by planting a marker on the
+// window object after each command and checking whether it is still there
+// after the next one.
+type navigationDetector struct {
+	mu       sync.Mutex
+	handlers map[*func(string)]bool
+	// order holds the handlers in the order in which they were registered.
+	order []*func(string)
+
+	// token is the marker, which is random so that pages cannot forge it.
+	token string
+	// planted is set once the marker has been planted in the current browsing
+	// context, so that the first check after a switch to another one is not
+	// reported as a navigation.
+	planted bool
+	// byURL is set if scripts cannot be run in the session, e.g. because of
+	// the Content Security Policy of a page, in which case navigations are
+	// detected by comparing the current URL with lastURL.
+	byURL   bool
+	lastURL string
+	// active is set while the detector checks for a navigation or calls the
+	// handlers, so that the commands they send do not trigger it.
+	active bool
+}
+
+func (wd *remoteWD) OnNavigation(f func(newURL string)) (remove func()) {
+	d := &wd.navigation
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.handlers == nil {
+		d.handlers = make(map[*func(string)]bool)
+	}
+	h := &f
+	d.handlers[h] = true
+	d.order = append(d.order, h)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			delete(d.handlers, h)
+			for i, o := range d.order {
+				if o == h {
+					d.order = append(d.order[:i:i], d.order[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+// watching reports whether any handler is registered, without which the
+// detector sends no commands.
+func (d *navigationDetector) watching() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.handlers) > 0
+}
+
+// resetContext makes the detector plant its marker again without reporting a
+// navigation, after a switch to another browsing context.
+func (d *navigationDetector) resetContext() {
+	d.planted = false
+	d.lastURL = ""
+}
+
+// reset forgets the state of the previous session, but keeps the handlers.
+func (d *navigationDetector) reset() {
+	d.resetContext()
+	d.byURL = false
+}
+
+// afterCommand checks for a navigation after the command with the given
+// method and URL succeeded. The commands that navigate explicitly report the
+// navigation themselves, and those that switch to another browsing context
+// only make the detector start over.
+func (wd *remoteWD) afterCommand(method, url string) {
+	d := &wd.navigation
+	if d.active {
+		return
+	}
+	path := strings.TrimPrefix(url, wd.urlPrefix+"/session/"+wd.id)
+	if method == "POST" {
+		switch path {
+		case "/url", "/back", "/forward", "/refresh":
+			return
+		case "/window", "/frame", "/frame/parent":
+			d.resetContext()
+			return
+		}
+	}
+	wd.checkNavigation(false)
+}
+
+// checkNavigation calls the handlers with the URL of the current document if
+// the browsing context navigated since the last check, or if explicit is set.
+// Failures to check are ignored, so as not to fail the command after which it
+// runs; a navigation missed because of one is reported after a later command.
+//
+// No check is done while a user prompt is open: running a script, or getting
+// the URL, would make the remote end handle the prompt according to the
+// session's unhandledPromptBehavior, which by default dismisses it.
+func (wd *remoteWD) checkNavigation(explicit bool) {
+	d := &wd.navigation
+	if d.active || !d.watching() {
+		return
+	}
+	d.active = true
+	defer func() { d.active = false }()
+
+	if open, ok := wd.promptOpen(); open || !ok {
+		return
+	}
+	url, navigated, ok := wd.detectNavigation()
+	if !ok || !navigated && !explicit {
+		return
+	}
+	d.mu.Lock()
+	handlers := append([]*func(string){}, d.order...)
+	d.mu.Unlock()
+	for _, h := range handlers {
+		(*h)(url)
+	}
+}
+
+// detectNavigation checks whether the current document is a new one and
+// returns its URL. It returns false if the check failed.
+func (wd *remoteWD) detectNavigation() (url string, navigated, ok bool) {
+	d := &wd.navigation
+	if !d.byURL {
+		if d.token == "" {
+			b := make([]byte, 16)
+			if _, err := rand.Read(b); err != nil {
+				return "", false, false
+			}
+			d.token = hex.EncodeToString(b)
+		}
+		fresh, url, err := wd.checkMarker()
+		if err == nil {
+			navigated = fresh && d.planted
+			d.planted = true
+			return url, navigated, true
+		}
+		if !isScriptBlocked(err) {
+			return "", false, false
+		}
+		d.byURL = true
+	}
+
+	// Without the marker, reloads and navigations to the same URL go
+	// unnoticed, whereas changes of the URL within the document, such as
+	// history.pushState, are reported as navigations.
+	url, err := wd.CurrentURL()
+	if err != nil {
+		return "", false, false
+	}
+	navigated = d.lastURL != "" && url != d.lastURL
+	d.lastURL = url
+	return url, navigated, true
+}
+
+// promptOpen reports whether a user prompt is open, with Get Alert Text,
+// which, unlike other commands, leaves the prompt open. It returns false if
+// the check failed.
+func (wd *remoteWD) promptOpen() (open, ok bool) {
+	path := "/session/%s/alert_text"
+	if wd.w3cCompatible {
+		path = "/session/%s/alert/text"
+	}
+	_, err := wd.execute("GET", wd.requestURL(path, wd.id), nil)
+	if err == nil {
+		return true, true
+	}
+	return false, isNoSuchAlert(err)
+}
+
+// isNoSuchAlert returns true if err indicates that no user prompt is open.
+func isNoSuchAlert(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Err == "no such alert"
+	}
+	return err != nil && strings.HasPrefix(unwrapElementError(err).Error(), remoteErrors[27])
+}
+
+// checkMarker runs navigationCheckScript. It sends the command itself, rather
+// than with ExecuteScript, so that the script is not counted in the session
+// statistics.
+func (wd *remoteWD) checkMarker() (fresh bool, url string, err error) {
+	data, err := json.Marshal(map[string]interface{}{
+		"script": navigationCheckScript,
+		"args":   []interface{}{navigationMarkerKey, wd.navigation.token},
+	})
+	if err != nil {
+		return false, "", err
+	}
+	suffix := ""
+	if wd.w3cCompatible {
+		suffix = "/sync"
+	}
+	response, err := wd.execute("POST", wd.requestURL("/session/%s/execute"+suffix, wd.id), data)
+	if err != nil {
+		return false, "", err
+	}
+	reply := new(struct{ Value []interface{} })
+	if err := json.Unmarshal(response, reply); err != nil {
+		return false, "", err
+	}
+	if len(reply.Value) != 2 {
+		return false, "", errors.New("unexpected result of the navigation check script")
+	}
+	fresh, _ = reply.Value[0].(bool)
+	url, _ = reply.Value[1].(string)
+	return fresh, url, nil
+}
+
+// isScriptBlocked returns true if err indicates that the remote end could not
+// run a script, e.g. because the Content Security Policy of the page forbids
+// it.
+func isScriptBlocked(err error) bool {
+	var e *Error
+	if errors.As(err, &e) && e.Err == "javascript error" {
+		return true
+	}
+	if err == nil {
+		return false
+	}
+	msg := unwrapElementError(err).Error()
+	return strings.HasPrefix(msg, remoteErrors[17]) || strings.Contains(msg, "Content Security Policy")
+}
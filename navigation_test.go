@@ -0,0 +1,268 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// navigationServer emulates a W3C remote end with two windows, whose
+// documents are replaced when they navigate.
+type navigationServer struct {
+	t       *testing.T
+	windows map[string]*navigationDocument
+	current string
+	// csp makes scripts fail as on a page whose Content Security Policy
+	// forbids them.
+	csp bool
+	// scripts counts the scripts run.
+	scripts int
+	// prompt is the text of the open user prompt, if any.
+	prompt string
+}
+
+type navigationDocument struct {
+	url   string
+	props map[string]interface{}
+}
+
+func newNavigationServer(t *testing.T) *navigationServer {
+	return &navigationServer{
+		t: t,
+		windows: map[string]*navigationDocument{
+			"w1": {url: "about:blank"},
+			"w2": {url: "http://example.com/other"},
+		},
+		current: "w1",
+	}
+}
+
+// navigate replaces the document of the current window.
+func (s *navigationServer) navigate(url string) {
+	s.windows[s.current] = &navigationDocument{url: url}
+}
+
+func (s *navigationServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", JSONType)
+	reply := func(v interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"value": v})
+	}
+	doc := s.windows[s.current]
+	params := make(map[string]interface{})
+	if r.Method == "POST" {
+		json.NewDecoder(r.Body).Decode(&params)
+	}
+	switch path := strings.TrimPrefix(r.URL.Path, "/session/123"); {
+	case path == "/url" && r.Method == "POST":
+		s.navigate(params["url"].(string))
+		reply(nil)
+	case path == "/refresh":
+		s.navigate(doc.url)
+		reply(nil)
+	case path == "/url":
+		reply(doc.url)
+	case path == "/title":
+		reply("Title")
+	case path == "/window":
+		s.current = params["handle"].(string)
+		reply(nil)
+	case path == "/element/link/click":
+		// The page navigates by script.
+		s.navigate("http://example.com/next")
+		reply(nil)
+	case path == "/element/reload/click":
+		s.navigate(doc.url)
+		reply(nil)
+	case path == "/element/tab/click":
+		// The page changes the URL without navigating.
+		doc.url += "#tab"
+		reply(nil)
+	case path == "/element/alert/click":
+		s.prompt = "Are you sure?"
+		reply(nil)
+	case path == "/alert/text" || path == "/alert_text":
+		if s.prompt == "" {
+			w.WriteHeader(http.StatusNotFound)
+			reply(map[string]string{"error": "no such alert", "message": "no such alert"})
+			return
+		}
+		reply(s.prompt)
+	case path == "/accept_alert" || path == "/alert/accept":
+		s.prompt = ""
+		s.navigate("http://example.com/confirmed")
+		reply(nil)
+	case path == "/execute/sync":
+		s.scripts++
+		if s.prompt != "" {
+			// The default unhandledPromptBehavior dismisses the prompt.
+			s.prompt = ""
+			w.WriteHeader(http.StatusInternalServerError)
+			reply(map[string]string{"error": "unexpected alert open", "message": "unexpected alert open"})
+			return
+		}
+		if s.csp {
+			w.WriteHeader(http.StatusInternalServerError)
+			reply(map[string]string{"error": "javascript error", "message": "call to eval() blocked by CSP"})
+			return
+		}
+		if params["script"] != navigationCheckScript {
+			s.t.Errorf("unexpected script %q", params["script"])
+		}
+		args := params["args"].([]interface{})
+		key, token := args[0].(string), args[1]
+		if doc.props == nil {
+			doc.props = make(map[string]interface{})
+		}
+		fresh := doc.props[key] != token
+		doc.props[key] = token
+		reply([]interface{}{fresh, doc.url})
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		reply(nil)
+	}
+}
+
+func TestOnNavigation(t *testing.T) {
+	ns := newNavigationServer(t)
+	s := httptest.NewServer(ns)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	link := &remoteWE{parent: wd, id: "link"}
+	reload := &remoteWE{parent: wd, id: "reload"}
+	tab := &remoteWE{parent: wd, id: "tab"}
+
+	// Without handlers, no scripts are run.
+	if err := wd.Get("http://example.com/"); err != nil {
+		t.Fatalf("wd.Get() returned error: %v", err)
+	}
+	if err := link.Click(); err != nil {
+		t.Fatalf("link.Click() returned error: %v", err)
+	}
+	if ns.scripts != 0 {
+		t.Errorf("%d scripts were run without navigation handlers, want none", ns.scripts)
+	}
+
+	var got []string
+	remove := wd.OnNavigation(func(url string) {
+		got = append(got, url)
+		// Commands sent by handlers do not trigger them.
+		if _, err := wd.Title(); err != nil {
+			t.Errorf("wd.Title() in a handler returned error: %v", err)
+		}
+	})
+	check := func(step string, want ...string) {
+		t.Helper()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("after %s, the handler was called with %q, want %q", step, got, want)
+		}
+		got = nil
+	}
+
+	if _, err := wd.Title(); err != nil {
+		t.Fatalf("wd.Title() returned error: %v", err)
+	}
+	check("the first command")
+	if err := link.Click(); err != nil {
+		t.Fatalf("link.Click() returned error: %v", err)
+	}
+	check("clicking a link", "http://example.com/next")
+	if err := tab.Click(); err != nil {
+		t.Fatalf("tab.Click() returned error: %v", err)
+	}
+	check("changing the URL within the document")
+	if err := wd.Get("http://example.com/a"); err != nil {
+		t.Fatalf("wd.Get() returned error: %v", err)
+	}
+	check("wd.Get()", "http://example.com/a")
+	if err := wd.Refresh(); err != nil {
+		t.Fatalf("wd.Refresh() returned error: %v", err)
+	}
+	check("wd.Refresh()", "http://example.com/a")
+	if err := wd.SwitchWindow("w2"); err != nil {
+		t.Fatalf("wd.SwitchWindow() returned error: %v", err)
+	}
+	if _, err := wd.Title(); err != nil {
+		t.Fatalf("wd.Title() returned error: %v", err)
+	}
+	check("switching to another window")
+	if err := reload.Click(); err != nil {
+		t.Fatalf("reload.Click() returned error: %v", err)
+	}
+	check("reloading by script", "http://example.com/other")
+
+	// Where scripts are blocked, the URL is compared instead.
+	ns.csp = true
+	if _, err := wd.Title(); err != nil {
+		t.Fatalf("wd.Title() returned error: %v", err)
+	}
+	check("the first command on a page blocking scripts")
+	if err := reload.Click(); err != nil {
+		t.Fatalf("reload.Click() returned error: %v", err)
+	}
+	check("reloading without scripts")
+	if err := link.Click(); err != nil {
+		t.Fatalf("link.Click() returned error: %v", err)
+	}
+	check("clicking a link without scripts", "http://example.com/next")
+	if err := tab.Click(); err != nil {
+		t.Fatalf("tab.Click() returned error: %v", err)
+	}
+	check("changing the URL within the document without scripts", "http://example.com/next#tab")
+
+	remove()
+	ns.scripts = 0
+	if err := link.Click(); err != nil {
+		t.Fatalf("link.Click() returned error: %v", err)
+	}
+	check("removing the handler")
+	if ns.scripts != 0 {
+		t.Errorf("%d scripts were run after removing the handler, want none", ns.scripts)
+	}
+}
+
+func TestOnNavigationLeavesPromptsOpen(t *testing.T) {
+	ns := newNavigationServer(t)
+	s := httptest.NewServer(ns)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	button := &remoteWE{parent: wd, id: "alert"}
+
+	var got []string
+	defer wd.OnNavigation(func(url string) { got = append(got, url) })()
+	if _, err := wd.Title(); err != nil {
+		t.Fatalf("wd.Title() returned error: %v", err)
+	}
+	if err := button.Click(); err != nil {
+		t.Fatalf("button.Click() returned error: %v", err)
+	}
+	if text, err := wd.AlertText(); err != nil || text != "Are you sure?" {
+		t.Fatalf("wd.AlertText() = %q, %v, want the text of the open prompt", text, err)
+	}
+	if err := wd.AcceptAlert(); err != nil {
+		t.Fatalf("wd.AcceptAlert() returned error: %v", err)
+	}
+	if want := []string{"http://example.com/confirmed"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("the handler was called with %q, want %q", got, want)
+	}
+}
+
+func TestIsScriptBlocked(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&Error{Err: "javascript error", Message: "eval blocked"}, true},
+		{&Error{Err: "unexpected alert open", Message: "alert"}, false},
+		{fmt.Errorf("%s: eval blocked", remoteErrors[17]), true},
+		{fmt.Errorf("unknown error: Refused to evaluate a string as JavaScript because 'unsafe-eval' is not allowed by the Content Security Policy"), true},
+	} {
+		if got := isScriptBlocked(tc.err); got != tc.want {
+			t.Errorf("isScriptBlocked(%v) = %t, want %t", tc.err, got, tc.want)
+		}
+	}
+}
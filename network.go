@@ -0,0 +1,194 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// networkPollInterval is the interval at which WaitForNetworkIdle checks for
+// network activity.
+const networkPollInterval = 100 * time.Millisecond
+
+// networkTracker tracks in-flight requests reported by the DevTools Network
+// domain.
+type networkTracker struct {
+	ignore       []*regexp.Regexp
+	pending      map[string]string // request ID to URL
+	lastActivity time.Time
+}
+
+func newNetworkTracker(ignore []string, now time.Time) (*networkTracker, error) {
+	t := &networkTracker{
+		pending:      make(map[string]string),
+		lastActivity: now,
+	}
+	for _, pattern := range ignore {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %v", pattern, err)
+		}
+		t.ignore = append(t.ignore, re)
+	}
+	return t, nil
+}
+
+func (t *networkTracker) ignored(url string) bool {
+	// WebSockets stay open for the lifetime of the page.
+	if strings.HasPrefix(url, "ws:") || strings.HasPrefix(url, "wss:") {
+		return true
+	}
+	for _, re := range t.ignore {
+		if re.MatchString(url) {
+			return true
+		}
+	}
+	return false
+}
+
+// observe records a DevTools Network event that happened at time now.
+func (t *networkTracker) observe(method, requestID, url string, now time.Time) {
+	switch method {
+	case "Network.requestWillBeSent":
+		if t.ignored(url) {
+			return
+		}
+		t.pending[requestID] = url
+	case "Network.loadingFinished", "Network.loadingFailed":
+		if _, ok := t.pending[requestID]; !ok {
+			return
+		}
+		delete(t.pending, requestID)
+	default:
+		return
+	}
+	t.lastActivity = now
+}
+
+// idle returns true if no tracked request has been in flight for idleFor.
+func (t *networkTracker) idle(idleFor time.Duration, now time.Time) bool {
+	return len(t.pending) == 0 && now.Sub(t.lastActivity) >= idleFor
+}
+
+// pendingURLs returns the URLs of the requests still in flight.
+func (t *networkTracker) pendingURLs() []string {
+	urls := make([]string, 0, len(t.pending))
+	for _, url := range t.pending {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// observeLogs feeds performance log messages to the tracker.
+func (t *networkTracker) observeLogs(logs []LogMessage, now time.Time) {
+	for _, l := range logs {
+		entry := new(struct {
+			Message struct {
+				Method string
+				Params struct {
+					RequestID string `json:"requestId"`
+					Request   struct {
+						URL string `json:"url"`
+					}
+				}
+			}
+		})
+		if err := json.Unmarshal([]byte(l.Message), entry); err != nil {
+			continue
+		}
+		m := entry.Message
+		t.observe(m.Method, m.Params.RequestID, m.Params.Request.URL, now)
+	}
+}
+
+// networkObserverScript installs a PerformanceObserver that records the
+// completion time of resource entries and returns the entries that completed
+// after the time passed as the first argument.
+const networkObserverScript = `
+var since = arguments[0];
+if (!window.__seleniumNetwork) {
+	var entries = window.__seleniumNetwork = [];
+	if (window.PerformanceObserver) {
+		new PerformanceObserver(function(list) {
+			list.getEntries().forEach(function(e) {
+				entries.push({name: e.name, end: e.responseEnd || e.startTime + e.duration});
+			});
+		}).observe({type: 'resource', buffered: true});
+	}
+}
+return window.__seleniumNetwork.filter(function(e) { return e.end > since; });
+`
+
+func (wd *remoteWD) WaitForNetworkIdle(idleFor, timeout time.Duration, ignore []string) error {
+	start := time.Now()
+	t, err := newNetworkTracker(ignore, start)
+	if err != nil {
+		return err
+	}
+	deadline := start.Add(timeout)
+
+	// Chromium-based drivers report network events in the performance log,
+	// if enabled in the capabilities.
+	if logs, err := wd.Log(Performance); err == nil {
+		for {
+			now := time.Now()
+			t.observeLogs(logs, now)
+			if t.idle(idleFor, now) {
+				return nil
+			}
+			if now.After(deadline) {
+				return fmt.Errorf("timeout after %s waiting for network idle; pending requests: %s", timeout, strings.Join(t.pendingURLs(), ", "))
+			}
+			time.Sleep(networkPollInterval)
+			if logs, err = wd.Log(Performance); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Otherwise, fall back to a heuristic: the network is considered idle if no
+	// resource finished loading within idleFor. Requests that are in flight
+	// cannot be observed this way.
+	var since float64
+	var recent []string
+	for {
+		raw, err := wd.ExecuteScriptRaw(networkObserverScript, []interface{}{since})
+		if err != nil {
+			return err
+		}
+		reply := new(struct {
+			Value []struct {
+				Name string
+				End  float64
+			}
+		})
+		if err := json.Unmarshal(raw, reply); err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, e := range reply.Value {
+			if e.End > since {
+				since = e.End
+			}
+			if t.ignored(e.Name) {
+				continue
+			}
+			recent = append(recent, e.Name)
+			t.lastActivity = now
+		}
+		if t.idle(idleFor, now) {
+			return nil
+		}
+		if now.After(deadline) {
+			return fmt.Errorf("timeout after %s waiting for network idle; recently loaded resources: %s", timeout, strings.Join(recent, ", "))
+		}
+		if len(recent) > 10 {
+			recent = recent[len(recent)-10:]
+		}
+		time.Sleep(networkPollInterval)
+	}
+}
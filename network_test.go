@@ -0,0 +1,56 @@
+package selenium
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func perfLog(method, requestID, url string) LogMessage {
+	return LogMessage{
+		Message: `{"message":{"method":"` + method + `","params":{"requestId":"` + requestID + `","request":{"url":"` + url + `"}}}}`,
+	}
+}
+
+func TestNetworkTracker(t *testing.T) {
+	start := time.Unix(0, 0)
+	tracker, err := newNetworkTracker([]string{`/analytics/`}, start)
+	if err != nil {
+		t.Fatalf("newNetworkTracker() returned error: %v", err)
+	}
+	const idleFor = 500 * time.Millisecond
+
+	tracker.observeLogs([]LogMessage{
+		perfLog("Network.requestWillBeSent", "1", "http://example.com/api"),
+		perfLog("Network.requestWillBeSent", "2", "http://example.com/analytics/beacon"),
+		perfLog("Network.requestWillBeSent", "3", "wss://example.com/socket"),
+		perfLog("Network.requestWillBeSent", "4", "http://example.com/image.png"),
+		{Message: "not JSON"},
+	}, start)
+	if got, want := tracker.pendingURLs(), []string{"http://example.com/api", "http://example.com/image.png"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("tracker.pendingURLs() = %v, want %v", got, want)
+	}
+
+	now := start.Add(time.Second)
+	tracker.observeLogs([]LogMessage{
+		perfLog("Network.loadingFinished", "1", ""),
+		perfLog("Network.loadingFailed", "4", ""),
+		// Completion of an ignored request is not activity.
+		perfLog("Network.loadingFinished", "2", ""),
+	}, now)
+	if len(tracker.pendingURLs()) != 0 {
+		t.Fatalf("tracker.pendingURLs() = %v, want none", tracker.pendingURLs())
+	}
+	if tracker.idle(idleFor, now.Add(idleFor/2)) {
+		t.Error("tracker.idle() = true before idleFor elapsed")
+	}
+	if !tracker.idle(idleFor, now.Add(idleFor)) {
+		t.Error("tracker.idle() = false after idleFor elapsed")
+	}
+}
+
+func TestNetworkTrackerInvalidPattern(t *testing.T) {
+	if _, err := newNetworkTracker([]string{"("}, time.Now()); err == nil {
+		t.Fatal(`newNetworkTracker([]string{"("}) returned nil error`)
+	}
+}
@@ -0,0 +1,232 @@
+package selenium
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cursor records the last page that a Paginator completed.
+type Cursor struct {
+	// URL is the URL of the page.
+	URL string `json:"url"`
+	// Index is the index of the page, starting at zero.
+	Index int `json:"index"`
+}
+
+// CursorStore persists the cursor of a Paginator, so that an interrupted run
+// can be resumed.
+type CursorStore interface {
+	// Load returns the stored cursor, or nil if none has been stored.
+	Load() (*Cursor, error)
+	// Save stores the cursor.
+	Save(c *Cursor) error
+}
+
+// FileCursorStore is a CursorStore that keeps the cursor in a JSON file.
+type FileCursorStore struct {
+	Path string
+}
+
+func (s FileCursorStore) Load() (*Cursor, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := new(Cursor)
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("reading the cursor from %s: %v", s.Path, err)
+	}
+	return c, nil
+}
+
+// Save replaces the file atomically, so that a crash cannot leave a partial
+// cursor behind.
+func (s FileCursorStore) Save(c *Cursor) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(s.Path), "."+filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.Path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// DefaultNavigationTimeout is the default NavigationTimeout of a Paginator.
+const DefaultNavigationTimeout = 10 * time.Second
+
+// Paginator extracts data from a sequence of pages, such as search results,
+// saving its progress after each page so that it can resume where it left
+// off.
+//
+// The next page is found either by clicking the element located by Next or,
+// if NextURL is set, by navigating to the URL it returns. Pagination ends
+// when there is no next page, when MaxPages pages have been extracted, or
+// with an error if a page is visited twice, which indicates that the next
+// page control does not lead anywhere new.
+type Paginator struct {
+	// Start is the URL of the first page.
+	Start string
+	// Next locates the element to click to reach the next page. There is no
+	// next page if it is not found or is disabled. The page must change URL
+	// within NavigationTimeout of the click.
+	Next Locator
+	// NavigationTimeout is how long to wait for the URL to change after
+	// clicking the Next element, as the click may return before the next page
+	// is loaded, e.g. if it navigates by script. It defaults to
+	// DefaultNavigationTimeout.
+	NavigationTimeout time.Duration
+	// NextURL, if set, returns the URL of the page following the page with
+	// the given index and URL, and false if it is the last page.
+	NextURL func(index int, url string) (string, bool)
+	// Extract is called on each page, with its index.
+	Extract func(wd WebDriver, index int) error
+	// Store, if set, persists the cursor of the paginator.
+	Store CursorStore
+	// MaxPages, if positive, is the number of pages after which pagination
+	// ends, counting those extracted before resuming.
+	MaxPages int
+}
+
+// Run extracts the pages, starting from the page following the cursor in
+// Store, if any, or else from Start. It returns when pagination ends, with
+// an error if it failed or ctx was done.
+func (p *Paginator) Run(ctx context.Context, wd WebDriver) error {
+	if p.Extract == nil {
+		return fmt.Errorf("the paginator has no Extract function")
+	}
+	if p.NextURL == nil && p.Next.Value == "" {
+		return fmt.Errorf("the paginator has neither a Next locator nor a NextURL function")
+	}
+
+	index := 0
+	if p.Store != nil {
+		cursor, err := p.Store.Load()
+		if err != nil {
+			return fmt.Errorf("loading the cursor: %v", err)
+		}
+		if cursor != nil {
+			if p.MaxPages > 0 && cursor.Index+1 >= p.MaxPages {
+				return nil
+			}
+			if err := wd.Get(cursor.URL); err != nil {
+				return fmt.Errorf("resuming at %s: %v", cursor.URL, err)
+			}
+			more, err := p.advance(ctx, wd, cursor.Index, cursor.URL)
+			if err != nil || !more {
+				return err
+			}
+			index = cursor.Index + 1
+		}
+	}
+	if index == 0 {
+		if err := wd.Get(p.Start); err != nil {
+			return fmt.Errorf("loading the first page: %v", err)
+		}
+	}
+
+	visited := make(map[string]bool)
+	for ; p.MaxPages <= 0 || index < p.MaxPages; index++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		url, err := wd.CurrentURL()
+		if err != nil {
+			return fmt.Errorf("page %d: %v", index, err)
+		}
+		if visited[url] {
+			return fmt.Errorf("page %d: %s was already visited; the next page control leads back", index, url)
+		}
+		visited[url] = true
+
+		if err := p.Extract(wd, index); err != nil {
+			return fmt.Errorf("extracting page %d (%s): %v", index, url, err)
+		}
+		if p.Store != nil {
+			if err := p.Store.Save(&Cursor{URL: url, Index: index}); err != nil {
+				return fmt.Errorf("saving the cursor after page %d: %v", index, err)
+			}
+		}
+		if p.MaxPages > 0 && index+1 >= p.MaxPages {
+			break
+		}
+		more, err := p.advance(ctx, wd, index, url)
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// advance navigates from the page with the given index and URL to the next
+// page, and returns false if there is none.
+func (p *Paginator) advance(ctx context.Context, wd WebDriver, index int, url string) (bool, error) {
+	if p.NextURL != nil {
+		next, ok := p.NextURL(index, url)
+		if !ok {
+			return false, nil
+		}
+		if err := wd.Get(next); err != nil {
+			return false, fmt.Errorf("loading page %d (%s): %v", index+1, next, err)
+		}
+		return true, nil
+	}
+
+	elems, err := wd.FindAll(p.Next)
+	if err != nil {
+		return false, fmt.Errorf("finding the next page control on page %d: %v", index, err)
+	}
+	if len(elems) == 0 {
+		return false, nil
+	}
+	if enabled, err := elems[0].IsEnabled(); err != nil {
+		return false, err
+	} else if !enabled {
+		return false, nil
+	}
+	if err := elems[0].Click(); err != nil {
+		return false, fmt.Errorf("clicking the next page control on page %d: %v", index, err)
+	}
+
+	timeout := p.NavigationTimeout
+	if timeout <= 0 {
+		timeout = DefaultNavigationTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		current, err := wd.CurrentURL()
+		if err != nil {
+			return false, fmt.Errorf("page %d: %v", index+1, err)
+		}
+		if current != url {
+			return true, nil
+		}
+		if !time.Now().Before(deadline) {
+			return false, fmt.Errorf("page %d: the URL is still %s %v after clicking the next page control", index, url, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(DefaultWaitInterval):
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package selenium
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// paginatedSite serves pages 1 to n, each linking to the next one with a
+// link with the ID "next", except for the last page. If loopAt is positive,
+// the page with that number links back to the first page.
+func paginatedSite(n, loopAt int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var page int
+		if _, err := fmt.Sscanf(r.URL.Path, "/page/%d", &page); err != nil || page < 1 || page > n {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `<h1 class="title">Page %d</h1>`, page)
+		switch {
+		case page == loopAt:
+			fmt.Fprint(w, `<a id="next" href="/page/1">next</a>`)
+		case page < n:
+			fmt.Fprintf(w, `<a id="next" href="/page/%d">next</a>`, page+1)
+		}
+	}))
+}
+
+var nextLinkRE = regexp.MustCompile(`<a id="next" href="([^"]*)"`)
+
+// linkBrowser is a remote end whose browser fetches pages over HTTP and can
+// find and follow the link with the ID "next".
+type linkBrowser struct {
+	t    *testing.T
+	url  string
+	page string
+	gets int
+	// lag is the number of times that the URL of the previous page is
+	// reported after following the link, as when it navigates by script.
+	lag int
+	// stale is the number of times left to report from, the URL of the
+	// previous page.
+	stale int
+	from  string
+	// stuck makes following the link do nothing.
+	stuck bool
+}
+
+func (b *linkBrowser) load(u string) {
+	b.url = u
+	b.page = ""
+	resp, err := http.Get(u)
+	if err != nil {
+		b.t.Errorf("fetching %s: %v", u, err)
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	b.page = string(body)
+}
+
+func (b *linkBrowser) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", JSONType)
+	reply := func(v interface{}) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, `{"value":%s}`, data)
+	}
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/session/123/url":
+		var params struct{ URL string }
+		json.NewDecoder(r.Body).Decode(&params)
+		b.gets++
+		b.load(params.URL)
+		reply(nil)
+	case r.Method == "GET" && r.URL.Path == "/session/123/url":
+		if b.stale > 0 {
+			b.stale--
+			reply(b.from)
+			return
+		}
+		reply(b.url)
+	case r.URL.Path == "/session/123/elements":
+		if nextLinkRE.MatchString(b.page) {
+			reply([]map[string]string{{webElementIdentifier: "next"}})
+		} else {
+			reply([]string{})
+		}
+	case r.URL.Path == "/session/123/element/next/enabled":
+		reply(true)
+	case r.URL.Path == "/session/123/element/next/click":
+		if b.stuck {
+			reply(nil)
+			return
+		}
+		b.from, b.stale = b.url, b.lag
+		m := nextLinkRE.FindStringSubmatch(b.page)
+		u := b.url[:strings.Index(b.url, "/page/")] + m[1]
+		b.load(u)
+		reply(nil)
+	default:
+		b.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}
+}
+
+func TestPaginator(t *testing.T) {
+	site := paginatedSite(5, 0)
+	defer site.Close()
+	b := &linkBrowser{t: t}
+	s := httptest.NewServer(b)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	dir, err := ioutil.TempDir("", "paginator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := FileCursorStore{Path: filepath.Join(dir, "cursor.json")}
+
+	var extracted []string
+	p := &Paginator{
+		Start: site.URL + "/page/1",
+		Next:  ID("next"),
+		Extract: func(wd WebDriver, index int) error {
+			u, _ := wd.CurrentURL()
+			extracted = append(extracted, fmt.Sprintf("%d:%s", index, strings.TrimPrefix(u, site.URL)))
+			return nil
+		},
+		Store:    store,
+		MaxPages: 3,
+	}
+	if err := p.Run(context.Background(), wd); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+	if got := strings.Join(extracted, " "); got != "0:/page/1 1:/page/2 2:/page/3" {
+		t.Errorf("Run() with MaxPages 3 extracted %s", got)
+	}
+	if c, err := store.Load(); err != nil || c == nil || c.Index != 2 || c.URL != site.URL+"/page/3" {
+		t.Errorf("the stored cursor is %+v, %v; want page 3 at index 2", c, err)
+	}
+
+	// A new run resumes after the stored cursor, navigating to it directly,
+	// and ends at the last page.
+	extracted, b.gets = nil, 0
+	p.MaxPages = 0
+	if err := p.Run(context.Background(), wd); err != nil {
+		t.Fatalf("resumed Run() returned error: %v", err)
+	}
+	if got := strings.Join(extracted, " "); got != "3:/page/4 4:/page/5" {
+		t.Errorf("resumed Run() extracted %s", got)
+	}
+	if b.gets != 1 {
+		t.Errorf("resumed Run() navigated %d times, want once, to the cursor", b.gets)
+	}
+
+	// Once complete, there is nothing left to extract.
+	extracted = nil
+	if err := p.Run(context.Background(), wd); err != nil || len(extracted) != 0 {
+		t.Errorf("Run() of a completed pagination extracted %v and returned error %v", extracted, err)
+	}
+}
+
+func TestPaginatorNextURL(t *testing.T) {
+	site := paginatedSite(10, 0)
+	defer site.Close()
+	b := &linkBrowser{t: t}
+	s := httptest.NewServer(b)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	var indexes []int
+	p := &Paginator{
+		Start: site.URL + "/page/1",
+		NextURL: func(index int, url string) (string, bool) {
+			return fmt.Sprintf("%s/page/%d", site.URL, index+2), index < 3
+		},
+		Extract: func(wd WebDriver, index int) error {
+			indexes = append(indexes, index)
+			return nil
+		},
+	}
+	if err := p.Run(context.Background(), wd); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+	if fmt.Sprint(indexes) != "[0 1 2 3]" {
+		t.Errorf("Run() extracted pages %v, want [0 1 2 3]", indexes)
+	}
+}
+
+func TestPaginatorLoop(t *testing.T) {
+	site := paginatedSite(5, 3)
+	defer site.Close()
+	s := httptest.NewServer(&linkBrowser{t: t})
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	pages := 0
+	p := &Paginator{
+		Start:   site.URL + "/page/1",
+		Next:    CSS("#next"),
+		Extract: func(WebDriver, int) error { pages++; return nil },
+	}
+	err := p.Run(context.Background(), wd)
+	if err == nil || !strings.Contains(err.Error(), "already visited") {
+		t.Errorf("Run() of looping pages returned error %v, want already visited", err)
+	}
+	if pages != 3 {
+		t.Errorf("Run() of looping pages extracted %d pages, want 3", pages)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Run(ctx, wd); err != context.Canceled {
+		t.Errorf("Run() with a canceled context returned error %v, want context.Canceled", err)
+	}
+}
+
+func TestPaginatorWaitsForNavigation(t *testing.T) {
+	site := paginatedSite(3, 0)
+	defer site.Close()
+	b := &linkBrowser{t: t, lag: 2}
+	s := httptest.NewServer(b)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	var extracted []string
+	p := &Paginator{
+		Start: site.URL + "/page/1",
+		Next:  ID("next"),
+		Extract: func(wd WebDriver, index int) error {
+			u, _ := wd.CurrentURL()
+			extracted = append(extracted, fmt.Sprintf("%d:%s", index, strings.TrimPrefix(u, site.URL)))
+			return nil
+		},
+	}
+	if err := p.Run(context.Background(), wd); err != nil {
+		t.Fatalf("Run() with a lagging URL returned error: %v", err)
+	}
+	if got := strings.Join(extracted, " "); got != "0:/page/1 1:/page/2 2:/page/3" {
+		t.Errorf("Run() with a lagging URL extracted %s", got)
+	}
+
+	// A next page control that does nothing times out.
+	b.lag, b.stuck = 0, true
+	p.NavigationTimeout = 300 * time.Millisecond
+	err := p.Run(context.Background(), wd)
+	if err == nil || !strings.Contains(err.Error(), "is still") {
+		t.Errorf("Run() with a next page control that does nothing returned error %v, want a timeout", err)
+	}
+}
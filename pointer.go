@@ -0,0 +1,152 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PointerPrecision controls how the target coordinates of pointer
+// interactions with elements are determined.
+type PointerPrecision int
+
+const (
+	// DefaultPointerPrecision lets the driver compute the in-view center point
+	// of elements from their bounding rectangle.
+	DefaultPointerPrecision PointerPrecision = iota
+	// UseElementFromPoint verifies, before clicking or moving to an element,
+	// that document.elementFromPoint at the element's center returns the
+	// element or one of its descendants. If it does not, as can happen for
+	// elements within CSS-transformed or zoomed containers, a spiral of points
+	// within the element's bounding rectangle is searched for one that does,
+	// and the pointer interaction is performed at that point instead.
+	UseElementFromPoint
+)
+
+// elementPointScript scrolls the element passed as the first argument into
+// view and returns a point in viewport coordinates at which
+// document.elementFromPoint hits the element or one of its descendants. If no
+// such point is found, ok is false and occupant describes the element found at
+// the center of the element's bounding rectangle.
+const elementPointScript = `
+var elem = arguments[0];
+elem.scrollIntoView({block: 'center', inline: 'center'});
+var r = elem.getBoundingClientRect();
+var cx = r.left + r.width / 2, cy = r.top + r.height / 2;
+function hits(x, y) {
+	var e = document.elementFromPoint(x, y);
+	return !!e && (e === elem || elem.contains(e));
+}
+function describe(e) {
+	if (!e) {
+		return 'nothing';
+	}
+	var s = e.tagName.toLowerCase();
+	if (e.id) {
+		s += '#' + e.id;
+	}
+	if (typeof e.className === 'string' && e.className) {
+		s += '.' + e.className.trim().split(/\s+/).join('.');
+	}
+	return s;
+}
+var result = {ok: false, center: true, x: cx, y: cy, left: r.left, top: r.top,
+	occupant: describe(document.elementFromPoint(cx, cy))};
+if (hits(cx, cy)) {
+	result.ok = true;
+	return result;
+}
+// Walk an outward spiral of candidate points within the rectangle.
+var step = Math.max(1, Math.min(r.width, r.height) / 10);
+var maxRadius = Math.max(r.width, r.height) / 2;
+for (var radius = step; radius <= maxRadius; radius += step) {
+	for (var angle = 0; angle < 2 * Math.PI; angle += Math.PI / 8) {
+		var x = cx + radius * Math.cos(angle), y = cy + radius * Math.sin(angle);
+		if (x < r.left || x > r.right || y < r.top || y > r.bottom) {
+			continue;
+		}
+		if (hits(x, y)) {
+			result.ok = true;
+			result.center = false;
+			result.x = x;
+			result.y = y;
+			return result;
+		}
+	}
+}
+return result;
+`
+
+type elementPoint struct {
+	OK        bool
+	Center    bool
+	X, Y      float64
+	Left, Top float64
+	Occupant  string
+}
+
+func (wd *remoteWD) SetPointerPrecision(p PointerPrecision) {
+	wd.pointerPrecision = p
+}
+
+// hitPoint returns the point at which pointer interactions with the element
+// should be performed when the UseElementFromPoint precision is in effect.
+func (elem *remoteWE) hitPoint() (*elementPoint, error) {
+	raw, err := elem.parent.ExecuteScriptRaw(elementPointScript, []interface{}{elem})
+	if err != nil {
+		return nil, elem.noteError(err)
+	}
+	reply := new(struct{ Value *elementPoint })
+	if err := json.Unmarshal(raw, reply); err != nil {
+		return nil, err
+	}
+	if reply.Value == nil {
+		return nil, fmt.Errorf("nil return value")
+	}
+	if !reply.Value.OK {
+		return nil, fmt.Errorf("no point within the element's bounding rectangle hits the element; its center (%.0f, %.0f) is occupied by %s", reply.Value.X, reply.Value.Y, reply.Value.Occupant)
+	}
+	return reply.Value, nil
+}
+
+// pointerAt moves the mouse to the point p and, if click is true, clicks the
+// left mouse button there.
+func (elem *remoteWE) pointerAt(p *elementPoint, click bool) error {
+	wd := elem.parent
+	if !wd.w3cCompatible {
+		// The legacy protocol's offsets are relative to the top-left corner of
+		// the element.
+		if err := wd.voidCommand("/session/%s/moveto", map[string]interface{}{
+			"element": elem.id,
+			"xoffset": int(p.X - p.Left),
+			"yoffset": int(p.Y - p.Top),
+		}); err != nil {
+			return err
+		}
+		if !click {
+			return nil
+		}
+		return wd.Click(LeftButton)
+	}
+
+	actions := []map[string]interface{}{{
+		"type":     "pointerMove",
+		"duration": 0,
+		"origin":   "viewport",
+		"x":        int(p.X),
+		"y":        int(p.Y),
+	}}
+	if click {
+		actions = append(actions,
+			map[string]interface{}{"type": "pointerDown", "button": LeftButton},
+			map[string]interface{}{"type": "pointerUp", "button": LeftButton})
+	}
+	return wd.voidCommand("/session/%s/actions", map[string]interface{}{
+		"actions": []interface{}{
+			map[string]interface{}{
+				"type":       "pointer",
+				"id":         defaultMouseID,
+				"parameters": map[string]string{"pointerType": MousePointer},
+				"actions":    actions,
+			}},
+	})
+}
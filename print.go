@@ -0,0 +1,103 @@
+package selenium
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Page orientations, for PrintOptions.
+const (
+	PrintPortrait  = "portrait"
+	PrintLandscape = "landscape"
+)
+
+// PrintOptions configure PrintPage. Zero values are left for the remote end
+// to choose; the W3C specification defaults to an unscaled portrait US
+// Letter page (21.59cm by 27.94cm) with 1cm margins, without backgrounds and
+// shrunk to fit.
+type PrintOptions struct {
+	// Orientation is PrintPortrait or PrintLandscape.
+	Orientation string `json:"orientation,omitempty"`
+	// Scale is the scale of the page, between 0.1 and 2.
+	Scale float64 `json:"scale,omitempty"`
+	// Background enables printing background colors and images.
+	Background bool `json:"background,omitempty"`
+	// Page is the size of the paper.
+	Page *PrintPageSize `json:"page,omitempty"`
+	// Margin is the margins of the paper.
+	Margin *PrintMargin `json:"margin,omitempty"`
+	// PageRanges are the pages to print, e.g. "1-3" or "5". All pages are
+	// printed if empty.
+	PageRanges []string `json:"pageRanges,omitempty"`
+	// ShrinkToFit, if set to false, prevents the page from being shrunk to
+	// fit the width of the paper.
+	ShrinkToFit *bool `json:"shrinkToFit,omitempty"`
+}
+
+// PrintPageSize is the size of the paper, in centimeters.
+type PrintPageSize struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// PrintMargin is the margins of the paper, in centimeters.
+type PrintMargin struct {
+	Top    float64 `json:"top"`
+	Bottom float64 `json:"bottom"`
+	Left   float64 `json:"left"`
+	Right  float64 `json:"right"`
+}
+
+// validate checks the options against the constraints of the W3C
+// specification.
+func (o *PrintOptions) validate() error {
+	switch o.Orientation {
+	case "", PrintPortrait, PrintLandscape:
+	default:
+		return fmt.Errorf("invalid orientation %q: must be %q or %q", o.Orientation, PrintPortrait, PrintLandscape)
+	}
+	if o.Scale != 0 && (o.Scale < 0.1 || o.Scale > 2) {
+		return fmt.Errorf("invalid scale %v: must be between 0.1 and 2", o.Scale)
+	}
+	// The specification requires the paper to be at least 1/72 inch square.
+	const minSize = 2.54 / 72
+	if p := o.Page; p != nil && (p.Width < minSize || p.Height < minSize) {
+		return fmt.Errorf("invalid page size %vcm by %vcm: must be at least %.4fcm by %.4fcm", p.Width, p.Height, minSize, minSize)
+	}
+	if m := o.Margin; m != nil && (m.Top < 0 || m.Bottom < 0 || m.Left < 0 || m.Right < 0) {
+		return fmt.Errorf("invalid margins %+v: must not be negative", *m)
+	}
+	return nil
+}
+
+func (wd *remoteWD) PrintPage(opts *PrintOptions) ([]byte, error) {
+	if opts == nil {
+		opts = new(PrintOptions)
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+	// Remote ends that do not implement the command answer with an unknown
+	// command error, which is returned as it is.
+	response, err := wd.execute("POST", wd.requestURL("/session/%s/print", wd.id), data)
+	if err != nil {
+		return nil, err
+	}
+	reply := new(struct{ Value *string })
+	if err := decodeReply(response, reply, wd.strictDecoding, "/session/%s/print", wd.id); err != nil {
+		return nil, err
+	}
+	if reply.Value == nil {
+		return nil, nullValueError("/session/%s/print", wd.id)
+	}
+	pdf, err := base64.StdEncoding.DecodeString(*reply.Value)
+	if err != nil {
+		return nil, fmt.Errorf("decoding the PDF: %v", err)
+	}
+	return pdf, nil
+}
@@ -0,0 +1,69 @@
+package selenium
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPrintPage(t *testing.T) {
+	var body string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		if r.Method != "POST" || r.URL.Path != "/session/123/print" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"unknown command","message":"unknown command: print"}}`)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		fmt.Fprintf(w, `{"value":%q}`, base64.StdEncoding.EncodeToString([]byte("%PDF-1.4 invoice")))
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	shrink := false
+	pdf, err := wd.PrintPage(&PrintOptions{
+		Orientation: PrintLandscape,
+		Scale:       0.5,
+		Background:  true,
+		Page:        &PrintPageSize{Width: 21, Height: 29.7},
+		Margin:      &PrintMargin{Top: 0, Bottom: 1, Left: 2, Right: 2},
+		PageRanges:  []string{"1-2", "4"},
+		ShrinkToFit: &shrink,
+	})
+	if err != nil {
+		t.Fatalf("PrintPage() returned error: %v", err)
+	}
+	if string(pdf) != "%PDF-1.4 invoice" {
+		t.Errorf("PrintPage() = %q, want the decoded PDF", pdf)
+	}
+	want := `{"orientation":"landscape","scale":0.5,"background":true,"page":{"width":21,"height":29.7},` +
+		`"margin":{"top":0,"bottom":1,"left":2,"right":2},"pageRanges":["1-2","4"],"shrinkToFit":false}`
+	if body != want {
+		t.Errorf("PrintPage() sent\n%s\nwant\n%s", body, want)
+	}
+
+	if _, err := wd.PrintPage(nil); err != nil || body != "{}" {
+		t.Errorf("PrintPage(nil) sent %s and returned error %v, want {} and nil", body, err)
+	}
+
+	for _, opts := range []*PrintOptions{
+		{Orientation: "sideways"},
+		{Scale: 3},
+		{Page: &PrintPageSize{Width: 0, Height: 10}},
+		{Margin: &PrintMargin{Left: -1}},
+	} {
+		if _, err := wd.PrintPage(opts); err == nil {
+			t.Errorf("PrintPage(%+v) returned nil error", opts)
+		}
+	}
+
+	wd.id = "456"
+	if _, err := wd.PrintPage(nil); !isUnknownCommand(err) {
+		t.Errorf("PrintPage() on a remote end without the command returned error %v, want unknown command", err)
+	}
+}
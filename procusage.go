@@ -0,0 +1,52 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotSupported is returned by Service.ResourceUsage on platforms where
+// the resource usage of processes cannot be read.
+var ErrNotSupported = errors.New("not supported on this platform")
+
+// ProcUsage is the resource usage of a process and its descendants.
+type ProcUsage struct {
+	// PID is the process ID.
+	PID int
+	// Name is the name of the executable of the process.
+	Name string
+	// RSS is the resident set size of the process, in bytes.
+	RSS uint64
+	// CPUTime is the CPU time the process has used, in user and system mode.
+	CPUTime time.Duration
+	// OpenFiles is the number of open file descriptors of the process, or -1
+	// if they cannot be read.
+	OpenFiles int
+	// Children is the usage of the child processes, such as the browsers
+	// started by a driver. It is collected on a best-effort basis: processes
+	// that exit while it is collected are left out.
+	Children []*ProcUsage
+}
+
+// Total returns the sum of the RSS and CPU time of the process and its
+// descendants.
+func (u *ProcUsage) Total() (rss uint64, cpu time.Duration) {
+	rss, cpu = u.RSS, u.CPUTime
+	for _, c := range u.Children {
+		childRSS, childCPU := c.Total()
+		rss += childRSS
+		cpu += childCPU
+	}
+	return rss, cpu
+}
+
+// ResourceUsage returns the resource usage of the driver process started by
+// the service, and of the processes it started, such as browsers. It returns
+// ErrNotSupported on platforms other than Linux and macOS.
+func (s *Service) ResourceUsage() (*ProcUsage, error) {
+	if s.cmd == nil || s.cmd.Process == nil {
+		return nil, fmt.Errorf("the service has not been started")
+	}
+	return procUsage(s.cmd.Process.Pid)
+}
@@ -0,0 +1,149 @@
+package selenium
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// psProcess holds the fields of a process listed by ps(1) used for
+// ProcUsage. Reading them directly needs libproc, which is only reachable
+// through cgo.
+type psProcess struct {
+	pid  int
+	ppid int
+	rss  uint64
+	cpu  time.Duration
+	name string
+}
+
+// parsePS parses the output of "ps -A -o pid=,ppid=,rss=,time=,comm=".
+func parsePS(out string) (map[int]*psProcess, error) {
+	procs := make(map[int]*psProcess)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("unexpected output of ps: %q", line)
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected output of ps: %q", line)
+		}
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected output of ps: %q", line)
+		}
+		rss, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected output of ps: %q", line)
+		}
+		cpu, err := parseCPUTime(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected output of ps: %q", line)
+		}
+		// The command is the path of the executable, which may itself
+		// contain spaces.
+		procs[pid] = &psProcess{
+			pid:  pid,
+			ppid: ppid,
+			rss:  rss * 1024,
+			cpu:  cpu,
+			name: filepath.Base(strings.Join(fields[4:], " ")),
+		}
+	}
+	return procs, nil
+}
+
+// parseCPUTime parses a CPU time printed by ps, "[[dd-]hh:]mm:ss[.cc]".
+// The minutes of macOS are not limited to 59.
+func parseCPUTime(s string) (time.Duration, error) {
+	var days int
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		d, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, err
+		}
+		days, s = d, s[i+1:]
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid CPU time %q", s)
+	}
+	secs, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return 0, err
+	}
+	t := time.Duration(secs*float64(time.Second)) + time.Duration(days)*24*time.Hour
+	for i, unit := range []time.Duration{time.Minute, time.Hour}[:len(parts)-1] {
+		n, err := strconv.Atoi(parts[len(parts)-2-i])
+		if err != nil {
+			return 0, err
+		}
+		t += time.Duration(n) * unit
+	}
+	return t, nil
+}
+
+// openFiles counts the open file descriptors of a process with lsof(8). It
+// returns -1 if they cannot be listed.
+func openFiles(pid int) int {
+	out, err := exec.Command("lsof", "-n", "-P", "-p", strconv.Itoa(pid), "-F", "f").Output()
+	if err != nil {
+		return -1
+	}
+	n := 0
+	for _, line := range strings.Split(string(out), "\n") {
+		// Descriptors are numbered; the others are the current directory,
+		// the executable, mapped files and so on.
+		if len(line) > 1 && line[0] == 'f' && line[1] >= '0' && line[1] <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+func procUsage(pid int) (*ProcUsage, error) {
+	out, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,rss=,time=,comm=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing the processes: %v", err)
+	}
+	procs, err := parsePS(string(out))
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[int][]int)
+	for _, p := range procs {
+		children[p.ppid] = append(children[p.ppid], p.pid)
+	}
+	for _, c := range children {
+		sort.Ints(c)
+	}
+	return psTreeUsage(pid, procs, children)
+}
+
+func psTreeUsage(pid int, procs map[int]*psProcess, children map[int][]int) (*ProcUsage, error) {
+	p, ok := procs[pid]
+	if !ok {
+		return nil, fmt.Errorf("no process with ID %d", pid)
+	}
+	u := &ProcUsage{
+		PID:       pid,
+		Name:      p.name,
+		RSS:       p.rss,
+		CPUTime:   p.cpu,
+		OpenFiles: openFiles(pid),
+	}
+	for _, child := range children[pid] {
+		if c, err := psTreeUsage(child, procs, children); err == nil {
+			u.Children = append(u.Children, c)
+		}
+	}
+	return u, nil
+}
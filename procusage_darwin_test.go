@@ -0,0 +1,54 @@
+package selenium
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePS(t *testing.T) {
+	out := `    1     0  13024   1:02.50 /sbin/launchd
+  501     1  40960   0:00.12 /usr/local/bin/chromedriver
+  502   501 204800 12-01:00:03 /Applications/Google Chrome.app/Contents/MacOS/Google Chrome
+`
+	procs, err := parsePS(out)
+	if err != nil {
+		t.Fatalf("parsePS() returned error: %v", err)
+	}
+	if len(procs) != 3 {
+		t.Fatalf("parsePS() = %d processes, want 3", len(procs))
+	}
+	driver := procs[501]
+	if driver.ppid != 1 || driver.rss != 40960*1024 || driver.cpu != 120*time.Millisecond || driver.name != "chromedriver" {
+		t.Errorf("parsePS() driver = %+v", driver)
+	}
+	browser := procs[502]
+	if want := 12*24*time.Hour + time.Hour + 3*time.Second; browser.cpu != want || browser.name != "Google Chrome" {
+		t.Errorf("parsePS() browser = %+v, want CPU time %s", browser, want)
+	}
+	if got := procs[1].cpu; got != time.Minute+2500*time.Millisecond {
+		t.Errorf("parsePS() launchd CPU time = %s, want 1m2.5s", got)
+	}
+
+	if _, err := parsePS("501 1 x 0:00.12 chromedriver\n"); err == nil {
+		t.Errorf("parsePS() of a malformed line returned nil error")
+	}
+}
+
+func TestParseCPUTime(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"0:00.03", 30 * time.Millisecond},
+		{"125:07.10", 125*time.Minute + 7100*time.Millisecond},
+		{"01:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+		{"2-00:00:01", 48*time.Hour + time.Second},
+	} {
+		if got, err := parseCPUTime(tc.in); err != nil || got != tc.want {
+			t.Errorf("parseCPUTime(%q) = %s, %v, want %s", tc.in, got, err, tc.want)
+		}
+	}
+	if _, err := parseCPUTime("12"); err == nil {
+		t.Errorf("parseCPUTime(%q) returned nil error", "12")
+	}
+}
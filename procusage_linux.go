@@ -0,0 +1,101 @@
+package selenium
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is the unit of the CPU times in /proc, USER_HZ, which is 100
+// on all supported architectures.
+const clockTicks = 100
+
+// procStat holds the fields of /proc/[pid]/stat used for ProcUsage.
+type procStat struct {
+	name     string
+	ppid     int
+	utime    uint64
+	stime    uint64
+	rssPages uint64
+}
+
+// readProcStat reads /proc/[pid]/stat.
+func readProcStat(pid int) (*procStat, error) {
+	data, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return nil, err
+	}
+	// The name is in parentheses, and may itself contain spaces and
+	// parentheses.
+	s := string(data)
+	open, end := strings.IndexByte(s, '('), strings.LastIndexByte(s, ')')
+	if open < 0 || end < open {
+		return nil, fmt.Errorf("unexpected format of /proc/%d/stat", pid)
+	}
+	// The fields after the name start with the third, the state.
+	fields := strings.Fields(s[end+1:])
+	if len(fields) < 22 {
+		return nil, fmt.Errorf("unexpected format of /proc/%d/stat", pid)
+	}
+	field := func(n int) uint64 {
+		v, _ := strconv.ParseUint(fields[n-3], 10, 64)
+		return v
+	}
+	return &procStat{
+		name:     s[open+1 : end],
+		ppid:     int(field(4)),
+		utime:    field(14),
+		stime:    field(15),
+		rssPages: field(24),
+	}, nil
+}
+
+// procChildren returns the IDs of the child processes of each process.
+func procChildren() map[int][]int {
+	children := make(map[int][]int)
+	entries, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return children
+	}
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		if stat, err := readProcStat(pid); err == nil {
+			children[stat.ppid] = append(children[stat.ppid], pid)
+		}
+	}
+	return children
+}
+
+func procUsage(pid int) (*ProcUsage, error) {
+	return procTreeUsage(pid, procChildren(), os.Getpagesize())
+}
+
+func procTreeUsage(pid int, children map[int][]int, pageSize int) (*ProcUsage, error) {
+	stat, err := readProcStat(pid)
+	if err != nil {
+		return nil, err
+	}
+	u := &ProcUsage{
+		PID:       pid,
+		Name:      stat.name,
+		RSS:       stat.rssPages * uint64(pageSize),
+		CPUTime:   time.Duration(stat.utime+stat.stime) * time.Second / clockTicks,
+		OpenFiles: -1,
+	}
+	if fds, err := ioutil.ReadDir(filepath.Join("/proc", strconv.Itoa(pid), "fd")); err == nil {
+		u.OpenFiles = len(fds)
+	}
+	for _, child := range children[pid] {
+		if c, err := procTreeUsage(child, children, pageSize); err == nil {
+			u.Children = append(u.Children, c)
+		}
+	}
+	return u, nil
+}
@@ -0,0 +1,102 @@
+package selenium
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// helperAllocation is the memory that the helper process allocates.
+const helperAllocation = 64 << 20
+
+// TestHelperProcess is not a test: it is run as a fake driver process by
+// TestServiceResourceUsage. The process allocates and touches
+// helperAllocation bytes, opens a few files, starts a child process like a
+// driver starts a browser, and waits for its standard input to be closed.
+func TestHelperProcess(t *testing.T) {
+	role := os.Getenv("SELENIUM_HELPER_PROCESS")
+	if role == "" {
+		return
+	}
+	if role == "driver" {
+		mem := make([]byte, helperAllocation)
+		for i := 0; i < len(mem); i += 4096 {
+			mem[i] = 1
+		}
+		for i := 0; i < 5; i++ {
+			if _, err := os.Open(os.Args[0]); err != nil {
+				os.Exit(1)
+			}
+		}
+		browser := exec.Command(os.Args[0], "-test.run=TestHelperProcess")
+		browser.Env = append(os.Environ(), "SELENIUM_HELPER_PROCESS=browser")
+		browser.Stdin = os.Stdin
+		browserOut, err := browser.StdoutPipe()
+		if err != nil {
+			os.Exit(1)
+		}
+		if err := browser.Start(); err != nil {
+			os.Exit(1)
+		}
+		// The browser must be running, with its memory mapped, before the
+		// driver reports that it is ready.
+		if line, err := bufio.NewReader(browserOut).ReadString('\n'); err != nil || line != "ready\n" {
+			os.Exit(1)
+		}
+		os.Stdout.WriteString("ready\n")
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		browser.Wait()
+		mem[0]++
+		os.Exit(0)
+	}
+	os.Stdout.WriteString("ready\n")
+	bufio.NewReader(os.Stdin).ReadString('\n')
+	os.Exit(0)
+}
+
+func TestServiceResourceUsage(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess")
+	cmd.Env = append(os.Environ(), "SELENIUM_HELPER_PROCESS=driver")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("starting the helper process: %v", err)
+	}
+	defer cmd.Wait()
+	defer stdin.Close()
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "ready\n" {
+		t.Fatalf("the helper process did not start: %q, %v", line, err)
+	}
+
+	s := &Service{cmd: cmd}
+	u, err := s.ResourceUsage()
+	if err != nil {
+		t.Fatalf("ResourceUsage() returned error: %v", err)
+	}
+	if u.PID != cmd.Process.Pid || u.Name == "" {
+		t.Errorf("ResourceUsage() = %+v, want the helper process %d", u, cmd.Process.Pid)
+	}
+	if u.RSS < helperAllocation {
+		t.Errorf("ResourceUsage().RSS = %d, want at least %d", u.RSS, helperAllocation)
+	}
+	if u.OpenFiles < 5 {
+		t.Errorf("ResourceUsage().OpenFiles = %d, want at least 5", u.OpenFiles)
+	}
+	if len(u.Children) != 1 || u.Children[0].RSS == 0 {
+		t.Fatalf("ResourceUsage().Children = %+v, want the browser process", u.Children)
+	}
+	if rss, _ := u.Total(); rss != u.RSS+u.Children[0].RSS {
+		t.Errorf("Total() RSS = %d, want the sum of the processes", rss)
+	}
+
+	if _, err := new(Service).ResourceUsage(); err == nil {
+		t.Errorf("ResourceUsage() of a service that was not started returned nil error")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package selenium
+
+func procUsage(pid int) (*ProcUsage, error) {
+	return nil, ErrNotSupported
+}
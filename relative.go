@@ -0,0 +1,209 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// DefaultNearDistance is the distance in CSS pixels within which
+// RelativeBy.Near finds elements if it is given no distance.
+const DefaultNearDistance = 50
+
+// RelativeBy is a relative locator: it finds the elements matched by a
+// locator that are in a spatial relationship with other elements, such as
+// the input below a label. Its methods return a new RelativeBy with the
+// relationship added, so that they can be chained:
+//
+//	label, _ := wd.FindElement(selenium.ByXPATH, "//label[text()='Email']")
+//	input, err := wd.FindElementRelative(selenium.WithTagName("input").Below(label))
+//
+// The relationships compare the bounding boxes of the elements in the
+// viewport, as the relative locators of Selenium 4 do. Since most remote ends
+// do not implement the "relative" locator strategy, the boxes are measured
+// with a script and compared on the client.
+type RelativeBy struct {
+	loc       Locator
+	relations []relation
+}
+
+// relation is a spatial relationship with an anchor element.
+type relation struct {
+	kind     string
+	anchor   WebElement
+	distance float64
+}
+
+// WithTagName returns a relative locator for the elements with the given tag
+// name.
+func WithTagName(tag string) *RelativeBy {
+	return WithLocator(Locator{By: ByTagName, Value: tag})
+}
+
+// WithLocator returns a relative locator for the elements matched by loc.
+func WithLocator(loc Locator) *RelativeBy {
+	return &RelativeBy{loc: loc}
+}
+
+func (rb *RelativeBy) with(r relation) *RelativeBy {
+	return &RelativeBy{
+		loc:       rb.loc,
+		relations: append(rb.relations[:len(rb.relations):len(rb.relations)], r),
+	}
+}
+
+// Above restricts the locator to the elements whose bottom edge is above
+// the top edge of anchor.
+func (rb *RelativeBy) Above(anchor WebElement) *RelativeBy {
+	return rb.with(relation{kind: "above", anchor: anchor})
+}
+
+// Below restricts the locator to the elements whose top edge is below the
+// bottom edge of anchor.
+func (rb *RelativeBy) Below(anchor WebElement) *RelativeBy {
+	return rb.with(relation{kind: "below", anchor: anchor})
+}
+
+// LeftOf restricts the locator to the elements whose right edge is left of
+// the left edge of anchor.
+func (rb *RelativeBy) LeftOf(anchor WebElement) *RelativeBy {
+	return rb.with(relation{kind: "left of", anchor: anchor})
+}
+
+// RightOf restricts the locator to the elements whose left edge is right of
+// the right edge of anchor.
+func (rb *RelativeBy) RightOf(anchor WebElement) *RelativeBy {
+	return rb.with(relation{kind: "right of", anchor: anchor})
+}
+
+// Near restricts the locator to the elements that are at most distance CSS
+// pixels away from anchor, measured between the closest points of their
+// bounding boxes, or DefaultNearDistance if distance is not positive.
+func (rb *RelativeBy) Near(anchor WebElement, distance float64) *RelativeBy {
+	if distance <= 0 {
+		distance = DefaultNearDistance
+	}
+	return rb.with(relation{kind: "near", anchor: anchor, distance: distance})
+}
+
+func (rb *RelativeBy) String() string {
+	parts := []string{rb.loc.String()}
+	for _, r := range rb.relations {
+		s := fmt.Sprintf("%s %v", r.kind, r.anchor)
+		if r.kind == "near" {
+			s += fmt.Sprintf(" (%gpx)", r.distance)
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// relativeRectsScript returns the bounding boxes of the anchors, the first
+// argument, and of the candidates, the second one, with null for the
+// candidates that are anchors themselves.
+const relativeRectsScript = `var anchors = arguments[0], candidates = arguments[1];
+function rect(e) {
+  var r = e.getBoundingClientRect();
+  return {x: r.left, y: r.top, width: r.width, height: r.height};
+}
+return {
+  anchors: anchors.map(rect),
+  candidates: candidates.map(function(e) { return anchors.indexOf(e) < 0 ? rect(e) : null; })
+};`
+
+func (wd *remoteWD) FindElementRelative(rb *RelativeBy) (WebElement, error) {
+	elems, err := wd.FindElementsRelative(rb)
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) == 0 {
+		return nil, &Error{Err: "no such element", Message: fmt.Sprintf("no element matches %s", rb)}
+	}
+	return elems[0], nil
+}
+
+func (wd *remoteWD) FindElementsRelative(rb *RelativeBy) ([]WebElement, error) {
+	if rb == nil {
+		return nil, errors.New("nil relative locator")
+	}
+	candidates, err := wd.FindElements(rb.loc.By, rb.loc.Value)
+	if err != nil || len(candidates) == 0 || len(rb.relations) == 0 {
+		return candidates, err
+	}
+
+	anchors := make([]interface{}, len(rb.relations))
+	for i, r := range rb.relations {
+		anchors[i] = r.anchor
+	}
+	args := make([]interface{}, len(candidates))
+	for i, c := range candidates {
+		args[i] = c
+	}
+	response, err := wd.ExecuteScriptRaw(relativeRectsScript, []interface{}{anchors, args})
+	if err != nil {
+		return nil, err
+	}
+	rects, err := decodeValue[struct {
+		Anchors    []Rect
+		Candidates []*Rect
+	}](response, wd.strictDecoding, "/session/%s/execute", wd.id)
+	if err != nil {
+		return nil, err
+	}
+	if len(rects.Anchors) != len(anchors) || len(rects.Candidates) != len(candidates) {
+		return nil, errors.New("unexpected result of the relative locator script")
+	}
+
+	type match struct {
+		elem     WebElement
+		distance float64
+	}
+	var matches []match
+	for i, c := range rects.Candidates {
+		if c == nil || !rb.matches(*c, rects.Anchors) {
+			continue
+		}
+		matches = append(matches, match{candidates[i], centerDistance(*c, rects.Anchors[0])})
+	}
+	// As in Selenium, the elements closest to the first anchor come first.
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+	elems := make([]WebElement, len(matches))
+	for i, m := range matches {
+		elems[i] = m.elem
+	}
+	return elems, nil
+}
+
+// matches reports whether the bounding box c is in all of the relationships
+// of the locator with the bounding boxes of the anchors.
+func (rb *RelativeBy) matches(c Rect, anchors []Rect) bool {
+	for i, r := range rb.relations {
+		a := anchors[i]
+		var ok bool
+		switch r.kind {
+		case "above":
+			ok = c.Y+c.Height <= a.Y
+		case "below":
+			ok = c.Y >= a.Y+a.Height
+		case "left of":
+			ok = c.X+c.Width <= a.X
+		case "right of":
+			ok = c.X >= a.X+a.Width
+		case "near":
+			dx := math.Max(0, math.Max(a.X-(c.X+c.Width), c.X-(a.X+a.Width)))
+			dy := math.Max(0, math.Max(a.Y-(c.Y+c.Height), c.Y-(a.Y+a.Height)))
+			ok = math.Hypot(dx, dy) <= r.distance
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// centerDistance returns the distance between the centers of a and b.
+func centerDistance(a, b Rect) float64 {
+	return math.Hypot(a.X+a.Width/2-(b.X+b.Width/2), a.Y+a.Height/2-(b.Y+b.Height/2))
+}
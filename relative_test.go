@@ -0,0 +1,128 @@
+package selenium
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gridLayout is a labelled grid of inputs: a label above each input, in two
+// columns and two rows.
+var gridLayout = map[string]Rect{
+	"first-label": {X: 0, Y: 0, Width: 100, Height: 20},
+	"first":       {X: 0, Y: 30, Width: 100, Height: 20},
+	"last-label":  {X: 200, Y: 0, Width: 100, Height: 20},
+	"last":        {X: 200, Y: 30, Width: 100, Height: 20},
+	"city-label":  {X: 0, Y: 60, Width: 100, Height: 20},
+	"city":        {X: 0, Y: 90, Width: 100, Height: 20},
+	"zip-label":   {X: 200, Y: 60, Width: 100, Height: 20},
+	"zip":         {X: 200, Y: 90, Width: 100, Height: 20},
+}
+
+func newGridServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		reply := func(v interface{}) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": v})
+		}
+		ref := func(id string) map[string]string {
+			return map[string]string{webElementIdentifier: id}
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/session/123") {
+		case "/elements":
+			var params struct{ Using, Value string }
+			json.NewDecoder(r.Body).Decode(&params)
+			var refs []map[string]string
+			for _, id := range []string{"first", "last", "city", "zip"} {
+				if params.Value == "label" {
+					id += "-label"
+				}
+				refs = append(refs, ref(id))
+			}
+			reply(refs)
+		case "/execute/sync":
+			var params struct {
+				Script string
+				Args   [2][]map[string]string
+			}
+			json.NewDecoder(r.Body).Decode(&params)
+			if params.Script != relativeRectsScript {
+				t.Errorf("unexpected script %q", params.Script)
+			}
+			anchors := make(map[string]bool)
+			result := map[string][]interface{}{"anchors": {}, "candidates": {}}
+			for _, a := range params.Args[0] {
+				id := a[webElementIdentifier]
+				anchors[id] = true
+				result["anchors"] = append(result["anchors"], gridLayout[id])
+			}
+			for _, c := range params.Args[1] {
+				id := c[webElementIdentifier]
+				if anchors[id] {
+					result["candidates"] = append(result["candidates"], nil)
+				} else {
+					result["candidates"] = append(result["candidates"], gridLayout[id])
+				}
+			}
+			reply(result)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			reply(nil)
+		}
+	}))
+}
+
+func TestFindElementsRelative(t *testing.T) {
+	s := newGridServer(t)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := func(id string) WebElement { return &remoteWE{parent: wd, id: id} }
+
+	for _, tc := range []struct {
+		rb   *RelativeBy
+		want []string
+	}{
+		{WithTagName("input"), []string{"first", "last", "city", "zip"}},
+		// Closest to the anchor first.
+		{WithTagName("input").Below(elem("first-label")), []string{"first", "city", "last", "zip"}},
+		{WithTagName("input").Below(elem("first-label")).LeftOf(elem("last-label")), []string{"first", "city"}},
+		{WithTagName("input").Above(elem("zip-label")), []string{"last", "first"}},
+		{WithTagName("label").RightOf(elem("city")), []string{"zip-label", "last-label"}},
+		{WithTagName("input").Near(elem("zip-label"), 0), []string{"last", "zip"}},
+		{WithTagName("input").Near(elem("zip-label"), 200), []string{"last", "zip", "first", "city"}},
+		// The anchors are not matched themselves.
+		{WithTagName("input").Near(elem("first"), 0), []string{"city"}},
+		{WithTagName("input").Above(elem("first-label")), nil},
+	} {
+		elems, err := wd.FindElementsRelative(tc.rb)
+		if err != nil {
+			t.Errorf("FindElementsRelative(%s) returned error: %v", tc.rb, err)
+			continue
+		}
+		var got []string
+		for _, e := range elems {
+			got = append(got, e.(*remoteWE).id)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("FindElementsRelative(%s) = %q, want %q", tc.rb, got, tc.want)
+		}
+	}
+
+	below := WithTagName("input").Below(elem("city-label"))
+	e, err := wd.FindElementRelative(below)
+	if err != nil || e.(*remoteWE).id != "city" {
+		t.Errorf("FindElementRelative(%s) = %v, %v; want the city input", below, e, err)
+	}
+	// Chaining does not change the locator it starts from.
+	below.RightOf(elem("city"))
+	if e, err := wd.FindElementRelative(below); err != nil || e.(*remoteWE).id != "city" {
+		t.Errorf("FindElementRelative(%s) after chaining = %v, %v; want the city input", below, e, err)
+	}
+	above := WithTagName("input").Above(elem("first"))
+	if _, err := wd.FindElementRelative(above); !isNoSuchElement(err) {
+		t.Errorf("FindElementRelative(%s) returned error %v, want a no such element error", above, err)
+	}
+}
@@ -5,14 +5,19 @@ package selenium
 
 import (
 	"bytes"
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"mime"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -48,6 +53,9 @@ const (
 	DefaultURLPrefix = "http://127.0.0.1:4444/wd/hub"
 	// JSONType is JSON content type.
 	JSONType = "application/json"
+	// JSONContentType is the Content-Type header value sent with request
+	// bodies.
+	JSONContentType = JSONType + "; charset=utf-8"
 	// MaxRedirects is the maximum number of redirects to follow.
 	MaxRedirects = 10
 )
@@ -55,28 +63,199 @@ const (
 type remoteWD struct {
 	id, urlPrefix string
 	capabilities  Capabilities
+	// negotiated holds the capabilities returned by the remote end when the
+	// session was created.
+	negotiated Capabilities
 
 	w3cCompatible bool
-	browser       string
+	// strictDecoding is set by SetStrictDecoding.
+	strictDecoding bool
+	// browser is the lower-cased name of the session's browser, as
+	// negotiated when the session was created.
+	browser string
+
+	fileDialogGuard  bool
+	fileDetector     FileDetector
+	pointerPrecision PointerPrecision
+
+	// clickRetry and clickRetryInterval are set by SetClickRetry.
+	clickRetry         int
+	clickRetryInterval time.Duration
+	// staleRetry and staleRetryCommands are set by SetStaleRetry.
+	staleRetry         int
+	staleRetryCommands map[string]bool
+
+	dialectWarnings bool
+	// dialectWarned holds the warnings already emitted, by ID and command.
+	// Commands may be sent concurrently, so it is guarded by dialectMu.
+	dialectMu     sync.Mutex
+	dialectWarned map[string]bool
+
+	relativeXPathCheck bool
+
+	// noSwitchOnClose disables switching to a remaining window in Close.
+	noSwitchOnClose bool
+	// lastWindowClosed is set when the last window of the session is closed.
+	lastWindowClosed bool
+
+	// history records recent commands, if enabled.
+	history *commandHistory
+
+	// elementDecoder and elementEncoder, if set, decode and encode element
+	// references for remote ends that use a non-standard key.
+	elementDecoder func(raw json.RawMessage) (id string, ok bool)
+	elementEncoder func(id string) interface{}
+
+	// artifactSink stores the session's artifacts, which are listed in
+	// artifacts. Artifacts may be saved concurrently, e.g. by failure hooks,
+	// so the list is guarded by artifactsMu.
+	artifactSink     ArtifactSink
+	artifactTestName string
+	artifactsMu      sync.Mutex
+	artifacts        []ArtifactInfo
+	// evidence records the steps of the session, if evidence mode is
+	// enabled.
+	evidence *evidenceJournal
+	// authGuard re-authenticates the session when it is logged out, if set.
+	authGuard *authGuard
+
+	// implicitWaitWarned, strictTimeouts and waitDepth control how client-side
+	// waits suspend the implicit wait timeout.
+	implicitWaitWarned bool
+	strictTimeouts     bool
+	waitDepth          int
+	// windowOrder remembers the order in which the handles of the windows
+	// were first seen, for WindowsSorted.
+	windowOrder windowRegistry
+	// navigation detects the navigations of the current browsing context for
+	// the handlers registered with OnNavigation.
+	navigation navigationDetector
+	// frames holds the frames switched to from the top-level browsing
+	// context, outermost first, as sent to the remote end.
+	frames []interface{}
+
+	// timeouts caches the session's timeouts, of which knownTimeouts are
+	// known, for GetTimeouts and the client-side waits. getTimeoutsErr is set
+	// if the remote end does not implement the Get Timeouts command.
+	timeouts       Timeouts
+	knownTimeouts  timeoutKind
+	getTimeoutsErr error
+
+	// namedCookieUnsupported is set if the remote end does not implement the
+	// "Get Named Cookie" command for the current session.
+	namedCookieUnsupported bool
+	// sessionPayloadHook, if set, is applied to the new session requests.
+	// sessionAttempt and sessionPayload record the last request sent.
+	sessionPayloadHook func(payload map[string]interface{})
+	sessionAttempt     int
+	sessionPayload     []byte
+
+	// zoomMethod is the method by which SetPageZoom zoomed the page.
+	zoomMethod ZoomMethod
+
+	// sessionClosed is set once the session has been ended with Quit, after
+	// which commands on it return ErrSessionClosed.
+	sessionClosed bool
+
+	// displayedUnsupported is set if the remote end does not implement the
+	// /displayed endpoint for the current session.
+	displayedUnsupported bool
+
+	// sessionEventHandlers are called with the lifecycle events of the
+	// WebDriver's sessions. crashReported is set once SessionCrashed has been
+	// emitted for the current session.
+	sessionEventHandlers sessionEventHandlers
+	crashReported        bool
+
+	// localServer serves the pages of RenderHTML and ServeDir until the
+	// session is quit, at publicBaseURL if it is set.
+	localServer   *localServer
+	publicBaseURL *url.URL
+	// consoles are the console attachments of the session, which are
+	// detached when it is quit.
+	consoles []*consoleAttachment
+
+	// counters hold the statistics of the current session. They are replaced
+	// when the session changes, possibly while SessionStats is called.
+	counters atomic.Pointer[sessionCounters]
+
+	// responseRecorder collects the responses for the current page reported
+	// in the performance log.
+	responseRecorder responseRecorder
+
+	// compressMinSize is the size from which request bodies are compressed,
+	// if positive. compressionRejected is set once the remote end of the
+	// current session has rejected a compressed body.
+	compressMinSize     int
+	compressionRejected bool
+
+	// client, if set, sends the requests of the WebDriver instead of the
+	// shared client returned by GetHTTPClient, e.g. with the TLS
+	// configuration of ConnectTLS.
+	client *http.Client
+}
+
+// httpClient is the shared HTTP client. ConfigureHTTP replaces it while
+// requests may be in flight, so it is only accessed atomically.
+var httpClient atomic.Pointer[http.Client]
+
+// httpClientOf returns the client that sends the requests of wd.
+func (wd *remoteWD) httpClientOf() *http.Client {
+	if wd.client != nil {
+		return wd.client
+	}
+	return httpClient.Load()
 }
 
-var httpClient *http.Client
-
 // GetHTTPClient returns the default HTTP client.
 func GetHTTPClient() *http.Client {
-	return httpClient
+	return httpClient.Load()
 }
 
-func newRequest(method string, url string, data []byte) (*http.Request, error) {
-	request, err := http.NewRequest(method, url, bytes.NewBuffer(data))
+// newRequest returns a request with the given body, compressed with gzip if
+// gzipped is set. The body is opened again if the request is redirected.
+func newRequest(method string, url string, body requestBody, gzipped bool) (*http.Request, error) {
+	r, n, err := body.open(gzipped)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequest(method, url, r)
 	if err != nil {
+		r.Close()
 		return nil, err
 	}
-	request.Header.Add("Accept", JSONType)
+	request.ContentLength = n
+	if n == 0 {
+		r.Close()
+		request.Body = http.NoBody
+		request.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+	} else {
+		request.GetBody = func() (io.ReadCloser, error) {
+			r, _, err := body.open(gzipped)
+			return r, err
+		}
+	}
+	setRequestHeaders(request, n != 0)
+	if gzipped {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
 
 	return request, nil
 }
 
+// setRequestHeaders sets the headers required by the WebDriver specification
+// on request. The Content-Type header is only set if the request carries a
+// body.
+func setRequestHeaders(request *http.Request, hasBody bool) {
+	request.Header.Set("Accept", JSONType)
+	request.Header.Set("Cache-Control", "no-cache")
+	if hasBody {
+		request.Header.Set("Content-Type", JSONContentType)
+	} else {
+		request.Header.Del("Content-Type")
+	}
+}
+
 func isRedirect(response *http.Response) bool {
 	switch response.StatusCode {
 	case 301, 302, 303, 307:
@@ -97,6 +276,47 @@ func normalizeURL(n string, base string) (string, error) {
 	return nURL.String(), nil
 }
 
+// sessionRedirectKey is the key of the context value with which postSession
+// asks the HTTP client not to follow a redirect to the URL of a session, and
+// to store its ID in the *string value instead.
+type sessionRedirectKey struct{}
+
+// sessionIDFromURL returns the session ID in a URL such as
+// "http://localhost:4444/wd/hub/session/abc", or the empty string if there is
+// none.
+func sessionIDFromURL(u *url.URL) string {
+	i := strings.LastIndex(u.Path, "/session/")
+	if i < 0 {
+		return ""
+	}
+	id := u.Path[i+len("/session/"):]
+	if j := strings.Index(id, "/"); j >= 0 {
+		id = id[:j]
+	}
+	return id
+}
+
+// postSession sends the new session request. Legacy remote ends may answer
+// it with a 303 redirect to the URL of the new session. Following it would
+// fetch the session with a GET request, but some HTTP clients and proxies
+// repeat the POST request instead, which creates a second session. So the
+// session ID is taken from the Location header instead, and only redirects
+// to other URLs, e.g. from HTTP to HTTPS, are followed.
+func (wd *remoteWD) postSession(data []byte) (json.RawMessage, error) {
+	var id string
+	ctx := context.WithValue(context.Background(), sessionRedirectKey{}, &id)
+	response, err := wd.executeContext(ctx, "POST", wd.requestURL("/session"), data)
+	if id == "" {
+		return response, err
+	}
+	debugLog("new session redirected to session %s", id)
+	return json.Marshal(map[string]interface{}{
+		"sessionId": id,
+		"status":    Success,
+		"value":     map[string]interface{}{},
+	})
+}
+
 func (wd *remoteWD) requestURL(template string, args ...interface{}) string {
 	return wd.urlPrefix + fmt.Sprintf(template, args...)
 }
@@ -122,6 +342,10 @@ type Error struct {
 	Err        string `json:"error"`
 	Message    string `json:"message"`
 	Stacktrace string `json:"stacktrace"`
+
+	// history holds the records of the failing command and its predecessors,
+	// if command history is enabled.
+	history []CommandRecord
 }
 
 // Error implements the error interface.
@@ -133,18 +357,65 @@ func (e *Error) Error() string {
 // encoded by the remote end in a JSON structure. If no error is present, the
 // entire, raw request payload is returned.
 func (wd *remoteWD) execute(method, url string, data []byte) (json.RawMessage, error) {
-	debugLog("-> %s %s\n%s", method, filteredURL(url), data)
-	request, err := newRequest(method, url, data)
-	if err != nil {
-		return nil, err
+	return wd.executeContext(context.Background(), method, url, data)
+}
+
+// executeContext is like execute, but aborts the request when ctx is done.
+func (wd *remoteWD) executeContext(ctx context.Context, method, url string, data []byte) (json.RawMessage, error) {
+	return wd.executeRequest(method, url, data, func() (*http.Response, error) {
+		return wd.sendRequest(ctx, method, url, requestBody{data: data})
+	})
+}
+
+// executeRequest is like execute, but sends the request with send, e.g. to
+// stream its body. data is the body as shown in the debug log and the command
+// history.
+func (wd *remoteWD) executeRequest(method, url string, data []byte, send func() (*http.Response, error)) (_ json.RawMessage, err error) {
+	if strings.HasPrefix(url, wd.urlPrefix+"/session/") {
+		if wd.sessionClosed {
+			return nil, ErrSessionClosed
+		}
+		if wd.id == "" {
+			return nil, ErrNoSession
+		}
+		if strings.HasPrefix(url, wd.urlPrefix+"/session/"+wd.id+"/") {
+			if err := wd.checkAuthBeforeCommand(); err != nil {
+				return nil, err
+			}
+			if wd.navigation.watching() {
+				defer func() {
+					if err == nil {
+						wd.afterCommand(method, url)
+					}
+				}()
+			}
+		}
+	}
+	defer func() {
+		if err != nil {
+			wd.noteCommandError(err)
+		}
+	}()
+	var (
+		status int
+		buf    []byte
+	)
+	if wd.history != nil {
+		start := time.Now()
+		defer func() {
+			wd.recordCommand(start, method, url, data, buf, status, err)
+		}()
 	}
 
-	response, err := httpClient.Do(request)
+	debugLog("-> %s %s\n%s", method, filteredURL(url), data)
+	response, err := send()
 	if err != nil {
 		return nil, err
 	}
+	status = response.StatusCode
 
-	buf, err := ioutil.ReadAll(response.Body)
+	buf, err = ioutil.ReadAll(response.Body)
+	wd.count(statBytesReceived, len(buf))
 	if debugFlag {
 		if err == nil {
 			// Pretty print the JSON response
@@ -214,34 +485,17 @@ func (wd *remoteWD) execute(method, url string, data []byte) (json.RawMessage, e
 // Providing an empty string for urlPrefix causes the DefaultURLPrefix to be
 // used.
 func NewRemote(capabilities Capabilities, urlPrefix string) (WebDriver, error) {
-	if len(urlPrefix) == 0 {
-		urlPrefix = DefaultURLPrefix
-	}
+	return NewRemoteWithOptions(capabilities, urlPrefix)
+}
 
-	wd := &remoteWD{urlPrefix: urlPrefix, capabilities: capabilities}
-	if _, err := wd.NewSession(); err != nil {
-		return nil, err
-	}
-	return wd, nil
+// nullValueError returns the error for a reply to a command that has a null
+// value where one is required.
+func nullValueError(urlTemplate string, args ...interface{}) error {
+	return fmt.Errorf("server returned null for "+urlTemplate, args...)
 }
 
 func (wd *remoteWD) stringCommand(urlTemplate string) (string, error) {
-	url := wd.requestURL(urlTemplate, wd.id)
-	response, err := wd.execute("GET", url, nil)
-	if err != nil {
-		return "", err
-	}
-
-	reply := new(struct{ Value *string })
-	if err := json.Unmarshal(response, reply); err != nil {
-		return "", err
-	}
-
-	if reply.Value == nil {
-		return "", fmt.Errorf("nil return value")
-	}
-
-	return *reply.Value, nil
+	return GetValue[string](wd, urlTemplate, wd.id)
 }
 
 func (wd *remoteWD) voidCommand(urlTemplate string, params interface{}) error {
@@ -256,52 +510,37 @@ func (wd *remoteWD) voidCommand(urlTemplate string, params interface{}) error {
 	return err
 }
 
-func (wd remoteWD) stringsCommand(urlTemplate string) ([]string, error) {
-	url := wd.requestURL(urlTemplate, wd.id)
-	response, err := wd.execute("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	reply := new(struct{ Value []string })
-	if err := json.Unmarshal(response, reply); err != nil {
-		return nil, err
-	}
-
-	return reply.Value, nil
+func (wd *remoteWD) stringsCommand(urlTemplate string) ([]string, error) {
+	return GetValue[[]string](wd, urlTemplate, wd.id)
 }
 
 func (wd *remoteWD) boolCommand(urlTemplate string) (bool, error) {
-	url := wd.requestURL(urlTemplate, wd.id)
-	response, err := wd.execute("GET", url, nil)
-	if err != nil {
-		return false, err
-	}
-
-	reply := new(struct{ Value bool })
-	if err := json.Unmarshal(response, reply); err != nil {
+	// A null value is false.
+	value, err := GetValue[*bool](wd, urlTemplate, wd.id)
+	if value == nil {
 		return false, err
 	}
-
-	return reply.Value, nil
+	return *value, err
 }
 
 func (wd *remoteWD) Status() (*Status, error) {
-	url := wd.requestURL("/status")
-	reply, err := wd.execute("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	status := new(struct{ Value Status })
-	if err := json.Unmarshal(reply, status); err != nil {
-		return nil, err
-	}
-
-	return &status.Value, nil
+	return (&ServerClient{wd: wd}).Status()
 }
 
 func (wd *remoteWD) NewSession() (string, error) {
+	wd.namedCookieUnsupported = false
+	wd.displayedUnsupported = false
+	wd.lastWindowClosed = false
+	wd.sessionClosed = false
+	wd.zoomMethod = ZoomNone
+	wd.crashReported = false
+	wd.compressionRejected = false
+	wd.responseRecorder = responseRecorder{}
+	wd.resetTimeouts()
+	wd.frames = nil
+	wd.windowOrder = windowRegistry{}
+	wd.navigation.reset()
+
 	// Detect whether the remote end complies with the W3C specification:
 	// non-compliant implementations use the top-level 'desiredCapabilities' JSON
 	// key, whereas the specification mandates the 'capabilities' key.
@@ -330,52 +569,68 @@ func (wd *remoteWD) NewSession() (string, error) {
 			"desiredCapabilities": wd.capabilities,
 		}}}
 
+	var lastErr error
 	for i, s := range attempts {
+		if err := wd.applySessionPayloadHook(i, s.params); err != nil {
+			return "", err
+		}
 		data, err := json.Marshal(s.params)
 		if err != nil {
 			return "", err
 		}
 
-		response, err := wd.execute("POST", wd.requestURL("/session"), data)
+		wd.sessionAttempt, wd.sessionPayload = i, data
+		response, err := wd.postSession(data)
 		if err != nil {
 			return "", err
 		}
 
 		reply := new(serverReply)
 		if err := json.Unmarshal(response, reply); err != nil {
-			if i < len(attempts) {
-				continue
-			}
-			return "", err
+			lastErr = err
+			continue
 		}
-		if reply.Status != 0 && i < len(attempts) {
+		if reply.Status != 0 {
+			lastErr = fmt.Errorf("server returned status %d", reply.Status)
 			continue
 		}
 
 		if reply.SessionID != nil {
+			if *reply.SessionID == "" {
+				return "", fmt.Errorf("server returned no session ID")
+			}
 			wd.id = *reply.SessionID
+			wd.negotiated = nil
+			json.Unmarshal(reply.Value, &wd.negotiated) // Best effort.
 		} else if len(reply.Value) > 0 {
 			value := new(struct {
 				SessionID        string
+				Capabilities     Capabilities
 				PageLoadStrategy string
 				Proxy            Proxy
-				Timeouts         struct {
-					Implicit int
-					PageLoad int `json:"page load"`
-					Script   int
-				}
 			})
 
 			if err := json.Unmarshal(reply.Value, value); err != nil {
 				return "", fmt.Errorf("error unmarshalling value: %v", err)
 			}
+			if value.SessionID == "" {
+				return "", fmt.Errorf("server returned no session ID")
+			}
 			wd.id = value.SessionID
+			wd.negotiated = value.Capabilities
 			wd.w3cCompatible = true
+			wd.noteNegotiatedTimeouts()
+		} else {
+			return "", nullValueError("/session")
 		}
 
+		wd.browser = wd.browserName()
+		wd.counters.Store(newSessionCounters())
+		wd.emitSessionEvent(SessionCreated, nil)
+		wd.emitSessionEvent(SessionDialectDetected, nil)
 		return wd.id, nil
 	}
-	panic("unreachable")
+	return "", fmt.Errorf("error creating a session: %v", lastErr)
 }
 
 // SessionId returns the current session ID
@@ -392,6 +647,19 @@ func (wd *remoteWD) SessionID() string {
 
 func (wd *remoteWD) SwitchSession(sessionID string) error {
 	wd.id = sessionID
+	wd.namedCookieUnsupported = false
+	wd.displayedUnsupported = false
+	wd.lastWindowClosed = false
+	wd.sessionClosed = false
+	wd.zoomMethod = ZoomNone
+	wd.crashReported = false
+	wd.compressionRejected = false
+	wd.responseRecorder = responseRecorder{}
+	wd.resetTimeouts()
+	wd.frames = nil
+	wd.windowOrder = windowRegistry{}
+	wd.navigation.reset()
+	wd.counters.Store(newSessionCounters())
 	return nil
 }
 
@@ -406,42 +674,73 @@ func (wd *remoteWD) Capabilities() (Capabilities, error) {
 	if err := json.Unmarshal(response, c); err != nil {
 		return nil, err
 	}
+	if c.Value == nil {
+		return nil, nullValueError("/session/%s", wd.id)
+	}
 
 	return c.Value, nil
 }
 
 func (wd *remoteWD) SetAsyncScriptTimeout(timeout time.Duration) error {
+	ms, err := timeoutMillis("script", timeout)
+	if err != nil {
+		return err
+	}
 	if !wd.w3cCompatible {
-		return wd.voidCommand("/session/%s/timeouts/async_script", map[string]uint{
-			"ms": uint(timeout / time.Millisecond),
+		err = wd.voidCommand("/session/%s/timeouts/async_script", map[string]uint{
+			"ms": ms,
+		})
+	} else {
+		err = wd.voidCommand("/session/%s/timeouts", map[string]uint{
+			"script": ms,
 		})
 	}
-	return wd.voidCommand("/session/%s/timeouts", map[string]uint{
-		"script": uint(timeout / time.Millisecond),
-	})
+	if err == nil {
+		wd.noteTimeouts(scriptTimeout, Timeouts{Script: millisTimeout(ms)})
+	}
+	return err
 }
 
 func (wd *remoteWD) SetImplicitWaitTimeout(timeout time.Duration) error {
+	ms, err := timeoutMillis("implicit wait", timeout)
+	if err != nil {
+		return err
+	}
 	if !wd.w3cCompatible {
-		return wd.voidCommand("/session/%s/timeouts/implicit_wait", map[string]uint{
-			"ms": uint(timeout / time.Millisecond),
+		err = wd.voidCommand("/session/%s/timeouts/implicit_wait", map[string]uint{
+			"ms": ms,
+		})
+	} else {
+		err = wd.voidCommand("/session/%s/timeouts", map[string]uint{
+			"implicit": ms,
 		})
 	}
-	return wd.voidCommand("/session/%s/timeouts", map[string]uint{
-		"implicit": uint(timeout / time.Millisecond),
-	})
+	if err != nil {
+		return err
+	}
+	wd.noteTimeouts(implicitTimeout, Timeouts{Implicit: millisTimeout(ms)})
+	return nil
 }
 
 func (wd *remoteWD) SetPageLoadTimeout(timeout time.Duration) error {
+	ms, err := timeoutMillis("page load", timeout)
+	if err != nil {
+		return err
+	}
 	if !wd.w3cCompatible {
-		return wd.voidCommand("/session/%s/timeouts", map[string]interface{}{
-			"ms":   uint(timeout / time.Millisecond),
+		err = wd.voidCommand("/session/%s/timeouts", map[string]interface{}{
+			"ms":   ms,
 			"type": "page load",
 		})
+	} else {
+		err = wd.voidCommand("/session/%s/timeouts", map[string]uint{
+			"pageLoad": ms,
+		})
 	}
-	return wd.voidCommand("/session/%s/timeouts", map[string]uint{
-		"pageLoad": uint(timeout / time.Millisecond),
-	})
+	if err == nil {
+		wd.noteTimeouts(pageLoadTimeout, Timeouts{PageLoad: millisTimeout(ms)})
+	}
+	return err
 }
 
 func (wd *remoteWD) AvailableEngines() ([]string, error) {
@@ -457,7 +756,7 @@ func (wd *remoteWD) IsEngineActivated() (bool, error) {
 }
 
 func (wd *remoteWD) DeactivateEngine() error {
-	return wd.voidCommand("session/%s/ime/deactivate", nil)
+	return wd.voidCommand("/session/%s/ime/deactivate", nil)
 }
 
 func (wd *remoteWD) ActivateEngine(engine string) error {
@@ -466,13 +765,47 @@ func (wd *remoteWD) ActivateEngine(engine string) error {
 	})
 }
 
+// ErrSessionClosed is returned by the commands of a session that has been
+// ended with Quit or abandoned by QuitContext.
+var ErrSessionClosed = errors.New("the session has been quit")
+
+// ErrNoSession is returned by the commands of a session when the WebDriver
+// has no session, because NewSession has not been called or failed.
+var ErrNoSession = errors.New("the WebDriver has no session")
+
 func (wd *remoteWD) Quit() error {
+	return wd.QuitContext(context.Background())
+}
+
+func (wd *remoteWD) QuitContext(ctx context.Context) error {
 	if wd.id == "" {
 		return nil
 	}
-	_, err := wd.execute("DELETE", wd.requestURL("/session/%s", wd.id), nil)
+	_, err := wd.executeContext(ctx, "DELETE", wd.requestURL("/session/%s", wd.id), nil)
+	if err != nil && wd.lastWindowClosed && isInvalidSession(err) {
+		// Some drivers end the session when its last window is closed.
+		err = nil
+	}
+	if err != nil && ctx.Err() != nil {
+		// The remote end may never answer. Give up on the session, so that
+		// later commands fail immediately instead of reaching it.
+		wd.emitSessionEvent(SessionQuit, ctx.Err())
+		wd.id = ""
+		wd.sessionClosed = true
+		wd.stopLocalServer()
+		wd.detachConsoles()
+		untrackSession(wd)
+		wd.sessionEventHandlers.closeAll()
+		return fmt.Errorf("quitting the session: %w", ctx.Err())
+	}
 	if err == nil {
+		wd.emitSessionEvent(SessionQuit, nil)
 		wd.id = ""
+		wd.sessionClosed = true
+		wd.stopLocalServer()
+		wd.detachConsoles()
+		untrackSession(wd)
+		wd.sessionEventHandlers.closeAll()
 	}
 	return err
 }
@@ -484,8 +817,46 @@ func (wd *remoteWD) CurrentWindowHandle() (string, error) {
 	return wd.stringCommand("/session/%s/window")
 }
 
+// endpointError is the error of a command, annotated with the endpoint that
+// was requested. Its message starts with that of the command's error, so
+// that it is classified alike.
+type endpointError struct {
+	method, endpoint string
+	err              error
+}
+
+func (e *endpointError) Error() string {
+	return fmt.Sprintf("%v (%s %s)", e.err, e.method, e.endpoint)
+}
+
+// Unwrap returns the error of the command.
+func (e *endpointError) Unwrap() error {
+	return e.err
+}
+
 func (wd *remoteWD) WindowHandles() ([]string, error) {
-	return wd.stringsCommand("/session/%s/window_handles")
+	var handles []string
+	var err error
+	if !wd.w3cCompatible {
+		handles, err = wd.stringsCommand("/session/%s/window_handles")
+		if err != nil {
+			return nil, &endpointError{"GET", "/session/" + wd.id + "/window_handles", err}
+		}
+	} else if handles, err = wd.stringsCommand("/session/%s/window/handles"); err != nil {
+		return nil, err
+	}
+
+	// Some remote ends list a handle more than once.
+	seen := make(map[string]bool, len(handles))
+	unique := handles[:0]
+	for _, h := range handles {
+		if !seen[h] {
+			seen[h] = true
+			unique = append(unique, h)
+		}
+	}
+	wd.windowOrder.update(unique)
+	return unique, nil
 }
 
 func (wd *remoteWD) CurrentURL() (string, error) {
@@ -495,9 +866,12 @@ func (wd *remoteWD) CurrentURL() (string, error) {
 		return "", err
 	}
 	reply := new(struct{ Value *string })
-	if err := json.Unmarshal(response, reply); err != nil {
+	if err := decodeReply(response, reply, wd.strictDecoding, "/session/%s/url", wd.id); err != nil {
 		return "", err
 	}
+	if reply.Value == nil {
+		return "", nullValueError("/session/%s/url", wd.id)
+	}
 
 	return *reply.Value, nil
 }
@@ -511,20 +885,49 @@ func (wd *remoteWD) Get(url string) error {
 	if err != nil {
 		return err
 	}
-	_, err = wd.execute("POST", requestURL, data)
-	return err
+	if _, err = wd.execute("POST", requestURL, data); err != nil {
+		return err
+	}
+	// Navigation switches to the top-level browsing context.
+	wd.frames = nil
+	wd.count(statNavigations, 1)
+	wd.checkNavigation(true)
+	if reauthed, err := wd.checkAuth(); err != nil || !reauthed {
+		return err
+	}
+	// Retry the navigation that was redirected to log in, once.
+	if _, err = wd.execute("POST", requestURL, data); err != nil {
+		return err
+	}
+	wd.count(statNavigations, 1)
+	wd.checkNavigation(true)
+	return nil
 }
 
 func (wd *remoteWD) Forward() error {
-	return wd.voidCommand("/session/%s/forward", nil)
+	return wd.navigate("/session/%s/forward")
 }
 
 func (wd *remoteWD) Back() error {
-	return wd.voidCommand("/session/%s/back", nil)
+	return wd.navigate("/session/%s/back")
 }
 
 func (wd *remoteWD) Refresh() error {
-	return wd.voidCommand("/session/%s/refresh", nil)
+	return wd.navigate("/session/%s/refresh")
+}
+
+// navigate sends a navigation command without parameters.
+func (wd *remoteWD) navigate(urlTemplate string) error {
+	if err := wd.voidCommand(urlTemplate, nil); err != nil {
+		return err
+	}
+	wd.frames = nil
+	wd.count(statNavigations, 1)
+	wd.checkNavigation(true)
+	// The history now holds the login pages, so the navigation is not
+	// retried.
+	_, err := wd.checkAuth()
+	return err
 }
 
 func (wd *remoteWD) Title() (string, error) {
@@ -536,6 +939,8 @@ func (wd *remoteWD) PageSource() (string, error) {
 }
 
 func (wd *remoteWD) find(by, value, suffix, url string) ([]byte, error) {
+	by = locatorStrategy(by)
+	wd.checkDialect("FindElement", by)
 	// The W3C specification removed the specific ID and Name locator strategies,
 	// instead only providing a CSS-based strategy. Emulate the old behavior to
 	// maintain API compatibility.
@@ -543,10 +948,10 @@ func (wd *remoteWD) find(by, value, suffix, url string) ([]byte, error) {
 		switch by {
 		case ByID:
 			by = "css selector"
-			value = "#" + value
+			value = "#" + CSSEscape(value)
 		case ByName:
 			by = "css selector"
-			value = fmt.Sprintf("input[name=%q]", value)
+			value = "input[name=" + CSSAttrValue(value) + "]"
 		}
 	}
 
@@ -566,74 +971,74 @@ func (wd *remoteWD) find(by, value, suffix, url string) ([]byte, error) {
 	return wd.execute("POST", wd.requestURL(url+suffix, wd.id), data)
 }
 
-type element struct {
-	Element string `json:"ELEMENT"`
+// elementRefID returns the ID of an element reference, which is keyed by the
+// W3C identifier or, as the legacy protocol does, by "ELEMENT". Both keys are
+// accepted whatever the protocol of the session, since some remote ends mix
+// them up.
+func elementRefID(ref map[string]interface{}) string {
+	for _, key := range []string{webElementIdentifier, "ELEMENT"} {
+		if id, ok := ref[key].(string); ok && id != "" {
+			return id
+		}
+	}
+	return ""
 }
 
 func (wd *remoteWD) DecodeElement(data []byte) (WebElement, error) {
-	if !wd.w3cCompatible {
-		reply := new(struct{ Value element })
-		if err := json.Unmarshal(data, reply); err != nil {
-			return nil, err
-		}
-		return &remoteWE{
-			parent: wd,
-			id:     reply.Value.Element,
-		}, nil
+	if elem, ok := wd.decodeCustomElement(data); ok {
+		return elem, nil
 	}
-	reply := new(struct{ Value map[string]string })
-	if err := json.Unmarshal(data, &reply); err != nil {
+	reply := new(struct{ Value map[string]interface{} })
+	if err := json.Unmarshal(data, reply); err != nil {
 		return nil, err
 	}
-	ref := reply.Value[webElementIdentifier]
-	if ref == "" {
+	if reply.Value == nil {
+		return nil, fmt.Errorf("server returned null for an element")
+	}
+	id := elementRefID(reply.Value)
+	if id == "" {
 		return nil, fmt.Errorf("invalid element returned: %+v", reply)
 	}
 
 	return &remoteWE{
 		parent: wd,
-		id:     ref,
+		id:     id,
 	}, nil
 }
 
 func (wd *remoteWD) FindElement(by, value string) (WebElement, error) {
 	response, err := wd.find(by, value, "", "")
+	if err != nil {
+		return nil, &ElementError{Op: "find", Locator: Locator{by, value}, Err: err}
+	}
+	elem, err := wd.DecodeElement(response)
 	if err != nil {
 		return nil, err
 	}
-	return wd.DecodeElement(response)
+	wd.count(statElementsFound, 1)
+	setProvenance(Locator{by, value}, nil, elem)
+	setRefindable(elem)
+	return elem, nil
 }
 
 func (wd *remoteWD) DecodeElements(data []byte) ([]WebElement, error) {
-	if !wd.w3cCompatible {
-		reply := new(struct{ Value []element })
-		if err := json.Unmarshal(data, reply); err != nil {
-			return nil, err
-		}
-
-		elems := make([]WebElement, len(reply.Value))
-		for i, elem := range reply.Value {
-			elems[i] = &remoteWE{
-				parent: wd,
-				id:     elem.Element,
-			}
-		}
+	if elems, ok := wd.decodeCustomElements(data); ok {
 		return elems, nil
 	}
-	reply := new(struct{ Value []map[string]string })
-	if err := json.Unmarshal(data, &reply); err != nil {
+	reply := new(struct{ Value []map[string]interface{} })
+	if err := json.Unmarshal(data, reply); err != nil {
 		return nil, err
 	}
 
 	elems := make([]WebElement, len(reply.Value))
 	for i, elem := range reply.Value {
-		ref := elem[webElementIdentifier]
-		if ref == "" {
+		id := elementRefID(elem)
+		if id == "" {
 			return nil, fmt.Errorf("invalid element returned: %+v", elem)
 		}
 		elems[i] = &remoteWE{
 			parent: wd,
-			id:     ref,
+			id:     id,
 		}
 	}
 
@@ -643,15 +1048,20 @@ func (wd *remoteWD) DecodeElements(data []byte) ([]WebElement, error) {
 func (wd *remoteWD) FindElements(by, value string) ([]WebElement, error) {
 	response, err := wd.find(by, value, "s", "")
 	if err != nil {
-		return nil, err
+		return nil, &ElementError{Op: "find", Locator: Locator{by, value}, Err: err}
 	}
 
-	return wd.DecodeElements(response)
+	elems, err := wd.DecodeElements(response)
+	if err != nil {
+		return nil, err
+	}
+	wd.count(statElementsFound, len(elems))
+	setProvenance(Locator{by, value}, nil, elems...)
+	return elems, nil
 }
 
 func (wd *remoteWD) Close() error {
-	url := wd.requestURL("/session/%s/window", wd.id)
-	_, err := wd.execute("DELETE", url, nil)
+	_, err := wd.CloseAndSwitch()
 	return err
 }
 
@@ -662,35 +1072,93 @@ func (wd *remoteWD) SwitchWindow(name string) error {
 	} else {
 		params["handle"] = name
 	}
-	url := wd.requestURL("/session/%s/window", wd.id)
-	return wd.voidCommand(url, params)
+	if err := wd.voidCommand("/session/%s/window", params); err != nil {
+		return err
+	}
+	wd.frames = nil
+	return nil
 }
 
-func (wd *remoteWD) CloseWindow(name string) error {
-	url := wd.requestURL("/session/%s/window", wd.id)
-	_, err := wd.execute("DELETE", url, nil)
-	return err
+func (wd *remoteWD) CloseWindow(name string) ([]string, error) {
+	// Only the current window can be closed, so switch to the named window
+	// first, and back to the original window afterwards if it was another.
+	var startWindow string
+	if name != "" {
+		var err error
+		startWindow, err = wd.CurrentWindowHandle()
+		if err != nil {
+			return nil, err
+		}
+		if name != startWindow {
+			if err := wd.switchToHandle(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	remaining, err := wd.closeCurrentWindow()
+	if err != nil || name == "" || name == startWindow {
+		return remaining, err
+	}
+	for _, h := range remaining {
+		if h == startWindow {
+			return remaining, wd.switchToHandle(startWindow)
+		}
+	}
+	return remaining, nil
 }
 
 func (wd *remoteWD) MaximizeWindow(name string) error {
+	wd.checkDialect("MaximizeWindow", name)
 	if !wd.w3cCompatible {
-		name, err := wd.CurrentWindowHandle()
-		if err != nil {
-			return err
+		if name == "" {
+			var err error
+			name, err = wd.CurrentWindowHandle()
+			if err != nil {
+				return err
+			}
 		}
 		url := wd.requestURL("/session/%s/window/%s/maximize", wd.id, name)
-		_, err = wd.execute("POST", url, nil)
+		_, err := wd.execute("POST", url, nil)
+		return err
+	}
+	_, err := wd.modifyWindow(name, "maximize", map[string]string{})
+	return err
+}
+
+// modifyWindow sends a command that modifies the window with the given name,
+// or the current window if name is empty, and returns the resulting window
+// rectangle, if the remote end reports it.
+func (wd *remoteWD) modifyWindow(name, command string, params interface{}) (*Rect, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var response []byte
+	err = wd.inWindow(name, func() error {
+		var err error
+		response, err = wd.execute("POST", wd.requestURL("/session/%s/window/%s", wd.id, command), data)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	return wd.modifyWindow(name, "maximize", map[string]string{})
+	reply := new(struct{ Value *Rect })
+	if err := decodeReply(response, reply, wd.strictDecoding, "/session/%s/window/%s", wd.id, command); err != nil {
+		return nil, err
+	}
+	return reply.Value, nil
 }
 
-func (wd *remoteWD) modifyWindow(name, command string, params interface{}) error {
+// inWindow runs f with the window with the given name as the current window,
+// or in the current window if name is empty.
+func (wd *remoteWD) inWindow(name string, f func() error) error {
 	// The original protocol allowed for maximizing any named window. The W3C
 	// specification only allows the current window be be modified. Emulate the
 	// previous behavior by switching to the target window, maximizing the
 	// current window, and switching back to the original window.
 	var startWindow string
+	frames := wd.FramePath()
 	if name != "" {
 		var err error
 		startWindow, err = wd.CurrentWindowHandle()
@@ -704,21 +1172,25 @@ func (wd *remoteWD) modifyWindow(name, command string, params interface{}) error
 		}
 	}
 
-	if err := wd.voidCommand("/session/%s/window/"+command, params); err != nil {
-		return err
-	}
+	err := f()
 
-	// TODO(minusnine): add a test for switching back to the original window.
 	if name != startWindow {
-		if err := wd.SwitchWindow(startWindow); err != nil {
-			return err
+		// Switching windows leaves the frames of the original window.
+		if switchErr := wd.SwitchWindow(startWindow); switchErr != nil {
+			if err == nil {
+				err = switchErr
+			}
+		} else if len(frames) > 0 {
+			if enterErr := wd.enterFramePath(frames); err == nil {
+				err = enterErr
+			}
 		}
 	}
-
-	return nil
+	return err
 }
 
 func (wd *remoteWD) ResizeWindow(name string, width, height int) error {
+	wd.checkDialect("ResizeWindow", name)
 	if !wd.w3cCompatible {
 		if len(name) == 0 {
 			var err error
@@ -744,54 +1216,130 @@ func (wd *remoteWD) ResizeWindow(name string, width, height int) error {
 		_, err = wd.execute("POST", url, data)
 		return err
 	}
-	return wd.modifyWindow(name, "rect", rect{
-		Width:  float64(width),
-		Height: float64(height),
+	// Leave out the position, which would otherwise be set to zero.
+	_, err := wd.modifyWindow(name, "rect", map[string]int{
+		"width":  width,
+		"height": height,
 	})
+	return err
 }
 
 func (wd *remoteWD) SwitchFrame(frame interface{}) error {
+	wd.checkDialect("SwitchFrame", frame)
 	params := map[string]interface{}{}
 	switch f := frame.(type) {
-	case WebElement, int, nil:
+	case nil:
+		params["id"] = nil
+	case WebElement:
 		params["id"] = f
+	case int:
+		params["id"] = wd.frameByIndex(f)
 	case string:
 		if f == "" {
 			params["id"] = nil
 		} else if wd.w3cCompatible {
 			e, err := wd.FindElement(ByID, f)
-			if err != nil {
+			if index, convErr := strconv.Atoi(f); isNoSuchElement(err) && convErr == nil {
+				params["id"] = wd.frameByIndex(index)
+			} else if err != nil {
 				return err
+			} else {
+				params["id"] = e
 			}
-			params["id"] = e
 		} else { // Legacy, non W3C-spec behavior.
 			params["id"] = f
 		}
 	default:
 		return fmt.Errorf("invalid type %T", frame)
 	}
-	return wd.voidCommand("/session/%s/frame", params)
+	if err := wd.voidCommand("/session/%s/frame", params); err != nil {
+		return err
+	}
+	if params["id"] == nil {
+		wd.frames = nil
+	} else {
+		wd.frames = append(wd.frames, params["id"])
+	}
+	return nil
 }
 
-func (wd *remoteWD) ActiveElement() (WebElement, error) {
-	url := wd.requestURL("/session/%s/element/active", wd.id)
-	response, err := wd.execute("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
+func (wd *remoteWD) SwitchToDefaultContent() error {
+	return wd.SwitchFrame(nil)
+}
 
-	return wd.DecodeElement(response)
+func (wd *remoteWD) SwitchFrameParent() error {
+	if err := wd.voidCommand("/session/%s/frame/parent", nil); err != nil {
+		return err
+	}
+	if n := len(wd.frames); n > 0 {
+		wd.frames = wd.frames[:n-1]
+	}
+	return nil
 }
 
-// ChromeDriver returns the expiration date as a float. Handle both formats
-// via a type switch.
-type cookie struct {
-	Name   string      `json:"name"`
-	Value  string      `json:"value"`
-	Path   string      `json:"path"`
-	Domain string      `json:"domain"`
-	Secure bool        `json:"secure"`
-	Expiry interface{} `json:"expiry"`
+func (wd *remoteWD) FramePath() []interface{} {
+	return append([]interface{}(nil), wd.frames...)
+}
+
+// enterFramePath switches to the top-level browsing context and then to the
+// frames of path in turn, e.g. to return to a frame after switching to
+// another window and back. It cannot re-enter frames after a reload, since
+// the element references in path are stale by then.
+func (wd *remoteWD) enterFramePath(path []interface{}) error {
+	if err := wd.SwitchToDefaultContent(); err != nil {
+		return err
+	}
+	for _, frame := range path {
+		if err := wd.voidCommand("/session/%s/frame", map[string]interface{}{"id": frame}); err != nil {
+			return err
+		}
+		wd.frames = append(wd.frames, frame)
+	}
+	return nil
+}
+
+// frameByIndex returns the frame or iframe element of the current document
+// with the given index, for W3C remote ends, some of which reject indices.
+// It returns the index itself if there is no such element, or for legacy
+// remote ends.
+func (wd *remoteWD) frameByIndex(index int) interface{} {
+	if !wd.w3cCompatible {
+		return index
+	}
+	frames, err := wd.FindElements(ByCSSSelector, "frame, iframe")
+	if err != nil || index < 0 || index >= len(frames) {
+		return index
+	}
+	return frames[index]
+}
+
+func (wd *remoteWD) ActiveElement() (WebElement, error) {
+	url := wd.requestURL("/session/%s/element/active", wd.id)
+	response, err := wd.execute("GET", url, nil)
+	if isUnknownCommand(err) || err != nil && strings.Contains(err.Error(), http.StatusText(http.StatusMethodNotAllowed)) {
+		// The legacy protocol defines the command with POST.
+		response, err = wd.execute("POST", url, []byte("{}"))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return wd.DecodeElement(response)
+}
+
+// ChromeDriver returns the expiration date as a float. Handle both formats
+// via a type switch.
+type cookie struct {
+	Name   string      `json:"name"`
+	Value  string      `json:"value"`
+	Path   string      `json:"path"`
+	Domain string      `json:"domain"`
+	Secure bool        `json:"secure"`
+	Expiry interface{} `json:"expiry"`
+	// HTTPOnly and SameSite are not held by Cookie, so a cookie that has
+	// them cannot be added back as it was.
+	HTTPOnly bool   `json:"httpOnly"`
+	SameSite string `json:"sameSite"`
 }
 
 func (c cookie) sanitize() Cookie {
@@ -813,9 +1361,28 @@ func (c cookie) sanitize() Cookie {
 	return sanitized
 }
 
+// isUnknownCommand returns true if err indicates that the remote end does not
+// implement the requested command.
+func isUnknownCommand(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Err == "unknown command" || e.Err == "unknown method"
+	}
+	return err != nil && strings.HasPrefix(unwrapElementError(err).Error(), remoteErrors[9])
+}
+
 func (wd *remoteWD) GetCookie(name string) (Cookie, error) {
-	url := wd.requestURL("/session/%s/cookie/%s", wd.id, name)
-	data, err := wd.execute("GET", url, nil)
+	if wd.namedCookieUnsupported {
+		return wd.findCookie(name)
+	}
+	cookieURL := wd.requestURL("/session/%s/cookie/%s", wd.id, url.PathEscape(name))
+	data, err := wd.execute("GET", cookieURL, nil)
+	if isUnknownCommand(err) {
+		// Servers predating the W3C specification, such as Selenium 2, do not
+		// implement this endpoint. Remember that for the rest of the session.
+		wd.namedCookieUnsupported = true
+		return wd.findCookie(name)
+	}
 	if err != nil {
 		return Cookie{}, err
 	}
@@ -824,8 +1391,11 @@ func (wd *remoteWD) GetCookie(name string) (Cookie, error) {
 	// cookie and a list.
 	//
 	// https://github.com/mozilla/geckodriver/issues/761
-	reply := new(struct{ Value cookie })
+	reply := new(struct{ Value *cookie })
 	if err := json.Unmarshal(data, reply); err == nil {
+		if reply.Value == nil {
+			return Cookie{}, nullValueError("/session/%s/cookie/%s", wd.id, name)
+		}
 		return reply.Value.sanitize(), nil
 	}
 	listReply := new(struct{ Value []cookie })
@@ -838,7 +1408,38 @@ func (wd *remoteWD) GetCookie(name string) (Cookie, error) {
 	return listReply.Value[0].sanitize(), nil
 }
 
+// findCookie emulates GetCookie by filtering the result of GetCookies.
+func (wd *remoteWD) findCookie(name string) (Cookie, error) {
+	cookies, err := wd.GetCookies()
+	if err != nil {
+		return Cookie{}, err
+	}
+	for _, c := range cookies {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return Cookie{}, &Error{
+		Err:     "no such cookie",
+		Message: fmt.Sprintf("no cookie named %q", name),
+	}
+}
+
 func (wd *remoteWD) GetCookies() ([]Cookie, error) {
+	raw, err := wd.cookies()
+	if err != nil {
+		return nil, err
+	}
+	cookies := make([]Cookie, len(raw))
+	for i, c := range raw {
+		cookies[i] = c.sanitize()
+	}
+	return cookies, nil
+}
+
+// cookies returns the cookies in the browser's jar, as reported by the remote
+// end.
+func (wd *remoteWD) cookies() ([]cookie, error) {
 	url := wd.requestURL("/session/%s/cookie", wd.id)
 	data, err := wd.execute("GET", url, nil)
 	if err != nil {
@@ -849,28 +1450,7 @@ func (wd *remoteWD) GetCookies() ([]Cookie, error) {
 	if err := json.Unmarshal(data, reply); err != nil {
 		return nil, err
 	}
-
-	cookies := make([]Cookie, len(reply.Value))
-	for i, c := range reply.Value {
-		sanitized := Cookie{
-			Name:   c.Name,
-			Value:  c.Value,
-			Path:   c.Path,
-			Domain: c.Domain,
-			Secure: c.Secure,
-		}
-		switch expiry := c.Expiry.(type) {
-		case int:
-			if expiry > 0 {
-				sanitized.Expiry = uint(expiry)
-			}
-		case float64:
-			sanitized.Expiry = uint(expiry)
-		}
-		cookies[i] = sanitized
-	}
-
-	return cookies, nil
+	return reply.Value, nil
 }
 
 func (wd *remoteWD) AddCookie(cookie *Cookie) error {
@@ -886,32 +1466,45 @@ func (wd *remoteWD) DeleteAllCookies() error {
 }
 
 func (wd *remoteWD) DeleteCookie(name string) error {
-	url := wd.requestURL("/session/%s/cookie/%s", wd.id, name)
-	_, err := wd.execute("DELETE", url, nil)
+	cookieURL := wd.requestURL("/session/%s/cookie/%s", wd.id, url.PathEscape(name))
+	_, err := wd.execute("DELETE", cookieURL, nil)
 	return err
 }
 
-func (wd *remoteWD) Click(button int) error {
-	return wd.voidCommand("/session/%s/click", map[string]int{
+func (wd *remoteWD) Click(button MouseButton) error {
+	if err := checkMouseButton(button); err != nil {
+		return err
+	}
+	if wd.w3cCompatible {
+		return wd.PerformActions([]ActionSequence{PointerSequence(defaultMouseID, MousePointer,
+			PointerDownAction(button),
+			PointerUpAction(button),
+		)})
+	}
+	return wd.voidCommand("/session/%s/click", map[string]MouseButton{
 		"button": button,
 	})
 }
 
 func (wd *remoteWD) DoubleClick() error {
+	wd.checkDialect("DoubleClick")
 	return wd.voidCommand("/session/%s/doubleclick", nil)
 }
 
 func (wd *remoteWD) ButtonDown() error {
+	wd.checkDialect("ButtonDown")
 	return wd.voidCommand("/session/%s/buttondown", nil)
 }
 
 func (wd *remoteWD) ButtonUp() error {
+	wd.checkDialect("ButtonUp")
 	return wd.voidCommand("/session/%s/buttonup", nil)
 }
 
 // TODO(minusnine): add a test for SendModifier.
 // TODO(minusnine): deprecate thie method in favor of KeyDown and KeyUp.
 func (wd *remoteWD) SendModifier(modifier string, isDown bool) error {
+	wd.checkDialect("SendModifier", modifier)
 	if !wd.w3cCompatible {
 		return wd.voidCommand("/session/%s/modifier", map[string]interface{}{
 			"value":  modifier,
@@ -926,25 +1519,11 @@ func (wd *remoteWD) SendModifier(modifier string, isDown bool) error {
 }
 
 func (wd *remoteWD) keyAction(action, keys string) error {
-	type keyAction struct {
-		Type string `json:"type"`
-		Key  string `json:"value"`
-	}
-	actions := make([]keyAction, 0, len(keys))
+	actions := make([]Action, 0, len(keys))
 	for _, key := range keys {
-		actions = append(actions, keyAction{
-			Type: action,
-			Key:  string(key),
-		})
+		actions = append(actions, Action{"type": action, "value": string(key)})
 	}
-	return wd.voidCommand("/session/%s/actions", map[string]interface{}{
-		"actions": []interface{}{
-			map[string]interface{}{
-				"type":    "key",
-				"id":      "default keyboard",
-				"actions": actions,
-			}},
-	})
+	return wd.PerformActions([]ActionSequence{KeySequence("default keyboard", actions...)})
 }
 
 func (wd *remoteWD) KeyDown(keys string) error {
@@ -955,15 +1534,13 @@ func (wd *remoteWD) KeyDown(keys string) error {
 }
 
 func (wd *remoteWD) KeyUp(keys string) error {
+	wd.checkDialect("KeyUp", keys)
 	if !wd.w3cCompatible {
 		return wd.KeyDown(keys)
 	}
 	return wd.keyAction("keyUp", keys)
 }
 
-// TODO(minusnine): Implement PerformActions and ReleaseActions, for more
-// direct access to the W3C specification.
-
 // TODO(minusnine): update the Alert methods to the W3C specification and add a
 // test.
 func (wd *remoteWD) DismissAlert() error {
@@ -1000,7 +1577,12 @@ func (wd *remoteWD) execScriptRaw(script string, args []interface{}, suffix stri
 		return nil, err
 	}
 
-	return wd.execute("POST", wd.requestURL("/session/%s/execute"+suffix, wd.id), data)
+	response, err := wd.execute("POST", wd.requestURL("/session/%s/execute"+suffix, wd.id), data)
+	if err != nil {
+		return nil, err
+	}
+	wd.count(statScriptsExecuted, 1)
+	return response, nil
 }
 
 func (wd *remoteWD) execScript(script string, args []interface{}, suffix string) (interface{}, error) {
@@ -1046,18 +1628,18 @@ func (wd *remoteWD) ExecuteScriptAsyncRaw(script string, args []interface{}) ([]
 }
 
 func (wd *remoteWD) Screenshot() ([]byte, error) {
-	data, err := wd.stringCommand("/session/%s/screenshot")
-	if err != nil {
+	buf := new(bytes.Buffer)
+	if err := wd.ScreenshotTo(buf); err != nil {
 		return nil, err
 	}
-
-	// Selenium returns a base64 encoded image.
-	buf := []byte(data)
-	decoder := base64.NewDecoder(base64.StdEncoding, bytes.NewBuffer(buf))
-	return ioutil.ReadAll(decoder)
+	return buf.Bytes(), nil
 }
 
 func (wd *remoteWD) Log(typ LogType) ([]LogMessage, error) {
+	typ, err := LogTypeOf(string(typ))
+	if err != nil {
+		return nil, err
+	}
 	url := wd.requestURL("/session/%s/log", wd.id)
 	params := map[string]LogType{
 		"type": typ,
@@ -1072,9 +1654,14 @@ func (wd *remoteWD) Log(typ LogType) ([]LogMessage, error) {
 	}
 
 	c := new(struct{ Value []LogMessage })
-	if err = json.Unmarshal(response, c); err != nil {
+	if err = decodeReply(response, c, wd.strictDecoding, "/session/%s/log", wd.id); err != nil {
 		return nil, err
 	}
+	// Reading the log drains it, so the responses it reports are recorded
+	// here for SubresourceResponses.
+	if typ == Performance {
+		wd.responseRecorder.observeLogs(c.Value)
+	}
 
 	return c.Value, nil
 }
@@ -1087,16 +1674,287 @@ type remoteWE struct {
 	// that the value is called a "reference". For ease of transition, we store
 	// the "reference" in this now misnamed field.
 	id string
+
+	// locator is the locator the element was found with, if known, and
+	// within is the element it was found within, if any.
+	locator Locator
+	within  *remoteWE
+	// refindable is set if the element was returned by FindElement, so that
+	// it can be found again with locator once it is stale.
+	refindable bool
+
+	// tagName caches the tag name of the element, which cannot change, once
+	// it is known from TagName or Describe.
+	tagName string
+}
+
+// ErrInvalidElement is returned by the methods of a WebElement that does not
+// reference an element, for example one decoded from a reply that carried no
+// element reference.
+var ErrInvalidElement = errors.New("invalid element: the element has no ID")
+
+func (elem *remoteWE) String() string {
+	if elem.locator.By == "" {
+		return fmt.Sprintf("element[%s]", elem.id)
+	}
+	return fmt.Sprintf("element[%s via %s]", elem.id, elem.locator)
+}
+
+// checkID returns ErrInvalidElement if the element has no ID, so that commands
+// are not sent to URLs such as "/element//click", which remote ends may route
+// to a different command.
+func (elem *remoteWE) checkID() error {
+	if elem.id == "" {
+		return ErrInvalidElement
+	}
+	return nil
+}
+
+// isStaleElement returns true if err indicates that the element referenced by
+// a command is no longer attached to the DOM.
+func isStaleElement(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Err == "stale element reference"
+	}
+	return err != nil && strings.HasPrefix(unwrapElementError(err).Error(), remoteErrors[10])
+}
+
+func isNoSuchElement(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Err == "no such element"
+	}
+	return err != nil && strings.HasPrefix(unwrapElementError(err).Error(), remoteErrors[7])
+}
+
+// isNotInteractable returns true if err indicates that the element cannot be
+// interacted with, e.g. because it is hidden.
+func isNotInteractable(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Err == "element not interactable" || e.Err == "element not visible"
+	}
+	return err != nil && strings.HasPrefix(unwrapElementError(err).Error(), remoteErrors[11])
+}
+
+// noteError inspects the error returned by a command on the element, and
+// invalidates cached data if the element has become stale.
+func (elem *remoteWE) noteError(err error) error {
+	if isStaleElement(err) {
+		elem.InvalidateCache()
+	}
+	return err
+}
+
+// execute performs the element command with the given URL suffix, e.g.
+// "/click".
+func (elem *remoteWE) execute(method, suffix string, data []byte) (json.RawMessage, error) {
+	if err := elem.checkID(); err != nil {
+		return nil, err
+	}
+	wd := elem.parent
+	response, err := wd.execute(method, wd.requestURL("/session/%s/element/%s%s", wd.id, elem.id, suffix), data)
+	for attempt := 1; attempt < wd.staleRetry && isStaleElement(err) && elem.retriesStale(suffix); attempt++ {
+		if elem.refind() != nil {
+			// The stale element error is more useful than the failure to
+			// find the element again.
+			break
+		}
+		response, err = wd.execute(method, wd.requestURL("/session/%s/element/%s%s", wd.id, elem.id, suffix), data)
+	}
+	return response, elem.wrapError(strings.TrimPrefix(suffix, "/"), err)
+}
+
+func (elem *remoteWE) voidCommand(suffix string, params interface{}) error {
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	_, err = elem.execute("POST", suffix, data)
+	return err
+}
+
+func (elem *remoteWE) stringCommand(suffix string) (string, error) {
+	response, err := elem.execute("GET", suffix, nil)
+	if err != nil {
+		return "", err
+	}
+	return decodeValue[string](response, elem.parent.strictDecoding, "/session/%s/element/%s%s", elem.parent.id, elem.id, suffix)
+}
+
+// getValue sends a GET request for the element command with the given URL
+// suffix and decodes the value of the response into v, leaving the fields of
+// v that the value lacks unchanged.
+func (elem *remoteWE) getValue(suffix string, v interface{}) error {
+	response, err := elem.execute("GET", suffix, nil)
+	if err != nil {
+		return err
+	}
+	if elem.parent.strictDecoding {
+		if err := checkShape(response, v, "/session/%s/element/%s%s", elem.parent.id, elem.id, suffix); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(response, &struct{ Value interface{} }{v})
+}
+
+func (elem *remoteWE) boolCommand(suffix string) (bool, error) {
+	response, err := elem.execute("GET", suffix, nil)
+	if err != nil {
+		return false, err
+	}
+	value, err := decodeValue[*bool](response, elem.parent.strictDecoding, "/session/%s/element/%s%s", elem.parent.id, elem.id, suffix)
+	if value == nil {
+		return false, err
+	}
+	return *value, err
 }
 
 func (elem *remoteWE) Click() error {
-	urlTemplate := fmt.Sprintf("/session/%%s/element/%s/click", elem.id)
-	return elem.parent.voidCommand(urlTemplate, nil)
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	elem.parent.checkDialect("Click")
+	if err := elem.checkFileDialogGuard(); err != nil {
+		return err
+	}
+	if elem.parent.pointerPrecision == UseElementFromPoint {
+		p, err := elem.hitPoint()
+		if err != nil {
+			return err
+		}
+		if !p.Center {
+			return elem.pointerAt(p, true)
+		}
+	}
+	return elem.clickWithRetry()
+}
+
+func (elem *remoteWE) Hover() error {
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	if elem.parent.pointerPrecision == UseElementFromPoint {
+		p, err := elem.hitPoint()
+		if err != nil {
+			return err
+		}
+		return elem.pointerAt(p, false)
+	}
+	return elem.HoverAt(0, 0)
+}
+
+func (elem *remoteWE) HoverAt(xOffset, yOffset int) error {
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	wd := elem.parent
+	move := PointerMoveAction(elem, xOffset, yOffset)
+	if wd.w3cCompatible {
+		return wd.PerformActions([]ActionSequence{PointerSequence(defaultMouseID, MousePointer, move)})
+	}
+	return wd.legacyAction(move)
+}
+
+func (elem *remoteWE) ClickAt(xOffset, yOffset int) error {
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	wd := elem.parent
+	if !wd.w3cCompatible {
+		id, err := elementReference(elem)
+		if err != nil {
+			return err
+		}
+		// The legacy protocol's offsets are relative to the top-left corner of
+		// the element.
+		if err := wd.voidCommand("/session/%s/moveto", map[string]interface{}{
+			"element": id,
+			"xoffset": xOffset,
+			"yoffset": yOffset,
+		}); err != nil {
+			return elem.wrapError("moveto", err)
+		}
+		return wd.Click(LeftButton)
+	}
+	// W3C offsets are relative to the in-view center point of the element.
+	rect, err := elem.Rect()
+	if err != nil {
+		return err
+	}
+	return wd.PerformActions([]ActionSequence{PointerSequence(defaultMouseID, MousePointer,
+		PointerMoveAction(elem, xOffset-int(rect.Width)/2, yOffset-int(rect.Height)/2),
+		PointerDownAction(LeftButton),
+		PointerUpAction(LeftButton),
+	)})
+}
+
+func (elem *remoteWE) RightClick() error {
+	return elem.clickButton(RightButton)
+}
+
+func (elem *remoteWE) DoubleClick() error {
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	wd := elem.parent
+	if wd.w3cCompatible {
+		return wd.PerformActions([]ActionSequence{PointerSequence(defaultMouseID, MousePointer,
+			PointerMoveAction(elem, 0, 0),
+			PointerDownAction(LeftButton),
+			PointerUpAction(LeftButton),
+			PointerDownAction(LeftButton),
+			PointerUpAction(LeftButton),
+		)})
+	}
+	if err := wd.legacyAction(PointerMoveAction(elem, 0, 0)); err != nil {
+		return err
+	}
+	return wd.DoubleClick()
+}
+
+func (elem *remoteWE) MiddleClick() error {
+	return elem.clickButton(MiddleButton)
+}
+
+// clickButton clicks the center of the element with the button.
+func (elem *remoteWE) clickButton(button MouseButton) error {
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	wd := elem.parent
+	if wd.w3cCompatible {
+		return wd.PerformActions([]ActionSequence{PointerSequence(defaultMouseID, MousePointer,
+			PointerMoveAction(elem, 0, 0),
+			PointerDownAction(button),
+			PointerUpAction(button),
+		)})
+	}
+	if err := wd.legacyAction(PointerMoveAction(elem, 0, 0)); err != nil {
+		return err
+	}
+	return wd.Click(button)
 }
 
 func (elem *remoteWE) SendKeys(keys string) error {
-	urlTemplate := fmt.Sprintf("/session/%%s/element/%s/value", elem.id)
-	return elem.parent.voidCommand(urlTemplate, elem.parent.processKeyString(keys))
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	elem.parent.checkDialect("SendKeys", keys)
+	keys, err := elem.parent.uploadKeys(keys)
+	if err != nil {
+		return err
+	}
+	return elem.voidCommand("/value", elem.parent.processKeyString(keys))
+}
+
+func (elem *remoteWE) SendKeysLiteral(keys string) error {
+	elem.parent.checkDialect("SendKeys", keys)
+	return elem.voidCommand("/value", elem.parent.processKeyString(keys))
 }
 
 func (wd *remoteWD) processKeyString(keys string) interface{} {
@@ -1111,162 +1969,244 @@ func (wd *remoteWD) processKeyString(keys string) interface{} {
 }
 
 func (elem *remoteWE) TagName() (string, error) {
-	urlTemplate := fmt.Sprintf("/session/%%s/element/%s/name", elem.id)
-	return elem.parent.stringCommand(urlTemplate)
+	if elem.tagName != "" {
+		return elem.tagName, nil
+	}
+	name, err := elem.stringCommand("/name")
+	if err != nil {
+		return "", err
+	}
+	elem.tagName = name
+	return name, nil
 }
 
 func (elem *remoteWE) Text() (string, error) {
-	urlTemplate := fmt.Sprintf("/session/%%s/element/%s/text", elem.id)
-	return elem.parent.stringCommand(urlTemplate)
+	return elem.stringCommand("/text")
 }
 
 func (elem *remoteWE) Submit() error {
-	urlTemplate := fmt.Sprintf("/session/%%s/element/%s/submit", elem.id)
-	return elem.parent.voidCommand(urlTemplate, nil)
+	elem.parent.checkDialect("Submit")
+	if elem.parent.w3cCompatible {
+		return elem.submitByScript()
+	}
+	return elem.voidCommand("/submit", nil)
 }
 
 func (elem *remoteWE) Clear() error {
-	urlTemplate := fmt.Sprintf("/session/%%s/element/%s/clear", elem.id)
-	return elem.parent.voidCommand(urlTemplate, nil)
+	return elem.voidCommand("/clear", nil)
 }
 
 func (elem *remoteWE) MoveTo(xOffset, yOffset int) error {
-	return elem.parent.voidCommand("/session/%s/moveto", map[string]interface{}{
-		"element": elem.id,
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	elem.parent.checkDialect("MoveTo", xOffset, yOffset)
+	if elem.parent.pointerPrecision == UseElementFromPoint && xOffset == 0 && yOffset == 0 {
+		p, err := elem.hitPoint()
+		if err != nil {
+			return err
+		}
+		return elem.pointerAt(p, false)
+	}
+	id, err := elementReference(elem)
+	if err != nil {
+		return err
+	}
+	return elem.wrapError("moveto", elem.parent.voidCommand("/session/%s/moveto", map[string]interface{}{
+		"element": id,
 		"xoffset": xOffset,
 		"yoffset": yOffset,
-	})
+	}))
 }
 
 func (elem *remoteWE) FindElement(by, value string) (WebElement, error) {
-	url := fmt.Sprintf("/session/%%s/element/%s/element", elem.id)
-	response, err := elem.parent.find(by, value, "", url)
+	response, err := elem.findFrom(by, value, "")
 	if err != nil {
 		return nil, err
 	}
 
-	return elem.parent.DecodeElement(response)
+	found, err := elem.parent.DecodeElement(response)
+	if err != nil {
+		return nil, err
+	}
+	elem.parent.count(statElementsFound, 1)
+	setProvenance(Locator{by, value}, elem, found)
+	setRefindable(found)
+	return found, nil
 }
 
 func (elem *remoteWE) FindElements(by, value string) ([]WebElement, error) {
-	url := fmt.Sprintf("/session/%%s/element/%s/element", elem.id)
-	response, err := elem.parent.find(by, value, "s", url)
+	response, err := elem.findFrom(by, value, "s")
 	if err != nil {
 		return nil, err
 	}
 
-	return elem.parent.DecodeElements(response)
-}
-
-func (elem *remoteWE) boolQuery(urlTemplate string) (bool, error) {
-	return elem.parent.boolCommand(fmt.Sprintf(urlTemplate, elem.id))
+	found, err := elem.parent.DecodeElements(response)
+	if err != nil {
+		return nil, err
+	}
+	elem.parent.count(statElementsFound, len(found))
+	setProvenance(Locator{by, value}, elem, found...)
+	return found, nil
 }
 
 func (elem *remoteWE) IsSelected() (bool, error) {
-	return elem.boolQuery("/session/%%s/element/%s/selected")
+	return elem.boolCommand("/selected")
 }
 
 func (elem *remoteWE) IsEnabled() (bool, error) {
-	return elem.boolQuery("/session/%%s/element/%s/enabled")
-}
-
-func (elem *remoteWE) IsDisplayed() (bool, error) {
-	return elem.boolQuery("/session/%%s/element/%s/displayed")
+	return elem.boolCommand("/enabled")
 }
 
 func (elem *remoteWE) GetAttribute(name string) (string, error) {
-	template := "/session/%%s/element/%s/attribute/%s"
-	urlTemplate := fmt.Sprintf(template, elem.id, name)
-
-	return elem.parent.stringCommand(urlTemplate)
+	elem.parent.checkDialect("GetAttribute", name)
+	return elem.stringCommand("/attribute/" + url.PathEscape(name))
 }
 
-func (elem *remoteWE) location(suffix string) (*Point, error) {
-	if !elem.parent.w3cCompatible {
-		wd := elem.parent
-		path := "/session/%s/element/%s/location" + suffix
-		url := wd.requestURL(path, wd.id, elem.id)
-		response, err := wd.execute("GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		reply := new(struct{ Value Point })
-		if err := json.Unmarshal(response, reply); err != nil {
-			return nil, err
-		}
-		return &reply.Value, nil
+func (elem *remoteWE) GetPropertyRaw(name string) (json.RawMessage, error) {
+	if err := elem.checkID(); err != nil {
+		return nil, err
+	}
+	var response []byte
+	var err error
+	if elem.parent.w3cCompatible {
+		response, err = elem.execute("GET", "/property/"+url.PathEscape(name), nil)
+	}
+	if !elem.parent.w3cCompatible || isUnknownCommand(err) {
+		// The legacy protocol has no command for properties.
+		response, err = elem.parent.ExecuteScriptRaw("return arguments[0][arguments[1]];", []interface{}{elem, name})
+		err = elem.wrapError("property/"+name, err)
 	}
-
-	rect, err := elem.rect()
 	if err != nil {
 		return nil, err
 	}
-	return &Point{int(rect.X), int(rect.Y)}, nil
+	reply := new(struct{ Value json.RawMessage })
+	if err := json.Unmarshal(response, reply); err != nil {
+		return nil, err
+	}
+	if len(reply.Value) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	return reply.Value, nil
+}
+
+func (elem *remoteWE) GetProperty(name string) (string, error) {
+	value, err := elem.GetPropertyRaw(name)
+	if err != nil {
+		return "", err
+	}
+	var s string
+	switch {
+	case string(value) == "null":
+		return "", nil
+	case json.Unmarshal(value, &s) == nil:
+		return s, nil
+	}
+	return string(value), nil
 }
 
 func (elem *remoteWE) Location() (*Point, error) {
-	return elem.location("")
+	rect, err := elem.rectPart("/location")
+	if err != nil {
+		return nil, err
+	}
+	return &Point{int(rect.X), int(rect.Y)}, nil
 }
 
 func (elem *remoteWE) LocationInView() (*Point, error) {
-	return elem.location("_in_view")
+	elem.parent.checkDialect("LocationInView")
+	if elem.parent.w3cCompatible {
+		return elem.Location()
+	}
+	// The legacy location in view endpoint scrolls the element into view, so
+	// the cached rectangle cannot be used in its place.
+	var p Point
+	if err := elem.getValue("/location_in_view", &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
 }
 
 func (elem *remoteWE) Size() (*Size, error) {
-	if !elem.parent.w3cCompatible {
-		wd := elem.parent
-		url := wd.requestURL("/session/%s/element/%s/size", wd.id, elem.id)
-		response, err := wd.execute("GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		reply := new(struct{ Value Size })
-		if err := json.Unmarshal(response, reply); err != nil {
-			return nil, err
-		}
-		return &reply.Value, nil
-	}
-
-	rect, err := elem.rect()
+	rect, err := elem.rectPart("/size")
 	if err != nil {
 		return nil, err
 	}
-
 	return &Size{int(rect.Width), int(rect.Height)}, nil
 }
 
-type rect struct {
-	X      float64 `json:"x"`
-	Y      float64 `json:"y"`
-	Width  float64 `json:"width"`
-	Height float64 `json:"height"`
+// Rect implements the "Get Element Rect" method of the W3C standard. On
+// legacy sessions, it combines the element's location and size.
+func (elem *remoteWE) Rect() (*Rect, error) {
+	if elem.parent.w3cCompatible {
+		return elem.rectPart("/rect")
+	}
+	r, err := elem.rectPart("/location")
+	if err != nil {
+		return nil, err
+	}
+	size, err := elem.rectPart("/size")
+	if err != nil {
+		return nil, err
+	}
+	r.Width, r.Height = size.Width, size.Height
+	return r, nil
 }
 
-// rect implements the "Get Element Rect" method of the W3C standard.
-func (elem *remoteWE) rect() (*rect, error) {
-	wd := elem.parent
-	url := wd.requestURL("/session/%s/element/%s/rect", wd.id, elem.id)
-	response, err := wd.execute("GET", url, nil)
+// rectPart returns the part of the element's rectangle returned by the
+// legacy command with the given suffix, "/location" or "/size". W3C sessions
+// send the "Get Element Rect" command instead, which returns all of it.
+func (elem *remoteWE) rectPart(suffix string) (*Rect, error) {
+	if elem.parent.w3cCompatible {
+		suffix = "/rect"
+	}
+	response, err := elem.execute("GET", suffix, nil)
 	if err != nil {
 		return nil, err
 	}
-	r := new(struct{ Value rect })
-	if err := json.Unmarshal(response, r); err != nil {
+	wd := elem.parent
+	reply := new(struct{ Value Rect })
+	if err := decodeReply(response, reply, wd.strictDecoding, "/session/%s/element/%s%s", wd.id, elem.id, suffix); err != nil {
 		return nil, err
 	}
-	return &r.Value, nil
+	return &reply.Value, nil
 }
 
 func (elem *remoteWE) CSSProperty(name string) (string, error) {
-	wd := elem.parent
-	return wd.stringCommand(fmt.Sprintf("/session/%%s/element/%s/css/%s", elem.id, name))
+	return elem.stringCommand("/css/" + url.PathEscape(name))
+}
+
+func (elem *remoteWE) ComputedRole() (string, error) {
+	role, err := elem.stringCommand("/computedrole")
+	if isUnknownCommand(err) {
+		return "", &UnsupportedCommandError{Command: "ComputedRole", Err: err}
+	}
+	return role, err
+}
+
+func (elem *remoteWE) ComputedLabel() (string, error) {
+	label, err := elem.stringCommand("/computedlabel")
+	if isUnknownCommand(err) {
+		return "", &UnsupportedCommandError{Command: "ComputedLabel", Err: err}
+	}
+	return label, err
 }
 
 // webElementIdentifier is the string constant defined by the W3C specification
 // that is the key for the map that contains an element.
 const webElementIdentifier = "element-6066-11e4-a52e-4f735466cecf"
 
+func (elem *remoteWE) Driver() WebDriver {
+	return elem.parent
+}
+
 func (elem *remoteWE) MarshalJSON() ([]byte, error) {
+	if err := elem.checkID(); err != nil {
+		return nil, err
+	}
+	if elem.parent != nil && elem.parent.elementEncoder != nil {
+		return json.Marshal(elem.parent.elementEncoder(elem.id))
+	}
 	return json.Marshal(map[string]string{
 		"ELEMENT":            elem.id,
 		webElementIdentifier: elem.id,
@@ -1275,14 +2215,22 @@ func (elem *remoteWE) MarshalJSON() ([]byte, error) {
 
 func init() {
 	// http.Client doesn't copy request headers, and selenium requires that
-	httpClient = &http.Client{
+	httpClient.Store(&http.Client{
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			if len(via) > MaxRedirects {
 				return fmt.Errorf("too many redirects (%d)", len(via))
 			}
 
-			req.Header.Add("Accept", JSONType)
+			if id, ok := req.Context().Value(sessionRedirectKey{}).(*string); ok {
+				if *id = sessionIDFromURL(req.URL); *id != "" {
+					return http.ErrUseLastResponse
+				}
+			}
+
+			// A 303 redirect turns a POST into a bodiless GET, whereas 307 and 308
+			// redirects preserve the body.
+			setRequestHeaders(req, req.GetBody != nil && req.ContentLength != 0)
 			return nil
 		},
-	}
+	})
 }
@@ -1,9 +1,15 @@
 package selenium
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"math"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -38,6 +44,10 @@ var (
 
 	startFrameBuffer = flag.Bool("start_frame_buffer", true, "If true, start an Xvfb subprocess and run the browsers in that X server.")
 
+	keepNoisyFeatures = flag.Bool("keep_noisy_features", false, "If set, the browsers are not configured to disable their password, translation and first-run prompts.")
+
+	vendorManifest = flag.String("vendor_manifest", "vendor/manifest.json", "The path to the manifest written by vendor/init.go. If the file is present, tests refuse to run against binaries in its directory that do not match it.")
+
 	serverURL string
 )
 
@@ -66,10 +76,57 @@ func pickUnusedPort() (int, error) {
 	return port, nil
 }
 
+// checkVendorManifest fails the test if any of the binaries at paths is
+// located in the directory of the vendor manifest but does not match the
+// manifest's record of it.
+func checkVendorManifest(t *testing.T, paths ...string) {
+	data, err := ioutil.ReadFile(*vendorManifest)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		t.Fatalf("Error reading the vendor manifest: %v", err)
+	}
+	var m struct {
+		Files []struct {
+			Path   string
+			Size   int64
+			SHA256 string
+		}
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("Error parsing the vendor manifest %q: %v", *vendorManifest, err)
+	}
+	dir := filepath.Dir(*vendorManifest)
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		if rel, err := filepath.Rel(dir, p); err != nil || strings.HasPrefix(rel, "..") {
+			// Binaries outside the vendor directory are not managed by init.go.
+			continue
+		}
+		for _, e := range m.Files {
+			if filepath.Join(dir, e.Path) != p {
+				continue
+			}
+			data, err := ioutil.ReadFile(p)
+			if err != nil {
+				t.Fatalf("Error reading %q: %v", p, err)
+			}
+			sum := sha256.Sum256(data)
+			if int64(len(data)) != e.Size || hex.EncodeToString(sum[:]) != e.SHA256 {
+				t.Fatalf("%q does not match the vendor manifest %q; run `go run init.go --verify` in the vendor directory for details", p, *vendorManifest)
+			}
+		}
+	}
+}
+
 type config struct {
 	addr, browser, path string
 	seleniumVersion     semver.Version
 	serviceOptions      []ServiceOption
+	// strictDecoding enables WebDriver.SetStrictDecoding in the sessions
+	// created by newRemote.
+	strictDecoding bool
 }
 
 func TestChrome(t *testing.T) {
@@ -86,6 +143,7 @@ func TestChrome(t *testing.T) {
 	if _, err := os.Stat(*chromeDriverPath); err != nil {
 		t.Skipf("Skipping Chrome tests because ChromeDriver not found at path %q", *chromeDriverPath)
 	}
+	checkVendorManifest(t, *chromeDriverPath, *chromeBinary)
 
 	var opts []ServiceOption
 	if *startFrameBuffer {
@@ -113,6 +171,12 @@ func TestChrome(t *testing.T) {
 
 	runTests(t, c)
 
+	// Run the suite once more with strict decoding, so that changes of the
+	// shapes of the responses of new ChromeDriver versions fail the tests.
+	strict := c
+	strict.strictDecoding = true
+	t.Run("StrictDecoding", func(t *testing.T) { runTests(t, strict) })
+
 	// Chrome-specific tests.
 	t.Run("Extension", runTest(testChromeExtension, c))
 
@@ -164,6 +228,7 @@ func TestFirefoxSelenium2(t *testing.T) {
 	if _, err := os.Stat(*selenium2Path); err != nil {
 		t.Skipf("Skipping Firefox tests using Selenium 2 because Selenium WebDriver JAR not found at path %q", *selenium2Path)
 	}
+	checkVendorManifest(t, *firefoxBinarySelenium2)
 	runFirefoxTests(t, *selenium2Path, config{
 		seleniumVersion: semver.MustParse("2.0.0"),
 		path:            *firefoxBinarySelenium2,
@@ -180,6 +245,7 @@ func TestFirefoxSelenium3(t *testing.T) {
 	if _, err := os.Stat(*geckoDriverPath); err != nil {
 		t.Skipf("Skipping Firefox tests on Selenium 3 because geckodriver binary %q not found", *geckoDriverPath)
 	}
+	checkVendorManifest(t, *geckoDriverPath, *firefoxBinarySelenium3)
 
 	runFirefoxTests(t, *selenium3Path, config{
 		seleniumVersion: semver.MustParse("3.0.0"),
@@ -196,6 +262,8 @@ func TestFirefoxGeckoDriver(t *testing.T) {
 		t.Skipf("Skipping Firefox tests on Selenium 3 because geckodriver binary %q not found", *geckoDriverPath)
 	}
 
+	checkVendorManifest(t, *geckoDriverPath, *firefoxBinarySelenium3)
+
 	runFirefoxTests(t, *geckoDriverPath, config{
 		path: *firefoxBinarySelenium3,
 	})
@@ -402,6 +470,9 @@ func newTestCapabilities(t *testing.T, c config) Capabilities {
 				"--no-sandbox",
 			},
 		}
+		if !*keepNoisyFeatures {
+			chrCaps.DisableNoisyFeatures()
+		}
 		caps.AddChrome(chrCaps)
 	case "firefox":
 		f := firefox.Capabilities{}
@@ -419,6 +490,9 @@ func newTestCapabilities(t *testing.T, c config) Capabilities {
 				Level: firefox.Trace,
 			}
 		}
+		if !*keepNoisyFeatures {
+			f.DisableNoisyFeatures()
+		}
 		caps.AddFirefox(f)
 	case "htmlunit":
 		caps["javascriptEnabled"] = true
@@ -432,6 +506,7 @@ func newRemote(t *testing.T, c config) WebDriver {
 	if err != nil {
 		t.Fatalf("NewRemote(%+v, %q) returned error: %v", caps, c.addr, err)
 	}
+	wd.SetStrictDecoding(c.strictDecoding)
 	return wd
 }
 
@@ -472,6 +547,7 @@ func runTests(t *testing.T, c config) {
 	t.Run("Location", runTest(testLocation, c))
 	t.Run("LocationInView", runTest(testLocationInView, c))
 	t.Run("Size", runTest(testSize, c))
+	t.Run("Rect", runTest(testRect, c))
 	t.Run("ExecuteScript", runTest(testExecuteScript, c))
 	t.Run("ExecuteScriptOnElement", runTest(testExecuteScriptOnElement, c))
 	t.Run("ExecuteScriptWithNilArgs", runTest(testExecuteScriptWithNilArgs, c))
@@ -479,13 +555,34 @@ func runTests(t *testing.T, c config) {
 	t.Run("Log", runTest(testLog, c))
 	t.Run("IsSelected", runTest(testIsSelected, c))
 	t.Run("IsDisplayed", runTest(testIsDisplayed, c))
+	t.Run("IsDisplayedAtom", runTest(testIsDisplayedAtom, c))
 	t.Run("GetAttributeNotFound", runTest(testGetAttributeNotFound, c))
+	t.Run("GetProperty", runTest(testGetProperty, c))
+	t.Run("GetAttributeOrProperty", runTest(testGetAttributeOrProperty, c))
+	t.Run("Attributes", runTest(testAttributes, c))
 	t.Run("MaximizeWindow", runTest(testMaximizeWindow, c))
 	t.Run("ResizeWindow", runTest(testResizeWindow, c))
 	t.Run("KeyDownUp", runTest(testKeyDownUp, c))
 	t.Run("CSSProperty", runTest(testCSSProperty, c))
+	t.Run("ComputedRoleAndLabel", runTest(testComputedRoleAndLabel, c))
+	t.Run("FindElementRelative", runTest(testFindElementRelative, c))
+	t.Run("Submit", runTest(testSubmit, c))
+	t.Run("Hover", runTest(testHover, c))
+	t.Run("ClickAt", runTest(testClickAt, c))
+	t.Run("ContextMenu", runTest(testContextMenu, c))
 	t.Run("Proxy", runTest(testProxy, c))
 	t.Run("SwitchFrame", runTest(testSwitchFrame, c))
+	t.Run("FileDialogGuard", runTest(testFileDialogGuard, c))
+	t.Run("DropFiles", runTest(testDropFiles, c))
+	t.Run("DragAndDrop", runTest(testDragAndDrop, c))
+	t.Run("PointerPrecision", runTest(testPointerPrecision, c))
+	t.Run("PageZoom", runTest(testPageZoom, c))
+	t.Run("StorageUsage", runTest(testStorageUsage, c))
+	t.Run("IndexedDB", runTest(testIndexedDB, c))
+	t.Run("ScrollContainer", runTest(testScrollContainer, c))
+	t.Run("ScrollToElement", runTest(testScrollToElement, c))
+	t.Run("AdversarialNames", runTest(testAdversarialNames, c))
+	t.Run("Stealth", runTest(testStealth, c))
 }
 
 func testStatus(t *testing.T, c config) {
@@ -874,9 +971,6 @@ func testClick(t *testing.T, c config) {
 }
 
 func testGetCookie(t *testing.T, c config) {
-	if c.browser == "chrome" {
-		t.Skip("ChromeDriver does not implement GetCookie")
-	}
 	wd := newRemote(t, c)
 	defer quitRemote(t, wd)
 
@@ -1077,6 +1171,35 @@ func testSize(t *testing.T, c config) {
 	}
 }
 
+func testRect(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL, err)
+	}
+	button, err := wd.FindElement(ByID, "submit")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rect, err := button.Rect()
+	if err != nil {
+		t.Fatalf("button.Rect() returned error: %v", err)
+	}
+	loc, err := button.Location()
+	if err != nil {
+		t.Fatalf("button.Location() returned error: %v", err)
+	}
+	size, err := button.Size()
+	if err != nil {
+		t.Fatalf("button.Size() returned error: %v", err)
+	}
+	if int(rect.X) != loc.X || int(rect.Y) != loc.Y || int(rect.Width) != size.Width || int(rect.Height) != size.Height {
+		t.Errorf("button.Rect() = %+v, which does not match Location() = %+v and Size() = %+v", *rect, *loc, *size)
+	}
+}
+
 func testExecuteScript(t *testing.T, c config) {
 	wd := newRemote(t, c)
 	defer quitRemote(t, wd)
@@ -1259,6 +1382,50 @@ func testIsDisplayed(t *testing.T, c config) {
 	}
 }
 
+func testIsDisplayedAtom(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/visibility"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/visibility", err)
+	}
+	elems, err := wd.FindElements(ByCSSSelector, "[data-displayed]")
+	if err != nil {
+		t.Fatalf("wd.FindElements(ByCSSSelector, %q) returned error: %v", "[data-displayed]", err)
+	}
+	if len(elems) == 0 {
+		t.Fatalf("no elements found on the visibility page")
+	}
+	for _, elem := range elems {
+		id, err := elem.GetAttribute("id")
+		if err != nil {
+			t.Fatalf("elem.GetAttribute(%q) returned error: %v", "id", err)
+		}
+		want, err := elem.GetAttribute("data-displayed")
+		if err != nil {
+			t.Fatalf("elem.GetAttribute(%q) returned error: %v", "data-displayed", err)
+		}
+		atom, err := elem.(*remoteWE).displayedByAtom()
+		if err != nil {
+			t.Fatalf("displayedByAtom() of %q returned error: %v", id, err)
+		}
+		if strconv.FormatBool(atom) != want {
+			t.Errorf("displayedByAtom() of %q = %t, want %s", id, atom, want)
+		}
+		// Compare with the native endpoint where the remote end has one.
+		native, err := elem.(*remoteWE).boolCommand("/displayed")
+		if isUnknownCommand(err) {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("the /displayed endpoint for %q returned error: %v", id, err)
+		}
+		if native != atom {
+			t.Errorf("displayedByAtom() of %q = %t, but the /displayed endpoint returned %t", id, atom, native)
+		}
+	}
+}
+
 func testGetAttributeNotFound(t *testing.T, c config) {
 	wd := newRemote(t, c)
 	defer quitRemote(t, wd)
@@ -1276,6 +1443,241 @@ func testGetAttributeNotFound(t *testing.T, c config) {
 	}
 }
 
+func testGetProperty(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL, err)
+	}
+	checkbox, err := wd.FindElement(ByID, "chuk")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByID, "chuk", err)
+	}
+	if err := checkbox.Click(); err != nil {
+		t.Fatalf("checkbox.Click() returned error: %v", err)
+	}
+	if got, err := checkbox.GetProperty("checked"); err != nil || got != "true" {
+		t.Errorf(`checkbox.GetProperty("checked") after clicking = %q, %v; want "true", nil`, got, err)
+	}
+
+	input, err := wd.FindElement(ByName, "q")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByName, "q", err)
+	}
+	if err := input.SendKeys("golang"); err != nil {
+		t.Fatalf("input.SendKeys() returned error: %v", err)
+	}
+	if got, err := input.GetProperty("value"); err != nil || got != "golang" {
+		t.Errorf(`input.GetProperty("value") after typing = %q, %v; want "golang", nil`, got, err)
+	}
+}
+
+func testGetAttributeOrProperty(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL, err)
+	}
+	checkbox, err := wd.FindElement(ByID, "chuk")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByID, "chuk", err)
+	}
+	if got, err := checkbox.GetAttributeOrProperty("checked"); err != nil || got != "" {
+		t.Errorf(`checkbox.GetAttributeOrProperty("checked") = %q, %v; want "", nil`, got, err)
+	}
+	if err := checkbox.Click(); err != nil {
+		t.Fatalf("checkbox.Click() returned error: %v", err)
+	}
+	if got, err := checkbox.GetAttributeOrProperty("checked"); err != nil || got != "true" {
+		t.Errorf(`checkbox.GetAttributeOrProperty("checked") after clicking = %q, %v; want "true", nil`, got, err)
+	}
+
+	input, err := wd.FindElement(ByName, "q")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByName, "q", err)
+	}
+	if err := input.SendKeys("golang"); err != nil {
+		t.Fatalf("input.SendKeys() returned error: %v", err)
+	}
+	if got, err := input.GetAttributeOrProperty("value"); err != nil || got != "golang" {
+		t.Errorf(`input.GetAttributeOrProperty("value") after typing = %q, %v; want "golang", nil`, got, err)
+	}
+	if _, err := wd.ExecuteScript(`arguments[0].className = "wide";`, []interface{}{input}); err != nil {
+		t.Fatalf("setting the class of the input returned error: %v", err)
+	}
+	if got, err := input.GetAttributeOrProperty("class"); err != nil || got != "wide" {
+		t.Errorf(`input.GetAttributeOrProperty("class") = %q, %v; want "wide", nil`, got, err)
+	}
+}
+
+func testAttributes(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/frame"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/frame", err)
+	}
+	icon, err := wd.FindElement(ByID, "icon")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByID, "icon", err)
+	}
+	want := map[string]string{"id": "icon", "xlink:href": "#shape", "class": "icon"}
+	if got, err := icon.Attributes(); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("icon.Attributes() = %v, %v; want %v, nil", got, err, want)
+	}
+
+	if err := wd.SwitchFrame("iframeID"); err != nil {
+		t.Fatalf("wd.SwitchFrame(%q) returned error: %v", "iframeID", err)
+	}
+	checkbox, err := wd.FindElement(ByID, "chuk")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByID, "chuk", err)
+	}
+	want = map[string]string{"id": "chuk", "type": "checkbox"}
+	if got, err := checkbox.Attributes(); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("checkbox.Attributes() in a frame = %v, %v; want %v, nil", got, err, want)
+	}
+}
+
+func testSubmit(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/form"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/form", err)
+	}
+	outside, err := wd.FindElement(ByID, "outside")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByID, "outside", err)
+	}
+	err = outside.Submit()
+	if err == nil {
+		t.Errorf("Submit() on an element outside of a form returned no error")
+	} else if wd.(*remoteWD).w3cCompatible && !errors.Is(err, ErrNotInForm) {
+		t.Errorf("Submit() on an element outside of a form returned %v, want ErrNotInForm", err)
+	}
+
+	input, err := wd.FindElement(ByName, "q")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByName, "q", err)
+	}
+	if err := input.Submit(); err != nil {
+		t.Fatalf("Submit() on an input of a form without a submit button returned error: %v", err)
+	}
+	if err := wd.WaitWithTimeout(func(wd WebDriver) (bool, error) {
+		source, err := wd.PageSource()
+		return strings.Contains(source, `You searched for "golang submitted"`), err
+	}, 5*time.Second); err != nil {
+		t.Errorf("waiting for the submitted search returned error: %v", err)
+	}
+}
+
+func testHover(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/hover"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/hover", err)
+	}
+	menu, err := wd.FindElement(ByID, "menu")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByID, "menu", err)
+	}
+	item, err := wd.FindElement(ByID, "item")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByID, "item", err)
+	}
+	if err := menu.Hover(); err != nil {
+		t.Fatalf("menu.Hover() returned error: %v", err)
+	}
+	if err := item.WaitUntilVisible(5 * time.Second); err != nil {
+		t.Fatalf("item.WaitUntilVisible() after hovering the menu returned error: %v", err)
+	}
+	if err := item.Click(); err != nil {
+		t.Fatalf("item.Click() returned error: %v", err)
+	}
+	result, err := wd.FindElement(ByID, "result")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByID, "result", err)
+	}
+	if text, err := result.Text(); err != nil || text != "Settings" {
+		t.Errorf("result.Text() after clicking the revealed item = %q, %v; want %q, nil", text, err, "Settings")
+	}
+}
+
+func testClickAt(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/canvas"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/canvas", err)
+	}
+	canvas, err := wd.FindElement(ByID, "canvas")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByID, "canvas", err)
+	}
+	corners := []Point{{0, 0}, {99, 0}, {0, 99}}
+	for _, p := range corners {
+		if err := canvas.ClickAt(p.X, p.Y); err != nil {
+			t.Fatalf("canvas.ClickAt(%d, %d) returned error: %v", p.X, p.Y, err)
+		}
+	}
+	clicks, err := wd.ExecuteScript("return window.clicks;", nil)
+	if err != nil {
+		t.Fatalf("reading the recorded clicks returned error: %v", err)
+	}
+	var got []Point
+	for _, c := range clicks.([]interface{}) {
+		xy := c.([]interface{})
+		got = append(got, Point{int(xy[0].(float64)), int(xy[1].(float64))})
+	}
+	if !reflect.DeepEqual(got, corners) {
+		t.Errorf("the clicks recorded on the canvas = %v, want %v", got, corners)
+	}
+}
+
+func testContextMenu(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/contextmenu"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/contextmenu", err)
+	}
+	target, err := wd.FindElement(ByID, "target")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByID, "target", err)
+	}
+	menu, err := wd.FindElement(ByID, "menu")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByID, "menu", err)
+	}
+
+	if err := target.RightClick(); err != nil {
+		t.Fatalf("target.RightClick() returned error: %v", err)
+	}
+	if err := menu.WaitUntilVisible(5 * time.Second); err != nil {
+		t.Fatalf("menu.WaitUntilVisible() after the right click returned error: %v", err)
+	}
+	if err := wd.KeyDown(EscapeKey); err != nil {
+		t.Fatalf("wd.KeyDown(EscapeKey) returned error: %v", err)
+	}
+	if err := wd.KeyUp(EscapeKey); err != nil {
+		t.Fatalf("wd.KeyUp(EscapeKey) returned error: %v", err)
+	}
+	if err := wd.WaitUntilGone(ByID, "menu", 5*time.Second); err != nil {
+		t.Errorf("wd.WaitUntilGone() after pressing Escape returned error: %v", err)
+	}
+
+	if err := target.DoubleClick(); err != nil {
+		t.Fatalf("target.DoubleClick() returned error: %v", err)
+	}
+	if text, err := target.Text(); err != nil || text != "double clicked" {
+		t.Errorf("target.Text() after the double click = %q, %v; want %q, nil", text, err, "double clicked")
+	}
+}
+
 func testMaximizeWindow(t *testing.T, c config) {
 	if c.browser == "firefox" {
 		t.Skip("Skipping test due to https://github.com/mozilla/geckodriver/issues/703")
@@ -1337,6 +1739,68 @@ func testKeyDownUp(t *testing.T, c config) {
 	}
 }
 
+func testComputedRoleAndLabel(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/aria"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/aria", err)
+	}
+	button, err := wd.FindElement(ByID, "close")
+	if err != nil {
+		t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByID, "close", err)
+	}
+
+	role, err := button.ComputedRole()
+	var unsupported *UnsupportedCommandError
+	if errors.As(err, &unsupported) {
+		t.Skipf("the remote end does not compute ARIA roles: %v", err)
+	}
+	if err != nil || role != "button" {
+		t.Errorf(`button.ComputedRole() = %q, %v; want "button", nil`, role, err)
+	}
+	if label, err := button.ComputedLabel(); err != nil || label != "Close dialog" {
+		t.Errorf(`button.ComputedLabel() = %q, %v; want "Close dialog", nil`, label, err)
+	}
+}
+
+func testFindElementRelative(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/grid"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/grid", err)
+	}
+	label := func(text string) WebElement {
+		t.Helper()
+		xpath := fmt.Sprintf("//label[text()=%q]", text)
+		elem, err := wd.FindElement(ByXPATH, xpath)
+		if err != nil {
+			t.Fatalf("wd.FindElement(%q, %q) returned error: %v", ByXPATH, xpath, err)
+		}
+		return elem
+	}
+	for _, tc := range []struct {
+		rb   *RelativeBy
+		want string
+	}{
+		{WithTagName("input").Below(label("First name")), "first"},
+		{WithTagName("input").Below(label("City")), "city"},
+		{WithTagName("input").Below(label("First name")).RightOf(label("City")), "last"},
+		{WithTagName("input").Below(label("Zip code")).Near(label("Zip code"), 0), "zip"},
+		{WithTagName("input").Above(label("Zip code")).RightOf(label("First name")), "last"},
+	} {
+		elem, err := wd.FindElementRelative(tc.rb)
+		if err != nil {
+			t.Errorf("wd.FindElementRelative(%s) returned error: %v", tc.rb, err)
+			continue
+		}
+		if got, err := elem.GetAttribute("name"); err != nil || got != tc.want {
+			t.Errorf("wd.FindElementRelative(%s) found the input %q, %v; want %q", tc.rb, got, err, tc.want)
+		}
+	}
+}
+
 func testCSSProperty(t *testing.T, c config) {
 	if c.browser == "htmlunit" {
 		t.Skip("Skipping on htmlunit")
@@ -1504,39 +1968,463 @@ func testSwitchFrame(t *testing.T, c config) {
 	}
 }
 
-var homePage = `
-<html>
-<head>
-	<title>Go Selenium Test Suite</title>
-</head>
-<body>
-	The home page. <br />
-	<form action="/search">
-		<input name="q" /> <input type="submit" id="submit"/> <br />
-		<input id="chuk" type="checkbox" /> A checkbox.
-	</form>
-	Link to the <a href="/other">other page</a>.
+func testFileDialogGuard(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
 
-	<a href="/log">тест</a>
-</body>
-</html>
-`
+	if err := wd.Get(serverURL + "/upload"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/upload", err)
+	}
+	wd.SetFileDialogGuard(true)
 
-var otherPage = `
-<html>
-<head>
-	<title>Go Selenium Test Suite - Other Page</title>
-</head>
-<body>
-	The other page.
-</body>
-</html>
-`
+	input, err := wd.FindElement(ByID, "file")
+	if err != nil {
+		t.Fatalf("wd.FindElement(ByID, %q) returned error: %v", "file", err)
+	}
+	if err := input.Click(); err != ErrWouldOpenFileDialog {
+		t.Fatalf("input.Click() returned error %v, want %v", err, ErrWouldOpenFileDialog)
+	}
 
-const searchContents = "The Go Proramming Language"
+	// The guard must not interfere with pages that build DataTransfer objects.
+	build, err := wd.FindElement(ByID, "build")
+	if err != nil {
+		t.Fatalf("wd.FindElement(ByID, %q) returned error: %v", "build", err)
+	}
+	if err := build.Click(); err != nil {
+		t.Fatalf("build.Click() returned error: %v", err)
+	}
+	result, err := wd.FindElement(ByID, "result")
+	if err != nil {
+		t.Fatalf("wd.FindElement(ByID, %q) returned error: %v", "result", err)
+	}
+	if text, err := result.Text(); err != nil || text != "fixture.txt" {
+		t.Fatalf("result.Text() = %q, %v, want %q", text, err, "fixture.txt")
+	}
 
-var searchPage = `
-<html>
+	// Setting files via SendKeys must still work.
+	f, err := ioutil.TempFile("", "selenium-upload")
+	if err != nil {
+		t.Fatalf("ioutil.TempFile() returned error: %v", err)
+	}
+	defer os.Remove(f.Name())
+	f.Close()
+	if err := input.SendKeys(f.Name()); err != nil {
+		t.Fatalf("input.SendKeys(%q) returned error: %v", f.Name(), err)
+	}
+
+	wd.SetFileDialogGuard(false)
+	if installed, err := wd.ExecuteScript("return !!window.__seleniumFileDialogGuard;", nil); err != nil || installed != false {
+		t.Errorf("the guard listener is installed: %v, %v; want it removed", installed, err)
+	}
+	button, err := wd.FindElement(ByID, "build")
+	if err != nil {
+		t.Fatalf("wd.FindElement(ByID, %q) returned error: %v", "build", err)
+	}
+	if err := button.Click(); err != nil {
+		t.Fatalf("button.Click() with the guard disabled returned error: %v", err)
+	}
+}
+
+func testDropFiles(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	dir, err := ioutil.TempDir("", "selenium-drop")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir() returned error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Not all systems have MIME types registered for these extensions.
+	DropFileMIMETypes[".csv"] = "text/csv"
+	DropFileMIMETypes[".txt"] = "text/plain"
+	defer func() {
+		delete(DropFileMIMETypes, ".csv")
+		delete(DropFileMIMETypes, ".txt")
+	}()
+
+	// The first file spans multiple chunks.
+	files := []struct {
+		name string
+		size int
+	}{
+		{"large.csv", dropChunkSize + 10},
+		{"empty.txt", 0},
+	}
+	var paths []string
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := ioutil.WriteFile(path, []byte(strings.Repeat("x", f.size)), 0600); err != nil {
+			t.Fatalf("ioutil.WriteFile(%q) returned error: %v", path, err)
+		}
+		paths = append(paths, path)
+	}
+
+	if err := wd.Get(serverURL + "/dropzone"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/dropzone", err)
+	}
+	zone, err := wd.FindElement(ByID, "zone")
+	if err != nil {
+		t.Fatalf("wd.FindElement(ByID, %q) returned error: %v", "zone", err)
+	}
+	if err := zone.DropFiles(paths...); err != nil {
+		t.Fatalf("zone.DropFiles(%v) returned error: %v", paths, err)
+	}
+	got, err := zone.Text()
+	if err != nil {
+		t.Fatalf("zone.Text() returned error: %v", err)
+	}
+	want := fmt.Sprintf("large.csv:%d:text/csv;empty.txt:0:text/plain", dropChunkSize+10)
+	if !strings.HasPrefix(got, want) {
+		t.Fatalf("zone.Text() = %q, want it to start with %q", got, want)
+	}
+}
+
+func testDragAndDrop(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/sortable"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/sortable", err)
+	}
+	order := func(list string) string {
+		items, err := wd.FindElements(ByCSSSelector, "#"+list+" li")
+		if err != nil {
+			t.Fatalf("wd.FindElements(ByCSSSelector, %q) returned error: %v", "#"+list+" li", err)
+		}
+		var texts []string
+		for _, item := range items {
+			text, err := item.Text()
+			if err != nil {
+				t.Fatalf("item.Text() returned error: %v", err)
+			}
+			texts = append(texts, text)
+		}
+		return strings.Join(texts, ",")
+	}
+	find := func(id string) WebElement {
+		elem, err := wd.FindElement(ByID, id)
+		if err != nil {
+			t.Fatalf("wd.FindElement(ByID, %q) returned error: %v", id, err)
+		}
+		return elem
+	}
+
+	// The HTML5 list only reacts to drag events.
+	if err := wd.DragAndDropViaScript(find("html5-a"), find("html5-c")); err != nil {
+		t.Fatalf("wd.DragAndDropViaScript() returned error: %v", err)
+	}
+	if got, want := order("html5"), "B,C,A"; got != want {
+		t.Errorf("after dragging A onto C, the HTML5 list is %s, want %s", got, want)
+	}
+
+	// The pointer list reacts to mouse events.
+	if err := wd.DragAndDrop(find("pointer-a"), find("pointer-c")); err != nil {
+		t.Fatalf("wd.DragAndDrop() returned error: %v", err)
+	}
+	if got, want := order("pointer"), "B,C,A"; got != want {
+		t.Errorf("after dragging A onto C, the pointer list is %s, want %s", got, want)
+	}
+	if err := wd.DragAndDropByOffset(find("pointer-a"), 0, -60); err != nil {
+		t.Fatalf("wd.DragAndDropByOffset() returned error: %v", err)
+	}
+	if got, want := order("pointer"), "A,B,C"; got != want {
+		t.Errorf("after dragging A up by 60 pixels, the pointer list is %s, want %s", got, want)
+	}
+}
+
+func testPointerPrecision(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/transformed"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/transformed", err)
+	}
+	wd.SetPointerPrecision(UseElementFromPoint)
+
+	for _, id := range []string{"scaled", "rotated"} {
+		button, err := wd.FindElement(ByID, id)
+		if err != nil {
+			t.Fatalf("wd.FindElement(ByID, %q) returned error: %v", id, err)
+		}
+		if err := button.Click(); err != nil {
+			t.Fatalf("button.Click() on %q returned error: %v", id, err)
+		}
+	}
+	result, err := wd.FindElement(ByID, "result")
+	if err != nil {
+		t.Fatalf("wd.FindElement(ByID, %q) returned error: %v", "result", err)
+	}
+	if text, err := result.Text(); err != nil || text != "scaled rotated" {
+		t.Fatalf("result.Text() = %q, %v, want %q", text, err, "scaled rotated")
+	}
+
+	// An element whose entire rectangle is covered must produce an error that
+	// names the covering element.
+	covered, err := wd.FindElement(ByID, "covered")
+	if err != nil {
+		t.Fatalf("wd.FindElement(ByID, %q) returned error: %v", "covered", err)
+	}
+	err = covered.Click()
+	if err == nil || !strings.Contains(err.Error(), "div#overlay") {
+		t.Fatalf("covered.Click() returned error %v, want it to mention %q", err, "div#overlay")
+	}
+}
+
+func testPageZoom(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL, err)
+	}
+	if err := wd.SetPageZoom(2); err != nil {
+		t.Fatalf("wd.SetPageZoom(2) returned error: %v", err)
+	}
+	zoom, method, err := wd.PageZoom()
+	if err != nil {
+		t.Fatalf("wd.PageZoom() returned error: %v", err)
+	}
+	if math.Abs(zoom-2) > 0.01 {
+		t.Errorf("wd.PageZoom() = %v (%v), want 2", zoom, method)
+	}
+
+	// Pointer interactions must land on the element at its zoomed position.
+	checkbox, err := wd.FindElement(ByID, "chuk")
+	if err != nil {
+		t.Fatalf("wd.FindElement(ByID, %q) returned error: %v", "chuk", err)
+	}
+	if err := wd.Actions().MoveToElement(checkbox).Click().Perform(); err != nil {
+		t.Fatalf("clicking the checkbox at 200%% zoom returned error: %v", err)
+	}
+	if selected, err := checkbox.IsSelected(); err != nil || !selected {
+		t.Errorf("checkbox.IsSelected() after clicking it at 200%% zoom (%v) = %t, %v; want true, nil", method, selected, err)
+	}
+
+	if err := wd.SetPageZoom(1); err != nil {
+		t.Fatalf("wd.SetPageZoom(1) returned error: %v", err)
+	}
+	if zoom, _, err := wd.PageZoom(); err != nil || math.Abs(zoom-1) > 0.01 {
+		t.Errorf("wd.PageZoom() after resetting = %v, %v; want 1, nil", zoom, err)
+	}
+}
+
+func testStorageUsage(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL, err)
+	}
+	for _, s := range []WebStorage{wd.LocalStorage(), wd.SessionStorage()} {
+		if err := s.Clear(); err != nil {
+			t.Fatalf("s.Clear() returned error: %v", err)
+		}
+		before, err := s.Usage()
+		if err != nil {
+			t.Fatalf("s.Usage() returned error: %v", err)
+		}
+		const key = "payload"
+		value := strings.Repeat("x", 10000)
+		if err := s.SetItem(key, value); err != nil {
+			t.Fatalf("s.SetItem(%q, ...) returned error: %v", key, err)
+		}
+		got, ok, err := s.Item(key)
+		if err != nil || !ok || got != value {
+			t.Fatalf("s.Item(%q) = %d bytes, %t, %v; want %d bytes, true, nil", key, len(got), ok, err, len(value))
+		}
+		after, err := s.Usage()
+		if err != nil {
+			t.Fatalf("s.Usage() returned error: %v", err)
+		}
+		want := int64(2 * (len(key) + len(value)))
+		if delta := after - before; delta < want || delta > want+64 {
+			t.Errorf("s.Usage() grew by %d bytes, want %d", delta, want)
+		}
+		if err := s.RemoveItem(key); err != nil {
+			t.Fatalf("s.RemoveItem(%q) returned error: %v", key, err)
+		}
+		if _, ok, err := s.Item(key); err != nil || ok {
+			t.Fatalf("s.Item(%q) after removal = %t, %v; want false, nil", key, ok, err)
+		}
+	}
+
+	estimate, err := wd.StorageEstimate()
+	if err != nil {
+		t.Fatalf("wd.StorageEstimate() returned error: %v", err)
+	}
+	if estimate.Supported && estimate.Quota <= 0 {
+		t.Errorf("wd.StorageEstimate() = %+v, want a positive quota", estimate)
+	}
+}
+
+func testIndexedDB(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/indexeddb"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/indexeddb", err)
+	}
+	// Wait for the page to populate the database.
+	if _, err := wd.ExecuteScriptAsync(`var done = arguments[0]; window.dbReady.then(function() { done(); });`, nil); err != nil {
+		t.Fatalf("waiting for the database returned error: %v", err)
+	}
+
+	if _, err := wd.IndexedDB("no-such-db"); err == nil {
+		t.Fatal(`wd.IndexedDB("no-such-db") returned nil error`)
+	}
+	db, err := wd.IndexedDB("drafts")
+	if err != nil {
+		t.Fatalf(`wd.IndexedDB("drafts") returned error: %v`, err)
+	}
+	stores, err := db.ObjectStores()
+	if err != nil {
+		t.Fatalf("db.ObjectStores() returned error: %v", err)
+	}
+	if want := []string{"blobs", "drafts"}; !reflect.DeepEqual(stores, want) {
+		t.Fatalf("db.ObjectStores() = %v, want %v", stores, want)
+	}
+
+	// A store with a key path.
+	n, err := db.Count("drafts")
+	if err != nil || n != 3 {
+		t.Fatalf(`db.Count("drafts") = %d, %v; want 3, nil`, n, err)
+	}
+	var drafts []struct {
+		ID      int
+		Title   string
+		Created string
+	}
+	if err := db.GetAll("drafts", 2, &drafts); err != nil {
+		t.Fatalf(`db.GetAll("drafts") returned error: %v`, err)
+	}
+	if len(drafts) != 2 || drafts[0].Title != "first" || drafts[0].Created != "2017-01-02T03:04:05.000Z" {
+		t.Fatalf(`db.GetAll("drafts", 2) = %+v`, drafts)
+	}
+
+	// A store with out-of-line keys.
+	var blobs []string
+	if err := db.GetAll("blobs", 0, &blobs); err != nil {
+		t.Fatalf(`db.GetAll("blobs") returned error: %v`, err)
+	}
+	if want := []string{"AQID"}; !reflect.DeepEqual(blobs, want) {
+		t.Fatalf(`db.GetAll("blobs") = %v, want %v`, blobs, want)
+	}
+
+	// The page holds a connection open, which blocks deletion.
+	if err := db.DeleteDatabase(); err != ErrIndexedDBBlocked {
+		t.Fatalf("db.DeleteDatabase() returned error %v, want %v", err, ErrIndexedDBBlocked)
+	}
+}
+
+func testScrollContainer(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/virtual"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/virtual", err)
+	}
+
+	// The virtualized list only renders the rows that are visible, so the
+	// target row must be found by repeatedly scrolling and finding again.
+	const target = "row-500"
+	first, err := wd.FindElement(ByCSSSelector, ".row")
+	if err != nil {
+		t.Fatalf("wd.FindElement(ByCSSSelector, %q) returned error: %v", ".row", err)
+	}
+	list, err := first.ScrollableAncestor()
+	if err != nil {
+		t.Fatalf("first.ScrollableAncestor() returned error: %v", err)
+	}
+	if id, err := list.GetAttribute("id"); err != nil || id != "list" {
+		t.Fatalf("first.ScrollableAncestor() returned element with id %q, %v; want %q", id, err, "list")
+	}
+
+	var row WebElement
+	for i := 0; i < 100; i++ {
+		if row, err = wd.FindElement(ByID, target); err == nil {
+			break
+		}
+		if err := wd.ScrollContainer(list, 0, 400); err != nil {
+			t.Fatalf("wd.ScrollContainer(list, 0, 400) returned error: %v", err)
+		}
+	}
+	if row == nil {
+		t.Fatalf("row %q not found after scrolling", target)
+	}
+	if err := row.ScrollIntoViewWithin(list); err != nil {
+		t.Fatalf("row.ScrollIntoViewWithin(list) returned error: %v", err)
+	}
+	if displayed, err := row.IsDisplayed(); err != nil || !displayed {
+		t.Fatalf("row.IsDisplayed() = %t, %v; want true, nil", displayed, err)
+	}
+}
+
+func testScrollToElement(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL + "/visibility"); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL+"/visibility", err)
+	}
+	elem, err := wd.FindElement(ByID, "below-fold")
+	if err != nil {
+		t.Fatalf("wd.FindElement(ByID, %q) returned error: %v", "below-fold", err)
+	}
+	err = wd.ScrollToElement(elem)
+	if err == ErrLegacyProtocol {
+		return
+	}
+	if err != nil {
+		t.Fatalf("wd.ScrollToElement() returned error: %v", err)
+	}
+	inView, err := wd.ExecuteScript(`var r = arguments[0].getBoundingClientRect();
+return r.top >= 0 && r.bottom <= window.innerHeight;`, []interface{}{elem})
+	if err != nil {
+		t.Fatalf("checking the element's position returned error: %v", err)
+	}
+	if inView != true {
+		t.Errorf("the element is not in view after wd.ScrollToElement()")
+	}
+
+	if err := wd.ScrollByAmount(0, 0, 0, -100); err != nil {
+		t.Fatalf("wd.ScrollByAmount() returned error: %v", err)
+	}
+}
+
+var homePage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite</title>
+</head>
+<body>
+	The home page. <br />
+	<form action="/search">
+		<input name="q" /> <input type="submit" id="submit"/> <br />
+		<input id="chuk" type="checkbox" /> A checkbox.
+	</form>
+	Link to the <a href="/other">other page</a>.
+
+	<a href="/log">тест</a>
+</body>
+</html>
+`
+
+var otherPage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - Other Page</title>
+</head>
+<body>
+	The other page.
+</body>
+</html>
+`
+
+const searchContents = "The Go Proramming Language"
+
+var searchPage = `
+<html>
 <head>
 	<title>Go Selenium Test Suite - Search Page</title>
 </head>
@@ -1574,6 +2462,456 @@ var framePage = `
 
 	<iframe id="iframeID" name="iframeName" src="/"></iframe>
 	<div id="outsideOfFrame"></div>
+	<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink">
+		<use id="icon" xlink:href="#shape" class="icon"/>
+	</svg>
+</body>
+</html>
+`
+
+var uploadPage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - Upload Page</title>
+</head>
+<body>
+	<input id="file" type="file" />
+	<input id="target" type="file" />
+	<button id="build" onclick="buildFiles()">Build</button>
+	<div id="result"></div>
+	<script>
+		function buildFiles() {
+			var dt = new DataTransfer();
+			dt.items.add(new File(["contents"], "fixture.txt", {type: "text/plain"}));
+			var target = document.getElementById("target");
+			target.files = dt.files;
+			document.getElementById("result").textContent = target.files[0].name;
+		}
+	</script>
+</body>
+</html>
+`
+
+var dropzonePage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - Dropzone Page</title>
+</head>
+<body>
+	<div id="zone" style="width: 200px; height: 200px;">Drop here</div>
+	<script>
+		var zone = document.getElementById("zone");
+		zone.addEventListener("dragover", function(e) { e.preventDefault(); });
+		zone.addEventListener("drop", function(e) {
+			e.preventDefault();
+			var out = [];
+			for (var i = 0; i < e.dataTransfer.files.length; i++) {
+				var f = e.dataTransfer.files[i];
+				out.push(f.name + ":" + f.size + ":" + f.type);
+			}
+			zone.textContent = out.join(";");
+		});
+	</script>
+</body>
+</html>
+`
+
+var transformedPage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - Transformed Page</title>
+	<style>
+		.box { width: 200px; height: 200px; margin: 50px; }
+		button { width: 200px; height: 40px; }
+	</style>
+</head>
+<body>
+	<div class="box" style="transform: scale(0.5); transform-origin: 0 0;">
+		<button id="scaled" onclick="record('scaled')">Scaled</button>
+	</div>
+	<div class="box" style="transform: rotate(30deg);">
+		<button id="rotated" onclick="record('rotated')">Rotated</button>
+	</div>
+	<div class="box" style="position: relative;">
+		<button id="covered" onclick="record('covered')">Covered</button>
+		<div id="overlay" style="position: absolute; top: 0; left: 0; width: 100%; height: 100%;"></div>
+	</div>
+	<div id="result"></div>
+	<script>
+		function record(name) {
+			var r = document.getElementById("result");
+			r.textContent = (r.textContent + " " + name).trim();
+		}
+	</script>
+</body>
+</html>
+`
+
+var indexedDBPage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - IndexedDB Page</title>
+	<script>
+		window.dbReady = new Promise(function(resolve) {
+			var req = indexedDB.open("drafts", 1);
+			req.onupgradeneeded = function() {
+				var db = req.result;
+				db.createObjectStore("drafts", {keyPath: "id"});
+				db.createObjectStore("blobs");
+			};
+			req.onsuccess = function() {
+				// Keep the connection open.
+				window.db = req.result;
+				var tx = window.db.transaction(["drafts", "blobs"], "readwrite");
+				var drafts = tx.objectStore("drafts");
+				drafts.put({id: 1, title: "first", created: new Date(Date.UTC(2017, 0, 2, 3, 4, 5))});
+				drafts.put({id: 2, title: "second", created: new Date()});
+				drafts.put({id: 3, title: "third", created: new Date()});
+				tx.objectStore("blobs").put(new Uint8Array([1, 2, 3]).buffer, "key");
+				tx.oncomplete = function() { resolve(); };
+			};
+		});
+	</script>
+</head>
+<body>
+	IndexedDB test page.
+</body>
+</html>
+`
+
+var virtualListPage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - Virtual List Page</title>
+	<style>
+		#list { height: 200px; overflow: auto; position: relative; }
+		#spacer { height: 20000px; }
+		.row { position: absolute; height: 20px; left: 0; right: 0; }
+	</style>
+</head>
+<body>
+	<div id="list"><div id="spacer"></div></div>
+	<script>
+		var list = document.getElementById("list");
+		function render() {
+			var first = Math.floor(list.scrollTop / 20);
+			Array.prototype.slice.call(list.querySelectorAll(".row")).forEach(function(r) {
+				r.remove();
+			});
+			for (var i = first; i < first + 12 && i < 1000; i++) {
+				var row = document.createElement("div");
+				row.className = "row";
+				row.id = "row-" + i;
+				row.style.top = (i * 20) + "px";
+				row.textContent = "Row " + i;
+				list.appendChild(row);
+			}
+		}
+		list.addEventListener("scroll", render);
+		render();
+	</script>
+</body>
+</html>
+`
+
+// visibilityPage contains elements whose data-displayed attribute holds
+// whether they should be considered displayed.
+var visibilityPage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - Visibility Page</title>
+	<style>
+		#clip { width: 100px; height: 20px; overflow: hidden; }
+		#clipped { position: relative; left: 200px; }
+	</style>
+</head>
+<body>
+	<p id="shown" data-displayed="true">Shown</p>
+	<p id="display-none" style="display: none" data-displayed="false">None</p>
+	<div style="display: none"><p id="parent-display-none" data-displayed="false">Parent</p></div>
+	<p id="visibility-hidden" style="visibility: hidden" data-displayed="false">Hidden</p>
+	<input id="input-hidden" type="hidden" data-displayed="false">
+	<div id="zero-size" style="width: 0; height: 0" data-displayed="false"></div>
+	<div id="zero-size-text" style="width: 0; height: 0" data-displayed="true">Text</div>
+	<div id="zero-size-hidden-overflow" style="width: 0; height: 0; overflow: hidden" data-displayed="false">Text</div>
+	<p id="opacity-zero" style="opacity: 0" data-displayed="true">Transparent</p>
+	<p id="off-screen-left" style="position: absolute; left: -9999px" data-displayed="false">Left</p>
+	<p id="off-screen-top" style="position: absolute; top: -9999px" data-displayed="false">Top</p>
+	<p id="below-fold" style="position: absolute; top: 5000px" data-displayed="true">Below</p>
+	<div id="clip"><span id="clipped" data-displayed="false">Clipped</span></div>
+	<select><option id="option" data-displayed="true">Option</option></select>
+	<select style="display: none"><option id="hidden-option" data-displayed="false">Option</option></select>
+</body>
+</html>
+`
+
+// sortablePage has two sortable lists: one using HTML5 drag and drop, the
+// other mouse events. Dropping an item on another moves it after that item.
+var sortablePage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - Sortable Page</title>
+	<style>
+		li { height: 20px; margin: 0; padding: 0; list-style: none; user-select: none; }
+	</style>
+</head>
+<body>
+	<ul id="html5">
+		<li id="html5-a" draggable="true">A</li>
+		<li id="html5-b" draggable="true">B</li>
+		<li id="html5-c" draggable="true">C</li>
+	</ul>
+	<ul id="pointer">
+		<li id="pointer-a">A</li>
+		<li id="pointer-b">B</li>
+		<li id="pointer-c">C</li>
+	</ul>
+	<script>
+		var dragged = null;
+		document.querySelectorAll("#html5 li").forEach(function(li) {
+			li.addEventListener("dragstart", function() { dragged = li; });
+			li.addEventListener("dragover", function(e) { e.preventDefault(); });
+			li.addEventListener("drop", function(e) {
+				e.preventDefault();
+				li.after(dragged);
+			});
+		});
+		var held = null;
+		document.querySelectorAll("#pointer li").forEach(function(li) {
+			li.addEventListener("mousedown", function() { held = li; });
+		});
+		document.addEventListener("mouseup", function(e) {
+			var list = document.getElementById("pointer");
+			if (!held) {
+				return;
+			}
+			var items = list.querySelectorAll("li");
+			var target = null;
+			for (var i = 0; i < items.length; i++) {
+				var r = items[i].getBoundingClientRect();
+				if (e.clientY >= r.top && e.clientY < r.bottom) {
+					target = items[i];
+				}
+			}
+			if (target && target !== held) {
+				target.after(held);
+			} else if (!target && e.clientY < items[0].getBoundingClientRect().top) {
+				list.insertBefore(held, items[0]);
+			}
+			held = null;
+		});
+	</script>
+</body>
+</html>
+`
+
+// adversarialNames are names and IDs that require escaping in CSS selectors.
+var adversarialNames = []string{
+	`a"b`,
+	`a"]b`,
+	`a\b`,
+	"a\nb",
+	"a b",
+	"1st",
+	"-1",
+	"-",
+	"#id.class",
+	"'single'",
+	"ünï©ode",
+	"a\tb\x7f",
+}
+
+func testAdversarialNames(t *testing.T, c config) {
+	wd := newRemote(t, c)
+	defer quitRemote(t, wd)
+
+	if err := wd.Get(serverURL); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL, err)
+	}
+
+	names := append([]string{}, adversarialNames...)
+	// Add random names drawn from characters that are significant in CSS.
+	const alphabet = "aZ09-_ \"'\\[]#.:,>+~*()=^$|\n\t"
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		name := make([]byte, 1+r.Intn(8))
+		for j := range name {
+			name[j] = alphabet[r.Intn(len(alphabet))]
+		}
+		names = append(names, string(name))
+	}
+
+	for i, name := range names {
+		if _, err := wd.ExecuteScript(`
+var input = document.createElement('input');
+input.name = arguments[0];
+input.id = arguments[0];
+input.setAttribute('data-index', arguments[1]);
+document.body.appendChild(input);`, []interface{}{name, i}); err != nil {
+			t.Fatalf("creating the input named %q returned error: %v", name, err)
+		}
+
+		escaped, err := wd.ExecuteScript("return CSS.escape(arguments[0]);", []interface{}{name})
+		if err != nil {
+			t.Fatalf("CSS.escape(%q) returned error: %v", name, err)
+		}
+		if got := CSSEscape(name); got != escaped {
+			t.Errorf("CSSEscape(%q) = %q, want %q as returned by CSS.escape", name, got, escaped)
+		}
+
+		for _, by := range []string{ByName, ByID} {
+			// An earlier random name may be equal to this one, in which case the
+			// first element with the name is found.
+			want, err := wd.ExecuteScript(`
+var name = arguments[0];
+var inputs = document.querySelectorAll('input');
+for (var i = 0; i < inputs.length; i++) {
+	if (inputs[i][arguments[1]] === name) {
+		return inputs[i].getAttribute('data-index');
+	}
+}
+return null;`, []interface{}{name, map[string]string{ByName: "name", ByID: "id"}[by]})
+			if err != nil {
+				t.Fatalf("finding the input named %q by script returned error: %v", name, err)
+			}
+			elem, err := wd.FindElement(by, name)
+			if err != nil {
+				t.Errorf("wd.FindElement(%q, %q) returned error: %v", by, name, err)
+				continue
+			}
+			if got, err := elem.GetAttribute("data-index"); err != nil || got != want {
+				t.Errorf("wd.FindElement(%q, %q) found the element with index %q, %v; want %q", by, name, got, err, want)
+			}
+		}
+	}
+}
+
+var ariaPage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - ARIA Page</title>
+</head>
+<body>
+	<button id="close" aria-label="Close dialog">X</button>
+</body>
+</html>
+`
+
+var gridPage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - Grid Page</title>
+	<style>
+		table { border-spacing: 20px; }
+		label { display: block; }
+	</style>
+</head>
+<body>
+	<table>
+		<tr><td><label>First name</label></td><td><label>Last name</label></td></tr>
+		<tr><td><input name="first"></td><td><input name="last"></td></tr>
+		<tr><td><label>City</label></td><td><label>Zip code</label></td></tr>
+		<tr><td><input name="city"></td><td><input name="zip"></td></tr>
+	</table>
+</body>
+</html>
+`
+
+// formPage has a form without a submit button, whose submit listener records
+// the event in the search query.
+var formPage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - Form Page</title>
+</head>
+<body>
+	<form action="/search" onsubmit="this.q.value += ' submitted'">
+		<input name="q" value="golang">
+	</form>
+	<div id="outside">Not in a form.</div>
+</body>
+</html>
+`
+
+// hoverPage has a menu whose items are only shown while the mouse is over it.
+var hoverPage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - Hover Page</title>
+	<style>
+		#menu { width: 200px; padding: 10px; }
+		#menu ul { display: none; margin: 0; }
+		#menu:hover ul { display: block; }
+	</style>
+</head>
+<body>
+	<div id="menu">Account
+		<ul>
+			<li id="item" onclick="document.getElementById('result').textContent = this.textContent">Settings</li>
+		</ul>
+	</div>
+	<div id="result"></div>
+</body>
+</html>
+`
+
+// canvasPage has a 100x100 canvas that records the offsets of the clicks on
+// it in window.clicks.
+var canvasPage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - Canvas Page</title>
+	<style>
+		body { margin: 0; }
+		#canvas { display: block; margin: 20px; border: 0; }
+	</style>
+</head>
+<body>
+	<canvas id="canvas" width="100" height="100"></canvas>
+	<script>
+		window.clicks = [];
+		document.getElementById('canvas').addEventListener('click', function(e) {
+			var r = this.getBoundingClientRect();
+			window.clicks.push([Math.floor(e.clientX - r.left), Math.floor(e.clientY - r.top)]);
+		});
+	</script>
+</body>
+</html>
+`
+
+// contextMenuPage has a target that opens a custom context menu, closed with
+// the Escape key, and records double clicks.
+var contextMenuPage = `
+<html>
+<head>
+	<title>Go Selenium Test Suite - Context Menu Page</title>
+	<style>
+		#target { width: 200px; height: 100px; }
+		#menu { display: none; position: absolute; }
+		#menu.open { display: block; }
+	</style>
+</head>
+<body>
+	<div id="target">Right click me</div>
+	<ul id="menu"><li>Copy</li><li>Paste</li></ul>
+	<script>
+		var target = document.getElementById('target');
+		var menu = document.getElementById('menu');
+		target.addEventListener('contextmenu', function(e) {
+			e.preventDefault();
+			menu.style.left = e.pageX + 'px';
+			menu.style.top = e.pageY + 'px';
+			menu.className = 'open';
+		});
+		target.addEventListener('dblclick', function() {
+			target.textContent = 'double clicked';
+		});
+		document.addEventListener('keydown', function(e) {
+			if (e.key === 'Escape') {
+				menu.className = '';
+			}
+		});
+	</script>
 </body>
 </html>
 `
@@ -1581,11 +2919,24 @@ var framePage = `
 func handler(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 	page, ok := map[string]string{
-		"/":       homePage,
-		"/other":  otherPage,
-		"/search": searchPage,
-		"/log":    logPage,
-		"/frame":  framePage,
+		"/":            homePage,
+		"/other":       otherPage,
+		"/search":      searchPage,
+		"/log":         logPage,
+		"/frame":       framePage,
+		"/upload":      uploadPage,
+		"/dropzone":    dropzonePage,
+		"/transformed": transformedPage,
+		"/indexeddb":   indexedDBPage,
+		"/virtual":     virtualListPage,
+		"/visibility":  visibilityPage,
+		"/sortable":    sortablePage,
+		"/aria":        ariaPage,
+		"/grid":        gridPage,
+		"/form":        formPage,
+		"/hover":       hoverPage,
+		"/canvas":      canvasPage,
+		"/contextmenu": contextMenuPage,
 	}[path]
 	if !ok {
 		http.NotFound(w, r)
@@ -1603,5 +2954,53 @@ func handler(w http.ResponseWriter, r *http.Request) {
 			Value: fmt.Sprintf("value-%d", i),
 		})
 	}
-	fmt.Fprintf(w, page)
+	fmt.Fprint(w, page)
+}
+
+func testStealth(t *testing.T, c config) {
+	opts := &StealthOptions{
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64) StagingTest/1.0",
+		Languages: []string{"fr-FR", "fr"},
+	}
+	caps := newTestCapabilities(t, c)
+	switch c.browser {
+	case "chrome":
+		co := caps[chrome.CapabilitiesKey].(chrome.Capabilities)
+		opts.ApplyChrome(&co)
+		caps.AddChrome(co)
+	case "firefox":
+		f := caps[firefox.CapabilitiesKey].(firefox.Capabilities)
+		opts.ApplyFirefox(&f)
+		caps.AddFirefox(f)
+	default:
+		t.Skipf("Skipping stealth test for browser %q", c.browser)
+	}
+	wd, err := NewRemote(caps, c.addr)
+	if err != nil {
+		t.Fatalf("NewRemote(_, _) returned error: %v", err)
+	}
+	defer quitRemote(t, wd)
+
+	if err := wd.ApplyStealth(opts); err != nil {
+		t.Fatalf("wd.ApplyStealth() returned error: %v", err)
+	}
+	if err := wd.Get(serverURL); err != nil {
+		t.Fatalf("wd.Get(%q) returned error: %v", serverURL, err)
+	}
+	const script = `return [String(navigator.webdriver), navigator.plugins.length > 0,
+		navigator.languages.join(","), navigator.userAgent];`
+	got, err := wd.ExecuteScript(script, nil)
+	if err != nil {
+		t.Fatalf("wd.ExecuteScript() returned error: %v", err)
+	}
+	// The shims hide navigator.webdriver on Chrome, while Firefox reports it
+	// as false once dom.webdriver.enabled is off.
+	webdriver := "undefined"
+	if c.browser == "firefox" {
+		webdriver = "false"
+	}
+	want := []interface{}{webdriver, true, "fr-FR,fr", opts.UserAgent}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("the navigator properties are %v, want %v", got, want)
+	}
 }
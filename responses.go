@@ -0,0 +1,226 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// ResourceResponse describes the response to a request of a page, for its
+// document or one of its subresources.
+type ResourceResponse struct {
+	// URL is the URL of the resource.
+	URL string
+	// Type is the type of the resource, e.g. "Document", "Script" or
+	// "Stylesheet" as reported by the DevTools protocol, or the initiator
+	// type of the resource timing entry, e.g. "script", for partial
+	// responses.
+	Type string
+	// Status is the HTTP status code of the response, or zero if unknown.
+	Status int
+	// Headers are the headers of the response, by canonical name. Multiple
+	// values of a header are separated by newlines.
+	Headers map[string]string
+	// Partial is set if the response was observed without its headers, which
+	// is the case for browsers other than Chromium-based ones.
+	Partial bool
+}
+
+// maxRecordedResponses is the number of responses kept by a
+// responseRecorder. Single-page applications never navigate the top-level
+// frame, so without a limit the responses of a session would be kept forever.
+const maxRecordedResponses = 1000
+
+// responseRecorder collects the responses reported by the DevTools Network
+// domain in the performance log, for the current page.
+type responseRecorder struct {
+	responses []ResourceResponse
+}
+
+// observeLogs records the responses in the performance log messages. A
+// navigation of the top-level frame forgets the responses of the previous
+// page, except those of the requests that led to the navigation.
+func (r *responseRecorder) observeLogs(logs []LogMessage) {
+	for _, l := range logs {
+		entry := new(struct {
+			Message struct {
+				Method string
+				Params struct {
+					Type     string
+					Response struct {
+						URL     string
+						Status  int
+						Headers map[string]string
+					}
+					Frame struct {
+						ParentID string `json:"parentId"`
+						URL      string
+					}
+				}
+			}
+		})
+		if err := json.Unmarshal([]byte(l.Message), entry); err != nil {
+			continue
+		}
+		m := entry.Message
+		switch m.Method {
+		case "Page.frameNavigated":
+			if m.Params.Frame.ParentID == "" {
+				r.forgetBefore(m.Params.Frame.URL)
+			}
+		case "Network.responseReceived":
+			headers := make(map[string]string, len(m.Params.Response.Headers))
+			for name, value := range m.Params.Response.Headers {
+				headers[http.CanonicalHeaderKey(name)] = value
+			}
+			r.responses = append(r.responses, ResourceResponse{
+				URL:     m.Params.Response.URL,
+				Type:    m.Params.Type,
+				Status:  m.Params.Response.Status,
+				Headers: headers,
+			})
+			if n := len(r.responses) - maxRecordedResponses; n > 0 {
+				r.responses = append([]ResourceResponse(nil), r.responses[n:]...)
+			}
+		}
+	}
+}
+
+// forgetBefore forgets the responses received before the last response for
+// the document with the given URL, which the top-level frame navigated to.
+func (r *responseRecorder) forgetBefore(url string) {
+	for i := len(r.responses) - 1; i >= 0; i-- {
+		if resp := r.responses[i]; resp.Type == "Document" && resp.URL == url {
+			r.responses = append([]ResourceResponse(nil), r.responses[i:]...)
+			return
+		}
+	}
+	r.responses = nil
+}
+
+// resourceEntriesScript returns the navigation and resource timing entries
+// of the page.
+const resourceEntriesScript = `
+var entries = performance.getEntriesByType('navigation').concat(performance.getEntriesByType('resource'));
+return entries.map(function(e) {
+	return {url: e.name, type: e.initiatorType || e.entryType, status: e.responseStatus || 0};
+});`
+
+func (wd *remoteWD) SubresourceResponses() ([]ResourceResponse, error) {
+	// Chromium-based drivers report network events in the performance log,
+	// if enabled in the capabilities.
+	if _, err := wd.Log(Performance); err == nil {
+		return append([]ResourceResponse(nil), wd.responseRecorder.responses...), nil
+	}
+
+	// Otherwise, fall back to the resource timing entries of the page, which
+	// have neither headers nor, in most browsers, status codes.
+	raw, err := wd.ExecuteScriptRaw(resourceEntriesScript, nil)
+	if err != nil {
+		return nil, err
+	}
+	reply := new(struct {
+		Value []struct {
+			URL    string
+			Type   string
+			Status int
+		}
+	})
+	if err := json.Unmarshal(raw, reply); err != nil {
+		return nil, err
+	}
+	responses := make([]ResourceResponse, 0, len(reply.Value))
+	for _, e := range reply.Value {
+		responses = append(responses, ResourceResponse{URL: e.URL, Type: e.Type, Status: e.Status, Partial: true})
+	}
+	return responses, nil
+}
+
+// HeaderRule is a rule on the headers of responses, for
+// CheckResponseHeaders.
+type HeaderRule struct {
+	// URLPattern, if set, is a regular expression that restricts the rule to
+	// the responses whose URL it matches.
+	URLPattern string
+	// Header is the name of the header.
+	Header string
+	// Forbidden is set if the header must be absent. Otherwise it must be
+	// present.
+	Forbidden bool
+	// ValuePattern, if set, is a regular expression that the value of a
+	// required header must match.
+	ValuePattern string
+}
+
+func (r HeaderRule) String() string {
+	s := "requires " + r.Header
+	if r.Forbidden {
+		s = "forbids " + r.Header
+	} else if r.ValuePattern != "" {
+		s += fmt.Sprintf(" matching %q", r.ValuePattern)
+	}
+	if r.URLPattern != "" {
+		s += fmt.Sprintf(" for URLs matching %q", r.URLPattern)
+	}
+	return s
+}
+
+// Violation is a response that breaks a HeaderRule.
+type Violation struct {
+	// URL is the URL of the response, which is empty if the rule is invalid.
+	URL  string
+	Rule HeaderRule
+	// Reason describes the violation.
+	Reason string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: rule that %s: %s", v.URL, v.Rule, v.Reason)
+}
+
+// CheckResponseHeaders checks the responses against the rules, and returns
+// the violations. A partial response, without headers, violates every rule
+// that applies to it, since it cannot be checked. An invalid rule is
+// reported as a violation without URL.
+func CheckResponseHeaders(resps []ResourceResponse, rules []HeaderRule) []Violation {
+	var violations []Violation
+	for _, rule := range rules {
+		var urlRE, valueRE *regexp.Regexp
+		var err error
+		if rule.URLPattern != "" {
+			if urlRE, err = regexp.Compile(rule.URLPattern); err != nil {
+				violations = append(violations, Violation{Rule: rule, Reason: fmt.Sprintf("invalid URL pattern: %v", err)})
+				continue
+			}
+		}
+		if rule.ValuePattern != "" {
+			if valueRE, err = regexp.Compile(rule.ValuePattern); err != nil {
+				violations = append(violations, Violation{Rule: rule, Reason: fmt.Sprintf("invalid value pattern: %v", err)})
+				continue
+			}
+		}
+		for _, resp := range resps {
+			if urlRE != nil && !urlRE.MatchString(resp.URL) {
+				continue
+			}
+			violation := func(reason string) {
+				violations = append(violations, Violation{URL: resp.URL, Rule: rule, Reason: reason})
+			}
+			if resp.Partial {
+				violation("the headers of the response are unknown")
+				continue
+			}
+			value, ok := resp.Headers[http.CanonicalHeaderKey(rule.Header)]
+			switch {
+			case rule.Forbidden && ok:
+				violation(fmt.Sprintf("the header is present with value %q", value))
+			case !rule.Forbidden && !ok:
+				violation("the header is missing")
+			case !rule.Forbidden && valueRE != nil && !valueRE.MatchString(value):
+				violation(fmt.Sprintf("the value %q does not match", value))
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,175 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// responseLog returns a performance log message for a DevTools event.
+func responseLog(t *testing.T, method string, params interface{}) LogMessage {
+	msg, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{"method": method, "params": params},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return LogMessage{Message: string(msg)}
+}
+
+// fixtureServer serves a page whose resources come with or without security
+// headers.
+func fixtureServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Security-Policy", "default-src 'self'")
+			w.Header().Set("Strict-Transport-Security", "max-age=63072000")
+		case "/app.js":
+			w.Header().Set("Strict-Transport-Security", "max-age=60")
+		case "/style.css":
+			w.Header().Set("X-Powered-By", "PHP/5.6")
+		}
+		fmt.Fprint(w, r.URL.Path)
+	}))
+}
+
+// fixtureLogs fetches the page and resources of the fixture server, and
+// returns the performance log of loading them in a browser.
+func fixtureLogs(t *testing.T, base string) []LogMessage {
+	logs := []LogMessage{
+		// The response for the previous page is forgotten on navigation.
+		responseLog(t, "Network.responseReceived", map[string]interface{}{
+			"type": "Document", "response": map[string]interface{}{"url": "http://old.example/", "status": 200},
+		}),
+	}
+	for _, resource := range []struct{ path, typ string }{
+		{"/page", "Document"}, {"/app.js", "Script"}, {"/style.css", "Stylesheet"},
+	} {
+		resp, err := http.Get(base + resource.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		headers := make(map[string]string)
+		for name, values := range resp.Header {
+			headers[strings.ToLower(name)] = strings.Join(values, "\n")
+		}
+		logs = append(logs, responseLog(t, "Network.responseReceived", map[string]interface{}{
+			"type":     resource.typ,
+			"response": map[string]interface{}{"url": base + resource.path, "status": resp.StatusCode, "headers": headers},
+		}))
+		if resource.path == "/page" {
+			logs = append(logs, responseLog(t, "Page.frameNavigated", map[string]interface{}{
+				"frame": map[string]interface{}{"url": base + "/page"},
+			}))
+		}
+	}
+	return logs
+}
+
+func TestSubresourceResponses(t *testing.T) {
+	fixture := fixtureServer()
+	defer fixture.Close()
+
+	var logs []LogMessage
+	performanceLog := true
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch {
+		case r.URL.Path == "/session/123/log" && performanceLog:
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": logs})
+			logs = nil
+		case r.URL.Path == "/session/123/execute/sync":
+			fmt.Fprintf(w, `{"value":[{"url":%q,"type":"navigation","status":200},{"url":%q,"type":"script","status":0}]}`,
+				fixture.URL+"/page", fixture.URL+"/app.js")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"unknown command","message":"not implemented"}}`)
+		}
+	}))
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	rules := []HeaderRule{
+		{Header: "Strict-Transport-Security", ValuePattern: `max-age=\d{7,}`},
+		{URLPattern: `/page$`, Header: "content-security-policy"},
+		{Header: "X-Powered-By", Forbidden: true},
+	}
+
+	logs = fixtureLogs(t, fixture.URL)
+	// The responses are kept across reads of the log, including direct ones.
+	if _, err := wd.Log(Performance); err != nil {
+		t.Fatalf("Log(Performance) returned error: %v", err)
+	}
+	resps, err := wd.SubresourceResponses()
+	if err != nil {
+		t.Fatalf("SubresourceResponses() returned error: %v", err)
+	}
+	var urls []string
+	for _, resp := range resps {
+		urls = append(urls, strings.TrimPrefix(resp.URL, fixture.URL)+" "+resp.Type)
+		if resp.Status != http.StatusOK || resp.Partial {
+			t.Errorf("the response for %s has status %d and partial=%t, want 200 and complete", resp.URL, resp.Status, resp.Partial)
+		}
+	}
+	if want := []string{"/page Document", "/app.js Script", "/style.css Stylesheet"}; !reflect.DeepEqual(urls, want) {
+		t.Fatalf("SubresourceResponses() returned responses for %v, want %v", urls, want)
+	}
+	if got := resps[0].Headers["Content-Security-Policy"]; got != "default-src 'self'" {
+		t.Errorf("the page has Content-Security-Policy %q, want the one served", got)
+	}
+
+	var got []string
+	for _, v := range CheckResponseHeaders(resps, rules) {
+		got = append(got, strings.TrimPrefix(v.URL, fixture.URL)+": "+v.Reason)
+	}
+	want := []string{
+		`/app.js: the value "max-age=60" does not match`,
+		`/style.css: the header is missing`,
+		`/style.css: the header is present with value "PHP/5.6"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckResponseHeaders() = %q, want %q", got, want)
+	}
+
+	// Without the performance log, the responses have no headers.
+	performanceLog = false
+	resps, err = wd.SubresourceResponses()
+	if err != nil {
+		t.Fatalf("SubresourceResponses() without performance log returned error: %v", err)
+	}
+	if len(resps) != 2 || !resps[0].Partial || resps[0].Status != 200 || resps[1].Type != "script" {
+		t.Errorf("SubresourceResponses() without performance log = %+v, want 2 partial responses", resps)
+	}
+	if v := CheckResponseHeaders(resps, rules[1:2]); len(v) != 1 || v[0].Reason != "the headers of the response are unknown" {
+		t.Errorf("CheckResponseHeaders() of partial responses = %v, want the page reported as unknown", v)
+	}
+}
+
+func TestCheckResponseHeadersInvalidRule(t *testing.T) {
+	v := CheckResponseHeaders([]ResourceResponse{{URL: "http://example.com/"}}, []HeaderRule{{URLPattern: "(", Header: "X"}})
+	if len(v) != 1 || v[0].URL != "" || !strings.HasPrefix(v[0].Reason, "invalid URL pattern") {
+		t.Errorf("CheckResponseHeaders() with an invalid rule = %v, want a violation without URL", v)
+	}
+}
+
+func TestResponseRecorderIsCapped(t *testing.T) {
+	var r responseRecorder
+	var logs []LogMessage
+	for i := 0; i < maxRecordedResponses+10; i++ {
+		msg := fmt.Sprintf(`{"message":{"method":"Network.responseReceived","params":{"type":"XHR","response":{"url":"https://example.com/%d","status":200}}}}`, i)
+		logs = append(logs, LogMessage{Message: msg})
+	}
+	r.observeLogs(logs)
+	if len(r.responses) != maxRecordedResponses {
+		t.Fatalf("the recorder kept %d responses, want %d", len(r.responses), maxRecordedResponses)
+	}
+	if got, want := r.responses[0].URL, "https://example.com/10"; got != want {
+		t.Errorf("the oldest kept response is for %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+package selenium
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// decodeScreenshot decodes a screenshot returned by the remote end, which
+// is base64 encoded.
+func decodeScreenshot(data string) ([]byte, error) {
+	decoder := base64.NewDecoder(base64.StdEncoding, bytes.NewBufferString(data))
+	return ioutil.ReadAll(decoder)
+}
+
+// screenshotReader returns a reader of the decoded screenshot in a reply to
+// the command with the given URL. The image is decoded as it is read, from
+// the base64 encoded value of the reply in place.
+func screenshotReader(response []byte, urlTemplate string, args ...interface{}) (io.Reader, error) {
+	reply := new(struct{ Value json.RawMessage })
+	if err := json.Unmarshal(response, reply); err != nil {
+		return nil, err
+	}
+	value := reply.Value
+	if len(value) == 0 || string(value) == "null" {
+		return nil, nullValueError(urlTemplate, args...)
+	}
+	if len(value) < 2 || value[0] != '"' {
+		return nil, fmt.Errorf("unexpected screenshot value %.20s", value)
+	}
+	data := value[1 : len(value)-1]
+	if bytes.IndexByte(data, '\\') >= 0 {
+		// Some remote ends escape slashes or wrap the lines of the image.
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return nil, err
+		}
+		data = []byte(s)
+	}
+	return base64.NewDecoder(base64.StdEncoding, bytes.NewReader(data)), nil
+}
+
+func (wd *remoteWD) ScreenshotTo(w io.Writer) error {
+	response, err := wd.execute("GET", wd.requestURL("/session/%s/screenshot", wd.id), nil)
+	if err != nil {
+		return err
+	}
+	r, err := screenshotReader(response, "/session/%s/screenshot", wd.id)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("decoding the screenshot: %v", err)
+	}
+	return nil
+}
+
+func (wd *remoteWD) SaveScreenshot(path string) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	err = wd.ScreenshotTo(f)
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+func (wd *remoteWD) FullPageScreenshot() ([]byte, error) {
+	if wd.browser != "firefox" {
+		browser := wd.browser
+		if browser == "" {
+			browser = "unknown browser"
+		}
+		return nil, fmt.Errorf("full-page screenshots are not supported by this driver (%s): only geckodriver implements them", browser)
+	}
+	data, err := wd.stringCommand("/session/%s/moz/screenshot/full")
+	if err != nil {
+		return nil, err
+	}
+	return decodeScreenshot(data)
+}
+
+// elementViewportRectScript returns the rectangle of the element passed as
+// the first argument relative to the viewport, in CSS pixels, and the device
+// pixel ratio. If the second argument is true, the element is first
+// scrolled into view.
+const elementViewportRectScript = `
+var elem = arguments[0];
+if (arguments[1]) {
+	elem.scrollIntoView({block: 'center', inline: 'center'});
+}
+var r = elem.getBoundingClientRect();
+return [r.left, r.top, r.width, r.height, window.devicePixelRatio || 1];`
+
+func (elem *remoteWE) Screenshot(scroll bool) ([]byte, error) {
+	if elem.parent.w3cCompatible {
+		// The W3C specification always scrolls the element into view.
+		data, err := elem.stringCommand("/screenshot")
+		if !isUnknownCommand(err) {
+			if err != nil {
+				return nil, err
+			}
+			return decodeScreenshot(data)
+		}
+	}
+	return elem.croppedScreenshot(scroll)
+}
+
+// croppedScreenshot takes a screenshot of the viewport and crops it to the
+// element, for remote ends without the "Take Element Screenshot" command.
+func (elem *remoteWE) croppedScreenshot(scroll bool) ([]byte, error) {
+	if err := elem.checkID(); err != nil {
+		return nil, err
+	}
+	wd := elem.parent
+	response, err := wd.ExecuteScriptRaw(elementViewportRectScript, []interface{}{elem, scroll})
+	if err != nil {
+		return nil, elem.wrapError("screenshot", err)
+	}
+	reply := new(struct{ Value []float64 })
+	if err := json.Unmarshal(response, reply); err != nil {
+		return nil, err
+	}
+	if len(reply.Value) != 5 {
+		return nil, fmt.Errorf("unexpected element rectangle %v", reply.Value)
+	}
+	left, top, width, height, ratio := reply.Value[0], reply.Value[1], reply.Value[2], reply.Value[3], reply.Value[4]
+
+	data, err := wd.Screenshot()
+	if err != nil {
+		return nil, err
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding the screenshot: %v", err)
+	}
+
+	// The screenshot has device pixels, the rectangle CSS pixels.
+	bounds := image.Rect(
+		int(math.Floor(left*ratio)), int(math.Floor(top*ratio)),
+		int(math.Ceil((left+width)*ratio)), int(math.Ceil((top+height)*ratio)),
+	).Add(img.Bounds().Min).Intersect(img.Bounds())
+	if bounds.Empty() {
+		return nil, fmt.Errorf("the element is outside of the viewport")
+	}
+	sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return nil, fmt.Errorf("cannot crop a screenshot of type %T", img)
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, sub.SubImage(bounds)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
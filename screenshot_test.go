@@ -0,0 +1,216 @@
+package selenium
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestElementScreenshot(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		if r.Method != "GET" || r.URL.Path != "/session/123/element/e1/screenshot" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"value":%q}`, base64.StdEncoding.EncodeToString([]byte("\x89PNG element")))
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	got, err := (&remoteWE{parent: wd, id: "e1"}).Screenshot(true)
+	if err != nil {
+		t.Fatalf("Screenshot() returned error: %v", err)
+	}
+	if string(got) != "\x89PNG element" {
+		t.Errorf("Screenshot() = %q, want the decoded screenshot", got)
+	}
+}
+
+func TestElementScreenshotCropped(t *testing.T) {
+	// The viewport is 10x10 CSS pixels at a device pixel ratio of 2. Each
+	// pixel of the screenshot encodes its coordinates.
+	page := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 20; y++ {
+			page.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	buf := new(bytes.Buffer)
+	png.Encode(buf, page)
+	screenshot := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var script string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch r.URL.Path {
+		case "/session/123/execute":
+			body, _ := ioutil.ReadAll(r.Body)
+			script = string(body)
+			fmt.Fprint(w, `{"status":0,"value":[2,1,3,2.5,2]}`)
+		case "/session/123/screenshot":
+			fmt.Fprintf(w, `{"status":0,"value":%q}`, screenshot)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL}
+	data, err := (&remoteWE{parent: wd, id: "e1"}).Screenshot(true)
+	if err != nil {
+		t.Fatalf("Screenshot() returned error: %v", err)
+	}
+	if !strings.Contains(script, `"args":[{"ELEMENT":"e1"`) || !strings.Contains(script, `"},true],`) {
+		t.Errorf("the element rectangle script was sent as %s, want the element and scroll arguments", script)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Screenshot() returned an invalid PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 6 || b.Dy() != 5 {
+		t.Errorf("the cropped screenshot is %dx%d, want 6x5", b.Dx(), b.Dy())
+	}
+	b := img.Bounds()
+	if r, g, _, _ := img.At(b.Min.X, b.Min.Y).RGBA(); r>>8 != 4 || g>>8 != 2 {
+		t.Errorf("the cropped screenshot starts at pixel (%d, %d) of the page, want (4, 2)", r>>8, g>>8)
+	}
+}
+
+func TestFullPageScreenshot(t *testing.T) {
+	browser := "firefox"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/session":
+			fmt.Fprintf(w, `{"value":{"sessionId":"123","capabilities":{"browserName":%q}}}`, browser)
+		case r.Method == "GET" && r.URL.Path == "/session/123/moz/screenshot/full":
+			fmt.Fprintf(w, `{"value":%q}`, base64.StdEncoding.EncodeToString([]byte("\x89PNG full")))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+
+	wd, err := NewRemote(nil, s.URL)
+	if err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	got, err := wd.FullPageScreenshot()
+	if err != nil {
+		t.Fatalf("FullPageScreenshot() returned error: %v", err)
+	}
+	if string(got) != "\x89PNG full" {
+		t.Errorf("FullPageScreenshot() = %q, want the decoded screenshot", got)
+	}
+
+	browser = "chrome"
+	if _, err := wd.NewSession(); err != nil {
+		t.Fatalf("NewSession() returned error: %v", err)
+	}
+	if _, err := wd.FullPageScreenshot(); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("FullPageScreenshot() on Chrome returned error %v, want not supported", err)
+	}
+}
+
+// screenshotServer serves the given reply value for screenshots.
+func screenshotServer(t *testing.T, value *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		if r.URL.Path != "/session/123/screenshot" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"value":%s}`, *value)
+	}))
+}
+
+func TestScreenshotTo(t *testing.T) {
+	want := bytes.Repeat([]byte("\x89PNG/image+data"), 1<<19) // 8MB
+	value := `"` + base64.StdEncoding.EncodeToString(want) + `"`
+	s := screenshotServer(t, &value)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	buf := new(bytes.Buffer)
+	if err := wd.ScreenshotTo(buf); err != nil {
+		t.Fatalf("ScreenshotTo() returned error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("ScreenshotTo() wrote %d bytes, want the %d bytes of the image", buf.Len(), len(want))
+	}
+
+	// Streaming to a writer allocates neither the decoded image nor a string
+	// copy of the encoded one, on top of the reply.
+	alloc := func(f func()) uint64 {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		f()
+		runtime.ReadMemStats(&after)
+		return after.TotalAlloc - before.TotalAlloc
+	}
+	streamed := alloc(func() { wd.ScreenshotTo(ioutil.Discard) })
+	decoded := alloc(func() { wd.Screenshot() })
+	if decoded < streamed+uint64(len(want)) {
+		t.Errorf("ScreenshotTo() allocated %d bytes and Screenshot() %d, want a difference of at least the %d bytes of the image", streamed, decoded, len(want))
+	}
+
+	// Escaped slashes are decoded too.
+	value = strings.Replace(value, "/", `\/`, -1)
+	if got, err := wd.Screenshot(); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("Screenshot() of an escaped value returned %d bytes, %v; want the image", len(got), err)
+	}
+}
+
+func TestSaveScreenshot(t *testing.T) {
+	value := `"` + base64.StdEncoding.EncodeToString([]byte("\x89PNG image")) + `"`
+	s := screenshotServer(t, &value)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	dir, err := ioutil.TempDir("", "screenshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "page.png")
+	if err := wd.SaveScreenshot(path); err != nil {
+		t.Fatalf("SaveScreenshot() returned error: %v", err)
+	}
+	if got, err := ioutil.ReadFile(path); err != nil || string(got) != "\x89PNG image" {
+		t.Errorf("SaveScreenshot() saved %q, %v; want the image", got, err)
+	}
+
+	value = `"` + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("x"), 3000))[:3000] + `!!!"`
+	invalid := filepath.Join(dir, "invalid.png")
+	if err := wd.SaveScreenshot(invalid); err == nil {
+		t.Errorf("SaveScreenshot() of invalid base64 returned nil error")
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		t.Errorf("SaveScreenshot() of invalid base64 left files %q, want only page.png", names)
+	}
+	value = "null"
+	if err := wd.ScreenshotTo(ioutil.Discard); err == nil {
+		t.Errorf("ScreenshotTo() of a null value returned nil error")
+	}
+}
@@ -0,0 +1,105 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// scrollableAncestorScript returns the nearest ancestor of the element passed
+// as the first argument whose content overflows it and that can be scrolled,
+// or null if there is none other than the document itself.
+const scrollableAncestorScript = `
+var elem = arguments[0].parentElement;
+for (; elem && elem !== document.body && elem !== document.documentElement; elem = elem.parentElement) {
+	var style = window.getComputedStyle(elem);
+	var overflow = style.overflowY + ' ' + style.overflowX;
+	if (/(auto|scroll|overlay)/.test(overflow) &&
+			(elem.scrollHeight > elem.clientHeight || elem.scrollWidth > elem.clientWidth)) {
+		return elem;
+	}
+}
+return null;
+`
+
+// scrollOffsetScript returns the distance by which the container passed as
+// the second argument has to be scrolled to center the element passed as the
+// first argument within it.
+const scrollOffsetScript = `
+var elem = arguments[0], container = arguments[1];
+var e = elem.getBoundingClientRect(), c = container.getBoundingClientRect();
+return {
+	x: Math.round((e.left + e.width / 2) - (c.left + container.clientWidth / 2)),
+	y: Math.round((e.top + e.height / 2) - (c.top + container.clientHeight / 2))
+};
+`
+
+const scrollByScript = `arguments[0].scrollBy(arguments[1], arguments[2]);`
+
+func (wd *remoteWD) ScrollContainer(container WebElement, dx, dy int) error {
+	if wd.w3cCompatible {
+		err := wd.PerformActions([]ActionSequence{
+			WheelSequence(defaultWheelID, ScrollAction(container, 0, 0, dx, dy)),
+		})
+		if err == nil {
+			return nil
+		}
+		// Not all drivers implement wheel input sources; fall back to scrolling
+		// via script.
+		debugLog("wheel action failed, scrolling via script: %v", err)
+	}
+	_, err := wd.ExecuteScript(scrollByScript, []interface{}{container, dx, dy})
+	return err
+}
+
+func (wd *remoteWD) ScrollToElement(elem WebElement) error {
+	return wd.PerformActions([]ActionSequence{
+		WheelSequence(defaultWheelID, ScrollAction(elem, 0, 0, 0, 0)),
+	})
+}
+
+func (wd *remoteWD) ScrollByAmount(x, y, deltaX, deltaY int) error {
+	return wd.PerformActions([]ActionSequence{
+		WheelSequence(defaultWheelID, ScrollAction(ViewportOrigin, x, y, deltaX, deltaY)),
+	})
+}
+
+func (elem *remoteWE) ScrollableAncestor() (WebElement, error) {
+	if err := elem.checkID(); err != nil {
+		return nil, err
+	}
+	wd := elem.parent
+	raw, err := wd.ExecuteScriptRaw(scrollableAncestorScript, []interface{}{elem})
+	if err != nil {
+		return nil, err
+	}
+	reply := new(struct{ Value json.RawMessage })
+	if err := json.Unmarshal(raw, reply); err != nil {
+		return nil, err
+	}
+	if len(reply.Value) == 0 || string(reply.Value) == "null" {
+		return nil, fmt.Errorf("element has no scrollable ancestor")
+	}
+	return wd.DecodeElement(raw)
+}
+
+func (elem *remoteWE) ScrollIntoViewWithin(container WebElement) error {
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	wd := elem.parent
+	raw, err := wd.ExecuteScriptRaw(scrollOffsetScript, []interface{}{elem, container})
+	if err != nil {
+		return err
+	}
+	reply := new(struct{ Value *struct{ X, Y int } })
+	if err := json.Unmarshal(raw, reply); err != nil {
+		return err
+	}
+	if reply.Value == nil {
+		return fmt.Errorf("nil return value")
+	}
+	if reply.Value.X == 0 && reply.Value.Y == 0 {
+		return nil
+	}
+	return wd.ScrollContainer(container, reply.Value.X, reply.Value.Y)
+}
@@ -0,0 +1,238 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Select interacts with a <select> element, e.g. a dropdown:
+//
+//	s, err := selenium.NewSelect(elem)
+//	if err != nil {
+//		return err
+//	}
+//	err = s.SelectByVisibleText("Canada")
+//
+// Options are selected and deselected by clicking them, so that the page
+// receives the same events as when a user does. Where the select is hidden,
+// e.g. behind a custom dropdown widget, and the remote end refuses to click
+// its options, they are selected with a script that sets their selectedness
+// and fires the input and change events.
+type Select struct {
+	elem     WebElement
+	multiple bool
+}
+
+// NewSelect returns a Select for elem, which must be a <select> element.
+func NewSelect(elem WebElement) (*Select, error) {
+	tag, err := elem.TagName()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(tag, "select") {
+		return nil, fmt.Errorf("%v is a <%s> element, not a <select>", elem, strings.ToLower(tag))
+	}
+	multiple, err := elem.GetPropertyRaw("multiple")
+	if err != nil {
+		return nil, err
+	}
+	return &Select{elem: elem, multiple: string(multiple) == "true"}, nil
+}
+
+// Element returns the <select> element.
+func (s *Select) Element() WebElement {
+	return s.elem
+}
+
+// IsMultiple reports whether the select allows selecting several options at
+// once.
+func (s *Select) IsMultiple() bool {
+	return s.multiple
+}
+
+// Options returns the options of the select, including those in option
+// groups, in document order.
+func (s *Select) Options() ([]WebElement, error) {
+	return s.elem.FindElements(ByTagName, "option")
+}
+
+// SelectedOptions returns the selected options of the select.
+func (s *Select) SelectedOptions() ([]WebElement, error) {
+	options, err := s.Options()
+	if err != nil {
+		return nil, err
+	}
+	var selected []WebElement
+	for _, o := range options {
+		ok, err := o.IsSelected()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			selected = append(selected, o)
+		}
+	}
+	return selected, nil
+}
+
+// FirstSelectedOption returns the first selected option of the select, or a
+// "no such element" error if none is selected.
+func (s *Select) FirstSelectedOption() (WebElement, error) {
+	options, err := s.Options()
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range options {
+		ok, err := o.IsSelected()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return o, nil
+		}
+	}
+	return nil, &Error{Err: "no such element", Message: "no option is selected"}
+}
+
+// SelectByValue selects the options whose value attribute is value, or the
+// first one if the select does not allow multiple selections.
+func (s *Select) SelectByValue(value string) error {
+	options, err := s.elem.FindElements(ByCSSSelector, "option[value="+CSSAttrValue(value)+"]")
+	if err != nil {
+		return err
+	}
+	return s.selectOptions(options, fmt.Sprintf("value %q", value))
+}
+
+// SelectByVisibleText selects the options whose text, with leading, trailing
+// and repeated whitespace removed, is text, or the first one if the select
+// does not allow multiple selections.
+func (s *Select) SelectByVisibleText(text string) error {
+	return s.selectByText(func(t string) bool { return t == text }, fmt.Sprintf("text %q", text))
+}
+
+// SelectByPartialText selects the options whose text contains text, or the
+// first one if the select does not allow multiple selections.
+func (s *Select) SelectByPartialText(text string) error {
+	return s.selectByText(func(t string) bool { return strings.Contains(t, text) }, fmt.Sprintf("text containing %q", text))
+}
+
+// SelectByIndex selects the option at the given index among the options of
+// the select, starting at zero.
+func (s *Select) SelectByIndex(index int) error {
+	options, err := s.Options()
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(options) {
+		return s.selectOptions(nil, fmt.Sprintf("index %d", index))
+	}
+	return s.selectOptions(options[index:index+1], "")
+}
+
+// DeselectAll deselects all the options of the select. It returns an error if
+// the select does not allow multiple selections, in which case one option is
+// always selected.
+func (s *Select) DeselectAll() error {
+	if !s.multiple {
+		return errors.New("only the options of a select that allows multiple selections can be deselected")
+	}
+	options, err := s.SelectedOptions()
+	if err != nil {
+		return err
+	}
+	for _, o := range options {
+		if err := setSelected(o, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Select) selectByText(match func(string) bool, description string) error {
+	options, err := s.Options()
+	if err != nil {
+		return err
+	}
+	var matches []WebElement
+	for _, o := range options {
+		text, err := optionText(o)
+		if err != nil {
+			return err
+		}
+		if match(text) {
+			matches = append(matches, o)
+		}
+	}
+	return s.selectOptions(matches, description)
+}
+
+// selectOptions selects the options, or the first one if the select does not
+// allow multiple selections. description describes how the options were
+// found, for the error returned if there are none.
+func (s *Select) selectOptions(options []WebElement, description string) error {
+	if len(options) == 0 {
+		return &Error{Err: "no such element", Message: "cannot locate an option with " + description}
+	}
+	if !s.multiple {
+		options = options[:1]
+	}
+	for _, o := range options {
+		enabled, err := o.IsEnabled()
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return fmt.Errorf("cannot select the disabled option %v", o)
+		}
+		if err := setSelected(o, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// optionText returns the text of the option with whitespace normalized. The
+// text property is used for options of hidden selects, whose visible text is
+// empty.
+func optionText(o WebElement) (string, error) {
+	text, err := o.Text()
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		if text, err = o.GetProperty("text"); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(strings.Fields(text), " "), nil
+}
+
+// setSelectedScript sets the selectedness of the option passed as the first
+// argument to the second argument, and fires the events that the select fires
+// when a user changes it.
+const setSelectedScript = `var option = arguments[0], selected = arguments[1];
+if (option.selected !== selected) {
+  option.selected = selected;
+  var select = option.closest('select');
+  if (select) {
+    select.dispatchEvent(new Event('input', {bubbles: true}));
+    select.dispatchEvent(new Event('change', {bubbles: true}));
+  }
+}`
+
+// setSelected clicks the option if its selectedness differs from selected, or
+// runs setSelectedScript with the driver of the option if the remote end
+// cannot click it.
+func setSelected(o WebElement, selected bool) error {
+	current, err := o.IsSelected()
+	if err != nil || current == selected {
+		return err
+	}
+	if err = o.Click(); !isNotInteractable(err) {
+		return err
+	}
+	_, err = o.Driver().ExecuteScript(setSelectedScript, []interface{}{o, selected})
+	return err
+}
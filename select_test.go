@@ -0,0 +1,257 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// selectOption is an option of the select served by newDropdownServer.
+type selectOption struct {
+	value, text       string
+	selected, enabled bool
+}
+
+// dropdownServer serves a <select> element with the ID "sel" and its options,
+// with the IDs "o0", "o1" etc., and records the options that are clicked.
+type dropdownServer struct {
+	*httptest.Server
+	multiple bool
+	// hidden makes clicks on options fail as they do for hidden selects.
+	hidden  bool
+	options []*selectOption
+	clicked []string
+	scripts []string
+}
+
+func newDropdownServer(t *testing.T, multiple bool, options ...*selectOption) *dropdownServer {
+	s := &dropdownServer{multiple: multiple, options: options}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		reply := func(v interface{}) {
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": v})
+		}
+		option := func(id string) *selectOption {
+			i, err := strconv.Atoi(strings.TrimPrefix(id, "o"))
+			if err != nil || i >= len(s.options) {
+				t.Fatalf("unknown option %q", id)
+			}
+			return s.options[i]
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/session/123")
+		switch {
+		case path == "/element/sel/name":
+			reply("SELECT")
+		case path == "/element/div/name":
+			reply("div")
+		case path == "/element/sel/property/multiple":
+			reply(s.multiple)
+		case path == "/element/sel/elements":
+			var params struct{ Using, Value string }
+			json.NewDecoder(r.Body).Decode(&params)
+			refs := []map[string]string{}
+			for i, o := range s.options {
+				if params.Value == "option" || params.Value == "option[value="+CSSAttrValue(o.value)+"]" {
+					refs = append(refs, map[string]string{webElementIdentifier: fmt.Sprintf("o%d", i)})
+				}
+			}
+			reply(refs)
+		case path == "/execute/sync":
+			var params struct {
+				Script string
+				Args   []json.RawMessage
+			}
+			json.NewDecoder(r.Body).Decode(&params)
+			var ref map[string]string
+			var selected bool
+			json.Unmarshal(params.Args[0], &ref)
+			json.Unmarshal(params.Args[1], &selected)
+			s.scripts = append(s.scripts, ref[webElementIdentifier])
+			option(ref[webElementIdentifier]).selected = selected
+			reply(nil)
+		case strings.HasPrefix(path, "/element/o"):
+			parts := strings.SplitN(strings.TrimPrefix(path, "/element/"), "/", 2)
+			o := option(parts[0])
+			switch parts[1] {
+			case "selected":
+				reply(o.selected)
+			case "enabled":
+				reply(o.enabled)
+			case "text":
+				if s.hidden {
+					reply("")
+				} else {
+					reply(o.text)
+				}
+			case "property/text":
+				reply(o.text)
+			case "click":
+				if s.hidden {
+					w.WriteHeader(http.StatusBadRequest)
+					json.NewEncoder(w).Encode(map[string]interface{}{"value": map[string]string{
+						"error":   "element not interactable",
+						"message": "element not interactable",
+					}})
+					return
+				}
+				s.clicked = append(s.clicked, parts[0])
+				if !s.multiple {
+					for _, other := range s.options {
+						other.selected = false
+					}
+				}
+				o.selected = !o.selected || !s.multiple
+				reply(nil)
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	return s
+}
+
+func (s *dropdownServer) selectElem(t *testing.T, id string) (*Select, error) {
+	t.Helper()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	return NewSelect(&remoteWE{parent: wd, id: id})
+}
+
+func (s *dropdownServer) selected() []string {
+	var values []string
+	for _, o := range s.options {
+		if o.selected {
+			values = append(values, o.value)
+		}
+	}
+	return values
+}
+
+func countryOptions() []*selectOption {
+	return []*selectOption{
+		{value: "ca", text: "Canada", enabled: true, selected: true},
+		{value: "fr", text: " French   Guiana ", enabled: true},
+		{value: "us", text: "United States", enabled: true},
+		{value: "um", text: "United States Minor Outlying Islands", enabled: false},
+	}
+}
+
+func TestSelectSingle(t *testing.T) {
+	s := newDropdownServer(t, false, countryOptions()...)
+	defer s.Close()
+	sel, err := s.selectElem(t, "sel")
+	if err != nil {
+		t.Fatalf("NewSelect() returned error: %v", err)
+	}
+	if sel.IsMultiple() {
+		t.Errorf("IsMultiple() = true, want false")
+	}
+
+	for _, tc := range []struct {
+		name   string
+		do     func() error
+		want   string
+		clicks int
+	}{
+		{"SelectByValue", func() error { return sel.SelectByValue("us") }, "us", 1},
+		{"SelectByVisibleText", func() error { return sel.SelectByVisibleText("French Guiana") }, "fr", 1},
+		{"SelectByPartialText", func() error { return sel.SelectByPartialText("Can") }, "ca", 1},
+		{"SelectByPartialTextFirstOnly", func() error { return sel.SelectByPartialText("United") }, "us", 1},
+		{"AlreadySelected", func() error { return sel.SelectByIndex(2) }, "us", 0},
+		{"SelectByIndex", func() error { return sel.SelectByIndex(0) }, "ca", 1},
+	} {
+		s.clicked = nil
+		if err := tc.do(); err != nil {
+			t.Errorf("%s returned error: %v", tc.name, err)
+			continue
+		}
+		if got := s.selected(); !reflect.DeepEqual(got, []string{tc.want}) {
+			t.Errorf("%s: selected options = %v, want [%s]", tc.name, got, tc.want)
+		}
+		if len(s.clicked) != tc.clicks {
+			t.Errorf("%s: clicked %v, want %d clicks", tc.name, s.clicked, tc.clicks)
+		}
+	}
+
+	first, err := sel.FirstSelectedOption()
+	if err != nil {
+		t.Fatalf("FirstSelectedOption() returned error: %v", err)
+	}
+	if id := first.(*remoteWE).id; id != "o0" {
+		t.Errorf("FirstSelectedOption() = %q, want %q", id, "o0")
+	}
+
+	for name, do := range map[string]func() error{
+		"SelectByValue":       func() error { return sel.SelectByValue("mx") },
+		"SelectByVisibleText": func() error { return sel.SelectByVisibleText("United") },
+		"SelectByIndex":       func() error { return sel.SelectByIndex(4) },
+	} {
+		if err := do(); !isNoSuchElement(err) {
+			t.Errorf("%s for a missing option returned %v, want a no such element error", name, err)
+		}
+	}
+	if err := sel.SelectByValue("um"); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("SelectByValue for a disabled option returned %v, want an error about it", err)
+	}
+	if err := sel.DeselectAll(); err == nil {
+		t.Errorf("DeselectAll() on a single select returned no error")
+	}
+}
+
+func TestSelectMultiple(t *testing.T) {
+	s := newDropdownServer(t, true, countryOptions()...)
+	defer s.Close()
+	sel, err := s.selectElem(t, "sel")
+	if err != nil {
+		t.Fatalf("NewSelect() returned error: %v", err)
+	}
+	if !sel.IsMultiple() {
+		t.Errorf("IsMultiple() = false, want true")
+	}
+	if err := sel.SelectByPartialText("States"); err == nil {
+		t.Errorf("SelectByPartialText() matching a disabled option returned no error")
+	}
+	if got, want := s.selected(), []string{"ca", "us"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("selected options = %v, want %v", got, want)
+	}
+	if err := sel.DeselectAll(); err != nil {
+		t.Fatalf("DeselectAll() returned error: %v", err)
+	}
+	if got := s.selected(); len(got) != 0 {
+		t.Errorf("selected options after DeselectAll() = %v, want none", got)
+	}
+	if _, err := sel.FirstSelectedOption(); !isNoSuchElement(err) {
+		t.Errorf("FirstSelectedOption() with no selected option returned %v, want a no such element error", err)
+	}
+}
+
+func TestSelectHidden(t *testing.T) {
+	s := newDropdownServer(t, false, countryOptions()...)
+	s.hidden = true
+	defer s.Close()
+	sel, err := s.selectElem(t, "sel")
+	if err != nil {
+		t.Fatalf("NewSelect() returned error: %v", err)
+	}
+	if err := sel.SelectByVisibleText("United States"); err != nil {
+		t.Fatalf("SelectByVisibleText() returned error: %v", err)
+	}
+	if got, want := s.scripts, []string{"o2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("options selected by script = %v, want %v", got, want)
+	}
+}
+
+func TestNewSelectRejectsOtherElements(t *testing.T) {
+	s := newDropdownServer(t, false)
+	defer s.Close()
+	_, err := s.selectElem(t, "div")
+	if err == nil || !strings.Contains(err.Error(), "<div>") {
+		t.Errorf("NewSelect() for a <div> returned %v, want an error naming the element", err)
+	}
+}
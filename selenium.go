@@ -1,6 +1,9 @@
 package selenium
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"time"
 
 	"github.com/tebeka/selenium/chrome"
@@ -24,9 +27,13 @@ const (
 	ByCSSSelector     = "css selector"
 )
 
+// MouseButton is a button of the mouse, numbered as in the W3C actions API
+// and the legacy protocol alike.
+type MouseButton int
+
 // Mouse buttons.
 const (
-	LeftButton = iota
+	LeftButton MouseButton = iota
 	MiddleButton
 	RightButton
 )
@@ -186,6 +193,14 @@ type Size struct {
 	Width, Height int
 }
 
+// Rect is the position and size of a window or an element, in CSS pixels.
+type Rect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
 // Cookie represents an HTTP cookie.
 type Cookie struct {
 	Name   string `json:"name"`
@@ -203,7 +218,8 @@ type LogMessage struct {
 	Message   string
 }
 
-// LogType are logger types
+// LogType are logger types. Use LogTypeOf to validate a log type given as a
+// string.
 type LogType string
 
 // The valid log types.
@@ -218,7 +234,10 @@ const (
 
 // WebDriver defines methods supported by WebDriver drivers.
 type WebDriver interface {
-	// Status returns various pieces of information about the server environment.
+	// Status returns various pieces of information about the server
+	// environment. Unlike the other commands, it does not need a session;
+	// see also ServerClient. The commands that need one return ErrNoSession
+	// without contacting the remote end if there is none.
 	Status() (*Status, error)
 
 	// NewSession starts a new session and returns the session ID.
@@ -233,22 +252,212 @@ type WebDriver interface {
 	// SessionID returns the current session ID.
 	SessionID() string
 
+	// OnSessionEvent registers f to be called with the lifecycle events of
+	// the WebDriver's sessions, and returns a function that unregisters it.
+	// See the package-level OnSessionEvent for how the events are delivered.
+	// The handlers of the WebDriver are unregistered after the SessionQuit
+	// event of its session.
+	OnSessionEvent(f func(SessionEvent)) (remove func())
+	// OnNavigation registers f to be called with the URL of the new document
+	// when the current browsing context navigates, and returns a function
+	// that unregisters it. The handlers are called synchronously, after Get,
+	// Back, Forward and Refresh, and after any other command during which
+	// the page navigated, e.g. by following a link or by script. Handlers may
+	// send commands, which do not trigger them again.
+	//
+	// Navigations are detected by planting a marker on the window object
+	// with a script after each command, which costs two round trips per
+	// command while handlers are registered, and none otherwise: the first
+	// checks, with Get Alert Text, that no user prompt is open, since running
+	// the script would make the remote end handle the prompt, by default by
+	// dismissing it. No check is done while a prompt is open, so a
+	// navigation during a command that opens a prompt is reported once the
+	// prompt has been closed. If scripts cannot
+	// be run, e.g. because of a Content Security Policy, the current URL is
+	// compared instead, which misses reloads and navigations to the same
+	// URL, and reports changes of the URL within the document, such as
+	// history.pushState, as navigations. The first command after switching
+	// to another window or frame only plants the marker, so navigations of
+	// a window or frame while it is not the current one are not reported.
+	OnNavigation(f func(newURL string)) (remove func())
+
+	// SessionStats returns the statistics of the current session, which are
+	// counted from when it was created or switched to.
+	SessionStats() SessionStats
+
 	// SwitchSession switches to the given session ID.
 	SwitchSession(sessionID string) error
 
 	// Capabilities returns the current session's capabilities.
 	Capabilities() (Capabilities, error)
+	// ChromeInfo returns the Chrome-specific information in the capabilities
+	// that chromedriver returned when the session was created, such as the
+	// address of the DevTools protocol endpoint. It returns false if there is
+	// none, e.g. on other browsers.
+	ChromeInfo() (*ChromeSessionInfo, bool)
+	// FirefoxInfo returns the Firefox-specific information in the
+	// capabilities that geckodriver returned when the session was created,
+	// such as the profile directory. It returns false if there is none, e.g.
+	// on other browsers.
+	FirefoxInfo() (*FirefoxSessionInfo, bool)
+	// SessionRequestPayload returns the index of the request shape with which
+	// the current session was created, among those that NewSession tries,
+	// and the JSON payload that was sent.
+	SessionRequestPayload() (attempt int, payload []byte)
 
 	// SetAsyncScriptTimeout sets the amount of time that asynchronous scripts
 	// are permitted to run before they are aborted. The timeout will be rounded
-	// to nearest millisecond.
+	// to nearest millisecond, but a non-zero timeout to at least 1ms; zero
+	// means that scripts are not given any time. Negative timeouts are
+	// rejected.
 	SetAsyncScriptTimeout(timeout time.Duration) error
 	// SetImplicitWaitTimeout sets the amount of time the driver should wait when
-	// searching for elements. The timeout will be rounded to nearest millisecond.
+	// searching for elements. The timeout is rounded as by
+	// SetAsyncScriptTimeout; zero means that the driver does not wait.
 	SetImplicitWaitTimeout(timeout time.Duration) error
 	// SetPageLoadTimeout sets the amount of time the driver should wait when
-	// loading a page. The timeout will be rounded to nearest millisecond.
+	// loading a page. The timeout is rounded as by SetAsyncScriptTimeout.
 	SetPageLoadTimeout(timeout time.Duration) error
+	// SetTimeouts sets all three timeouts of the session, rounded as by
+	// SetAsyncScriptTimeout, with a single command where the protocol allows.
+	SetTimeouts(timeouts Timeouts) error
+	// GetTimeouts returns the timeouts of the session, which it reads from the
+	// remote end on every call. If the remote end does not implement the
+	// command, as legacy ones do not, it stops trying for the rest of the
+	// session and returns the timeouts negotiated when the session was
+	// created, as updated by the methods that set them, or an
+	// *UnsupportedCommandError if they are not all known.
+	GetTimeouts() (Timeouts, error)
+	// StrictTimeouts controls how the client-side waits (Wait and its variants,
+	// and FindElementWithTimeout) treat a non-zero implicit wait timeout, which
+	// would otherwise stretch every poll that finds elements. By default they
+	// warn once per session through TimeoutWarningHandler. When enabled, they
+	// set the implicit wait to zero for the duration of the wait and restore
+	// it afterwards, even if the condition panics.
+	StrictTimeouts(enabled bool)
+
+	// SetFileDialogGuard enables or disables the file dialog guard. While
+	// enabled, WebElement.Click returns ErrWouldOpenFileDialog for
+	// <input type="file"> elements instead of opening a native file dialog,
+	// and trusted clicks on file inputs in the current document are prevented
+	// from opening one. Disabling the guard removes its listener from the
+	// current document; documents of other windows and frames keep it until
+	// they are unloaded.
+	SetFileDialogGuard(enabled bool)
+
+	// SetFileDetector sets the function that decides whether the keys passed
+	// to WebElement.SendKeys name local files to upload to the remote end,
+	// e.g. LocalFileDetector. It is nil by default, which disables uploads.
+	SetFileDetector(detect FileDetector)
+	// UploadFile uploads the local file at localPath to the remote end and
+	// returns its path there, which can be typed into file inputs with
+	// WebElement.SendKeys, as often as needed. The file is zipped and encoded
+	// as it is sent, so that large files are not held in memory. It returns
+	// an *UnsupportedCommandError if the remote end does not accept uploads,
+	// as with Safari.
+	UploadFile(localPath string) (remotePath string, err error)
+	// SetPointerPrecision sets how the target coordinates of WebElement.Click
+	// and WebElement.MoveTo with zero offsets are determined.
+	SetPointerPrecision(p PointerPrecision)
+
+	// SetDialectWarnings enables or disables warnings for calls whose behavior
+	// is known to differ between the legacy and W3C protocols. Warnings are
+	// passed to DialectWarningHandler, at most once per difference and command.
+	SetDialectWarnings(enabled bool)
+
+	// SetElementDecoder sets a function that extracts the ID of an element
+	// from its encoding in a reply, for remote ends that return element
+	// references under a non-standard key. It is consulted before the W3C and
+	// legacy encodings, which are used if it returns false.
+	SetElementDecoder(decode func(raw json.RawMessage) (id string, ok bool))
+	// SetElementEncoder sets a function that returns the encoding of an
+	// element passed as an argument to the remote end, e.g. to a script, for
+	// remote ends that expect a non-standard key. By default, elements are
+	// encoded with both the W3C and legacy keys.
+	SetElementEncoder(encode func(id string) interface{})
+	// SetStrictDecoding enables or disables strict decoding of the values of
+	// responses, meant for canary runs against pre-releases of drivers. While
+	// enabled, commands whose values have fields that the client does not
+	// expect, or have non-zero fields none of which the client decodes,
+	// return a *DecodeError carrying the value, rather than zero values.
+	//
+	// Strict decoding covers the values of the commands of the protocol,
+	// and of GetValue and PostValue. It does not cover the results of
+	// scripts, including those that the client runs itself; values that are
+	// decoded leniently on purpose because remote ends disagree on their
+	// shape: capabilities, cookies, timeouts, screenshots, element
+	// references and the handles returned by CloseWindow; nor the status of
+	// the remote end.
+	SetStrictDecoding(strict bool)
+	// StrictDecoding reports whether strict decoding is enabled.
+	StrictDecoding() bool
+	// SetStaleRetry makes element commands that fail because the element is
+	// stale find the element again, with the locator that it was found with
+	// by FindElement, and retry, up to attempts times in all. Only the
+	// WebElement methods named in commands are retried, or those in
+	// DefaultStaleRetryCommands if none are given; Submit never is. Elements
+	// returned by FindElements are not found again, as the locator may not
+	// identify them. An attempts value of 1 or less disables retries.
+	SetStaleRetry(attempts int, commands ...string)
+	// SetClickRetry makes WebElement.Click retry clicks that fail because
+	// another element, e.g. a toast or a sticky header, would receive them,
+	// up to attempts times in all. Before each retry, it waits for interval
+	// and scrolls the element to the center of the viewport. Other errors are
+	// not retried. If the last attempt is intercepted too, Click returns a
+	// *ClickRetryError wrapping its error. An attempts value of 1 or less
+	// disables retries.
+	SetClickRetry(attempts int, interval time.Duration)
+
+	// SetRelativeXPathCheck enables or disables checking XPath expressions
+	// passed to WebElement.FindElement and WebElement.FindElements. While
+	// enabled, expressions that start with "/" or "//", which search the whole
+	// document rather than the element's subtree, return an error. Those
+	// that start with "//" are rewritten to start with ".//" instead if
+	// RewriteRelativeXPath is true.
+	SetRelativeXPathCheck(enabled bool)
+
+	// EnableCommandHistory enables recording the last n commands of the
+	// session, which are returned by CommandHistory and included in DebugDump.
+	// Bodies of commands that may carry secrets, such as typed keys and
+	// cookies, are masked. While enabled, the Detail method of *Error values
+	// returned by the remote end includes the records of the failing command
+	// and its two predecessors. A value of n of zero or less disables
+	// recording.
+	EnableCommandHistory(n int)
+	// CommandHistory returns the recorded commands, oldest first.
+	CommandHistory() []CommandRecord
+	// DebugDump writes a Zip file to w describing the state of the session, for
+	// attaching to bug reports. It includes the requested and negotiated
+	// capabilities, the current URL, title, page source and screenshot,
+	// cookies, logs, the command history and the versions of the
+	// client, driver and browser. Sections that cannot be collected are listed
+	// in errors.txt instead of failing the dump.
+	DebugDump(w io.Writer, opts DumpOptions) error
+	// SetArtifactSink sets where the files produced for the session, such as
+	// those of SaveDebugDump, are stored.
+	SetArtifactSink(s ArtifactSink)
+	// SetArtifactTestName sets the directory of the session's artifacts within
+	// the sink, typically to the name of the test, e.g. t.Name(). By default,
+	// the session ID is used.
+	SetArtifactTestName(name string)
+	// SaveDebugDump stores a debug dump, as written by DebugDump, in the
+	// artifact sink, and returns its name. The reason is recorded in the
+	// session's artifact index.
+	SaveDebugDump(reason string, opts DumpOptions) (string, error)
+	// EnableEvidence enables evidence mode, in which Step captures a
+	// screenshot and the URL before and after each step and stores them in
+	// sink, along with a journal of the steps as JSON and as an HTML report,
+	// evidence-{session}.json and .html, in the session's artifact directory.
+	// The journal is written whenever a top-level step ends. A nil sink
+	// disables evidence mode.
+	EnableEvidence(sink ArtifactSink)
+	// Step runs fn as a named step. In evidence mode, the step is recorded in
+	// the journal with the evidence captured before and after it, even if it
+	// fails; steps run within fn are nested in it. Otherwise, Step only calls
+	// fn.
+	Step(name string, fn func() error) error
+	// EvidenceSteps returns the steps recorded in evidence mode.
+	EvidenceSteps() []*EvidenceStep
 
 	// AvailableEngines lists all available engines on the machine.
 	AvailableEngines() ([]string, error)
@@ -263,6 +472,10 @@ type WebDriver interface {
 
 	// Quit ends the current session. The browser instance will be closed.
 	Quit() error
+	// QuitContext is like Quit, but gives up when ctx is done. The session is
+	// then considered ended even if the remote end did not answer, and later
+	// commands return ErrSessionClosed without contacting it.
+	QuitContext(ctx context.Context) error
 
 	// CurrentWindowHandle returns the ID of current window handle.
 	CurrentWindowHandle() (string, error)
@@ -270,40 +483,174 @@ type WebDriver interface {
 	WindowHandles() ([]string, error)
 	// CurrentURL returns the browser's current URL.
 	CurrentURL() (string, error)
+	// SetAuthGuard makes the session re-authenticate when it is logged out,
+	// e.g. because its login expired during a long run. After Get, Back,
+	// Forward and Refresh, the current URL is passed to detect, and if it
+	// returns true, e.g. because the page redirected to the login page, reauth
+	// is called to log in again. Get is then retried once; the other commands
+	// are not, since the history holds the login pages. Commands sent by
+	// reauth do not trigger the guard. Re-authentications are limited per
+	// hour; once the limit is reached, the commands that find the session
+	// logged out return ErrReauthLimit. A nil detect or reauth removes the
+	// guard.
+	SetAuthGuard(detect func(url string) bool, reauth func(wd WebDriver) error)
+	// SetAuthGuardLimits configures the guard set with SetAuthGuard. If
+	// checkEvery is positive, the current URL is also checked before every
+	// checkEvery-th command, and the session re-authenticated before the
+	// command is sent if it is logged out. maxPerHour limits the
+	// re-authentications per hour, which defaults to
+	// DefaultMaxReauthsPerHour. It has no effect if no guard is set.
+	SetAuthGuardLimits(checkEvery, maxPerHour int)
+
 	// Title returns the current page's title.
 	Title() (string, error)
 	// PageSource returns the current page's source.
 	PageSource() (string, error)
-	// Close closes the current window.
+	// Close closes the current window and, unless disabled with
+	// SetSwitchOnClose, switches to the first remaining window. If the closed
+	// window was the last one, ErrLastWindowClosed is returned; Quit should
+	// then be called to end the session.
 	Close() error
+	// CloseAndSwitch is like Close, but also returns the handles of the
+	// remaining windows.
+	CloseAndSwitch() (remaining []string, err error)
+	// SetSwitchOnClose sets whether Close and CloseAndSwitch switch to the
+	// first remaining window after closing the current one. It is enabled by
+	// default.
+	SetSwitchOnClose(enabled bool)
 	// SwitchFrame switches to the given frame. The frame parameter can be the
 	// frame's ID as a string, its WebElement instance as returned by
-	// GetElement, or nil to switch to the current top-level browsing context.
+	// GetElement, or its index among the frames of the current document as an
+	// int, or as a string if no frame has it as ID. nil or the empty string
+	// switch to the current top-level browsing context. On W3C sessions,
+	// indices are resolved to the frame and iframe elements of the document,
+	// since some remote ends reject them.
 	SwitchFrame(frame interface{}) error
+	// SwitchToDefaultContent switches to the current top-level browsing
+	// context, like SwitchFrame(nil).
+	SwitchToDefaultContent() error
+	// SwitchFrameParent switches to the parent of the current frame.
+	SwitchFrameParent() error
+	// FramePath returns the frames switched to from the top-level browsing
+	// context, outermost first, as element references, indices or, on legacy
+	// sessions, names and IDs. It is empty in the top-level browsing context,
+	// to which SwitchWindow and navigation return. The element references
+	// become stale when the page that contains them is reloaded.
+	FramePath() []interface{}
+	// NewWindow opens a new tab or window, according to typ, which is
+	// WindowTypeTab or WindowTypeWindow, and returns its handle and the type
+	// of window that was actually opened, which the remote end may choose
+	// differently. The new window is not switched to. Legacy sessions open
+	// the window by script.
+	NewWindow(typ string) (handle, windowType string, err error)
+	// Windows describes all the windows of the session, by switching to each
+	// of them in turn. The original window is switched back to, even if a
+	// query fails. Windows that are closed meanwhile are left out, and
+	// reported by a *MultiError returned along with the other windows.
+	Windows() ([]WindowInfo, error)
+	// WindowsSorted is like Windows, but returns the windows sorted by the
+	// key, rather than in the order in which the remote end lists them, which
+	// is unspecified and may change from one call to the next. Windows with
+	// the same title or URL are sorted by WindowsByOpenOrder.
+	WindowsSorted(by WindowSortKey) ([]WindowInfo, error)
 	// SwitchWindow switches the context to the specified window.
 	SwitchWindow(name string) error
-	// CloseWindow closes the specified window.
-	CloseWindow(name string) error
+	// CloseWindow closes the specified window, or the current window if the
+	// name is empty, and returns the handles of the remaining windows. If
+	// another window was current, it is switched back to; otherwise no window
+	// is current until one of the remaining windows is switched to. If the
+	// closed window was the last one, ErrLastWindowClosed is returned.
+	CloseWindow(name string) (remaining []string, err error)
 	// MaximizeWindow maximizes a window. If the name is empty, the current
 	// window will be maximized.
 	MaximizeWindow(name string) error
+	// MinimizeWindow minimizes a window. If the name is empty, the current
+	// window will be minimized. It returns an *UnsupportedCommandError if the
+	// remote end cannot minimize windows, as on sessions using the legacy
+	// protocol.
+	MinimizeWindow(name string) error
+	// FullscreenWindow makes a window fullscreen, as with F11. If the name is
+	// empty, the current window is made fullscreen. It returns the resulting
+	// rectangle of the window, which window managers that ignore the request
+	// leave unchanged. It returns an *UnsupportedCommandError if the remote
+	// end cannot make windows fullscreen, as on sessions using the legacy
+	// protocol.
+	FullscreenWindow(name string) (*Rect, error)
 	// ResizeWindow changes the dimensions of a window. If the name is empty, the
 	// current window will be maximized.
 	ResizeWindow(name string, width, height int) error
+	// WindowRect returns the position and size of the window with the given
+	// handle, or of the current window if the handle is empty.
+	WindowRect(handle string) (*Rect, error)
+	// SetWindowRect moves and resizes the window with the given handle, or
+	// the current window if the handle is empty. Drivers may clamp the
+	// rectangle to the screen; WindowRect returns the one applied.
+	SetWindowRect(handle string, r Rect) error
+	// GetWindowPosition returns the position of a window on the screen. If
+	// the name is empty, the current window is used.
+	GetWindowPosition(name string) (*Point, error)
+	// SetWindowPosition moves a window to the given position on the screen,
+	// keeping its size. If the name is empty, the current window is moved.
+	SetWindowPosition(name string, x, y int) error
 
-	// Get navigates the browser to the provided URL.
+	// Get navigates the browser to the provided URL. data: and about: URLs
+	// are passed to the remote end as they are; to load an HTML snippet,
+	// RenderHTML is more portable.
 	Get(url string) error
+	// RenderHTML navigates the browser to a page with the given HTML, served
+	// by a local HTTP server that the WebDriver starts when first needed and
+	// stops when the session is quit. Only the last 32 pages rendered are
+	// kept, so navigating back to older ones finds them missing. See
+	// PublicBaseURL for remote ends on another host.
+	RenderHTML(html string) error
+	// ServeDir serves the files in dir from the WebDriver's local HTTP server
+	// and returns the URL of the directory, which ends with a slash. The
+	// files are served until stop is called or the session is quit.
+	ServeDir(dir string) (baseURL string, stop func(), err error)
 	// Forward moves forward in history.
 	Forward() error
 	// Back moves backward in history.
 	Back() error
 	// Refresh refreshes the page.
 	Refresh() error
+	// WaitForNetworkIdle waits until no network request has been in flight
+	// for idleFor, or returns an error after timeout listing the requests that
+	// were still pending. Requests whose URLs match any of the regular
+	// expressions in ignore, as well as WebSockets, are not tracked.
+	//
+	// In-flight requests are tracked via the performance log, which must be
+	// enabled in the capabilities, e.g. on ChromeDriver. Otherwise, the network
+	// is considered idle if no resource finished loading, as reported by a
+	// PerformanceObserver, within idleFor; requests that have not completed
+	// cannot be observed in this mode.
+	WaitForNetworkIdle(idleFor, timeout time.Duration, ignore []string) error
+	// SubresourceResponses returns the responses to the requests of the
+	// current page, starting with its document, with their status codes and
+	// headers; see CheckResponseHeaders. On Chromium-based browsers, the
+	// performance log must be enabled in the capabilities. Otherwise the
+	// responses are taken from the resource timing entries of the page and
+	// marked as partial, without headers. Responses are recorded whenever the
+	// performance log is read, including through Log, and only the last 1000
+	// are kept.
+	SubresourceResponses() ([]ResourceResponse, error)
 
 	// FindElement finds exactly one element in the current page's DOM.
 	FindElement(by, value string) (WebElement, error)
 	// FindElement finds potentially many elements in the current page's DOM.
 	FindElements(by, value string) ([]WebElement, error)
+	// Find finds exactly one element in the current page's DOM. It is
+	// equivalent to FindElement(loc.By, loc.Value).
+	Find(loc Locator) (WebElement, error)
+	// FindAll finds potentially many elements in the current page's DOM. It
+	// is equivalent to FindElements(loc.By, loc.Value).
+	FindAll(loc Locator) ([]WebElement, error)
+	// FindElementRelative finds the element matched by the relative locator
+	// that is closest to its first anchor, e.g.
+	// FindElementRelative(WithTagName("input").Below(label)).
+	FindElementRelative(rb *RelativeBy) (WebElement, error)
+	// FindElementsRelative finds the elements matched by the relative locator,
+	// from the closest to its first anchor to the farthest.
+	FindElementsRelative(rb *RelativeBy) ([]WebElement, error)
 	// ActiveElement returns the currently active element on the page.
 	ActiveElement() (WebElement, error)
 
@@ -314,8 +661,9 @@ type WebDriver interface {
 
 	// GetCookies returns all of the cookies in the browser's jar.
 	GetCookies() ([]Cookie, error)
-	// GetCookie returns the named cookie in the jar, if present. This method is
-	// only implemented for Firefox.
+	// GetCookie returns the named cookie in the jar, if present. If the remote
+	// end does not implement this command, the cookie is looked up in the
+	// result of GetCookies instead.
 	GetCookie(name string) (Cookie, error)
 	// AddCookie adds a cookie to the browser's jar.
 	AddCookie(cookie *Cookie) error
@@ -323,11 +671,29 @@ type WebDriver interface {
 	DeleteAllCookies() error
 	// DeleteCookie deletes a cookie to the browser's jar.
 	DeleteCookie(name string) error
+	// DeleteCookiesMatching deletes the cookies in the browser's jar for
+	// which pred returns true, e.g. CookieNameMatches or CookieDomainSuffix,
+	// and returns how many were deleted. Since the remote end deletes cookies
+	// by name, cookies that share their name with a deleted cookie but do not
+	// match are deleted too and then added back. Cookie does not hold the
+	// HttpOnly and SameSite attributes, so if such a cookie has either, no
+	// cookie with its name is deleted and the name is reported as a failure.
+	// Failures to delete or restore cookies do not stop the others from being
+	// processed, and are reported together by a *MultiError.
+	//
+	// The operation is best-effort with respect to scripts of the page that
+	// set cookies while it runs: a cookie set after the jar is listed is not
+	// deleted, unless it shares the name of a matching cookie.
+	DeleteCookiesMatching(pred func(Cookie) bool) (int, error)
 
-	// Click clicks a mouse button. The button should be one of RightButton,
-	// MiddleButton or LeftButton.
-	Click(button int) error
-	// DoubleClick clicks the left mouse button twice.
+	// Click clicks a mouse button at the current position of the mouse. The
+	// button must be one of LeftButton, MiddleButton or RightButton. W3C
+	// sessions click with pointer actions; note that the /click endpoint of
+	// some legacy drivers always clicks the left button.
+	Click(button MouseButton) error
+	// DoubleClick clicks the left mouse button twice at the current position
+	// of the mouse. To double click an element, use WebElement.DoubleClick,
+	// which also works on W3C sessions.
 	DoubleClick() error
 	// ButtonDown causes the left mouse button to be held down.
 	ButtonDown() error
@@ -337,6 +703,20 @@ type WebDriver interface {
 	// SendModifier sends the modifier key to the active element. The modifier
 	// can be one of ShiftKey, ControlKey, AltKey, MetaKey.
 	SendModifier(modifier string, isDown bool) error
+	// ScrollContainer scrolls the contents of the container element by dx and dy
+	// pixels, using a wheel action targeted at the container where supported
+	// and script otherwise.
+	ScrollContainer(container WebElement, dx, dy int) error
+	// ScrollToElement scrolls the element into view with a wheel action, so
+	// that it can be clicked. It returns ErrLegacyProtocol for sessions using
+	// the legacy protocol.
+	ScrollToElement(elem WebElement) error
+	// ScrollByAmount scrolls by (deltaX, deltaY) pixels with the mouse wheel
+	// at the point (x, y) of the viewport, which determines the scrolled
+	// container. It returns ErrLegacyProtocol for sessions using the legacy
+	// protocol.
+	ScrollByAmount(x, y, deltaX, deltaY int) error
+
 	// KeyDown sends a sequence of keystrokes to the active element. This method
 	// is similar to SendKeys but without the implicit termination. Modifiers are
 	// not released at the end of each call.
@@ -344,13 +724,85 @@ type WebDriver interface {
 	// KeyUp indicates that a previous keystroke sent by KeyDown should be
 	// released.
 	KeyUp(keys string) error
+	// PerformActions performs the action sequences of one or more input
+	// sources, tick by tick. It returns ErrLegacyProtocol for sessions using
+	// the legacy protocol.
+	PerformActions(sequences []ActionSequence) error
+	// ReleaseActions releases the keys and buttons held down by previous calls
+	// to PerformActions and resets the state of the input sources.
+	ReleaseActions() error
+	// Actions returns a new, empty chain of keyboard and mouse actions.
+	Actions() *Actions
+	// Tap taps the center of the element with one finger, as on a touch
+	// screen.
+	Tap(elem WebElement) error
+	// DragAndDrop drags the source element with the left mouse button and
+	// drops it on the center of the target element.
+	DragAndDrop(source, target WebElement) error
+	// DragAndDropByOffset drags the source element with the left mouse button
+	// and drops it at the offset (x, y) from its center.
+	DragAndDropByOffset(source WebElement, x, y int) error
+	// DragAndDropViaScript drops the source element on the target element by
+	// dispatching the HTML5 drag and drop events, dragstart to dragend, from
+	// script. Use it for HTML5 drag and drop targets, which many browsers do
+	// not activate with synthetic pointer events.
+	DragAndDropViaScript(source, target WebElement) error
 	// Screenshot takes a screenshot of the browser window.
 	Screenshot() ([]byte, error)
+	// ScreenshotTo writes a screenshot of the browser window to w, in PNG
+	// format, decoding it as it is written rather than in memory.
+	ScreenshotTo(w io.Writer) error
+	// SaveScreenshot saves a screenshot of the browser window to the file
+	// with the given path, in PNG format. The file is only created, or
+	// replaced, once the whole screenshot has been decoded.
+	SaveScreenshot(path string) error
+	// PrintPage prints the current page with the W3C "Print Page" command
+	// and returns the PDF document. opts may be nil for the defaults.
+	PrintPage(opts *PrintOptions) ([]byte, error)
+	// FullPageScreenshot takes a screenshot of the whole scrollable document
+	// rather than only of the viewport, in PNG format. It is only supported by
+	// Firefox.
+	FullPageScreenshot() ([]byte, error)
+	// SetPageZoom zooms the current page by the factor, where 2 zooms to 200%.
+	// It uses the Chrome DevTools Protocol on Chromium-based browsers and the
+	// chrome context on Firefox, and otherwise falls back to scaling the body
+	// element with a CSS transform; see ZoomMethod for its limitations.
+	SetPageZoom(factor float64) error
+	// PageZoom returns the effective zoom factor of the current page and the
+	// method by which SetPageZoom zoomed it, or 1 and ZoomNone if it was not
+	// called in this session.
+	PageZoom() (float64, ZoomMethod, error)
 	// Log fetches the logs. Log types must be previously configured in the
 	// capabilities.
 	//
+	// The log type is validated with LogTypeOf.
+	//
 	// NOTE: will return an error (not implemented) on IE11 or Edge drivers.
 	Log(typ LogType) ([]LogMessage, error)
+	// AttachConsole streams the console messages and uncaught JavaScript
+	// errors of the session's pages over WebDriver BiDi, which gives Firefox
+	// sessions the console log that chromedriver offers through Log. The
+	// session must have been created with Capabilities.EnableBiDi.
+	//
+	// If the BiDi connection drops, AttachConsole reconnects and subscribes
+	// again, losing only the entries emitted in the meantime; see
+	// BiDiReconnectDelay and BiDiReconnectAttempts. The channel is closed
+	// when ctx is done, when the session is quit, or when reconnecting fails.
+	AttachConsole(ctx context.Context) (<-chan ConsoleEntry, error)
+
+	// LocalStorage returns the localStorage area of the current page's origin.
+	LocalStorage() WebStorage
+	// SessionStorage returns the sessionStorage area of the current page's
+	// origin.
+	SessionStorage() WebStorage
+	// StorageEstimate returns the storage quota and usage of the current page's
+	// origin, as reported by navigator.storage.estimate(). If the browser does
+	// not implement it, a StorageEstimate with Supported set to false is
+	// returned.
+	StorageEstimate() (*StorageEstimate, error)
+	// IndexedDB returns a handle to the named IndexedDB database of the current
+	// page's origin. An error is returned if the database does not exist.
+	IndexedDB(dbName string) (*IDBHandle, error)
 
 	// DismissAlert dismisses current alert.
 	DismissAlert() error
@@ -371,28 +823,135 @@ type WebDriver interface {
 	// ExecuteScriptAsyncRaw asynchronously executes a script but does not
 	// perform JSON decoding.
 	ExecuteScriptAsyncRaw(script string, args []interface{}) ([]byte, error)
+	// ExecuteCommand sends a command that the WebDriver interface does not
+	// cover, such as a vendor extension, to the path of the remote end, e.g.
+	// "/session/{id}/appium/device/lock". The params are encoded as JSON for
+	// POST requests, as an empty object if nil; other methods send no body,
+	// and passing them params is an error. It returns the body of the
+	// response; GetValue and PostValue decode its value.
+	ExecuteCommand(method, path string, params interface{}) (json.RawMessage, error)
+	// AddInitScript makes the script run in every document loaded from now
+	// on, before the scripts of the page. It is only supported by
+	// Chromium-based browsers.
+	AddInitScript(script string) error
+	// ApplyStealth installs the JavaScript shims of the options; see
+	// StealthOptions, which only targets testing environments that you
+	// control. opts may be nil for the defaults.
+	ApplyStealth(opts *StealthOptions) error
+
+	// WaitWithTimeoutAndInterval waits for the condition to evaluate to true,
+	// polling it every interval until the timeout elapses.
+	WaitWithTimeoutAndInterval(condition Condition, timeout, interval time.Duration) error
+	// WaitWithTimeout works like WaitWithTimeoutAndInterval, but with
+	// DefaultWaitInterval as the interval.
+	WaitWithTimeout(condition Condition, timeout time.Duration) error
+	// Wait works like WaitWithTimeoutAndInterval, but using the default
+	// timeout and polling interval.
+	Wait(condition Condition) error
+	// FindElementWithTimeout finds an element, polling until it is present or
+	// the timeout elapses.
+	FindElementWithTimeout(by, value string, timeout time.Duration) (WebElement, error)
+	// WaitForElementCount polls FindElements until pred, e.g. AtLeast,
+	// Exactly or Between, holds for the number of elements found, and returns
+	// them. If the timeout elapses first, it returns an *ElementCountError
+	// with the last count.
+	WaitForElementCount(by, value string, pred func(int) bool, timeout time.Duration) ([]WebElement, error)
+	// WaitUntilGone polls until no element found with the locator is
+	// displayed: none is found, or those found are hidden or have been
+	// removed since. If the timeout elapses, or the context set with
+	// WaitContext is done, first, it returns an *ElementWaitError with the
+	// number of displayed elements last observed.
+	WaitUntilGone(by, value string, timeout time.Duration, opts ...WaitOption) error
 }
 
 // WebElement defines method supported by web elements.
 type WebElement interface {
-	// Click clicks on the element.
+	// Click clicks on the element. If the file dialog guard is enabled and the
+	// element is a file input, ErrWouldOpenFileDialog is returned.
 	Click() error
-	// SendKeys types into the element.
+	// ClickAt clicks the element at the offset from its top-left corner, in
+	// CSS pixels, e.g. to hit a point of a canvas or an image map. The
+	// offsets mean the same on W3C and legacy sessions. The element should be
+	// fully in view, as W3C offsets are converted from its center.
+	ClickAt(xOffset, yOffset int) error
+	// SendKeys types into the element. If a file detector is set with
+	// WebDriver.SetFileDetector, the lines of keys that name local files are
+	// uploaded to the remote end first, and replaced with their paths there.
 	SendKeys(keys string) error
-	// Submit submits the button.
+	// SendKeysLiteral types keys into the element as SendKeys does, but
+	// without uploading the files they may name.
+	SendKeysLiteral(keys string) error
+	// Submit submits the form that the element is in, or the element itself
+	// if it is a form. It returns an error wrapping ErrNotInForm if there is
+	// no such form.
 	Submit() error
+	// RightClick clicks the center of the element with the right mouse
+	// button, which usually opens a context menu.
+	RightClick() error
+	// DoubleClick double clicks the center of the element with the left mouse
+	// button.
+	DoubleClick() error
+	// MiddleClick clicks the center of the element with the middle mouse
+	// button.
+	MiddleClick() error
 	// Clear clears the element.
 	Clear() error
+	// DropFiles simulates dropping the local files at the given paths onto the
+	// element, as with a drag-and-drop upload widget. The files are read
+	// locally, transferred to the page and dispatched on the element in the
+	// dragenter, dragover and drop events. MIME types are inferred from file
+	// extensions, subject to DropFileMIMETypes.
+	DropFiles(paths ...string) error
 	// MoveTo moves the mouse to relative coordinates from center of element, If
 	// the element is not visible, it will be scrolled into view.
 	MoveTo(xOffset, yOffset int) error
+	// Hover moves the mouse to the center of the element, scrolling it into
+	// view, e.g. to open a menu shown on :hover. Unlike MoveTo, it uses
+	// pointer actions on W3C sessions.
+	Hover() error
+	// HoverAt works like Hover, but moves the mouse to the offset from the
+	// center of the element, in CSS pixels.
+	HoverAt(xOffset, yOffset int) error
+
+	// ScrollableAncestor returns the nearest ancestor of the element that is
+	// scrollable, such as an element with overflow: auto whose content
+	// overflows it.
+	ScrollableAncestor() (WebElement, error)
+	// ScrollIntoViewWithin scrolls the container, which must be an ancestor of
+	// the element, so that the element is centered within it.
+	ScrollIntoViewWithin(container WebElement) error
 
 	// FindElement finds a child element.
 	FindElement(by, value string) (WebElement, error)
 	// FindElement finds multiple children elements.
 	FindElements(by, value string) ([]WebElement, error)
+	// WaitForElementCount works like WebDriver.WaitForElementCount, for the
+	// children of the element, e.g. the rows of a table. If the element
+	// becomes stale while polling, the returned error wraps ErrStaleParent.
+	WaitForElementCount(by, value string, pred func(int) bool, timeout time.Duration) ([]WebElement, error)
+	// WaitUntilVisible polls IsDisplayed until the element is displayed. A
+	// stale element is polled further, as it may be found again if
+	// WebDriver.SetStaleRetry is enabled. If the timeout elapses, or the
+	// context set with WaitContext is done, first, it returns an
+	// *ElementWaitError with the last state of the element observed.
+	WaitUntilVisible(timeout time.Duration, opts ...WaitOption) error
+	// WaitUntilClickable works like WaitUntilVisible, but also waits for the
+	// element to be enabled.
+	WaitUntilClickable(timeout time.Duration, opts ...WaitOption) error
+	// Find finds a child element. It is equivalent to
+	// FindElement(loc.By, loc.Value).
+	Find(loc Locator) (WebElement, error)
+	// FindAll finds multiple children elements. It is equivalent to
+	// FindElements(loc.By, loc.Value).
+	FindAll(loc Locator) ([]WebElement, error)
+	// FindElementRaw finds a child element and returns the remote end's
+	// response without decoding it, e.g. to access vendor-specific fields of
+	// the element payload. The response can be decoded with
+	// WebDriver.DecodeElement.
+	FindElementRaw(by, value string) (json.RawMessage, error)
 
-	// TagName returns the element's name.
+	// TagName returns the element's name. It is cached on the element, since
+	// it cannot change; see Describe.
 	TagName() (string, error)
 	// Text returns the text of the element.
 	Text() (string, error)
@@ -402,8 +961,34 @@ type WebElement interface {
 	IsEnabled() (bool, error)
 	// IsDisplayed returns true if the element is displayed.
 	IsDisplayed() (bool, error)
+	// IsStale returns true if the element is no longer attached to the
+	// document, e.g. because the page re-rendered it, as reported by the
+	// remote end for a cheap command on the element. Other errors are
+	// returned as is.
+	IsStale() (bool, error)
 	// GetAttribute returns the named attribute of the element.
 	GetAttribute(name string) (string, error)
+	// GetProperty returns the named DOM property of the element, which, unlike
+	// the attribute, reflects the current state of the element, e.g. the text
+	// typed into an input as its "value" property. Strings are returned as
+	// is, null as the empty string, and other values, such as booleans and
+	// numbers, as JSON, e.g. "true" for the "checked" property of a checked
+	// checkbox.
+	GetProperty(name string) (string, error)
+	// GetPropertyRaw is like GetProperty, but returns the value as JSON.
+	GetPropertyRaw(name string) (json.RawMessage, error)
+	// GetAttributeOrProperty returns the named attribute of the element as
+	// legacy drivers did: the current value of the corresponding property
+	// where there is one, e.g. the text typed into an input for "value", and
+	// the attribute from the markup otherwise. Boolean attributes, such as
+	// "checked", are returned as "true" or the empty string. On W3C drivers,
+	// whose GetAttribute only returns the attribute from the markup, the value
+	// is computed by a script.
+	GetAttributeOrProperty(name string) (string, error)
+	// Attributes returns all the attributes of the element from the markup,
+	// by their qualified names, e.g. "xlink:href" on SVG elements, with a
+	// single command.
+	Attributes() (map[string]string, error)
 	// Location returns the element's location.
 	Location() (*Point, error)
 	// LocationInView returns the element's location once it has been scrolled
@@ -411,7 +996,40 @@ type WebElement interface {
 	LocationInView() (*Point, error)
 	// Size returns the element's size.
 	Size() (*Size, error)
+	// Rect returns the element's location and size. Unlike Location and
+	// Size, which truncate them to integers, it keeps the fractional pixels
+	// of elements that are scaled, transformed or laid out on displays with
+	// a device pixel ratio other than 1.
+	Rect() (*Rect, error)
 	// CSSProperty returns the value of the specified CSS property of the
 	// element.
 	CSSProperty(name string) (string, error)
+	// ComputedRole returns the ARIA role that the browser computed for the
+	// element, e.g. "button". Remote ends that do not implement the command,
+	// such as older versions of chromedriver, make it return an
+	// *UnsupportedCommandError.
+	ComputedRole() (string, error)
+	// ComputedLabel returns the accessible name that the browser computed
+	// for the element, e.g. the value of its aria-label attribute. It fails
+	// like ComputedRole on remote ends that do not implement the command.
+	ComputedLabel() (string, error)
+	// Screenshot takes a screenshot of the element's rectangle, in PNG
+	// format. If scroll is true, the element is scrolled into view first;
+	// remote ends implementing the W3C "Take Element Screenshot" command
+	// always do so. Other remote ends get a screenshot of the viewport
+	// cropped to the element.
+	Screenshot(scroll bool) ([]byte, error)
+
+	// Describe returns the element's tag name, common attributes and
+	// rectangle, fetched with a single script execution. The tag name, which
+	// cannot change, is cached on the element, as it is by TagName, and used
+	// by NewSelect and the file dialog guard until InvalidateCache is called
+	// or a command reports that the element is stale. The attributes and the
+	// rectangle are fetched anew on each call.
+	Describe() (*ElementInfo, error)
+	// InvalidateCache discards the tag name cached by Describe and TagName.
+	InvalidateCache()
+	// Driver returns the WebDriver of the session that the element belongs
+	// to, e.g. to pass the element to a script.
+	Driver() WebDriver
 }
@@ -0,0 +1,39 @@
+package selenium
+
+import "encoding/json"
+
+// ServerClient sends the commands of a remote end that do not need a
+// session, such as Status. It can be used to probe a server before creating
+// a session with NewRemote; the WebDriver returned by NewRemote sends these
+// commands through a ServerClient of its own.
+type ServerClient struct {
+	// wd sends the commands, without using its session if it has one: it is
+	// either a driver without a session, for NewServerClient, or the
+	// WebDriver whose Status is delegated here, so that the commands go
+	// through its HTTP client.
+	wd *remoteWD
+}
+
+// NewServerClient returns a client of the remote end at urlPrefix, e.g.
+// "http://localhost:4444/wd/hub".
+func NewServerClient(urlPrefix string) *ServerClient {
+	if urlPrefix == "" {
+		urlPrefix = DefaultURLPrefix
+	}
+	return &ServerClient{wd: &remoteWD{urlPrefix: urlPrefix}}
+}
+
+// Status returns various pieces of information about the server environment.
+func (c *ServerClient) Status() (*Status, error) {
+	reply, err := c.wd.execute("GET", c.wd.requestURL("/status"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	status := new(struct{ Value Status })
+	if err := json.Unmarshal(reply, status); err != nil {
+		return nil, err
+	}
+
+	return &status.Value, nil
+}
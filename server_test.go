@@ -0,0 +1,99 @@
+package selenium
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestServerClient(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		if r.URL.Path != "/status" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"value":{"ready":true,"message":"ready for new sessions"}}`)
+	}))
+	defer s.Close()
+
+	status, err := NewServerClient(s.URL).Status()
+	if err != nil {
+		t.Fatalf("Status() returned error: %v", err)
+	}
+	if !status.Ready || status.Message != "ready for new sessions" {
+		t.Errorf("Status() = %+v, want ready", status)
+	}
+}
+
+func TestCommandsWithoutSession(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", JSONType)
+		fmt.Fprint(w, `{"value":{}}`)
+	}))
+	defer s.Close()
+
+	// The commands that do not need a session, and the requests that other
+	// commands send without one.
+	sessionless := map[string]bool{
+		"Status":     true,
+		"NewSession": true,
+		// ExecuteCommand sends requests to any path of the remote end.
+		"ExecuteCommand": true,
+	}
+	allowed := map[string]string{
+		"DebugDump":     "GET /status",
+		"SaveDebugDump": "GET /status",
+	}
+	for _, w3c := range []bool{false, true} {
+		wd := &remoteWD{urlPrefix: s.URL, w3cCompatible: w3c}
+		v := reflect.ValueOf(WebDriver(wd))
+		typ := v.Type()
+		for i := 0; i < typ.NumMethod(); i++ {
+			m := typ.Method(i)
+			if sessionless[m.Name] {
+				continue
+			}
+			mu.Lock()
+			requests = nil
+			mu.Unlock()
+
+			method := v.Method(i)
+			args := make([]reflect.Value, method.Type().NumIn())
+			for j := range args {
+				args[j] = reflect.Zero(method.Type().In(j))
+			}
+			func() {
+				// Some commands call their arguments, which are nil.
+				defer func() { recover() }()
+				method.Call(args)
+			}()
+
+			mu.Lock()
+			for _, r := range requests {
+				if r != allowed[m.Name] {
+					t.Errorf("W3C=%t: %s without a session sent %s", w3c, m.Name, r)
+				}
+			}
+			mu.Unlock()
+		}
+	}
+
+	wd := &remoteWD{urlPrefix: s.URL}
+	if _, err := wd.CurrentURL(); err != ErrNoSession {
+		t.Errorf("CurrentURL() without a session returned error %v, want ErrNoSession", err)
+	}
+	if _, err := wd.Status(); err != nil {
+		t.Errorf("Status() without a session returned error: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package selenium
+
+// ChromeSessionInfo is the information about a Chrome session that
+// chromedriver returns in the capabilities of a new session.
+type ChromeSessionInfo struct {
+	// ChromedriverVersion is the version of chromedriver, followed by the
+	// revision it was built from, e.g. "120.0.6099.109 (3419140a...)".
+	ChromedriverVersion string
+	// UserDataDir is the directory of the profile of the browser.
+	UserDataDir string
+	// DebuggerAddress is the host and port at which the browser serves the
+	// Chrome DevTools Protocol, e.g. "localhost:38157".
+	DebuggerAddress string
+}
+
+// FirefoxSessionInfo is the information about a Firefox session that
+// geckodriver returns in the "moz:" capabilities of a new session.
+type FirefoxSessionInfo struct {
+	// GeckodriverVersion is the version of geckodriver, e.g. "0.34.0".
+	GeckodriverVersion string
+	// BuildID is the build ID of the browser, e.g. "20231211174248".
+	BuildID string
+	// Profile is the directory of the profile of the browser.
+	Profile string
+	// ProcessID is the ID of the process of the browser.
+	ProcessID int
+	// Headless is set if the browser runs headless.
+	Headless bool
+	// DebuggerAddress is the host and port at which the browser serves the
+	// Chrome DevTools Protocol, if it was requested with the
+	// "moz:debuggerAddress" capability.
+	DebuggerAddress string
+}
+
+func (wd *remoteWD) ChromeInfo() (*ChromeSessionInfo, bool) {
+	block, ok := wd.negotiated["chrome"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	info := &ChromeSessionInfo{
+		ChromedriverVersion: stringField(block, "chromedriverVersion"),
+		UserDataDir:         stringField(block, "userDataDir"),
+	}
+	// chromedriver returns the debugger address in the options, under the
+	// W3C name even when the session does not follow the W3C protocol.
+	for _, key := range []string{"goog:chromeOptions", "chromeOptions"} {
+		if opts, ok := wd.negotiated[key].(map[string]interface{}); ok && info.DebuggerAddress == "" {
+			info.DebuggerAddress = stringField(opts, "debuggerAddress")
+		}
+	}
+	return info, true
+}
+
+func (wd *remoteWD) FirefoxInfo() (*FirefoxSessionInfo, bool) {
+	caps := map[string]interface{}(wd.negotiated)
+	if _, ok := caps["moz:geckodriverVersion"]; !ok {
+		if _, ok := caps["moz:profile"]; !ok {
+			return nil, false
+		}
+	}
+	info := &FirefoxSessionInfo{
+		GeckodriverVersion: stringField(caps, "moz:geckodriverVersion"),
+		BuildID:            stringField(caps, "moz:buildID"),
+		Profile:            stringField(caps, "moz:profile"),
+		// The capability is a boolean in the request, and the address in the
+		// reply.
+		DebuggerAddress: stringField(caps, "moz:debuggerAddress"),
+	}
+	if pid, ok := caps["moz:processID"].(float64); ok {
+		info.ProcessID = int(pid)
+	}
+	info.Headless, _ = caps["moz:headless"].(bool)
+	return info, true
+}
+
+// stringField returns the string value of key in m, or the empty string if it
+// is not a string.
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
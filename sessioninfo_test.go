@@ -0,0 +1,95 @@
+package selenium
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newSessionFromFixture creates a session on a remote end that replies with
+// the new session payload captured in the file testdata/sessions/name.
+func newSessionFromFixture(t *testing.T, name string) *remoteWD {
+	t.Helper()
+	payload, err := ioutil.ReadFile(filepath.Join("testdata", "sessions", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/session" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", JSONType)
+		w.Write(payload)
+	}))
+	defer s.Close()
+	wd := &remoteWD{urlPrefix: s.URL, capabilities: Capabilities{"browserName": "chrome"}}
+	if _, err := wd.NewSession(); err != nil {
+		t.Fatalf("%s: NewSession() returned error: %v", name, err)
+	}
+	return wd
+}
+
+func TestChromeInfo(t *testing.T) {
+	for _, tc := range []struct {
+		fixture string
+		want    *ChromeSessionInfo
+	}{
+		{
+			fixture: "chromedriver-120.json",
+			want: &ChromeSessionInfo{
+				ChromedriverVersion: "120.0.6099.109 (3419140ab665596f21b385ce136419fde0924272-refs/branch-heads/6099@{#1483})",
+				UserDataDir:         "/tmp/.com.google.Chrome.dV0Ivb",
+				DebuggerAddress:     "localhost:38157",
+			},
+		},
+		{
+			fixture: "chromedriver-2.46-legacy.json",
+			want: &ChromeSessionInfo{
+				ChromedriverVersion: "2.46.628388 (4a34a70827ac54148e092aafb70504c4ea7ae926)",
+				UserDataDir:         "/tmp/.org.chromium.Chromium.Q8xKz2",
+				DebuggerAddress:     "localhost:45023",
+			},
+		},
+	} {
+		wd := newSessionFromFixture(t, tc.fixture)
+		got, ok := wd.ChromeInfo()
+		if !ok || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: ChromeInfo() = %+v, %t; want %+v, true", tc.fixture, got, ok, tc.want)
+		}
+		if got, ok := wd.FirefoxInfo(); ok {
+			t.Errorf("%s: FirefoxInfo() = %+v, true; want false", tc.fixture, got)
+		}
+	}
+}
+
+func TestFirefoxInfo(t *testing.T) {
+	const fixture = "geckodriver-0.34.json"
+	wd := newSessionFromFixture(t, fixture)
+	want := &FirefoxSessionInfo{
+		GeckodriverVersion: "0.34.0",
+		BuildID:            "20231211174248",
+		Profile:            "/tmp/rust_mozprofileVJqvyG",
+		ProcessID:          48213,
+		Headless:           true,
+		DebuggerAddress:    "127.0.0.1:9222",
+	}
+	got, ok := wd.FirefoxInfo()
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: FirefoxInfo() = %+v, %t; want %+v, true", fixture, got, ok, want)
+	}
+	if got, ok := wd.ChromeInfo(); ok {
+		t.Errorf("%s: ChromeInfo() = %+v, true; want false", fixture, got)
+	}
+
+	// Without a session, there is no information.
+	wd = &remoteWD{}
+	if _, ok := wd.FirefoxInfo(); ok {
+		t.Error("FirefoxInfo() without a session returned true")
+	}
+	if _, ok := wd.ChromeInfo(); ok {
+		t.Error("ChromeInfo() without a session returned true")
+	}
+}
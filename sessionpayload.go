@@ -0,0 +1,72 @@
+package selenium
+
+import (
+	"fmt"
+)
+
+// RemoteOption configures a WebDriver created by NewRemoteWithOptions.
+type RemoteOption func(*remoteWD) error
+
+// NewSessionPayloadHook sets a function that may add or modify top-level
+// fields of the new session request, such as the routing hints required by
+// some grids. It is called for each of the request shapes that NewSession
+// tries, just before the request is sent. The hook must leave the
+// "capabilities" and "desiredCapabilities" objects of the request in place.
+func NewSessionPayloadHook(hook func(payload map[string]interface{})) RemoteOption {
+	return func(wd *remoteWD) error {
+		if wd.sessionPayloadHook != nil {
+			return fmt.Errorf("new session payload hook already set")
+		}
+		wd.sessionPayloadHook = hook
+		return nil
+	}
+}
+
+// NewRemoteWithOptions is like NewRemote, but configures the WebDriver with
+// the options before the session is created.
+func NewRemoteWithOptions(capabilities Capabilities, urlPrefix string, opts ...RemoteOption) (WebDriver, error) {
+	if len(urlPrefix) == 0 {
+		urlPrefix = DefaultURLPrefix
+	}
+
+	wd := &remoteWD{urlPrefix: urlPrefix, capabilities: capabilities}
+	for _, opt := range opts {
+		if err := opt(wd); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := wd.NewSession(); err != nil {
+		return nil, err
+	}
+	trackSession(wd)
+	return wd, nil
+}
+
+// applySessionPayloadHook calls the new session payload hook, if any, on the
+// payload of the given attempt and checks that it kept the required objects.
+func (wd *remoteWD) applySessionPayloadHook(attempt int, payload map[string]interface{}) error {
+	if wd.sessionPayloadHook == nil {
+		return nil
+	}
+	var required []string
+	for _, key := range []string{"capabilities", "desiredCapabilities"} {
+		if _, ok := payload[key]; ok {
+			required = append(required, key)
+		}
+	}
+	wd.sessionPayloadHook(payload)
+	for _, key := range required {
+		switch payload[key].(type) {
+		case map[string]interface{}, Capabilities:
+		case nil:
+			return fmt.Errorf("new session payload hook removed the %q object from request shape %d", key, attempt)
+		default:
+			return fmt.Errorf("new session payload hook replaced the %q object of request shape %d with %T", key, attempt, payload[key])
+		}
+	}
+	return nil
+}
+
+func (wd *remoteWD) SessionRequestPayload() (attempt int, payload []byte) {
+	return wd.sessionAttempt, wd.sessionPayload
+}
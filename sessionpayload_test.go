@@ -0,0 +1,75 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewSessionPayloadHook(t *testing.T) {
+	var bodies []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		fmt.Fprint(w, `{"value":{"sessionId":"s1","capabilities":{}}}`)
+	}))
+	defer s.Close()
+
+	var calls int
+	wd, err := NewRemoteWithOptions(Capabilities{"browserName": "firefox"}, s.URL,
+		NewSessionPayloadHook(func(payload map[string]interface{}) {
+			calls++
+			payload["tenant"] = "acme"
+		}))
+	if err != nil {
+		t.Fatalf("NewRemoteWithOptions() returned error: %v", err)
+	}
+	if calls != 1 || len(bodies) != 1 {
+		t.Fatalf("the hook was called %d times for %d requests, want 1 and 1", calls, len(bodies))
+	}
+	var sent map[string]interface{}
+	json.Unmarshal([]byte(bodies[0]), &sent)
+	if sent["tenant"] != "acme" || sent["capabilities"] == nil {
+		t.Errorf("NewRemoteWithOptions() sent %s, want the capabilities and the tenant field added by the hook", bodies[0])
+	}
+	attempt, payload := wd.SessionRequestPayload()
+	if attempt != 0 || string(payload) != bodies[0] {
+		t.Errorf("SessionRequestPayload() = %d, %s; want 0, %s", attempt, payload, bodies[0])
+	}
+}
+
+func TestNewSessionPayloadHookValidation(t *testing.T) {
+	var requests int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}))
+	defer s.Close()
+
+	for _, tc := range []struct {
+		name string
+		hook func(map[string]interface{})
+		want string
+	}{
+		{"Removed", func(p map[string]interface{}) { delete(p, "capabilities") }, `removed the "capabilities" object`},
+		{"Replaced", func(p map[string]interface{}) { p["desiredCapabilities"] = "firefox" }, `replaced the "desiredCapabilities" object`},
+	} {
+		_, err := NewRemoteWithOptions(nil, s.URL, NewSessionPayloadHook(tc.hook))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: NewRemoteWithOptions() returned error %v, want one containing %q", tc.name, err, tc.want)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("%d requests were sent with invalid payloads, want 0", requests)
+	}
+
+	hook := NewSessionPayloadHook(func(map[string]interface{}) {})
+	if _, err := NewRemoteWithOptions(nil, s.URL, hook, hook); err == nil {
+		t.Errorf("NewRemoteWithOptions() with two hooks returned nil error")
+	}
+}
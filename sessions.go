@@ -0,0 +1,115 @@
+package selenium
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sessionRegistry tracks the live sessions created by NewRemote while
+// tracking is enabled.
+var sessionRegistry = struct {
+	sync.Mutex
+	enabled bool
+	drivers map[*remoteWD]bool
+}{drivers: make(map[*remoteWD]bool)}
+
+// TrackSessions enables or disables tracking of the sessions created by
+// NewRemote, so that they can be ended with QuitAllTracked. Sessions are no
+// longer tracked once they have been ended with Quit. Disabling tracking
+// forgets all tracked sessions.
+func TrackSessions(enabled bool) {
+	sessionRegistry.Lock()
+	defer sessionRegistry.Unlock()
+	sessionRegistry.enabled = enabled
+	if !enabled {
+		sessionRegistry.drivers = make(map[*remoteWD]bool)
+	}
+}
+
+func trackSession(wd *remoteWD) {
+	sessionRegistry.Lock()
+	defer sessionRegistry.Unlock()
+	if sessionRegistry.enabled {
+		sessionRegistry.drivers[wd] = true
+	}
+}
+
+func untrackSession(wd *remoteWD) {
+	sessionRegistry.Lock()
+	defer sessionRegistry.Unlock()
+	delete(sessionRegistry.drivers, wd)
+}
+
+// QuitAllTracked ends all tracked sessions concurrently, waiting at most
+// timeout for them to end. See QuitAllTrackedContext for the error it
+// returns.
+func QuitAllTracked(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return QuitAllTrackedContext(ctx)
+}
+
+// QuitAllTrackedContext ends all tracked sessions concurrently with
+// QuitContext, giving up on the sessions that have not ended when ctx is
+// done. If any session could not be ended or did not end in time, it returns
+// a *MultiError with the error of each such session, which wraps ctx.Err()
+// for those that did not end in time.
+func QuitAllTrackedContext(ctx context.Context) error {
+	sessionRegistry.Lock()
+	var drivers []*remoteWD
+	for wd := range sessionRegistry.drivers {
+		drivers = append(drivers, wd)
+	}
+	sessionRegistry.Unlock()
+
+	type result struct {
+		id  string
+		err error
+	}
+	results := make(chan result, len(drivers))
+	for _, wd := range drivers {
+		go func(wd *remoteWD) {
+			id := wd.id
+			results <- result{id, wd.QuitContext(ctx)}
+		}(wd)
+	}
+
+	// QuitContext returns once ctx is done, so this does not wait past the
+	// deadline.
+	failures := new(MultiError)
+	for range drivers {
+		r := <-results
+		failures.Append(fmt.Sprintf("session %q", r.id), r.err)
+	}
+	return failures.ErrorOrNil()
+}
+
+// HandlePanics runs the tests with m.Run and then ends all tracked sessions,
+// also if the goroutine calling it panics, e.g. in TestMain. It returns the
+// exit code of m.Run. It is intended to be used as
+//
+//	func TestMain(m *testing.M) {
+//		selenium.TrackSessions(true)
+//		os.Exit(selenium.HandlePanics(m))
+//	}
+//
+// Note that a panic within a test function terminates the test binary from
+// the test's goroutine; sessions created by such a test should additionally
+// be ended in a function registered with t.Cleanup, which runs before the
+// panic propagates.
+func HandlePanics(m *testing.M) int {
+	defer func() {
+		if err := QuitAllTracked(HandlePanicsTimeout); err != nil {
+			log.Printf("selenium: quitting the tracked sessions: %v", err)
+		}
+	}()
+	return m.Run()
+}
+
+// HandlePanicsTimeout is the timeout with which HandlePanics calls
+// QuitAllTracked.
+var HandlePanicsTimeout = time.Minute
@@ -0,0 +1,207 @@
+package selenium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sessionServer creates and deletes W3C sessions.
+type sessionServer struct {
+	mu   sync.Mutex
+	next int
+	live map[string]bool
+	// hang causes deleting sessions to block until it is closed.
+	hang chan struct{}
+}
+
+func (s *sessionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", JSONType)
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/session":
+		s.mu.Lock()
+		s.next++
+		id := fmt.Sprintf("s%d", s.next)
+		s.live[id] = true
+		s.mu.Unlock()
+		fmt.Fprintf(w, `{"value":{"sessionId":%q,"capabilities":{}}}`, id)
+	case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/session/"):
+		if s.hang != nil {
+			<-s.hang
+		}
+		s.mu.Lock()
+		delete(s.live, strings.TrimPrefix(r.URL.Path, "/session/"))
+		s.mu.Unlock()
+		fmt.Fprint(w, `{"value":null}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *sessionServer) liveSessions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.live)
+}
+
+func trackedSessions() int {
+	sessionRegistry.Lock()
+	defer sessionRegistry.Unlock()
+	return len(sessionRegistry.drivers)
+}
+
+func TestQuitAllTracked(t *testing.T) {
+	ss := &sessionServer{live: make(map[string]bool)}
+	s := httptest.NewServer(ss)
+	defer s.Close()
+
+	TrackSessions(true)
+	defer TrackSessions(false)
+
+	const n = 20
+	var wg sync.WaitGroup
+	drivers := make(chan WebDriver, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wd, err := NewRemote(nil, s.URL)
+			if err != nil {
+				t.Errorf("NewRemote() returned error: %v", err)
+				return
+			}
+			drivers <- wd
+		}()
+	}
+	wg.Wait()
+	close(drivers)
+
+	// Sessions that were quit explicitly are no longer tracked.
+	quit := 0
+	for wd := range drivers {
+		if quit == 5 {
+			break
+		}
+		if err := wd.Quit(); err != nil {
+			t.Fatalf("wd.Quit() returned error: %v", err)
+		}
+		quit++
+	}
+	if got, want := trackedSessions(), n-quit; got != want {
+		t.Errorf("%d sessions are tracked, want %d", got, want)
+	}
+
+	if err := QuitAllTracked(10 * time.Second); err != nil {
+		t.Fatalf("QuitAllTracked() returned error: %v", err)
+	}
+	if got := ss.liveSessions(); got != 0 {
+		t.Errorf("%d sessions are still live after QuitAllTracked()", got)
+	}
+	if got := trackedSessions(); got != 0 {
+		t.Errorf("%d sessions are still tracked after QuitAllTracked()", got)
+	}
+}
+
+func TestQuitAllTrackedTimeout(t *testing.T) {
+	ss := &sessionServer{live: make(map[string]bool), hang: make(chan struct{})}
+	s := httptest.NewServer(ss)
+	defer s.Close()
+	defer close(ss.hang)
+
+	TrackSessions(true)
+	defer TrackSessions(false)
+
+	if _, err := NewRemote(nil, s.URL); err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	err := QuitAllTracked(50 * time.Millisecond)
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(multi.Items) != 1 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("QuitAllTracked() = %v, want a *MultiError with one timeout error", err)
+	}
+}
+
+func TestUntrackedSessions(t *testing.T) {
+	ss := &sessionServer{live: make(map[string]bool)}
+	s := httptest.NewServer(ss)
+	defer s.Close()
+
+	wd, err := NewRemote(nil, s.URL)
+	if err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	defer wd.Quit()
+	if got := trackedSessions(); got != 0 {
+		t.Errorf("%d sessions are tracked with tracking disabled, want 0", got)
+	}
+}
+
+func TestQuitContext(t *testing.T) {
+	ss := &sessionServer{live: make(map[string]bool), hang: make(chan struct{})}
+	s := httptest.NewServer(ss)
+	defer s.Close()
+	defer close(ss.hang)
+
+	TrackSessions(true)
+	defer TrackSessions(false)
+
+	wd, err := NewRemote(nil, s.URL)
+	if err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := wd.QuitContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("QuitContext() returned error %v, want a deadline error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("QuitContext() returned after %v, want it to give up at the deadline", elapsed)
+	}
+	if got := trackedSessions(); got != 0 {
+		t.Errorf("%d sessions are tracked after QuitContext() gave up, want 0", got)
+	}
+	if _, err := wd.Title(); err != ErrSessionClosed {
+		t.Errorf("Title() after QuitContext() returned error %v, want ErrSessionClosed", err)
+	}
+	if err := wd.Quit(); err != nil {
+		t.Errorf("Quit() after QuitContext() returned error: %v", err)
+	}
+}
+
+func TestQuitAllTrackedContext(t *testing.T) {
+	ss := &sessionServer{live: make(map[string]bool), hang: make(chan struct{})}
+	s := httptest.NewServer(ss)
+	defer s.Close()
+	defer close(ss.hang)
+
+	TrackSessions(true)
+	defer TrackSessions(false)
+
+	var drivers []WebDriver
+	for i := 0; i < 3; i++ {
+		wd, err := NewRemote(nil, s.URL)
+		if err != nil {
+			t.Fatalf("NewRemote() returned error: %v", err)
+		}
+		drivers = append(drivers, wd)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := QuitAllTrackedContext(ctx)
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(multi.Items) != 3 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("QuitAllTrackedContext() = %v, want a *MultiError with a timeout error for each of the three sessions", err)
+	}
+	for _, wd := range drivers {
+		if _, err := wd.CurrentURL(); err != ErrSessionClosed {
+			t.Errorf("CurrentURL() after QuitAllTrackedContext() returned error %v, want ErrSessionClosed", err)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+(function(languages) {
+	languages = Object.freeze(languages);
+	Object.defineProperty(Navigator.prototype, 'languages', {
+		get: function() { return languages; },
+		configurable: true
+	});
+	Object.defineProperty(Navigator.prototype, 'language', {
+		get: function() { return languages[0]; },
+		configurable: true
+	});
+})
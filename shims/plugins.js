@@ -0,0 +1,39 @@
+(function() {
+	if (navigator.plugins && navigator.plugins.length > 0) {
+		return;
+	}
+	function list(items, key) {
+		items.item = function(i) { return this[i] || null; };
+		items.namedItem = function(name) {
+			for (var i = 0; i < this.length; i++) {
+				if (this[i][key] === name) {
+					return this[i];
+				}
+			}
+			return null;
+		};
+		return items;
+	}
+	var mimeType = {type: 'application/pdf', suffixes: 'pdf', description: 'Portable Document Format'};
+	var names = ['PDF Viewer', 'Chrome PDF Viewer', 'Chromium PDF Viewer'];
+	var plugins = [];
+	for (var i = 0; i < names.length; i++) {
+		var plugin = list([mimeType], 'type');
+		plugin.name = names[i];
+		plugin.filename = 'internal-pdf-viewer';
+		plugin.description = 'Portable Document Format';
+		plugins.push(plugin);
+	}
+	mimeType.enabledPlugin = plugins[0];
+	plugins = list(plugins, 'name');
+	plugins.refresh = function() {};
+	var mimeTypes = list([mimeType], 'type');
+	Object.defineProperty(Navigator.prototype, 'plugins', {
+		get: function() { return plugins; },
+		configurable: true
+	});
+	Object.defineProperty(Navigator.prototype, 'mimeTypes', {
+		get: function() { return mimeTypes; },
+		configurable: true
+	});
+})();
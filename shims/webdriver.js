@@ -0,0 +1,6 @@
+(function() {
+	Object.defineProperty(Navigator.prototype, 'webdriver', {
+		get: function() { return undefined; },
+		configurable: true
+	});
+})();
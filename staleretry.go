@@ -0,0 +1,136 @@
+package selenium
+
+import "strings"
+
+// RetryStale calls fn until it returns an error that does not report a stale
+// element reference, at most attempts times, and returns the last error. fn
+// is expected to find the elements it uses again on each call:
+//
+//	err := selenium.RetryStale(func() error {
+//		row, err := wd.FindElement(selenium.ByCSSSelector, "#orders tr")
+//		if err != nil {
+//			return err
+//		}
+//		return row.Click()
+//	}, 3)
+func RetryStale(fn func() error, attempts int) error {
+	err := fn()
+	for i := 1; i < attempts && isStaleElement(err); i++ {
+		err = fn()
+	}
+	return err
+}
+
+// DefaultStaleRetryCommands are the methods of WebElement that are retried by
+// WebDriver.SetStaleRetry if no commands are given: the methods that read the
+// state of the element, and Click and SendKeys.
+var DefaultStaleRetryCommands = []string{
+	"Click", "SendKeys", "Clear",
+	"FindElement", "FindElements",
+	"TagName", "Text", "IsSelected", "IsEnabled", "IsDisplayed",
+	"GetAttribute", "GetProperty", "CSSProperty",
+	"Rect", "Location", "LocationInView", "Size",
+	"ComputedRole", "ComputedLabel", "Screenshot",
+}
+
+// staleRetryMethods maps the first component of the URL suffix of element
+// commands to the WebElement method that sends them.
+var staleRetryMethods = map[string]string{
+	"click":            "Click",
+	"value":            "SendKeys",
+	"clear":            "Clear",
+	"element":          "FindElement",
+	"elements":         "FindElements",
+	"name":             "TagName",
+	"text":             "Text",
+	"selected":         "IsSelected",
+	"enabled":          "IsEnabled",
+	"displayed":        "IsDisplayed",
+	"attribute":        "GetAttribute",
+	"property":         "GetProperty",
+	"css":              "CSSProperty",
+	"rect":             "Rect",
+	"location":         "Location",
+	"location_in_view": "LocationInView",
+	"size":             "Size",
+	"computedrole":     "ComputedRole",
+	"computedlabel":    "ComputedLabel",
+	"screenshot":       "Screenshot",
+}
+
+func (wd *remoteWD) SetStaleRetry(attempts int, commands ...string) {
+	if attempts <= 1 {
+		wd.staleRetry = 0
+		wd.staleRetryCommands = nil
+		return
+	}
+	if len(commands) == 0 {
+		commands = DefaultStaleRetryCommands
+	}
+	wd.staleRetry = attempts
+	wd.staleRetryCommands = make(map[string]bool)
+	for _, c := range commands {
+		// Submit may have submitted the form before the element became
+		// stale, so it is never retried.
+		if c != "Submit" {
+			wd.staleRetryCommands[c] = true
+		}
+	}
+}
+
+// retriesStale reports whether the element command with the URL suffix, e.g.
+// "/attribute/value", is retried when the element is stale.
+func (elem *remoteWE) retriesStale(suffix string) bool {
+	wd := elem.parent
+	if wd.staleRetry == 0 || !elem.refindable {
+		return false
+	}
+	op := strings.SplitN(strings.TrimPrefix(suffix, "/"), "/", 2)[0]
+	return wd.staleRetryCommands[staleRetryMethods[op]]
+}
+
+// refind finds the element again with the locator it was found with, within
+// the element it was found within, and makes elem reference the element found.
+func (elem *remoteWE) refind() error {
+	var found WebElement
+	var err error
+	if elem.within != nil {
+		found, err = elem.within.FindElement(elem.locator.By, elem.locator.Value)
+	} else {
+		found, err = elem.parent.FindElement(elem.locator.By, elem.locator.Value)
+	}
+	if err != nil {
+		return err
+	}
+	f, ok := found.(*remoteWE)
+	if !ok || f.id == "" {
+		return ErrInvalidElement
+	}
+	elem.id = f.id
+	elem.InvalidateCache()
+	return nil
+}
+
+func (elem *remoteWE) IsStale() (bool, error) {
+	if err := elem.checkID(); err != nil {
+		return false, err
+	}
+	// The command is sent directly rather than with elem.execute, so that it
+	// is not retried if SetStaleRetry is enabled.
+	wd := elem.parent
+	_, err := wd.execute("GET", wd.requestURL("/session/%s/element/%s/enabled", wd.id, elem.id), nil)
+	// Since the element was returned by the remote end, an unknown element
+	// is one that it has discarded, e.g. geckodriver after a navigation.
+	if isStaleElement(err) || isNoSuchElement(err) {
+		elem.InvalidateCache()
+		return true, nil
+	}
+	return false, elem.wrapError("enabled", err)
+}
+
+// setRefindable marks elem, returned by FindElement, as refindable.
+func setRefindable(elem WebElement) {
+	if e, ok := elem.(*remoteWE); ok {
+		e.refindable = true
+	}
+}
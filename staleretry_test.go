@@ -0,0 +1,184 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var errStale = &Error{Err: "stale element reference", Message: "gone"}
+
+func TestRetryStale(t *testing.T) {
+	errOther := errors.New("invalid selector")
+	for _, tc := range []struct {
+		attempts int
+		errs     []error
+		want     error
+		calls    int
+	}{
+		{attempts: 3, errs: []error{errStale, nil}, want: nil, calls: 2},
+		{attempts: 3, errs: []error{errStale, errStale, errStale, nil}, want: errStale, calls: 3},
+		{attempts: 3, errs: []error{errOther}, want: errOther, calls: 1},
+		{attempts: 0, errs: []error{errStale, nil}, want: errStale, calls: 1},
+	} {
+		calls := 0
+		err := RetryStale(func() error {
+			calls++
+			return tc.errs[calls-1]
+		}, tc.attempts)
+		if err != tc.want || calls != tc.calls {
+			t.Errorf("RetryStale(%v, %d) = %v after %d calls, want %v after %d", tc.errs, tc.attempts, err, calls, tc.want, tc.calls)
+		}
+	}
+}
+
+// staleServer serves elements that become stale after the first command sent
+// to them. Each search returns a new element.
+type staleServer struct {
+	found    int
+	stale    map[string]bool
+	requests []string
+}
+
+func (s *staleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", JSONType)
+	path := strings.TrimPrefix(r.URL.Path, "/session/123")
+	s.requests = append(s.requests, r.Method+" "+path)
+	switch {
+	case path == "/element":
+		s.found++
+		fmt.Fprintf(w, `{"value":{%q:"e%d"}}`, webElementIdentifier, s.found)
+		return
+	case path == "/elements":
+		s.found++
+		fmt.Fprintf(w, `{"value":[{%q:"e%d"}]}`, webElementIdentifier, s.found)
+		return
+	}
+	id := strings.SplitN(strings.TrimPrefix(path, "/element/"), "/", 2)[0]
+	if s.stale[id] {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"value":{"error":"stale element reference","message":"gone","stacktrace":""}}`)
+		return
+	}
+	s.stale[id] = true
+	if strings.HasSuffix(path, "/text") {
+		fmt.Fprintf(w, `{"value":"text of %s"}`, id)
+		return
+	}
+	fmt.Fprint(w, `{"value":null}`)
+}
+
+func TestSetStaleRetry(t *testing.T) {
+	s := &staleServer{stale: map[string]bool{}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	wd := &remoteWD{id: "123", urlPrefix: server.URL, w3cCompatible: true}
+	wd.SetStaleRetry(3)
+
+	elem, err := wd.FindElement(ByCSSSelector, ".row")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	if err := elem.Click(); err != nil {
+		t.Fatalf("Click() returned error: %v", err)
+	}
+	// The element is now stale, and found again as e2.
+	if text, err := elem.Text(); err != nil || text != "text of e2" {
+		t.Errorf("Text() on a stale element = %q, %v; want %q, nil", text, err, "text of e2")
+	}
+	// Only legacy drivers have an element submit command.
+	wd.w3cCompatible = false
+	if err := elem.Submit(); !isStaleElement(err) {
+		t.Errorf("Submit() on a stale element returned %v, want a stale element error", err)
+	}
+	wd.w3cCompatible = true
+
+	elems, err := wd.FindElements(ByCSSSelector, ".row")
+	if err != nil {
+		t.Fatalf("FindElements() returned error: %v", err)
+	}
+	elems[0].Click()
+	if err := elems[0].Click(); !isStaleElement(err) {
+		t.Errorf("Click() on a stale element returned by FindElements returned %v, want a stale element error", err)
+	}
+
+	wd.SetStaleRetry(3, "Text")
+	elem, err = wd.FindElement(ByCSSSelector, ".row")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	elem.Click()
+	if err := elem.Click(); !isStaleElement(err) {
+		t.Errorf("Click() on a stale element with only Text retried returned %v, want a stale element error", err)
+	}
+
+	wd.SetStaleRetry(0)
+	if _, err := elem.Text(); !isStaleElement(err) {
+		t.Errorf("Text() on a stale element with retries disabled returned %v, want a stale element error", err)
+	}
+}
+
+func TestSetStaleRetryGivesUp(t *testing.T) {
+	// Every element is stale, so that every attempt fails.
+	s := &staleServer{stale: map[string]bool{"e1": true, "e2": true, "e3": true, "e4": true}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	wd := &remoteWD{id: "123", urlPrefix: server.URL, w3cCompatible: true}
+	wd.SetStaleRetry(3)
+
+	elem, err := wd.FindElement(ByCSSSelector, ".row")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	s.requests = nil
+	if err := elem.Click(); !isStaleElement(err) {
+		t.Errorf("Click() returned %v, want a stale element error", err)
+	}
+	want := []string{
+		"POST /element/e1/click",
+		"POST /element", "POST /element/e2/click",
+		"POST /element", "POST /element/e3/click",
+	}
+	if fmt.Sprint(s.requests) != fmt.Sprint(want) {
+		t.Errorf("requests = %v, want %v", s.requests, want)
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		status int
+		reply  string
+		want   bool
+		err    bool
+	}{
+		{"Attached", http.StatusOK, `{"value":true}`, false, false},
+		{"W3CStale", http.StatusNotFound, `{"value":{"error":"stale element reference","message":"detached"}}`, true, false},
+		{"W3CUnknownElement", http.StatusNotFound, `{"value":{"error":"no such element","message":"unknown"}}`, true, false},
+		{"LegacyStale", http.StatusOK, `{"status":10,"value":{"message":"Element is no longer attached"}}`, true, false},
+		{"OtherError", http.StatusNotFound, `{"value":{"error":"invalid session id","message":"gone"}}`, false, true},
+	} {
+		var requests []string
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			w.Header().Set("Content-Type", JSONType)
+			w.WriteHeader(tc.status)
+			fmt.Fprint(w, tc.reply)
+		}))
+		wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+		// Stale retries must not hide the staleness.
+		wd.SetStaleRetry(3)
+		elem := &remoteWE{parent: wd, id: "e1", locator: Locator{ByCSSSelector, ".row"}, refindable: true}
+		stale, err := elem.IsStale()
+		if stale != tc.want || (err != nil) != tc.err {
+			t.Errorf("%s: IsStale() = %t, %v; want %t, error %t", tc.name, stale, err, tc.want, tc.err)
+		}
+		if want := []string{"GET /session/123/element/e1/enabled"}; fmt.Sprint(requests) != fmt.Sprint(want) {
+			t.Errorf("%s: requests = %v, want %v", tc.name, requests, want)
+		}
+		s.Close()
+	}
+}
@@ -0,0 +1,66 @@
+package selenium
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SessionStats counts the work that a session has done, e.g. to decide when
+// to replace a browser whose memory use grows with the pages it loads.
+type SessionStats struct {
+	// Navigations counts the pages loaded with Get, Back, Forward and
+	// Refresh.
+	Navigations uint64
+	// ElementsFound counts the elements returned by FindElement and
+	// FindElements, on the WebDriver and on elements.
+	ElementsFound uint64
+	// ScriptsExecuted counts the scripts executed.
+	ScriptsExecuted uint64
+	// BytesReceived counts the bytes of the replies of the remote end.
+	BytesReceived uint64
+	// Age is the time since the session was created, or switched to.
+	Age time.Duration
+}
+
+// statCounter is a counter of SessionStats.
+type statCounter int
+
+const (
+	statNavigations statCounter = iota
+	statElementsFound
+	statScriptsExecuted
+	statBytesReceived
+	numStatCounters
+)
+
+// sessionCounters holds the counters of a session. They are updated
+// atomically, so that SessionStats can be called concurrently with commands.
+type sessionCounters struct {
+	values [numStatCounters]uint64
+	start  time.Time
+}
+
+func newSessionCounters() *sessionCounters {
+	return &sessionCounters{start: time.Now()}
+}
+
+// count adds n to the counter c of the current session.
+func (wd *remoteWD) count(c statCounter, n int) {
+	if counters := wd.counters.Load(); counters != nil && n > 0 {
+		atomic.AddUint64(&counters.values[c], uint64(n))
+	}
+}
+
+func (wd *remoteWD) SessionStats() SessionStats {
+	counters := wd.counters.Load()
+	if counters == nil {
+		return SessionStats{}
+	}
+	return SessionStats{
+		Navigations:     atomic.LoadUint64(&counters.values[statNavigations]),
+		ElementsFound:   atomic.LoadUint64(&counters.values[statElementsFound]),
+		ScriptsExecuted: atomic.LoadUint64(&counters.values[statScriptsExecuted]),
+		BytesReceived:   atomic.LoadUint64(&counters.values[statBytesReceived]),
+		Age:             time.Since(counters.start),
+	}
+}
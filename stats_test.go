@@ -0,0 +1,96 @@
+package selenium
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSessionStats(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch r.URL.Path {
+		case "/session":
+			fmt.Fprint(w, `{"value":{"sessionId":"123","capabilities":{}}}`)
+		case "/session/123/url", "/session/123/back", "/session/123/refresh":
+			fmt.Fprint(w, `{"value":null}`)
+		case "/session/123/element":
+			fmt.Fprintf(w, `{"value":{"%s":"e1"}}`, webElementIdentifier)
+		case "/session/123/elements", "/session/123/element/e1/elements":
+			fmt.Fprintf(w, `{"value":[{"%[1]s":"e2"},{"%[1]s":"e3"}]}`, webElementIdentifier)
+		case "/session/123/execute/sync":
+			fmt.Fprint(w, `{"value":42}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"unknown command","message":"not implemented"}}`)
+		}
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{urlPrefix: s.URL, capabilities: Capabilities{}}
+	if got := wd.SessionStats(); got != (SessionStats{}) {
+		t.Errorf("SessionStats() without a session = %+v, want zero", got)
+	}
+	if _, err := wd.NewSession(); err != nil {
+		t.Fatalf("NewSession() returned error: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			wd.SessionStats()
+		}
+	}()
+	for _, f := range []func() error{
+		func() error { return wd.Get("http://example.com") },
+		wd.Back,
+		wd.Refresh,
+		wd.Forward, // Unknown, so not counted.
+		func() error {
+			elem, err := wd.FindElement(ByID, "list")
+			if err != nil {
+				return err
+			}
+			_, err = elem.FindElements(ByTagName, "li")
+			return err
+		},
+		func() error {
+			_, err := wd.FindElements(ByTagName, "li")
+			return err
+		},
+		func() error {
+			_, err := wd.ExecuteScript("return 42", nil)
+			return err
+		},
+	} {
+		f()
+	}
+	<-done
+
+	stats := wd.SessionStats()
+	if stats.Navigations != 3 || stats.ElementsFound != 5 || stats.ScriptsExecuted != 1 {
+		t.Errorf("SessionStats() = %+v, want 3 navigations, 5 elements found and 1 script executed", stats)
+	}
+	if stats.BytesReceived < 100 || stats.Age <= 0 {
+		t.Errorf("SessionStats() = %+v, want some bytes received and a positive age", stats)
+	}
+
+	// The counters are replaced while SessionStats may be called; run with
+	// -race.
+	done = make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			wd.SessionStats()
+		}
+	}()
+	if err := wd.SwitchSession("123"); err != nil {
+		t.Fatalf("SwitchSession() returned error: %v", err)
+	}
+	<-done
+	if got := wd.SessionStats(); got.Navigations != 0 || got.BytesReceived != 0 {
+		t.Errorf("SessionStats() after SwitchSession() = %+v, want the counters reset", got)
+	}
+}
@@ -0,0 +1,120 @@
+package selenium
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tebeka/selenium/chrome"
+	"github.com/tebeka/selenium/firefox"
+)
+
+// StealthOptions reduces the signals by which a page can tell that the
+// browser is automated, for testing sites that run bot detection in a
+// staging environment that you control, where the detection cannot be turned
+// off for the test run. It is not meant to, and does not reliably, defeat
+// bot detection on sites that you do not operate.
+//
+// The options are applied in two parts: ApplyChrome or ApplyFirefox adjust
+// the capabilities before the session is created, and WebDriver.ApplyStealth
+// installs the JavaScript shims once it is.
+type StealthOptions struct {
+	// UserAgent, if set, replaces the user agent of the browser, e.g. to
+	// remove the "HeadlessChrome" token.
+	UserAgent string
+	// Languages, if set, are reported by navigator.languages, most preferred
+	// first. It defaults to ["en-US", "en"].
+	Languages []string
+}
+
+func (o *StealthOptions) languages() []string {
+	if len(o.Languages) == 0 {
+		return []string{"en-US", "en"}
+	}
+	return o.Languages
+}
+
+// ApplyChrome adds the options to the Chrome capabilities. It stops
+// ChromeDriver from passing --enable-automation, which shows the "controlled
+// by automated test software" bar and sets navigator.webdriver.
+func (o *StealthOptions) ApplyChrome(c *chrome.Capabilities) {
+	c.ExcludeSwitches = append(c.ExcludeSwitches, "enable-automation")
+	if o.UserAgent != "" {
+		c.Args = append(c.Args, "--user-agent="+o.UserAgent)
+	}
+	c.Args = append(c.Args, "--lang="+o.languages()[0])
+}
+
+// ApplyFirefox adds the options to the Firefox capabilities, as preferences.
+func (o *StealthOptions) ApplyFirefox(c *firefox.Capabilities) {
+	if c.Prefs == nil {
+		c.Prefs = make(map[string]interface{})
+	}
+	c.Prefs["dom.webdriver.enabled"] = false
+	if o.UserAgent != "" {
+		c.Prefs["general.useragent.override"] = o.UserAgent
+	}
+	c.Prefs["intl.accept_languages"] = strings.Join(o.languages(), ",")
+}
+
+// stealthWebdriverShim hides navigator.webdriver, as it is in a browser that
+// is not automated.
+//
+//go:embed shims/webdriver.js
+var stealthWebdriverShim string
+
+// stealthPluginsShim populates navigator.plugins and navigator.mimeTypes
+// with the built-in PDF viewer if they are empty, as they are in some
+// headless browsers.
+//
+//go:embed shims/plugins.js
+var stealthPluginsShim string
+
+// stealthLanguagesShim is a function that sets navigator.languages to the
+// array passed as argument, and navigator.language to its first element.
+//
+//go:embed shims/languages.js
+var stealthLanguagesShim string
+
+// scripts returns the shims to run before the scripts of each page.
+func (o *StealthOptions) scripts() ([]string, error) {
+	languages, err := json.Marshal(o.languages())
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		stealthWebdriverShim,
+		stealthPluginsShim,
+		fmt.Sprintf("%s(%s);", strings.TrimSpace(stealthLanguagesShim), languages),
+	}, nil
+}
+
+func (wd *remoteWD) AddInitScript(script string) error {
+	if !wd.isChromium() {
+		return fmt.Errorf("init scripts are not supported by this driver (%s): only Chromium-based browsers implement them", wd.browserName())
+	}
+	_, err := wd.executeCDP("Page.addScriptToEvaluateOnNewDocument", map[string]interface{}{"source": script})
+	return err
+}
+
+func (wd *remoteWD) ApplyStealth(opts *StealthOptions) error {
+	if opts == nil {
+		opts = new(StealthOptions)
+	}
+	if !wd.isChromium() {
+		// Firefox needs no shims: ApplyFirefox covers navigator.webdriver and
+		// the languages, and its plugins are never empty.
+		return nil
+	}
+	scripts, err := opts.scripts()
+	if err != nil {
+		return err
+	}
+	for _, script := range scripts {
+		if err := wd.AddInitScript(script); err != nil {
+			return fmt.Errorf("installing the stealth shims: %v", err)
+		}
+	}
+	return nil
+}
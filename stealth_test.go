@@ -0,0 +1,143 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tebeka/selenium/chrome"
+	"github.com/tebeka/selenium/firefox"
+)
+
+func TestStealthCapabilities(t *testing.T) {
+	opts := &StealthOptions{UserAgent: "Staging/1.0", Languages: []string{"de-DE", "de"}}
+
+	c := chrome.Capabilities{Args: []string{"--no-sandbox"}}
+	opts.ApplyChrome(&c)
+	if want := []string{"enable-automation"}; !reflect.DeepEqual(c.ExcludeSwitches, want) {
+		t.Errorf("ApplyChrome() set ExcludeSwitches to %v, want %v", c.ExcludeSwitches, want)
+	}
+	wantArgs := []string{"--no-sandbox", "--user-agent=Staging/1.0", "--lang=de-DE"}
+	if !reflect.DeepEqual(c.Args, wantArgs) {
+		t.Errorf("ApplyChrome() set Args to %v, want %v", c.Args, wantArgs)
+	}
+
+	var f firefox.Capabilities
+	opts.ApplyFirefox(&f)
+	wantPrefs := map[string]interface{}{
+		"dom.webdriver.enabled":      false,
+		"general.useragent.override": "Staging/1.0",
+		"intl.accept_languages":      "de-DE,de",
+	}
+	if !reflect.DeepEqual(f.Prefs, wantPrefs) {
+		t.Errorf("ApplyFirefox() set Prefs to %v, want %v", f.Prefs, wantPrefs)
+	}
+}
+
+func TestApplyStealth(t *testing.T) {
+	var sources []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		var params struct {
+			Cmd    string
+			Params struct{ Source string }
+		}
+		json.Unmarshal(body, &params)
+		if r.URL.Path != "/session/123/goog/cdp/execute" || params.Cmd != "Page.addScriptToEvaluateOnNewDocument" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"unknown command","message":"not implemented"}}`)
+			return
+		}
+		sources = append(sources, params.Params.Source)
+		fmt.Fprintf(w, `{"value":{"identifier":"%d"}}`, len(sources))
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true,
+		negotiated: Capabilities{"browserName": "chrome"}}
+	if err := wd.ApplyStealth(&StealthOptions{Languages: []string{"ja"}}); err != nil {
+		t.Fatalf("ApplyStealth() returned error: %v", err)
+	}
+	if len(sources) != 3 {
+		t.Fatalf("ApplyStealth() installed %d scripts, want 3", len(sources))
+	}
+	for i, want := range []string{"'webdriver'", "'plugins'", `})(["ja"]);`} {
+		if !strings.Contains(sources[i], want) {
+			t.Errorf("script %d does not contain %s:\n%s", i, want, sources[i])
+		}
+	}
+
+	wd.negotiated = Capabilities{"browserName": "firefox"}
+	if err := wd.AddInitScript("1"); err == nil {
+		t.Errorf("AddInitScript() on Firefox returned no error")
+	}
+	if err := wd.ApplyStealth(nil); err != nil || len(sources) != 3 {
+		t.Errorf("ApplyStealth() on Firefox returned error %v and installed %d scripts, want nil and none", err, len(sources)-3)
+	}
+}
+
+// stealthShimsEnvironment is a minimal navigator, as seen by the shims in an
+// automated headless browser, before which they run.
+const stealthShimsEnvironment = `
+function Navigator() {}
+[['webdriver', true], ['plugins', []], ['mimeTypes', []], ['languages', ['en-US']], ['language', 'en-US']].forEach(function(p) {
+	Object.defineProperty(Navigator.prototype, p[0], {get: function() { return p[1]; }, configurable: true});
+});
+var navigator = new Navigator();
+`
+
+// stealthShimsReport reports the navigator properties that the shims
+// change.
+const stealthShimsReport = `
+console.log(JSON.stringify({
+	webdriver: navigator.webdriver === undefined ? 'undefined' : String(navigator.webdriver),
+	plugins: navigator.plugins.length,
+	pdfViewer: (navigator.plugins.namedItem('PDF Viewer') || {}).filename,
+	mimeType: navigator.mimeTypes.item(0).type,
+	languages: navigator.languages,
+	language: navigator.language
+}));
+`
+
+func TestStealthShims(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("Skipping the stealth shims test: node is not installed")
+	}
+	scripts, err := (&StealthOptions{Languages: []string{"de-DE", "de"}}).scripts()
+	if err != nil {
+		t.Fatalf("scripts() returned error: %v", err)
+	}
+	program := stealthShimsEnvironment + strings.Join(scripts, "\n") + stealthShimsReport
+	out, err := exec.Command(node, "-e", program).Output()
+	if err != nil {
+		t.Fatalf("running the shims with node returned error: %v", err)
+	}
+	var got struct {
+		Webdriver string
+		Plugins   int
+		PDFViewer string
+		MimeType  string
+		Languages []string
+		Language  string
+	}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("the shims reported %q, which is not JSON: %v", out, err)
+	}
+	if got.Webdriver != "undefined" {
+		t.Errorf("navigator.webdriver is %s, want undefined", got.Webdriver)
+	}
+	if got.Plugins != 3 || got.PDFViewer != "internal-pdf-viewer" || got.MimeType != "application/pdf" {
+		t.Errorf("navigator.plugins has %d plugins, with the PDF Viewer in %q and the MIME type %q; want 3, internal-pdf-viewer and application/pdf", got.Plugins, got.PDFViewer, got.MimeType)
+	}
+	if want := []string{"de-DE", "de"}; !reflect.DeepEqual(got.Languages, want) || got.Language != "de-DE" {
+		t.Errorf("navigator.languages is %v and navigator.language is %q, want %v and de-DE", got.Languages, got.Language, want)
+	}
+}
@@ -0,0 +1,158 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WebStorage provides access to one of the browser's Web Storage areas,
+// localStorage or sessionStorage, for the origin of the current page.
+type WebStorage interface {
+	// Keys returns the keys of all items in the storage area.
+	Keys() ([]string, error)
+	// Item returns the value of the item with the given key. ok is false if
+	// there is no such item.
+	Item(key string) (value string, ok bool, err error)
+	// SetItem sets the value of the item with the given key.
+	SetItem(key, value string) error
+	// RemoveItem removes the item with the given key.
+	RemoveItem(key string) error
+	// Clear removes all items from the storage area.
+	Clear() error
+	// Usage returns the number of bytes used by the storage area, computed as
+	// the sum of the UTF-16 encoded lengths of all keys and values. This is
+	// the measure against which browsers enforce their per-origin quota.
+	Usage() (int64, error)
+}
+
+// StorageEstimate is returned by WebDriver.StorageEstimate.
+type StorageEstimate struct {
+	// Supported is false if the browser does not implement
+	// navigator.storage.estimate, in which case the other fields are not
+	// populated.
+	Supported bool
+	// Quota is the number of bytes available to the origin.
+	Quota int64
+	// Usage is the number of bytes used by the origin.
+	Usage int64
+	// UsageDetails breaks down Usage by storage system, e.g. "indexedDB" or
+	// "caches", where the browser supports it.
+	UsageDetails map[string]int64
+}
+
+type remoteStorage struct {
+	wd *remoteWD
+	// name is either "localStorage" or "sessionStorage".
+	name string
+}
+
+func (wd *remoteWD) LocalStorage() WebStorage {
+	return &remoteStorage{wd, "localStorage"}
+}
+
+func (wd *remoteWD) SessionStorage() WebStorage {
+	return &remoteStorage{wd, "sessionStorage"}
+}
+
+// script executes the script with the storage area bound to the variable s,
+// and decodes the result into v, if v is not nil.
+func (s *remoteStorage) script(script string, v interface{}, args ...interface{}) error {
+	if args == nil {
+		args = make([]interface{}, 0)
+	}
+	raw, err := s.wd.ExecuteScriptRaw(fmt.Sprintf("var s = window.%s;\n%s", s.name, script), args)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	reply := new(struct{ Value json.RawMessage })
+	if err := json.Unmarshal(raw, reply); err != nil {
+		return err
+	}
+	return json.Unmarshal(reply.Value, v)
+}
+
+func (s *remoteStorage) Keys() ([]string, error) {
+	var keys []string
+	err := s.script(`
+var keys = [];
+for (var i = 0; i < s.length; i++) {
+	keys.push(s.key(i));
+}
+return keys;`, &keys)
+	return keys, err
+}
+
+func (s *remoteStorage) Item(key string) (string, bool, error) {
+	var value *string
+	if err := s.script(`return s.getItem(arguments[0]);`, &value, key); err != nil {
+		return "", false, err
+	}
+	if value == nil {
+		return "", false, nil
+	}
+	return *value, true, nil
+}
+
+func (s *remoteStorage) SetItem(key, value string) error {
+	return s.script(`s.setItem(arguments[0], arguments[1]);`, nil, key, value)
+}
+
+func (s *remoteStorage) RemoveItem(key string) error {
+	return s.script(`s.removeItem(arguments[0]);`, nil, key)
+}
+
+func (s *remoteStorage) Clear() error {
+	return s.script(`s.clear();`, nil)
+}
+
+func (s *remoteStorage) Usage() (int64, error) {
+	var usage int64
+	// String lengths in JavaScript are measured in UTF-16 code units.
+	err := s.script(`
+var total = 0;
+for (var i = 0; i < s.length; i++) {
+	var key = s.key(i);
+	total += key.length + (s.getItem(key) || '').length;
+}
+return total * 2;`, &usage)
+	return usage, err
+}
+
+const storageEstimateScript = `
+var done = arguments[arguments.length - 1];
+if (!navigator.storage || !navigator.storage.estimate) {
+	done({supported: false});
+	return;
+}
+navigator.storage.estimate().then(function(e) {
+	done({supported: true, quota: e.quota, usage: e.usage, usageDetails: e.usageDetails || null});
+}, function(err) {
+	done({error: String(err)});
+});
+`
+
+func (wd *remoteWD) StorageEstimate() (*StorageEstimate, error) {
+	raw, err := wd.ExecuteScriptAsyncRaw(storageEstimateScript, nil)
+	if err != nil {
+		return nil, err
+	}
+	reply := new(struct {
+		Value *struct {
+			StorageEstimate
+			Error string
+		}
+	})
+	if err := json.Unmarshal(raw, reply); err != nil {
+		return nil, err
+	}
+	if reply.Value == nil {
+		return nil, fmt.Errorf("nil return value")
+	}
+	if reply.Value.Error != "" {
+		return nil, fmt.Errorf("navigator.storage.estimate() failed: %s", reply.Value.Error)
+	}
+	return &reply.Value.StorageEstimate, nil
+}
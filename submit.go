@@ -0,0 +1,58 @@
+package selenium
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrNotInForm is wrapped by the error returned by WebElement.Submit for an
+// element that is neither a form nor inside one.
+var ErrNotInForm = errors.New("the element is not a form or inside one")
+
+// submitScript submits the form of the element passed as the first argument,
+// and returns false if there is none. It clicks the default button of the
+// form if there is one, as a user pressing Enter would, so that its name and
+// value are submitted. Otherwise, as the Selenium atoms do, it fires a submit
+// event and submits the form unless a listener cancels the event. The
+// prototype's submit is called in case a control named "submit" shadows the
+// method.
+const submitScript = `var elem = arguments[0];
+var form = elem.tagName.toUpperCase() === 'FORM' ? elem : elem.form || elem.closest('form');
+if (!form) {
+  return false;
+}
+for (var i = 0; i < form.elements.length; i++) {
+  var control = form.elements[i];
+  if (control.type === 'submit') {
+    if (control.disabled) {
+      break;
+    }
+    control.click();
+    return true;
+  }
+}
+var event = new Event('submit', {bubbles: true, cancelable: true});
+if (form.dispatchEvent(event)) {
+  HTMLFormElement.prototype.submit.call(form);
+}
+return true;`
+
+// submitByScript submits the form of the element with submitScript, for W3C
+// sessions, which have no element submit endpoint.
+func (elem *remoteWE) submitByScript() error {
+	if err := elem.checkID(); err != nil {
+		return err
+	}
+	response, err := elem.parent.ExecuteScriptRaw(submitScript, []interface{}{elem})
+	if err != nil {
+		return elem.wrapError("submit", err)
+	}
+	reply := new(struct{ Value bool })
+	if err := json.Unmarshal(response, reply); err != nil {
+		return err
+	}
+	if !reply.Value {
+		return elem.wrapError("submit", ErrNotInForm)
+	}
+	return nil
+}
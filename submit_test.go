@@ -0,0 +1,50 @@
+package selenium
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSubmit(t *testing.T) {
+	var inForm bool
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/session/123/execute/sync" {
+			var params struct{ Script string }
+			json.NewDecoder(r.Body).Decode(&params)
+			if params.Script != submitScript {
+				t.Errorf("unexpected script %q", params.Script)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": inForm})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"value": nil})
+	}))
+	defer s.Close()
+
+	for _, tc := range []struct {
+		w3c, inForm bool
+		want        string
+		wantErr     error
+	}{
+		{w3c: true, inForm: true, want: "POST /session/123/execute/sync"},
+		{w3c: true, inForm: false, want: "POST /session/123/execute/sync", wantErr: ErrNotInForm},
+		{w3c: false, inForm: true, want: "POST /session/123/element/e1/submit"},
+	} {
+		inForm = tc.inForm
+		requests = nil
+		wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: tc.w3c}
+		err := (&remoteWE{parent: wd, id: "e1"}).Submit()
+		if tc.wantErr == nil && err != nil || tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+			t.Errorf("w3c=%t, inForm=%t: Submit() returned %v, want %v", tc.w3c, tc.inForm, err, tc.wantErr)
+		}
+		if len(requests) != 1 || requests[0] != tc.want {
+			t.Errorf("w3c=%t, inForm=%t: requests = %v, want [%s]", tc.w3c, tc.inForm, requests, tc.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Timeouts are the timeouts of a session. A zero duration means that the
+// remote end does not wait at all, which differs from leaving a timeout
+// unset: SetTimeouts sets all three, so to change only one of them, use
+// SetImplicitWaitTimeout, SetPageLoadTimeout or SetAsyncScriptTimeout.
+type Timeouts struct {
+	// Implicit is the time to wait for elements to appear when finding them.
+	Implicit time.Duration
+	// PageLoad is the time to wait for a page to load when navigating.
+	PageLoad time.Duration
+	// Script is the time that scripts may run before they are aborted, or
+	// NoTimeout if they are never aborted.
+	Script time.Duration
+}
+
+// NoTimeout is a timeout that never expires. GetTimeouts reports it as the
+// script timeout of a W3C session whose scripts are never aborted, for which
+// the remote end returns null. Setting it sends the longest timeout that a
+// time.Duration can hold, which is as good as no timeout.
+const NoTimeout = time.Duration(math.MaxInt64)
+
+// noTimeoutMillis is NoTimeout in milliseconds, as sent to the remote end.
+const noTimeoutMillis = uint(NoTimeout / time.Millisecond)
+
+// timeoutKind is a set of the timeouts of a session.
+type timeoutKind uint8
+
+const (
+	implicitTimeout timeoutKind = 1 << iota
+	pageLoadTimeout
+	scriptTimeout
+
+	allTimeouts = implicitTimeout | pageLoadTimeout | scriptTimeout
+)
+
+// timeoutMillis converts a timeout to the milliseconds sent to the remote
+// end, rounding to the nearest millisecond. A negative timeout is an error,
+// and a positive one shorter than half a millisecond is rounded up to one
+// millisecond rather than down to zero, which would disable the wait. The
+// W3C specification limits timeouts to 2^53-1 milliseconds, which is more
+// than any time.Duration.
+func timeoutMillis(name string, timeout time.Duration) (uint, error) {
+	if timeout < 0 {
+		return 0, fmt.Errorf("invalid %s timeout %v: must not be negative", name, timeout)
+	}
+	ms := uint(timeout.Round(time.Millisecond) / time.Millisecond)
+	if ms == 0 && timeout > 0 {
+		TimeoutWarningHandler(fmt.Sprintf("the %s timeout %v is rounded up to 1ms", name, timeout))
+		ms = 1
+	}
+	return ms, nil
+}
+
+// millisTimeout converts milliseconds sent to or received from the remote
+// end back to a timeout. It is the inverse of timeoutMillis, including for
+// NoTimeout.
+func millisTimeout(ms uint) time.Duration {
+	if ms >= noTimeoutMillis {
+		return NoTimeout
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// SetSessionTimeouts sets the "timeouts" capability, which the remote end
+// applies when it creates the session, before any navigation. The timeouts
+// are rounded as by WebDriver.SetTimeouts, and negative ones are an error.
+// Only W3C remote ends support the capability.
+func (c Capabilities) SetSessionTimeouts(timeouts Timeouts) error {
+	implicit, err := timeoutMillis("implicit wait", timeouts.Implicit)
+	if err != nil {
+		return err
+	}
+	pageLoad, err := timeoutMillis("page load", timeouts.PageLoad)
+	if err != nil {
+		return err
+	}
+	script, err := timeoutMillis("script", timeouts.Script)
+	if err != nil {
+		return err
+	}
+	c["timeouts"] = map[string]uint{
+		"implicit": implicit,
+		"pageLoad": pageLoad,
+		"script":   script,
+	}
+	return nil
+}
+
+// parseTimeouts parses the timeouts reported by a W3C remote end, in
+// milliseconds, and returns which of them were reported. A null script
+// timeout means that scripts are never aborted, and is parsed as NoTimeout.
+func parseTimeouts(v interface{}) (Timeouts, timeoutKind) {
+	m, _ := v.(map[string]interface{})
+	var timeouts Timeouts
+	var known timeoutKind
+	for _, t := range []struct {
+		key      string
+		kind     timeoutKind
+		duration *time.Duration
+	}{
+		{"implicit", implicitTimeout, &timeouts.Implicit},
+		{"pageLoad", pageLoadTimeout, &timeouts.PageLoad},
+		{"script", scriptTimeout, &timeouts.Script},
+	} {
+		value, present := m[t.key]
+		ms, isNumber := value.(float64)
+		switch {
+		case isNumber && ms >= float64(noTimeoutMillis):
+			*t.duration = NoTimeout
+		case isNumber && ms >= 0:
+			*t.duration = millisTimeout(uint(ms))
+		case present && value == nil && t.kind == scriptTimeout:
+			*t.duration = NoTimeout
+		default:
+			continue
+		}
+		known |= t.kind
+	}
+	return timeouts, known
+}
+
+// noteTimeouts records the given kinds of timeouts of the session, taken from
+// timeouts.
+func (wd *remoteWD) noteTimeouts(kinds timeoutKind, timeouts Timeouts) {
+	if kinds&implicitTimeout != 0 {
+		wd.timeouts.Implicit = timeouts.Implicit
+	}
+	if kinds&pageLoadTimeout != 0 {
+		wd.timeouts.PageLoad = timeouts.PageLoad
+	}
+	if kinds&scriptTimeout != 0 {
+		wd.timeouts.Script = timeouts.Script
+	}
+	wd.knownTimeouts |= kinds
+}
+
+// resetTimeouts forgets the timeouts of the session and the per-session
+// state of the client-side waits when the session changes.
+func (wd *remoteWD) resetTimeouts() {
+	wd.timeouts = Timeouts{}
+	wd.knownTimeouts = 0
+	wd.getTimeoutsErr = nil
+	wd.implicitWaitWarned = false
+}
+
+func (wd *remoteWD) GetTimeouts() (Timeouts, error) {
+	var unsupported error
+	if wd.w3cCompatible && wd.getTimeoutsErr == nil {
+		response, err := wd.execute("GET", wd.requestURL("/session/%s/timeouts", wd.id), nil)
+		if err == nil {
+			reply := new(struct{ Value interface{} })
+			if err := json.Unmarshal(response, reply); err != nil {
+				return Timeouts{}, err
+			}
+			timeouts, known := parseTimeouts(reply.Value)
+			if known != allTimeouts {
+				return Timeouts{}, fmt.Errorf("the remote end returned invalid timeouts: %s", response)
+			}
+			wd.noteTimeouts(allTimeouts, timeouts)
+			return timeouts, nil
+		}
+		if !isUnknownCommand(err) {
+			return Timeouts{}, err
+		}
+		// Remember that for the rest of the session.
+		wd.getTimeoutsErr = err
+	}
+	if wd.w3cCompatible {
+		unsupported = wd.getTimeoutsErr
+	}
+	if wd.knownTimeouts != allTimeouts {
+		return Timeouts{}, &UnsupportedCommandError{Command: "GetTimeouts", Err: unsupported}
+	}
+	return wd.timeouts, nil
+}
+
+func (wd *remoteWD) SetTimeouts(timeouts Timeouts) error {
+	implicit, err := timeoutMillis("implicit wait", timeouts.Implicit)
+	if err != nil {
+		return err
+	}
+	pageLoad, err := timeoutMillis("page load", timeouts.PageLoad)
+	if err != nil {
+		return err
+	}
+	script, err := timeoutMillis("script", timeouts.Script)
+	if err != nil {
+		return err
+	}
+	if !wd.w3cCompatible {
+		for _, t := range []struct {
+			typ string
+			ms  uint
+		}{{"implicit", implicit}, {"page load", pageLoad}, {"script", script}} {
+			if err := wd.voidCommand("/session/%s/timeouts", map[string]interface{}{
+				"type": t.typ,
+				"ms":   t.ms,
+			}); err != nil {
+				return err
+			}
+		}
+	} else if err := wd.voidCommand("/session/%s/timeouts", map[string]uint{
+		"implicit": implicit,
+		"pageLoad": pageLoad,
+		"script":   script,
+	}); err != nil {
+		return err
+	}
+	wd.noteTimeouts(allTimeouts, Timeouts{
+		Implicit: millisTimeout(implicit),
+		PageLoad: millisTimeout(pageLoad),
+		Script:   millisTimeout(script),
+	})
+	return nil
+}
@@ -0,0 +1,211 @@
+package selenium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutMillis(t *testing.T) {
+	var warnings []string
+	defer func(h func(string)) { TimeoutWarningHandler = h }(TimeoutWarningHandler)
+	TimeoutWarningHandler = func(msg string) { warnings = append(warnings, msg) }
+
+	for _, tc := range []struct {
+		timeout time.Duration
+		want    uint
+		warn    bool
+	}{
+		{0, 0, false},
+		{time.Nanosecond, 1, true},
+		{500 * time.Microsecond, 1, false},
+		{499 * time.Microsecond, 1, true},
+		{time.Millisecond, 1, false},
+		{1499 * time.Microsecond, 1, false},
+		{1500 * time.Microsecond, 2, false},
+		{time.Second, 1000, false},
+		{time.Hour, 3600000, false},
+		{time.Duration(1<<63 - 1), uint((1<<63 - 1) / int64(time.Millisecond)), false},
+	} {
+		warnings = nil
+		got, err := timeoutMillis("test", tc.timeout)
+		if err != nil {
+			t.Errorf("timeoutMillis(%v) returned error: %v", tc.timeout, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("timeoutMillis(%v) = %d, want %d", tc.timeout, got, tc.want)
+		}
+		if (len(warnings) > 0) != tc.warn {
+			t.Errorf("timeoutMillis(%v) warned %q, want a warning: %t", tc.timeout, warnings, tc.warn)
+		}
+	}
+
+	for _, timeout := range []time.Duration{-time.Nanosecond, -time.Second, -1 << 63} {
+		if _, err := timeoutMillis("test", timeout); err == nil {
+			t.Errorf("timeoutMillis(%v) returned nil error", timeout)
+		}
+	}
+}
+
+func TestSetTimeouts(t *testing.T) {
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, strings.TrimPrefix(r.URL.Path, "/session/123")+" "+string(body))
+		fmt.Fprint(w, `{"status":0,"value":null}`)
+	}))
+	defer s.Close()
+
+	timeouts := Timeouts{Implicit: 0, PageLoad: 30 * time.Second, Script: 100 * time.Microsecond}
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	if err := wd.SetTimeouts(timeouts); err != nil {
+		t.Fatalf("SetTimeouts() returned error: %v", err)
+	}
+	wd.w3cCompatible = false
+	if err := wd.SetTimeouts(timeouts); err != nil {
+		t.Fatalf("SetTimeouts() on a legacy session returned error: %v", err)
+	}
+	want := []string{
+		`/timeouts {"implicit":0,"pageLoad":30000,"script":1}`,
+		`/timeouts {"ms":0,"type":"implicit"}`,
+		`/timeouts {"ms":30000,"type":"page load"}`,
+		`/timeouts {"ms":1,"type":"script"}`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("SetTimeouts() sent\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+	if wd.knownTimeouts != allTimeouts || wd.timeouts.Implicit != 0 {
+		t.Errorf("SetTimeouts() did not record the implicit wait timeout")
+	}
+
+	requests = nil
+	for name, set := range map[string]func(time.Duration) error{
+		"SetTimeouts":            func(d time.Duration) error { return wd.SetTimeouts(Timeouts{PageLoad: d}) },
+		"SetImplicitWaitTimeout": wd.SetImplicitWaitTimeout,
+		"SetPageLoadTimeout":     wd.SetPageLoadTimeout,
+		"SetAsyncScriptTimeout":  wd.SetAsyncScriptTimeout,
+	} {
+		if err := set(-time.Second); err == nil {
+			t.Errorf("%s() of a negative timeout returned nil error", name)
+		}
+	}
+	if len(requests) != 0 {
+		t.Errorf("negative timeouts were sent: %q", requests)
+	}
+}
+
+func TestSessionTimeouts(t *testing.T) {
+	caps := Capabilities{"browserName": "firefox"}
+	if err := caps.SetSessionTimeouts(Timeouts{Implicit: 2 * time.Second, PageLoad: time.Minute, Script: 1500 * time.Microsecond}); err != nil {
+		t.Fatalf("SetSessionTimeouts() returned error: %v", err)
+	}
+	if err := (Capabilities{}).SetSessionTimeouts(Timeouts{Script: -time.Second}); err == nil {
+		t.Errorf("SetSessionTimeouts() of a negative timeout returned nil error")
+	}
+
+	var sent string
+	getSupported := false
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/session":
+			body, _ := ioutil.ReadAll(r.Body)
+			var payload struct {
+				Capabilities struct {
+					AlwaysMatch struct{ Timeouts json.RawMessage }
+				}
+			}
+			json.Unmarshal(body, &payload)
+			sent = string(payload.Capabilities.AlwaysMatch.Timeouts)
+			fmt.Fprintf(w, `{"value":{"sessionId":"123","capabilities":{"browserName":"firefox","timeouts":%s}}}`, sent)
+		case r.Method == "GET" && r.URL.Path == "/session/123/timeouts" && getSupported:
+			fmt.Fprint(w, `{"value":{"implicit":0,"pageLoad":300000,"script":30000}}`)
+		case r.Method == "POST" && r.URL.Path == "/session/123/timeouts":
+			fmt.Fprint(w, `{"value":null}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"unknown command","message":"not implemented","stacktrace":""}}`)
+		}
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{urlPrefix: s.URL, capabilities: caps}
+	if _, err := wd.NewSession(); err != nil {
+		t.Fatalf("NewSession() returned error: %v", err)
+	}
+	if want := `{"implicit":2000,"pageLoad":60000,"script":2}`; sent != want {
+		t.Errorf("NewSession() sent the timeouts capability %s, want %s", sent, want)
+	}
+
+	want := Timeouts{Implicit: 2 * time.Second, PageLoad: time.Minute, Script: 2 * time.Millisecond}
+	if got, err := wd.GetTimeouts(); err != nil || got != want {
+		t.Errorf("GetTimeouts() = %+v, %v; want the negotiated %+v", got, err, want)
+	}
+	if err := wd.SetPageLoadTimeout(time.Second); err != nil {
+		t.Fatalf("SetPageLoadTimeout() returned error: %v", err)
+	}
+	want.PageLoad = time.Second
+	if got, err := wd.GetTimeouts(); err != nil || got != want {
+		t.Errorf("GetTimeouts() after SetPageLoadTimeout() = %+v, %v; want %+v", got, err, want)
+	}
+
+	getSupported = true
+	if _, err := wd.NewSession(); err != nil {
+		t.Fatalf("NewSession() returned error: %v", err)
+	}
+	want = Timeouts{PageLoad: 5 * time.Minute, Script: 30 * time.Second}
+	if got, err := wd.GetTimeouts(); err != nil || got != want {
+		t.Errorf("GetTimeouts() = %+v, %v; want those returned by the remote end, %+v", got, err, want)
+	}
+
+	wd = &remoteWD{id: "123", urlPrefix: s.URL}
+	var unsupported *UnsupportedCommandError
+	if _, err := wd.GetTimeouts(); !errors.As(err, &unsupported) {
+		t.Errorf("GetTimeouts() with unknown timeouts returned error %v, want an *UnsupportedCommandError", err)
+	}
+}
+
+func TestNullScriptTimeout(t *testing.T) {
+	var sent string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		if r.Method == "POST" {
+			body, _ := ioutil.ReadAll(r.Body)
+			sent = string(body)
+			fmt.Fprint(w, `{"value":null}`)
+			return
+		}
+		fmt.Fprint(w, `{"value":{"implicit":0,"pageLoad":300000,"script":null}}`)
+	}))
+	defer s.Close()
+
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	want := Timeouts{PageLoad: 5 * time.Minute, Script: NoTimeout}
+	got, err := wd.GetTimeouts()
+	if err != nil || got != want {
+		t.Fatalf("GetTimeouts() = %+v, %v; want %+v", got, err, want)
+	}
+
+	if err := wd.SetTimeouts(got); err != nil {
+		t.Fatalf("SetTimeouts() returned error: %v", err)
+	}
+	if want := fmt.Sprintf(`{"implicit":0,"pageLoad":300000,"script":%d}`, noTimeoutMillis); sent != want {
+		t.Errorf("SetTimeouts() sent %s, want %s", sent, want)
+	}
+	if wd.timeouts != want {
+		t.Errorf("SetTimeouts() cached %+v, want %+v", wd.timeouts, want)
+	}
+
+	// A null implicit wait or page load timeout is invalid.
+	if _, known := parseTimeouts(map[string]interface{}{"implicit": nil, "pageLoad": 0.0, "script": 0.0}); known == allTimeouts {
+		t.Errorf("parseTimeouts() accepted a null implicit wait timeout")
+	}
+}
@@ -0,0 +1,244 @@
+package selenium
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// HTTPOption configures the HTTP transport used to communicate with the remote
+// end. Options are applied with ConfigureHTTP.
+type HTTPOption func(*httpOptions) error
+
+type httpOptions struct {
+	http2, cleartextHTTP2 bool
+	idleConnTimeout       time.Duration
+	retryStale            bool
+}
+
+// HTTP2 enables HTTP/2 for connections to the remote end. For https:// URLs,
+// HTTP/2 is negotiated via TLS ALPN and the client falls back to HTTP/1.1 if
+// the server does not support it. If cleartext is true, http:// URLs will use
+// HTTP/2 with prior knowledge (h2c); the remote end must support h2c, as there
+// is no fallback to HTTP/1.1 in this mode.
+func HTTP2(cleartext bool) HTTPOption {
+	return func(o *httpOptions) error {
+		o.http2 = true
+		o.cleartextHTTP2 = cleartext
+		return nil
+	}
+}
+
+// IdleConnTimeout sets the maximum amount of time an idle keep-alive
+// connection to the remote end remains open. This should be set below the
+// idle cutoff of any load balancer between the client and the remote end, so
+// that the client closes idle connections before the load balancer silently
+// drops them.
+func IdleConnTimeout(d time.Duration) HTTPOption {
+	return func(o *httpOptions) error {
+		if d < 0 {
+			return fmt.Errorf("idle connection timeout must not be negative, got %s", d)
+		}
+		o.idleConnTimeout = d
+		return nil
+	}
+}
+
+// RetryStaleConnections causes idempotent commands to be transparently retried
+// once if they fail because a reused keep-alive connection was closed by the
+// remote end (or an intermediary) before a response was received.
+//
+// The Go HTTP transport already retries GET requests in this case, but not
+// POST requests, which make up most WebDriver commands.
+func RetryStaleConnections() HTTPOption {
+	return func(o *httpOptions) error {
+		o.retryStale = true
+		return nil
+	}
+}
+
+// retryStaleConnections is set by the RetryStaleConnections option. It is
+// read by every request, possibly while ConfigureHTTP is called.
+var retryStaleConnections atomic.Bool
+
+// ConfigureHTTP replaces the HTTP client returned by GetHTTPClient with a copy
+// whose transport is configured by the provided options. Options not provided
+// take the defaults of http.DefaultTransport. It is safe to call while
+// commands are sent: they use either the previous client or the new one, and
+// clients previously returned by GetHTTPClient are left unchanged.
+func ConfigureHTTP(opts ...HTTPOption) error {
+	o := new(httpOptions)
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return err
+		}
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if o.idleConnTimeout > 0 {
+		t.IdleConnTimeout = o.idleConnTimeout
+	}
+	if o.http2 {
+		t.ForceAttemptHTTP2 = true
+		p := new(http.Protocols)
+		if o.cleartextHTTP2 {
+			// Without HTTP/1 enabled, the transport uses HTTP/2 with prior
+			// knowledge for http:// URLs.
+			p.SetUnencryptedHTTP2(true)
+		} else {
+			p.SetHTTP1(true)
+		}
+		p.SetHTTP2(true)
+		t.Protocols = p
+	}
+
+	client := *httpClient.Load()
+	client.Transport = t
+	httpClient.Store(&client)
+	retryStaleConnections.Store(o.retryStale)
+	return nil
+}
+
+// isStaleConnectionError returns true if err was caused by the remote end
+// closing or resetting a connection before any response was received.
+func isStaleConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch {
+	case errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE):
+		return true
+	}
+	// The transport reports a closed, reused connection with an unexported
+	// error value.
+	return strings.Contains(err.Error(), "server closed idle connection")
+}
+
+// idempotentCommands are suffixes of POST endpoints that can be safely
+// repeated. Navigation to a URL is not one of them, as repeating it loads the
+// page again.
+var idempotentCommands = []string{
+	"/element",
+	"/elements",
+	"/timeouts",
+	"/timeouts/async_script",
+	"/timeouts/implicit_wait",
+	"/window",
+	"/frame",
+	"/window/rect",
+	"/window/maximize",
+	"/file",
+}
+
+// isIdempotent returns true if a request with the given method to the given
+// URL may be sent more than once without changing its result.
+func isIdempotent(method, url string) bool {
+	switch method {
+	case "GET", "HEAD", "DELETE":
+		return true
+	case "POST":
+		for _, suffix := range idempotentCommands {
+			if strings.HasSuffix(url, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requestBody is the body of a request, which is either held in data or, if
+// write is set, written by write as the request is sent, so that it is not
+// held in memory.
+type requestBody struct {
+	data []byte
+	// write writes the body to w. It is called each time the request is
+	// sent or redirected. size is the approximate size of the body.
+	write func(w io.Writer) error
+	size  int64
+}
+
+// len returns the size of the body.
+func (b requestBody) len() int64 {
+	if b.write != nil {
+		return b.size
+	}
+	return int64(len(b.data))
+}
+
+// open returns a reader of the body, compressed with gzip if gzipped is set,
+// and its length, or -1 if the body is written as it is read.
+func (b requestBody) open(gzipped bool) (io.ReadCloser, int64, error) {
+	if b.write == nil {
+		data := b.data
+		if gzipped {
+			var err error
+			if data, err = gzipBody(data); err != nil {
+				return nil, 0, err
+			}
+		}
+		return ioutil.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+	}
+
+	// Closing the reader stops the writer if the request fails before the
+	// body is read.
+	r, w := io.Pipe()
+	go func() {
+		if !gzipped {
+			w.CloseWithError(b.write(w))
+			return
+		}
+		zw := gzip.NewWriter(w)
+		err := b.write(zw)
+		if closeErr := zw.Close(); err == nil {
+			err = closeErr
+		}
+		w.CloseWithError(err)
+	}()
+	return r, -1, nil
+}
+
+// doRequest sends the request built from the provided arguments with client,
+// and aborts it when ctx is done. The body is compressed with gzip if gzipped
+// is set. If enabled via RetryStaleConnections, an idempotent request that
+// failed on a reused connection that was found to be stale is sent once more.
+func doRequest(ctx context.Context, client *http.Client, method, url string, body requestBody, gzipped bool) (*http.Response, error) {
+	request, err := newRequest(method, url, body, gzipped)
+	if err != nil {
+		return nil, err
+	}
+	request = request.WithContext(ctx)
+	if !retryStaleConnections.Load() || !isIdempotent(method, url) {
+		return client.Do(request)
+	}
+
+	var reused bool
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			reused = info.Reused
+		},
+	}
+	response, err := client.Do(request.WithContext(httptrace.WithClientTrace(request.Context(), trace)))
+	if err == nil || !reused || !isStaleConnectionError(err) {
+		return response, err
+	}
+
+	debugLog("retrying %s %s after stale connection error: %v", method, filteredURL(url), err)
+	request, err = newRequest(method, url, body, gzipped)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(request.WithContext(ctx))
+}
@@ -0,0 +1,357 @@
+package selenium
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// flakyServer serves WebDriver-style JSON replies, but after answering
+// requestsPerConn requests on a connection, it reads the next request and
+// closes the connection without replying, as an idle-killing load balancer
+// would.
+type flakyServer struct {
+	l               net.Listener
+	requestsPerConn int
+
+	mu       sync.Mutex
+	requests int
+}
+
+func newFlakyServer(t *testing.T, requestsPerConn int) *flakyServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() returned error: %v", err)
+	}
+	s := &flakyServer{l: l, requestsPerConn: requestsPerConn}
+	go s.serve()
+	return s
+}
+
+func (s *flakyServer) URL() string { return "http://" + s.l.Addr().String() }
+
+func (s *flakyServer) Close() { s.l.Close() }
+
+func (s *flakyServer) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *flakyServer) serve() {
+	for {
+		conn, err := s.l.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *flakyServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for served := 0; ; served++ {
+		req, err := http.ReadRequest(r)
+		if err != nil {
+			return
+		}
+		io.Copy(ioutil.Discard, req.Body)
+		s.mu.Lock()
+		s.requests++
+		s.mu.Unlock()
+		if served == s.requestsPerConn {
+			return
+		}
+		body := `{"sessionId":"123","status":0,"value":"ok"}`
+		fmt.Fprintf(conn, "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+	}
+}
+
+func withHTTPOptions(t *testing.T, opts ...HTTPOption) {
+	oldClient, oldRetry := httpClient.Load(), retryStaleConnections.Load()
+	if err := ConfigureHTTP(opts...); err != nil {
+		t.Fatalf("ConfigureHTTP() returned error: %v", err)
+	}
+	t.Cleanup(func() {
+		httpClient.Store(oldClient)
+		retryStaleConnections.Store(oldRetry)
+	})
+}
+
+func TestRetryStaleConnections(t *testing.T) {
+	const requestsPerConn = 2
+	tests := []struct {
+		desc        string
+		opts        []HTTPOption
+		urlTemplate string
+		wantErr     bool
+	}{
+		{
+			desc:        "retry enabled, idempotent command",
+			opts:        []HTTPOption{RetryStaleConnections()},
+			urlTemplate: "/session/%s/timeouts",
+		},
+		{
+			desc:        "retry disabled",
+			urlTemplate: "/session/%s/timeouts",
+			wantErr:     true,
+		},
+		{
+			desc:        "retry enabled, navigation",
+			opts:        []HTTPOption{RetryStaleConnections()},
+			urlTemplate: "/session/%s/url",
+			wantErr:     true,
+		},
+		{
+			desc:        "retry enabled, non-idempotent command",
+			opts:        []HTTPOption{RetryStaleConnections()},
+			urlTemplate: "/session/%s/element/abc/click",
+			wantErr:     true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			withHTTPOptions(t, tc.opts...)
+			s := newFlakyServer(t, requestsPerConn)
+			defer s.Close()
+
+			wd := &remoteWD{id: "123", urlPrefix: s.URL()}
+			for i := 0; i < requestsPerConn; i++ {
+				if err := wd.voidCommand(tc.urlTemplate, nil); err != nil {
+					t.Fatalf("request %d: wd.voidCommand(%q) returned error: %v", i, tc.urlTemplate, err)
+				}
+			}
+			err := wd.voidCommand(tc.urlTemplate, nil)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("wd.voidCommand(%q) on a stale connection returned nil, expected an error", tc.urlTemplate)
+				}
+				if !isStaleConnectionError(err) {
+					t.Errorf("isStaleConnectionError(%v) = false, want true", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("wd.voidCommand(%q) on a stale connection returned error: %v", tc.urlTemplate, err)
+			}
+			if got, want := s.Requests(), requestsPerConn+2; got != want {
+				t.Errorf("server received %d requests, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestIsStaleConnectionError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{io.EOF, true},
+		{io.ErrUnexpectedEOF, true},
+		{&url.Error{Op: "Post", URL: "http://x", Err: io.EOF}, true},
+		{&net.OpError{Op: "read", Err: syscall.ECONNRESET}, true},
+		{&net.OpError{Op: "write", Err: syscall.EPIPE}, true},
+		{errors.New("http: server closed idle connection"), true},
+		{&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, false},
+		{errors.New("no such element"), false},
+	}
+	for _, tc := range tests {
+		if got := isStaleConnectionError(tc.err); got != tc.want {
+			t.Errorf("isStaleConnectionError(%v) = %t, want %t", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestConfigureHTTPRejectsNegativeIdleTimeout(t *testing.T) {
+	if err := ConfigureHTTP(IdleConnTimeout(-1)); err == nil {
+		t.Fatal("ConfigureHTTP(IdleConnTimeout(-1)) returned nil, expected an error")
+	}
+}
+
+func TestConfigureHTTPWhileSending(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		fmt.Fprint(w, `{"value":"Title"}`)
+	}))
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	// Run with -race: the shared client must not be modified in place.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := wd.Title(); err != nil {
+					t.Errorf("wd.Title() returned error: %v", err)
+				}
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		withHTTPOptions(t, IdleConnTimeout(time.Minute))
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+}
+
+func TestRequestHeaders(t *testing.T) {
+	type recorded struct {
+		method, path string
+		header       http.Header
+		bodyLen      int
+	}
+	var (
+		mu   sync.Mutex
+		reqs []recorded
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/redirect/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/session/123/url", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/see-other/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/session/123/url", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		reqs = append(reqs, recorded{r.Method, r.URL.Path, r.Header, len(body)})
+		mu.Unlock()
+		w.Header().Set("Content-Type", JSONType)
+		fmt.Fprint(w, `{"sessionId":"123","status":0,"value":null}`)
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	tests := []struct {
+		method, path string
+		body         []byte
+		wantBody     bool
+	}{
+		{method: "GET", path: "/session/123/url"},
+		{method: "DELETE", path: "/session/123"},
+		{method: "POST", path: "/session/123/url", body: []byte(`{"url":"x"}`), wantBody: true},
+		{method: "POST", path: "/redirect/", body: []byte(`{"url":"x"}`), wantBody: true},
+		{method: "POST", path: "/see-other/", body: []byte(`{"url":"x"}`)},
+	}
+	for _, tc := range tests {
+		reqs = nil
+		wd := &remoteWD{id: "123", urlPrefix: s.URL}
+		if _, err := wd.execute(tc.method, s.URL+tc.path, tc.body); err != nil {
+			t.Fatalf("wd.execute(%q, %q) returned error: %v", tc.method, tc.path, err)
+		}
+		if len(reqs) != 1 {
+			t.Fatalf("wd.execute(%q, %q) made %d requests to the final handler, want 1", tc.method, tc.path, len(reqs))
+		}
+		r := reqs[0]
+		if got := r.header.Get("Accept"); got != JSONType {
+			t.Errorf("%s %s: Accept = %q, want %q", tc.method, tc.path, got, JSONType)
+		}
+		if got := r.header.Get("Cache-Control"); got != "no-cache" {
+			t.Errorf("%s %s: Cache-Control = %q, want %q", tc.method, tc.path, got, "no-cache")
+		}
+		wantCType := ""
+		if tc.wantBody {
+			wantCType = JSONContentType
+		}
+		if got := r.header.Get("Content-Type"); got != wantCType {
+			t.Errorf("%s %s: Content-Type = %q, want %q", tc.method, tc.path, got, wantCType)
+		}
+		if gotBody := r.bodyLen > 0; gotBody != tc.wantBody {
+			t.Errorf("%s %s: request had body = %t, want %t", tc.method, tc.path, gotBody, tc.wantBody)
+		}
+	}
+}
+
+func TestNewSessionRedirect(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		creates int
+		others  []string
+	)
+	mux := http.NewServeMux()
+	// A legacy remote end only tells the session ID by redirecting.
+	mux.HandleFunc("/legacy/session", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		creates++
+		mu.Unlock()
+		http.Redirect(w, r, "/legacy/session/abc", http.StatusSeeOther)
+	})
+	// A proxy turns the 303 redirect into a 307 one, which is followed with
+	// the POST request, and the remote end creates a session for each.
+	mux.HandleFunc("/proxy/session", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		creates++
+		mu.Unlock()
+		http.Redirect(w, r, "/proxy/session/abc", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/proxy/session/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		creates++
+		mu.Unlock()
+		w.Header().Set("Content-Type", JSONType)
+		fmt.Fprint(w, `{"value":{"sessionId":"duplicate","capabilities":{}}}`)
+	})
+	// Redirects to other URLs than that of a session are followed.
+	mux.HandleFunc("/moved/session", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/w3c/session", http.StatusPermanentRedirect)
+	})
+	mux.HandleFunc("/w3c/session", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		creates++
+		mu.Unlock()
+		w.Header().Set("Content-Type", JSONType)
+		fmt.Fprint(w, `{"value":{"sessionId":"w3c","capabilities":{"browserName":"firefox"}}}`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		others = append(others, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		http.NotFound(w, r)
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	for _, tc := range []struct {
+		prefix string
+		wantID string
+		w3c    bool
+	}{
+		{prefix: "/legacy", wantID: "abc"},
+		{prefix: "/proxy", wantID: "abc"},
+		{prefix: "/moved", wantID: "w3c", w3c: true},
+	} {
+		creates, others = 0, nil
+		wd := &remoteWD{urlPrefix: s.URL + tc.prefix, capabilities: Capabilities{"browserName": "firefox"}}
+		id, err := wd.NewSession()
+		if err != nil {
+			t.Errorf("%s: NewSession() returned error: %v", tc.prefix, err)
+			continue
+		}
+		if id != tc.wantID || wd.SessionID() != tc.wantID {
+			t.Errorf("%s: NewSession() = %q, SessionID() = %q; want %q", tc.prefix, id, wd.SessionID(), tc.wantID)
+		}
+		if wd.w3cCompatible != tc.w3c {
+			t.Errorf("%s: w3cCompatible = %t, want %t", tc.prefix, wd.w3cCompatible, tc.w3c)
+		}
+		if creates != 1 {
+			t.Errorf("%s: NewSession() created %d sessions, want 1", tc.prefix, creates)
+		}
+		if len(others) > 0 {
+			t.Errorf("%s: NewSession() sent unexpected requests %q", tc.prefix, others)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package selenium
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// FileDetector reports whether keys passed to WebElement.SendKeys name a
+// local file, which is then uploaded to the remote end, and the keys replaced
+// with the path of the uploaded file on the remote end. It is set with
+// WebDriver.SetFileDetector.
+type FileDetector func(keys string) bool
+
+// LocalFileDetector is a FileDetector for the regular files of the local file
+// system, for typing into file inputs on a remote end that runs on another
+// machine, such as a Selenium Grid node:
+//
+//	wd.SetFileDetector(selenium.LocalFileDetector)
+//	err := input.SendKeys("/home/me/report.pdf")
+func LocalFileDetector(keys string) bool {
+	fi, err := os.Stat(keys)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+func (wd *remoteWD) SetFileDetector(detect FileDetector) {
+	wd.fileDetector = detect
+}
+
+// uploadKeys uploads the files named by the lines of keys that the file
+// detector accepts, and returns keys with the names replaced by the paths
+// of the uploaded files on the remote end.
+func (wd *remoteWD) uploadKeys(keys string) (string, error) {
+	if wd.fileDetector == nil {
+		return keys, nil
+	}
+	// Multiple files are selected by typing their names on separate lines.
+	lines := strings.Split(keys, "\n")
+	for i, line := range lines {
+		if line == "" || !wd.fileDetector(line) {
+			continue
+		}
+		path, err := wd.UploadFile(line)
+		if err != nil {
+			return "", err
+		}
+		lines[i] = path
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// uploadPlaceholder is the body of upload requests shown in the debug log
+// and the command history, instead of the file.
+var uploadPlaceholder = []byte(`{"file":"..."}`)
+
+func (wd *remoteWD) UploadFile(localPath string) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !fi.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", localPath)
+	}
+
+	// The body is written as it is sent, so that large files are not held
+	// in memory. It is written again from the start of the file if the
+	// request is redirected or retried.
+	body := requestBody{
+		write: func(w io.Writer) error {
+			return writeUploadBody(w, io.NewSectionReader(f, 0, fi.Size()), fi)
+		},
+		size: fi.Size(),
+	}
+
+	url := wd.requestURL("/session/%s/file", wd.id)
+	response, err := wd.executeRequest("POST", url, uploadPlaceholder, func() (*http.Response, error) {
+		return wd.sendRequest(context.Background(), "POST", url, body)
+	})
+	if isUnknownCommand(err) {
+		return "", &UnsupportedCommandError{Command: "UploadFile", Err: err}
+	}
+	if err != nil {
+		return "", err
+	}
+	return decodeValue[string](response, wd.strictDecoding, "/session/%s/file", wd.id)
+}
+
+// writeUploadBody writes the parameters of the upload command to w: the file
+// f, with the file info fi, zipped and base64-encoded.
+func writeUploadBody(w io.Writer, f io.Reader, fi os.FileInfo) error {
+	if _, err := io.WriteString(w, `{"file":"`); err != nil {
+		return err
+	}
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	zw := zip.NewWriter(enc)
+	header, err := zip.FileInfoHeader(fi)
+	if err != nil {
+		return err
+	}
+	header.Method = zip.Deflate
+	zf, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(zf, f); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, `"}`)
+	return err
+}
@@ -0,0 +1,241 @@
+package selenium
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// uploadServer emulates a remote end that stores uploaded files under
+// /remote.
+type uploadServer struct {
+	t *testing.T
+	// uploads maps the names of the uploaded files to their contents.
+	uploads map[string]string
+	// typed are the keys sent to elements.
+	typed []string
+	// unsupported makes the server reject uploads as Safari does.
+	unsupported bool
+	// chunked is set if the last upload was streamed, without a length.
+	chunked bool
+	// redirect makes the server redirect the next upload with 307 Temporary
+	// Redirect.
+	redirect bool
+	// gzipped is set if the last upload was compressed.
+	gzipped bool
+}
+
+func (s *uploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", JSONType)
+	path := strings.TrimPrefix(r.URL.Path, "/redirected")
+	switch path {
+	case "/session/123/file":
+		if s.redirect {
+			s.redirect = false
+			io.Copy(ioutil.Discard, r.Body)
+			http.Redirect(w, r, "/redirected"+path, http.StatusTemporaryRedirect)
+			return
+		}
+		if s.unsupported {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"unknown command","message":"unknown command"}}`)
+			return
+		}
+		var body io.Reader = r.Body
+		if s.gzipped = r.Header.Get("Content-Encoding") == "gzip"; s.gzipped {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				s.t.Fatalf("the upload is not compressed with gzip: %v", err)
+			}
+			body = zr
+		}
+		var params struct{ File string }
+		json.NewDecoder(body).Decode(&params)
+		data, err := base64.StdEncoding.DecodeString(params.File)
+		if err != nil {
+			s.t.Fatalf("the upload is not base64-encoded: %v", err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil || len(zr.File) != 1 {
+			s.t.Fatalf("the upload is not a zip file of one file: %v", err)
+		}
+		f, err := zr.File[0].Open()
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		content, _ := ioutil.ReadAll(f)
+		s.uploads[zr.File[0].Name] = string(content)
+		s.chunked = r.ContentLength < 0
+		fmt.Fprintf(w, `{"value":"/remote/%s"}`, zr.File[0].Name)
+	case "/session/123/element/e1/value":
+		var params struct{ Text string }
+		json.NewDecoder(r.Body).Decode(&params)
+		s.typed = append(s.typed, params.Text)
+		fmt.Fprint(w, `{"value":null}`)
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		fmt.Fprint(w, `{"value":null}`)
+	}
+}
+
+func TestSendKeysUploadsFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.csv")
+	ioutil.WriteFile(a, []byte("contents of a"), 0644)
+	ioutil.WriteFile(b, []byte("x,y\n1,2\n"), 0644)
+
+	us := &uploadServer{t: t}
+	s := httptest.NewServer(us)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "e1"}
+
+	for _, tc := range []struct {
+		name     string
+		detector FileDetector
+		literal  bool
+		keys     string
+		want     string
+		uploads  map[string]string
+	}{
+		{name: "without a detector", keys: a, want: a},
+		{name: "one file", detector: LocalFileDetector, keys: a, want: "/remote/a.txt", uploads: map[string]string{"a.txt": "contents of a"}},
+		{
+			name:     "several files",
+			detector: LocalFileDetector,
+			keys:     a + "\n" + b,
+			want:     "/remote/a.txt\n/remote/b.csv",
+			uploads:  map[string]string{"a.txt": "contents of a", "b.csv": "x,y\n1,2\n"},
+		},
+		{name: "text", detector: LocalFileDetector, keys: "hello\n" + dir, want: "hello\n" + dir},
+		{name: "literal", detector: LocalFileDetector, literal: true, keys: a, want: a},
+	} {
+		us.uploads, us.typed = map[string]string{}, nil
+		wd.SetFileDetector(tc.detector)
+		send := elem.SendKeys
+		if tc.literal {
+			send = elem.SendKeysLiteral
+		}
+		if err := send(tc.keys); err != nil {
+			t.Errorf("%s: sending %q returned error: %v", tc.name, tc.keys, err)
+			continue
+		}
+		if len(us.typed) != 1 || us.typed[0] != tc.want {
+			t.Errorf("%s: sending %q typed %q, want %q", tc.name, tc.keys, us.typed, tc.want)
+		}
+		if fmt.Sprint(us.uploads) != fmt.Sprint(tc.uploads) {
+			t.Errorf("%s: sending %q uploaded %q, want %q", tc.name, tc.keys, us.uploads, tc.uploads)
+		}
+	}
+
+	us.unsupported, us.typed = true, nil
+	wd.SetFileDetector(LocalFileDetector)
+	var unsupported *UnsupportedCommandError
+	if err := elem.SendKeys(a); !errors.As(err, &unsupported) {
+		t.Errorf("SendKeys(%q) on a remote end without uploads returned error %v, want an UnsupportedCommandError", a, err)
+	}
+	if len(us.typed) > 0 {
+		t.Errorf("SendKeys(%q) typed %q after the upload failed", a, us.typed)
+	}
+}
+
+func TestUploadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "fixture.bin")
+	content := bytes.Repeat([]byte("0123456789abcdef"), 256*1024)
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	us := &uploadServer{t: t, uploads: map[string]string{}}
+	s := httptest.NewServer(us)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	remote, err := wd.UploadFile(path)
+	if err != nil {
+		t.Fatalf("UploadFile(%q) returned error: %v", path, err)
+	}
+	if remote != "/remote/fixture.bin" {
+		t.Errorf("UploadFile(%q) = %q, want %q", path, remote, "/remote/fixture.bin")
+	}
+	if us.uploads["fixture.bin"] != string(content) {
+		t.Errorf("UploadFile(%q) uploaded %d bytes, want the %d bytes of the file", path, len(us.uploads["fixture.bin"]), len(content))
+	}
+	if !us.chunked {
+		t.Errorf("UploadFile(%q) sent a request with a length, want it streamed", path)
+	}
+
+	// The remote path can be typed several times without uploading again.
+	elem := &remoteWE{parent: wd, id: "e1"}
+	us.uploads = map[string]string{}
+	for i := 0; i < 2; i++ {
+		if err := elem.SendKeys(remote); err != nil {
+			t.Fatalf("SendKeys(%q) returned error: %v", remote, err)
+		}
+	}
+	if len(us.uploads) > 0 || len(us.typed) != 2 {
+		t.Errorf("typing the remote path twice uploaded %d files and typed %q", len(us.uploads), us.typed)
+	}
+
+	if _, err := wd.UploadFile(dir); err == nil {
+		t.Errorf("UploadFile(%q) of a directory returned no error", dir)
+	}
+	us.unsupported = true
+	var unsupported *UnsupportedCommandError
+	if _, err := wd.UploadFile(path); !errors.As(err, &unsupported) || unsupported.Command != "UploadFile" {
+		t.Errorf("UploadFile(%q) on a remote end without uploads returned error %v, want an UnsupportedCommandError", path, err)
+	}
+}
+
+func TestUploadFileRedirectedAndCompressed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "fixture.txt")
+	content := strings.Repeat("a compressible line\n", 1024)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	us := &uploadServer{t: t, uploads: map[string]string{}, redirect: true}
+	s := httptest.NewServer(us)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	wd.compressMinSize = 1024
+
+	if _, err := wd.UploadFile(path); err != nil {
+		t.Fatalf("UploadFile(%q) returned error: %v", path, err)
+	}
+	if us.redirect {
+		t.Fatalf("UploadFile(%q) was not redirected", path)
+	}
+	if us.uploads["fixture.txt"] != content {
+		t.Errorf("UploadFile(%q) uploaded %d bytes after the redirect, want the %d bytes of the file", path, len(us.uploads["fixture.txt"]), len(content))
+	}
+	if !us.gzipped {
+		t.Errorf("UploadFile(%q) sent an uncompressed request, want it compressed", path)
+	}
+}
@@ -0,0 +1,133 @@
+package selenium
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Condition is a function that Wait and its variants poll until it returns
+// true or an error.
+type Condition func(wd WebDriver) (bool, error)
+
+const (
+	// DefaultWaitInterval is the interval between polls of Wait and
+	// WaitWithTimeout.
+	DefaultWaitInterval = 100 * time.Millisecond
+	// DefaultWaitTimeout is the timeout of Wait.
+	DefaultWaitTimeout = 60 * time.Second
+)
+
+// TimeoutWarningHandler is called when a client-side wait is started on a
+// session that has a non-zero implicit wait and strict timeouts are not
+// enabled, once per session. The default handler writes the warning to the
+// standard logger.
+var TimeoutWarningHandler = func(msg string) {
+	log.Printf("selenium: %s", msg)
+}
+
+func (wd *remoteWD) StrictTimeouts(enabled bool) {
+	wd.strictTimeouts = enabled
+}
+
+// implicitWaitTimeout returns the implicit wait timeout of the session: the
+// value last set or negotiated or, failing that, the value reported by the
+// remote end.
+func (wd *remoteWD) implicitWaitTimeout() (time.Duration, error) {
+	if wd.knownTimeouts&implicitTimeout != 0 {
+		return wd.timeouts.Implicit, nil
+	}
+	if !wd.w3cCompatible {
+		// The legacy protocol has no command to read the timeouts, which
+		// default to zero.
+		return 0, nil
+	}
+	timeouts, err := wd.GetTimeouts()
+	if err != nil {
+		return 0, err
+	}
+	return timeouts.Implicit, nil
+}
+
+// noteNegotiatedTimeouts records the timeouts reported in the capabilities
+// of a new W3C session.
+func (wd *remoteWD) noteNegotiatedTimeouts() {
+	timeouts, known := parseTimeouts(wd.negotiated["timeouts"])
+	wd.noteTimeouts(known, timeouts)
+}
+
+// suspendImplicitWait prepares for a client-side wait. If the session has a
+// non-zero implicit wait, it warns or, with strict timeouts enabled, sets the
+// implicit wait to zero. The returned function must be called when the wait
+// ends to restore the implicit wait. Nested waits leave the implicit wait to
+// the outermost one.
+func (wd *remoteWD) suspendImplicitWait() func() {
+	wd.waitDepth++
+	done := func() { wd.waitDepth-- }
+	if wd.waitDepth > 1 {
+		return done
+	}
+	implicit, err := wd.implicitWaitTimeout()
+	if err != nil || implicit == 0 {
+		return done
+	}
+	if !wd.strictTimeouts {
+		if !wd.implicitWaitWarned {
+			wd.implicitWaitWarned = true
+			TimeoutWarningHandler(fmt.Sprintf("client-side wait on a session with an implicit wait of %v; each poll that finds elements may take up to %v. Use StrictTimeouts(true) to suspend the implicit wait during client-side waits.", implicit, implicit))
+		}
+		return done
+	}
+	if err := wd.SetImplicitWaitTimeout(0); err != nil {
+		return done
+	}
+	return func() {
+		wd.waitDepth--
+		wd.SetImplicitWaitTimeout(implicit)
+	}
+}
+
+func (wd *remoteWD) WaitWithTimeoutAndInterval(condition Condition, timeout, interval time.Duration) error {
+	restore := wd.suspendImplicitWait()
+	defer restore()
+
+	startTime := time.Now()
+	for {
+		done, err := condition(wd)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		if elapsed := time.Since(startTime); elapsed > timeout {
+			return fmt.Errorf("timeout after %v", elapsed)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func (wd *remoteWD) WaitWithTimeout(condition Condition, timeout time.Duration) error {
+	return wd.WaitWithTimeoutAndInterval(condition, timeout, DefaultWaitInterval)
+}
+
+func (wd *remoteWD) Wait(condition Condition) error {
+	return wd.WaitWithTimeoutAndInterval(condition, DefaultWaitTimeout, DefaultWaitInterval)
+}
+
+func (wd *remoteWD) FindElementWithTimeout(by, value string, timeout time.Duration) (WebElement, error) {
+	var elem WebElement
+	err := wd.WaitWithTimeout(func(WebDriver) (bool, error) {
+		var err error
+		elem, err = wd.FindElement(by, value)
+		if isNoSuchElement(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for element %s: %v", Locator{by, value}, err)
+	}
+	return elem, nil
+}
@@ -0,0 +1,164 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// timeoutsServer serves a W3C session whose implicit wait timeout starts at
+// implicitMS, and records the implicit wait values set by the client.
+type timeoutsServer struct {
+	*httptest.Server
+	implicitMS int
+	reads      int
+	sets       []int
+	// found is the number of Find Element requests that fail before one
+	// succeeds.
+	found int
+}
+
+func newTimeoutsServer(t *testing.T, implicitMS int) *timeoutsServer {
+	ts := &timeoutsServer{implicitMS: implicitMS}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		switch {
+		case r.URL.Path == "/session/123/timeouts" && r.Method == "GET":
+			ts.reads++
+			fmt.Fprintf(w, `{"value":{"implicit":%d,"pageLoad":300000,"script":30000}}`, ts.implicitMS)
+		case r.URL.Path == "/session/123/timeouts":
+			body, _ := ioutil.ReadAll(r.Body)
+			var params struct{ Implicit int }
+			json.Unmarshal(body, &params)
+			ts.implicitMS = params.Implicit
+			ts.sets = append(ts.sets, params.Implicit)
+			fmt.Fprint(w, `{"value":null}`)
+		case r.URL.Path == "/session/123/element":
+			if ts.found > 0 {
+				ts.found--
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"value":{"error":"no such element","message":"not yet"}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"value":{"%s":"e1"}}`, webElementIdentifier)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	return ts
+}
+
+func TestStrictTimeoutsSuspendImplicitWait(t *testing.T) {
+	ts := newTimeoutsServer(t, 10000)
+	defer ts.Close()
+	wd := &remoteWD{id: "123", urlPrefix: ts.URL, w3cCompatible: true}
+	wd.StrictTimeouts(true)
+
+	var during []int
+	err := wd.WaitWithTimeoutAndInterval(func(WebDriver) (bool, error) {
+		during = append(during, ts.implicitMS)
+		// Nested waits leave the implicit wait to the outermost one.
+		return true, wd.WaitWithTimeout(func(WebDriver) (bool, error) {
+			during = append(during, ts.implicitMS)
+			return true, nil
+		}, time.Second)
+	}, time.Second, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Wait() returned error: %v", err)
+	}
+	if fmt.Sprint(during) != "[0 0]" {
+		t.Errorf("implicit wait during the waits = %v ms, want [0 0]", during)
+	}
+	if fmt.Sprint(ts.sets) != "[0 10000]" {
+		t.Errorf("implicit wait set to %v ms, want [0 10000]", ts.sets)
+	}
+	if ts.reads != 1 {
+		t.Errorf("the timeouts were read %d times, want 1", ts.reads)
+	}
+
+	// The value read from the remote end is remembered.
+	ts.sets = nil
+	if _, err := wd.FindElementWithTimeout(ByID, "x", time.Second); err != nil {
+		t.Fatalf("FindElementWithTimeout() returned error: %v", err)
+	}
+	if fmt.Sprint(ts.sets) != "[0 10000]" || ts.reads != 1 {
+		t.Errorf("FindElementWithTimeout() set the implicit wait to %v ms after %d reads, want [0 10000] after 1", ts.sets, ts.reads)
+	}
+}
+
+func TestStrictTimeoutsRestoreOnPanic(t *testing.T) {
+	ts := newTimeoutsServer(t, 0)
+	defer ts.Close()
+	wd := &remoteWD{id: "123", urlPrefix: ts.URL, w3cCompatible: true}
+	wd.StrictTimeouts(true)
+	if err := wd.SetImplicitWaitTimeout(5 * time.Second); err != nil {
+		t.Fatalf("SetImplicitWaitTimeout() returned error: %v", err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("the condition's panic was not propagated")
+			}
+		}()
+		wd.Wait(func(WebDriver) (bool, error) {
+			panic("condition failed")
+		})
+	}()
+	if fmt.Sprint(ts.sets) != "[5000 0 5000]" {
+		t.Errorf("implicit wait set to %v ms, want [5000 0 5000]", ts.sets)
+	}
+	if ts.reads != 0 {
+		t.Errorf("the timeouts were read %d times, want 0", ts.reads)
+	}
+	if wd.waitDepth != 0 {
+		t.Errorf("waitDepth = %d after the wait, want 0", wd.waitDepth)
+	}
+
+	// The next wait suspends the implicit wait again.
+	ts.sets = nil
+	ts.found = 2
+	if _, err := wd.FindElementWithTimeout(ByID, "x", time.Minute); err != nil {
+		t.Fatalf("FindElementWithTimeout() returned error: %v", err)
+	}
+	if fmt.Sprint(ts.sets) != "[0 5000]" {
+		t.Errorf("implicit wait set to %v ms, want [0 5000]", ts.sets)
+	}
+}
+
+func TestImplicitWaitWarning(t *testing.T) {
+	var warnings []string
+	defer func(h func(string)) { TimeoutWarningHandler = h }(TimeoutWarningHandler)
+	TimeoutWarningHandler = func(msg string) { warnings = append(warnings, msg) }
+
+	ts := newTimeoutsServer(t, 10000)
+	defer ts.Close()
+	wd := &remoteWD{id: "123", urlPrefix: ts.URL, w3cCompatible: true}
+
+	for i := 0; i < 2; i++ {
+		if err := wd.WaitWithTimeout(func(WebDriver) (bool, error) { return true, nil }, time.Second); err != nil {
+			t.Fatalf("WaitWithTimeout() returned error: %v", err)
+		}
+	}
+	if len(ts.sets) != 0 {
+		t.Errorf("implicit wait set to %v ms without strict timeouts, want no requests", ts.sets)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "10s") {
+		t.Errorf("warnings = %q, want one warning about the 10s implicit wait", warnings)
+	}
+
+	// No warning is issued once the implicit wait is zero.
+	warnings = nil
+	wd.SwitchSession("123")
+	wd.SetImplicitWaitTimeout(0)
+	wd.Wait(func(WebDriver) (bool, error) { return true, nil })
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %q with no implicit wait, want none", warnings)
+	}
+}
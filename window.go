@@ -0,0 +1,514 @@
+package selenium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrLastWindowClosed is returned by WebDriver.Close,
+// WebDriver.CloseAndSwitch and WebDriver.CloseWindow when the closed window
+// was the last window of the session. The session should then be ended with Quit.
+var ErrLastWindowClosed = errors.New("the last window of the session was closed")
+
+// UnsupportedCommandError is returned by commands that the remote end does
+// not implement.
+type UnsupportedCommandError struct {
+	// Command is the method of the WebDriver, e.g. "MinimizeWindow".
+	Command string
+	// Err is the error returned by the remote end, or nil if the command was
+	// not sent because the protocol of the session does not define it.
+	Err error
+}
+
+func (e *UnsupportedCommandError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%s is not supported by the legacy protocol", e.Command)
+	}
+	return fmt.Sprintf("%s is not supported by the remote end: %v", e.Command, e.Err)
+}
+
+// Unwrap returns the error returned by the remote end.
+func (e *UnsupportedCommandError) Unwrap() error {
+	return e.Err
+}
+
+// isInvalidSession returns true if err indicates that the session does not
+// exist.
+func isInvalidSession(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Err == "invalid session id"
+	}
+	return err != nil && strings.HasPrefix(unwrapElementError(err).Error(), remoteErrors[6])
+}
+
+// isNoSuchWindow returns true if err indicates that the window referenced by
+// a command does not exist, e.g. because it was closed.
+func isNoSuchWindow(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Err == "no such window"
+	}
+	return err != nil && strings.HasPrefix(unwrapElementError(err).Error(), remoteErrors[23])
+}
+
+func (wd *remoteWD) SetSwitchOnClose(enabled bool) {
+	wd.noSwitchOnClose = !enabled
+}
+
+// switchToHandle switches to the window with the given handle.
+func (wd *remoteWD) switchToHandle(handle string) error {
+	params := map[string]string{"handle": handle}
+	if !wd.w3cCompatible {
+		params = map[string]string{"name": handle}
+	}
+	if err := wd.voidCommand("/session/%s/window", params); err != nil {
+		return err
+	}
+	wd.frames = nil
+	return nil
+}
+
+func (wd *remoteWD) CloseAndSwitch() ([]string, error) {
+	remaining, err := wd.closeCurrentWindow()
+	if err != nil {
+		return remaining, err
+	}
+	if !wd.noSwitchOnClose {
+		if err := wd.switchToHandle(remaining[0]); err != nil {
+			return remaining, err
+		}
+	}
+	return remaining, nil
+}
+
+// closeCurrentWindow closes the current window and returns the handles of
+// the remaining windows, or ErrLastWindowClosed if there are none.
+func (wd *remoteWD) closeCurrentWindow() ([]string, error) {
+	response, err := wd.execute("DELETE", wd.requestURL("/session/%s/window", wd.id), nil)
+	if err != nil {
+		return nil, err
+	}
+	wd.frames = nil
+
+	// W3C remote ends return the remaining handles; legacy ones have to be
+	// asked for them.
+	reply := new(struct{ Value []string })
+	var remaining []string
+	if err := json.Unmarshal(response, reply); err == nil && reply.Value != nil {
+		remaining = reply.Value
+	} else {
+		remaining, err = wd.WindowHandles()
+		if isInvalidSession(err) {
+			// The driver ended the session along with its last window.
+			remaining, err = nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(remaining) == 0 {
+		wd.lastWindowClosed = true
+		return remaining, ErrLastWindowClosed
+	}
+	return remaining, nil
+}
+
+func (wd *remoteWD) MinimizeWindow(name string) error {
+	wd.checkDialect("MinimizeWindow", name)
+	if !wd.w3cCompatible {
+		// The legacy protocol has no endpoint to minimize a window.
+		return &UnsupportedCommandError{Command: "MinimizeWindow"}
+	}
+	_, err := wd.modifyWindow(name, "minimize", map[string]string{})
+	if isUnknownCommand(err) {
+		return &UnsupportedCommandError{Command: "MinimizeWindow", Err: err}
+	}
+	return err
+}
+
+func (wd *remoteWD) FullscreenWindow(name string) (*Rect, error) {
+	wd.checkDialect("FullscreenWindow", name)
+	if !wd.w3cCompatible {
+		// The legacy protocol has no endpoint to make a window fullscreen.
+		return nil, &UnsupportedCommandError{Command: "FullscreenWindow"}
+	}
+	r, err := wd.modifyWindow(name, "fullscreen", map[string]string{})
+	if isUnknownCommand(err) {
+		return nil, &UnsupportedCommandError{Command: "FullscreenWindow", Err: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, nullValueError("/session/%s/window/fullscreen", wd.id)
+	}
+	return r, nil
+}
+
+func (wd *remoteWD) WindowRect(handle string) (*Rect, error) {
+	wd.checkDialect("WindowRect", handle)
+	return wd.windowRect(handle)
+}
+
+func (wd *remoteWD) windowRect(handle string) (*Rect, error) {
+	if !wd.w3cCompatible {
+		size := new(struct{ Width, Height float64 })
+		if err := wd.legacyWindowCommand("GET", handle, "size", nil, size); err != nil {
+			return nil, err
+		}
+		position := new(struct{ X, Y float64 })
+		if err := wd.legacyWindowCommand("GET", handle, "position", nil, position); err != nil {
+			return nil, err
+		}
+		return &Rect{X: position.X, Y: position.Y, Width: size.Width, Height: size.Height}, nil
+	}
+	var response []byte
+	err := wd.inWindow(handle, func() error {
+		var err error
+		response, err = wd.execute("GET", wd.requestURL("/session/%s/window/rect", wd.id), nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	reply := new(struct{ Value *Rect })
+	if err := decodeReply(response, reply, wd.strictDecoding, "/session/%s/window/rect", wd.id); err != nil {
+		return nil, err
+	}
+	if reply.Value == nil {
+		return nil, nullValueError("/session/%s/window/rect", wd.id)
+	}
+	return reply.Value, nil
+}
+
+// legacyWindowCommand sends a command of the legacy protocol on the window
+// with the given handle, or the current window if it is empty, such as
+// GET /session/{id}/window/{handle}/size. If value is not nil, the value of
+// the reply is decoded into it.
+func (wd *remoteWD) legacyWindowCommand(method, handle, endpoint string, params, value interface{}) error {
+	if handle == "" {
+		var err error
+		if handle, err = wd.CurrentWindowHandle(); err != nil {
+			return err
+		}
+	}
+	var data []byte
+	if params != nil {
+		var err error
+		if data, err = json.Marshal(params); err != nil {
+			return err
+		}
+	}
+	response, err := wd.execute(method, wd.requestURL("/session/%s/window/%s/%s", wd.id, handle, endpoint), data)
+	if err != nil || value == nil {
+		return err
+	}
+	if wd.strictDecoding {
+		if err := checkShape(response, value, "/session/%s/window/%s/%s", wd.id, handle, endpoint); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(response, &struct{ Value interface{} }{value})
+}
+
+func (wd *remoteWD) SetWindowRect(handle string, r Rect) error {
+	wd.checkDialect("SetWindowRect", handle)
+	if r.Width <= 0 || r.Height <= 0 {
+		return fmt.Errorf("invalid window size %vx%v: must be positive", r.Width, r.Height)
+	}
+	if wd.w3cCompatible {
+		_, err := wd.modifyWindow(handle, "rect", r)
+		return err
+	}
+	if handle == "" {
+		// Resolve the handle once for both commands.
+		var err error
+		if handle, err = wd.CurrentWindowHandle(); err != nil {
+			return err
+		}
+	}
+	if err := wd.legacyWindowCommand("POST", handle, "size", map[string]float64{"width": r.Width, "height": r.Height}, nil); err != nil {
+		return err
+	}
+	return wd.legacyWindowCommand("POST", handle, "position", map[string]float64{"x": r.X, "y": r.Y}, nil)
+}
+
+func (wd *remoteWD) GetWindowPosition(name string) (*Point, error) {
+	wd.checkDialect("GetWindowPosition", name)
+	if !wd.w3cCompatible {
+		p := new(Point)
+		if err := wd.legacyWindowCommand("GET", name, "position", nil, p); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+	r, err := wd.windowRect(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Point{X: int(r.X), Y: int(r.Y)}, nil
+}
+
+func (wd *remoteWD) SetWindowPosition(name string, x, y int) error {
+	wd.checkDialect("SetWindowPosition", name)
+	params := map[string]int{"x": x, "y": y}
+	if !wd.w3cCompatible {
+		return wd.legacyWindowCommand("POST", name, "position", params, nil)
+	}
+	// Leave out the size, which would otherwise be set to zero.
+	_, err := wd.modifyWindow(name, "rect", params)
+	return err
+}
+
+// Window types, for NewWindow.
+const (
+	WindowTypeTab    = "tab"
+	WindowTypeWindow = "window"
+)
+
+// The scripts with which NewWindow opens a window on legacy sessions.
+// Browsers open a tab unless window features are requested.
+const (
+	openTabScript    = `window.open('about:blank', '_blank');`
+	openWindowScript = `window.open('about:blank', '_blank', 'width=' + window.outerWidth + ',height=' + window.outerHeight);`
+)
+
+// newWindowTimeout is how long NewWindow waits for the handle of a window
+// opened by script to appear.
+const newWindowTimeout = 2 * time.Second
+
+func (wd *remoteWD) NewWindow(typ string) (handle, windowType string, err error) {
+	if typ != WindowTypeTab && typ != WindowTypeWindow {
+		return "", "", fmt.Errorf("invalid window type %q: must be %q or %q", typ, WindowTypeTab, WindowTypeWindow)
+	}
+	if !wd.w3cCompatible {
+		return wd.newWindowByScript(typ)
+	}
+	data, err := json.Marshal(map[string]string{"type": typ})
+	if err != nil {
+		return "", "", err
+	}
+	response, err := wd.execute("POST", wd.requestURL("/session/%s/window/new", wd.id), data)
+	if err != nil {
+		return "", "", err
+	}
+	reply := new(struct {
+		Value *struct {
+			Handle string
+			Type   string
+		}
+	})
+	if err := decodeReply(response, reply, wd.strictDecoding, "/session/%s/window/new", wd.id); err != nil {
+		return "", "", err
+	}
+	if reply.Value == nil || reply.Value.Handle == "" {
+		return "", "", nullValueError("/session/%s/window/new", wd.id)
+	}
+	wd.windowOrder.add(reply.Value.Handle)
+	return reply.Value.Handle, reply.Value.Type, nil
+}
+
+// newWindowByScript opens a window with window.open and finds its handle
+// among the handles that were not there before.
+func (wd *remoteWD) newWindowByScript(typ string) (string, string, error) {
+	before, err := wd.WindowHandles()
+	if err != nil {
+		return "", "", err
+	}
+	known := make(map[string]bool)
+	for _, h := range before {
+		known[h] = true
+	}
+	script := openTabScript
+	if typ == WindowTypeWindow {
+		script = openWindowScript
+	}
+	if _, err := wd.ExecuteScript(script, nil); err != nil {
+		return "", "", err
+	}
+
+	deadline := time.Now().Add(newWindowTimeout)
+	for {
+		after, err := wd.WindowHandles()
+		if err != nil {
+			return "", "", err
+		}
+		for _, h := range after {
+			if !known[h] {
+				return h, typ, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return "", "", fmt.Errorf("no new window appeared after opening one by script; it may have been blocked as a popup")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// WindowInfo describes a window, as returned by WebDriver.Windows.
+type WindowInfo struct {
+	Handle string
+	Title  string
+	URL    string
+}
+
+func (wd *remoteWD) Windows() ([]WindowInfo, error) {
+	frames := wd.FramePath()
+	startWindow, err := wd.CurrentWindowHandle()
+	if isNoSuchWindow(err) {
+		// The current window was closed; there is nothing to switch back to.
+		startWindow, err = "", nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	handles, err := wd.WindowHandles()
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []WindowInfo
+	closed := new(MultiError)
+	for _, handle := range handles {
+		var info WindowInfo
+		info, err = wd.windowInfo(handle)
+		if isNoSuchWindow(err) {
+			closed.Append(fmt.Sprintf("window %q", handle), err)
+			err = nil
+			continue
+		}
+		if err != nil {
+			break
+		}
+		infos = append(infos, info)
+	}
+
+	if startWindow != "" {
+		// Switching windows leaves the frames of the original window.
+		if switchErr := wd.switchToHandle(startWindow); isNoSuchWindow(switchErr) {
+			closed.Append(fmt.Sprintf("window %q", startWindow), switchErr)
+		} else if switchErr != nil {
+			if err == nil {
+				err = switchErr
+			}
+		} else if len(frames) > 0 {
+			if enterErr := wd.enterFramePath(frames); err == nil {
+				err = enterErr
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return infos, closed.ErrorOrNil()
+}
+
+// windowInfo switches to the window with the given handle and describes it.
+func (wd *remoteWD) windowInfo(handle string) (WindowInfo, error) {
+	info := WindowInfo{Handle: handle}
+	if err := wd.switchToHandle(handle); err != nil {
+		return info, err
+	}
+	var err error
+	if info.Title, err = wd.Title(); err != nil {
+		return info, err
+	}
+	info.URL, err = wd.CurrentURL()
+	return info, err
+}
+
+// WindowSortKey is the order in which WebDriver.WindowsSorted returns the
+// windows.
+type WindowSortKey int
+
+const (
+	// WindowsByOpenOrder sorts the windows in the order in which the
+	// WebDriver first saw their handles, in WindowHandles or NewWindow. This
+	// is the order in which they were opened if the handles were listed after
+	// each window opened, and approximates it otherwise.
+	WindowsByOpenOrder WindowSortKey = iota
+	// WindowsByTitle sorts the windows by title.
+	WindowsByTitle
+	// WindowsByURL sorts the windows by URL.
+	WindowsByURL
+)
+
+// windowRegistry remembers the order in which the handles of the session's
+// windows were first seen, as remote ends list them in an unspecified order
+// that may change from one call to the next.
+type windowRegistry struct {
+	// order maps the handles of the open windows to the number of handles
+	// seen before them.
+	order map[string]int
+	seen  int
+}
+
+// add registers the handle, unless it is already registered.
+func (r *windowRegistry) add(handle string) {
+	if r.order == nil {
+		r.order = make(map[string]int)
+	}
+	if _, ok := r.order[handle]; !ok {
+		r.order[handle] = r.seen
+		r.seen++
+	}
+}
+
+// update registers the handles of all the open windows, in the order listed,
+// and forgets those of the windows that were closed.
+func (r *windowRegistry) update(handles []string) {
+	open := make(map[string]bool, len(handles))
+	for _, h := range handles {
+		r.add(h)
+		open[h] = true
+	}
+	for h := range r.order {
+		if !open[h] {
+			delete(r.order, h)
+		}
+	}
+}
+
+// less reports whether handle a was seen before handle b. Unknown handles
+// come last.
+func (r *windowRegistry) less(a, b string) bool {
+	i, ok := r.order[a]
+	if !ok {
+		return false
+	}
+	j, ok := r.order[b]
+	return !ok || i < j
+}
+
+func (wd *remoteWD) WindowsSorted(by WindowSortKey) ([]WindowInfo, error) {
+	var key func(WindowInfo) string
+	switch by {
+	case WindowsByOpenOrder:
+	case WindowsByTitle:
+		key = func(info WindowInfo) string { return info.Title }
+	case WindowsByURL:
+		key = func(info WindowInfo) string { return info.URL }
+	default:
+		return nil, fmt.Errorf("invalid window sort key %d", by)
+	}
+	infos, err := wd.Windows()
+	if infos == nil {
+		return nil, err
+	}
+	// Windows with the same title or URL are in the order of the handles.
+	sort.SliceStable(infos, func(i, j int) bool {
+		if key != nil {
+			if a, b := key(infos[i]), key(infos[j]); a != b {
+				return a < b
+			}
+		}
+		return wd.windowOrder.less(infos[i].Handle, infos[j].Handle)
+	})
+	return infos, err
+}
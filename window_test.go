@@ -0,0 +1,658 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// windowServer emulates the window endpoints of a W3C or legacy remote end
+// that ends the session when its last window is closed.
+type windowServer struct {
+	w3c     bool
+	windows []string
+	current string
+	ended   bool
+	// opened counts the windows opened with the New Window command or by
+	// script.
+	opened int
+	// states records the last command that modified each window, such as
+	// "minimize". If unsupported is set, these commands are unknown.
+	states      map[string]string
+	unsupported bool
+	// rects holds the rectangle of each window.
+	rects map[string]Rect
+	// frames holds the frames switched to in the current window.
+	frames []interface{}
+	// intercept, if set, is called first with each request, and returns true
+	// if it handled it.
+	intercept func(w http.ResponseWriter, r *http.Request) bool
+}
+
+// windowRect handles the commands that get and set the rectangle of a window,
+// and returns false for other requests.
+func (s *windowServer) windowRect(r *http.Request, reply func(interface{})) bool {
+	handle, endpoint := s.current, strings.TrimPrefix(r.URL.Path, "/session/123/window/")
+	if parts := strings.Split(endpoint, "/"); len(parts) == 2 && !s.w3c {
+		handle, endpoint = parts[0], parts[1]
+	}
+	if endpoint != "rect" && endpoint != "size" && endpoint != "position" {
+		return false
+	}
+	if s.rects == nil {
+		s.rects = make(map[string]Rect)
+	}
+	rect := s.rects[handle]
+	if r.Method == "POST" {
+		params := make(map[string]float64)
+		json.NewDecoder(r.Body).Decode(&params)
+		for k, v := range params {
+			switch k {
+			case "x":
+				rect.X = v
+			case "y":
+				rect.Y = v
+			case "width":
+				rect.Width = v
+			case "height":
+				rect.Height = v
+			}
+		}
+		s.rects[handle] = rect
+	}
+	switch endpoint {
+	case "size":
+		reply(map[string]float64{"width": rect.Width, "height": rect.Height})
+	case "position":
+		reply(map[string]float64{"x": rect.X, "y": rect.Y})
+	default:
+		reply(rect)
+	}
+	return true
+}
+
+func (s *windowServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", JSONType)
+	if s.ended {
+		w.WriteHeader(http.StatusNotFound)
+		if s.w3c {
+			fmt.Fprint(w, `{"value":{"error":"invalid session id","message":"session deleted","stacktrace":""}}`)
+		} else {
+			fmt.Fprint(w, `{"sessionId":"123","status":6,"value":{"message":"session deleted"}}`)
+		}
+		return
+	}
+	if s.intercept != nil && s.intercept(w, r) {
+		return
+	}
+	reply := func(v interface{}) {
+		b, _ := json.Marshal(v)
+		fmt.Fprintf(w, `{"sessionId":"123","status":0,"value":%s}`, b)
+	}
+	if s.windowRect(r, reply) {
+		return
+	}
+	switch {
+	case r.Method == "DELETE" && r.URL.Path == "/session/123/window":
+		var remaining []string
+		for _, h := range s.windows {
+			if h != s.current {
+				remaining = append(remaining, h)
+			}
+		}
+		s.windows = remaining
+		s.current = ""
+		if len(remaining) == 0 {
+			s.ended = true
+		}
+		if s.w3c {
+			reply(append([]string{}, remaining...))
+		} else {
+			reply(nil)
+		}
+	case r.Method == "POST" && r.URL.Path == "/session/123/window":
+		body, _ := ioutil.ReadAll(r.Body)
+		params := make(map[string]string)
+		json.Unmarshal(body, &params)
+		handle := params["handle"] + params["name"]
+		for _, h := range s.windows {
+			if h == handle {
+				s.current = handle
+				s.frames = nil
+				reply(nil)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		if s.w3c {
+			fmt.Fprint(w, `{"value":{"error":"no such window","message":"no window `+handle+`","stacktrace":""}}`)
+		} else {
+			fmt.Fprint(w, `{"sessionId":"123","status":23,"value":{"message":"no window `+handle+`"}}`)
+		}
+	case r.Method == "GET" && r.URL.Path == "/session/123/title":
+		reply("Window " + s.current)
+	case r.Method == "GET" && r.URL.Path == "/session/123/url":
+		reply("http://example.com/" + s.current)
+	case r.Method == "POST" && (r.URL.Path == "/session/123/window/new" || r.URL.Path == "/session/123/execute"):
+		s.opened++
+		handle := fmt.Sprintf("new%d", s.opened)
+		s.windows = append(s.windows, handle)
+		if r.URL.Path == "/session/123/window/new" {
+			params := make(map[string]string)
+			json.NewDecoder(r.Body).Decode(&params)
+			reply(map[string]string{"handle": handle, "type": params["type"]})
+		} else {
+			reply(nil)
+		}
+	case r.Method == "GET" && (r.URL.Path == "/session/123/window" || r.URL.Path == "/session/123/window_handle"):
+		reply(s.current)
+	case r.Method == "POST" && strings.HasPrefix(r.URL.Path, "/session/123/window/") && !strings.Contains(r.URL.Path[len("/session/123/window/"):], "/"):
+		if s.unsupported {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"value":{"error":"unknown command","message":"not implemented"}}`)
+			return
+		}
+		command := path.Base(r.URL.Path)
+		if s.states == nil {
+			s.states = make(map[string]string)
+		}
+		s.states[s.current] = command
+		if command == "fullscreen" {
+			reply(map[string]int{"x": 0, "y": 0, "width": 1920, "height": 1080})
+		} else {
+			reply(map[string]int{"x": 10, "y": 20, "width": 800, "height": 600})
+		}
+	case r.URL.Path == "/session/123/window_handles" && !s.w3c, r.URL.Path == "/session/123/window/handles" && s.w3c:
+		reply(s.windows)
+	case r.Method == "POST" && r.URL.Path == "/session/123/frame":
+		params := make(map[string]interface{})
+		json.NewDecoder(r.Body).Decode(&params)
+		if params["id"] == nil {
+			s.frames = nil
+		} else {
+			s.frames = append(s.frames, params["id"])
+		}
+		reply(nil)
+	case r.Method == "DELETE" && r.URL.Path == "/session/123":
+		reply(nil)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestCloseAndSwitch(t *testing.T) {
+	for _, w3c := range []bool{false, true} {
+		t.Run(fmt.Sprintf("W3C=%t", w3c), func(t *testing.T) {
+			ws := &windowServer{w3c: w3c, windows: []string{"a", "b", "c"}, current: "b"}
+			s := httptest.NewServer(ws)
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+
+			remaining, err := wd.CloseAndSwitch()
+			if err != nil {
+				t.Fatalf("wd.CloseAndSwitch() returned error: %v", err)
+			}
+			if len(remaining) != 2 || remaining[0] != "a" || remaining[1] != "c" {
+				t.Errorf("wd.CloseAndSwitch() = %v, want [a c]", remaining)
+			}
+			if ws.current != "a" {
+				t.Errorf("after wd.CloseAndSwitch(), the current window is %q, want %q", ws.current, "a")
+			}
+
+			wd.SetSwitchOnClose(false)
+			if err := wd.Close(); err != nil {
+				t.Fatalf("wd.Close() returned error: %v", err)
+			}
+			if ws.current != "" {
+				t.Errorf("wd.Close() with switching disabled switched to %q", ws.current)
+			}
+
+			ws.current = "c"
+			if err := wd.Close(); err != ErrLastWindowClosed {
+				t.Fatalf("closing the last window returned error %v, want ErrLastWindowClosed", err)
+			}
+			if err := wd.Quit(); err != nil {
+				t.Errorf("wd.Quit() after closing the last window returned error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSwitchWindow(t *testing.T) {
+	for _, w3c := range []bool{false, true} {
+		t.Run(fmt.Sprintf("W3C=%t", w3c), func(t *testing.T) {
+			var requests []string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				requests = append(requests, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, body))
+				w.Header().Set("Content-Type", JSONType)
+				fmt.Fprint(w, `{"sessionId":"123","status":0,"value":null}`)
+			}))
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL + "/wd/hub", w3cCompatible: w3c}
+
+			if err := wd.SwitchWindow("b"); err != nil {
+				t.Fatalf(`wd.SwitchWindow("b") returned error: %v`, err)
+			}
+			want := `POST /wd/hub/session/123/window {"handle":"b"}`
+			if !w3c {
+				want = `POST /wd/hub/session/123/window {"name":"b"}`
+			}
+			if len(requests) != 1 || requests[0] != want {
+				t.Errorf(`wd.SwitchWindow("b") sent %q, want [%q]`, requests, want)
+			}
+		})
+	}
+}
+
+func TestModifyBackgroundWindow(t *testing.T) {
+	ws := &windowServer{w3c: true, windows: []string{"a", "b"}, current: "a"}
+	s := httptest.NewServer(ws)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	if err := wd.MaximizeWindow("b"); err != nil {
+		t.Fatalf(`wd.MaximizeWindow("b") returned error: %v`, err)
+	}
+	if ws.states["b"] != "maximize" || ws.states["a"] != "" {
+		t.Errorf(`wd.MaximizeWindow("b") modified the windows %v, want only b`, ws.states)
+	}
+	if ws.current != "a" {
+		t.Errorf(`after wd.MaximizeWindow("b"), the current window is %q, want "a"`, ws.current)
+	}
+}
+
+func TestWindows(t *testing.T) {
+	for _, w3c := range []bool{false, true} {
+		t.Run(fmt.Sprintf("W3C=%t", w3c), func(t *testing.T) {
+			ws := &windowServer{w3c: w3c, windows: []string{"a", "b", "c"}, current: "b"}
+			s := httptest.NewServer(ws)
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+
+			infos, err := wd.Windows()
+			if err != nil {
+				t.Fatalf("wd.Windows() returned error: %v", err)
+			}
+			want := []WindowInfo{
+				{"a", "Window a", "http://example.com/a"},
+				{"b", "Window b", "http://example.com/b"},
+				{"c", "Window c", "http://example.com/c"},
+			}
+			if !reflect.DeepEqual(infos, want) {
+				t.Errorf("wd.Windows() = %+v, want %+v", infos, want)
+			}
+			if ws.current != "b" {
+				t.Errorf("after wd.Windows(), the current window is %q, want %q", ws.current, "b")
+			}
+
+			// Window a is closed once the handles are listed.
+			ws.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+				if !strings.HasSuffix(r.URL.Path, "handles") {
+					return false
+				}
+				fmt.Fprint(w, `{"sessionId":"123","status":0,"value":["a","b","c"]}`)
+				ws.windows = []string{"b", "c"}
+				return true
+			}
+			infos, err = wd.Windows()
+			if err == nil || !strings.Contains(err.Error(), `"a"`) {
+				t.Errorf("wd.Windows() with a window closing returned error %v, want one naming it", err)
+			}
+			if !reflect.DeepEqual(infos, want[1:]) {
+				t.Errorf("wd.Windows() with a window closing = %+v, want %+v", infos, want[1:])
+			}
+			if ws.current != "b" {
+				t.Errorf("after wd.Windows(), the current window is %q, want %q", ws.current, "b")
+			}
+
+			// Getting the title of window c fails.
+			ws.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+				if ws.current != "c" || r.URL.Path != "/session/123/title" {
+					return false
+				}
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"sessionId":"123","status":13,"value":{"error":"unknown error","message":"title failed"}}`)
+				return true
+			}
+			if _, err := wd.Windows(); err == nil {
+				t.Errorf("wd.Windows() with a failing query returned nil error")
+			}
+			if ws.current != "b" {
+				t.Errorf("after wd.Windows() failed, the current window is %q, want %q", ws.current, "b")
+			}
+
+			// The frame that was current is switched back to.
+			ws.intercept = nil
+			if err := wd.enterFramePath([]interface{}{1}); err != nil {
+				t.Fatalf("wd.enterFramePath() returned error: %v", err)
+			}
+			if _, err := wd.Windows(); err != nil {
+				t.Fatalf("wd.Windows() in a frame returned error: %v", err)
+			}
+			if want := []interface{}{float64(1)}; ws.current != "b" || !reflect.DeepEqual(ws.frames, want) {
+				t.Errorf("after wd.Windows() in a frame, the current window is %q with frames %v, want %q with %v", ws.current, ws.frames, "b", want)
+			}
+			if got, want := wd.FramePath(), []interface{}{1}; !reflect.DeepEqual(got, want) {
+				t.Errorf("after wd.Windows() in a frame, wd.FramePath() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCloseWindow(t *testing.T) {
+	for _, w3c := range []bool{false, true} {
+		t.Run(fmt.Sprintf("W3C=%t", w3c), func(t *testing.T) {
+			ws := &windowServer{w3c: w3c, windows: []string{"a", "b", "c"}, current: "b"}
+			s := httptest.NewServer(ws)
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+
+			remaining, err := wd.CloseWindow("c")
+			if err != nil {
+				t.Fatalf(`wd.CloseWindow("c") returned error: %v`, err)
+			}
+			if len(remaining) != 2 || remaining[0] != "a" || remaining[1] != "b" {
+				t.Errorf(`wd.CloseWindow("c") = %v, want [a b]`, remaining)
+			}
+			if ws.current != "b" {
+				t.Errorf(`after wd.CloseWindow("c"), the current window is %q, want "b"`, ws.current)
+			}
+
+			remaining, err = wd.CloseWindow("b")
+			if err != nil {
+				t.Fatalf(`wd.CloseWindow("b") returned error: %v`, err)
+			}
+			if len(remaining) != 1 || remaining[0] != "a" {
+				t.Errorf(`wd.CloseWindow("b") = %v, want [a]`, remaining)
+			}
+			if ws.current != "" {
+				t.Errorf(`after closing the current window, the current window is %q, want none`, ws.current)
+			}
+
+			ws.current = "a"
+			if _, err := wd.CloseWindow("a"); err != ErrLastWindowClosed {
+				t.Fatalf("closing the last window returned error %v, want ErrLastWindowClosed", err)
+			}
+			if err := wd.Quit(); err != nil {
+				t.Errorf("wd.Quit() after closing the last window returned error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewWindow(t *testing.T) {
+	for _, w3c := range []bool{false, true} {
+		t.Run(fmt.Sprintf("W3C=%t", w3c), func(t *testing.T) {
+			ws := &windowServer{w3c: w3c, windows: []string{"a"}, current: "a"}
+			s := httptest.NewServer(ws)
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+
+			handle, typ, err := wd.NewWindow(WindowTypeTab)
+			if err != nil {
+				t.Fatalf("wd.NewWindow() returned error: %v", err)
+			}
+			if handle != "new1" || typ != WindowTypeTab {
+				t.Errorf("wd.NewWindow() = %q, %q; want %q, %q", handle, typ, "new1", WindowTypeTab)
+			}
+			if err := wd.switchToHandle(handle); err != nil {
+				t.Fatalf("switching to the new tab returned error: %v", err)
+			}
+			if ws.current != handle {
+				t.Errorf("the current window is %q, want the new tab", ws.current)
+			}
+			remaining, err := wd.CloseAndSwitch()
+			if err != nil {
+				t.Fatalf("closing the new tab returned error: %v", err)
+			}
+			if len(remaining) != 1 || remaining[0] != "a" || ws.current != "a" {
+				t.Errorf("after closing the new tab, the windows are %v and the current one %q, want [a] and a", remaining, ws.current)
+			}
+
+			if _, _, err := wd.NewWindow("popup"); err == nil {
+				t.Errorf("wd.NewWindow() of an invalid type returned nil error")
+			}
+		})
+	}
+}
+
+func TestMinimizeWindow(t *testing.T) {
+	ws := &windowServer{w3c: true, windows: []string{"a", "b"}, current: "a"}
+	s := httptest.NewServer(ws)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	for _, name := range []string{"", "a"} {
+		ws.states = nil
+		if err := wd.MinimizeWindow(name); err != nil {
+			t.Fatalf("wd.MinimizeWindow(%q) returned error: %v", name, err)
+		}
+		if ws.states["a"] != "minimize" || ws.current != "a" {
+			t.Errorf("after wd.MinimizeWindow(%q), the windows are %v and the current one %q, want a minimized and current", name, ws.states, ws.current)
+		}
+	}
+
+	ws.unsupported = true
+	err := wd.MinimizeWindow("")
+	e, ok := err.(*UnsupportedCommandError)
+	if !ok || e.Command != "MinimizeWindow" || !isUnknownCommand(e.Err) {
+		t.Errorf("wd.MinimizeWindow() on a remote end without the command returned error %#v, want an *UnsupportedCommandError wrapping unknown command", err)
+	}
+
+	wd.w3cCompatible = false
+	ws.unsupported = false
+	ws.states = nil
+	err = wd.MinimizeWindow("")
+	if e, ok := err.(*UnsupportedCommandError); !ok || e.Err != nil {
+		t.Errorf("wd.MinimizeWindow() on a legacy session returned error %#v, want an *UnsupportedCommandError", err)
+	}
+	if len(ws.states) != 0 {
+		t.Errorf("wd.MinimizeWindow() on a legacy session sent a command: %v", ws.states)
+	}
+}
+
+func TestFullscreenWindow(t *testing.T) {
+	ws := &windowServer{w3c: true, windows: []string{"a", "b"}, current: "a"}
+	s := httptest.NewServer(ws)
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+
+	r, err := wd.FullscreenWindow("")
+	if err != nil {
+		t.Fatalf("wd.FullscreenWindow() returned error: %v", err)
+	}
+	if want := (Rect{Width: 1920, Height: 1080}); *r != want {
+		t.Errorf("wd.FullscreenWindow() = %+v, want %+v", *r, want)
+	}
+	if ws.states["a"] != "fullscreen" {
+		t.Errorf("after wd.FullscreenWindow(), the windows are %v, want a fullscreen", ws.states)
+	}
+
+	ws.unsupported = true
+	if _, err := wd.FullscreenWindow(""); !isUnknownCommand(err) {
+		t.Errorf("wd.FullscreenWindow() on a remote end without the command returned error %v, want unknown command", err)
+	} else if _, ok := err.(*UnsupportedCommandError); !ok {
+		t.Errorf("wd.FullscreenWindow() returned error %#v, want an *UnsupportedCommandError", err)
+	}
+
+	wd.w3cCompatible = false
+	if _, err := wd.FullscreenWindow(""); err == nil {
+		t.Errorf("wd.FullscreenWindow() on a legacy session returned nil error")
+	}
+}
+
+func TestWindowRect(t *testing.T) {
+	for _, w3c := range []bool{false, true} {
+		t.Run(fmt.Sprintf("W3C=%t", w3c), func(t *testing.T) {
+			ws := &windowServer{w3c: w3c, windows: []string{"a"}, current: "a",
+				rects: map[string]Rect{"a": {X: 1, Y: 2, Width: 300, Height: 400}}}
+			s := httptest.NewServer(ws)
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+
+			r, err := wd.WindowRect("")
+			if err != nil {
+				t.Fatalf("wd.WindowRect() returned error: %v", err)
+			}
+			if want := (Rect{X: 1, Y: 2, Width: 300, Height: 400}); *r != want {
+				t.Errorf("wd.WindowRect() = %+v, want %+v", *r, want)
+			}
+
+			want := Rect{X: 50, Y: 60, Width: 1024, Height: 768}
+			if err := wd.SetWindowRect("", want); err != nil {
+				t.Fatalf("wd.SetWindowRect() returned error: %v", err)
+			}
+			if got := ws.rects["a"]; got != want {
+				t.Errorf("after wd.SetWindowRect(), the window rectangle is %+v, want %+v", got, want)
+			}
+
+			// Resizing keeps the position of the window.
+			if err := wd.ResizeWindow("", 800, 600); err != nil {
+				t.Fatalf("wd.ResizeWindow() returned error: %v", err)
+			}
+			if got, want := ws.rects["a"], (Rect{X: 50, Y: 60, Width: 800, Height: 600}); got != want {
+				t.Errorf("after wd.ResizeWindow(), the window rectangle is %+v, want %+v", got, want)
+			}
+
+			if err := wd.SetWindowRect("", Rect{}); err == nil {
+				t.Errorf("wd.SetWindowRect() with an empty rectangle returned nil error")
+			}
+		})
+	}
+}
+
+func TestWindowPosition(t *testing.T) {
+	for _, w3c := range []bool{false, true} {
+		t.Run(fmt.Sprintf("W3C=%t", w3c), func(t *testing.T) {
+			ws := &windowServer{w3c: w3c, windows: []string{"a"}, current: "a",
+				rects: map[string]Rect{"a": {X: 1, Y: 2, Width: 300, Height: 400}}}
+			s := httptest.NewServer(ws)
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+
+			p, err := wd.GetWindowPosition("")
+			if err != nil {
+				t.Fatalf("wd.GetWindowPosition() returned error: %v", err)
+			}
+			if *p != (Point{1, 2}) {
+				t.Errorf("wd.GetWindowPosition() = %+v, want {1 2}", *p)
+			}
+			if err := wd.SetWindowPosition("a", 1920, -10); err != nil {
+				t.Fatalf("wd.SetWindowPosition() returned error: %v", err)
+			}
+			if got, want := ws.rects["a"], (Rect{X: 1920, Y: -10, Width: 300, Height: 400}); got != want {
+				t.Errorf("after wd.SetWindowPosition(), the window rectangle is %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestWindowHandles(t *testing.T) {
+	for _, w3c := range []bool{false, true} {
+		t.Run(fmt.Sprintf("W3C=%t", w3c), func(t *testing.T) {
+			// The server only serves the route of its protocol.
+			ws := &windowServer{w3c: w3c, windows: []string{"a", "b"}, current: "a"}
+			s := httptest.NewServer(ws)
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+
+			handles, err := wd.WindowHandles()
+			if err != nil {
+				t.Fatalf("wd.WindowHandles() returned error: %v", err)
+			}
+			if len(handles) != 2 || handles[0] != "a" || handles[1] != "b" {
+				t.Errorf("wd.WindowHandles() = %v, want [a b]", handles)
+			}
+
+			ws.ended = true
+			_, err = wd.WindowHandles()
+			if !isInvalidSession(err) {
+				t.Errorf("wd.WindowHandles() of an ended session returned error %v, want invalid session id", err)
+			}
+			if !w3c && !strings.Contains(fmt.Sprint(err), "GET /session/123/window_handles") {
+				t.Errorf("wd.WindowHandles() returned error %q, want it to name the endpoint", err)
+			}
+		})
+	}
+}
+
+func TestWindowsSorted(t *testing.T) {
+	for _, w3c := range []bool{false, true} {
+		t.Run(fmt.Sprintf("W3C=%t", w3c), func(t *testing.T) {
+			ws := &windowServer{w3c: w3c, windows: []string{"c", "a", "b"}, current: "a"}
+			s := httptest.NewServer(ws)
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: w3c}
+
+			// The handles are listed in the order in which the windows were
+			// opened the first time, and then shuffled, with duplicates.
+			if _, err := wd.WindowHandles(); err != nil {
+				t.Fatalf("wd.WindowHandles() returned error: %v", err)
+			}
+			if _, _, err := wd.NewWindow(WindowTypeTab); err != nil {
+				t.Fatalf("wd.NewWindow() returned error: %v", err)
+			}
+			listed := 0
+			ws.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+				if !strings.HasSuffix(r.URL.Path, "handles") {
+					return false
+				}
+				listed++
+				handles := append([]string{}, ws.windows...)
+				rand.New(rand.NewSource(int64(listed))).Shuffle(len(handles), func(i, j int) {
+					handles[i], handles[j] = handles[j], handles[i]
+				})
+				handles = append(handles, handles[0])
+				b, _ := json.Marshal(handles)
+				fmt.Fprintf(w, `{"sessionId":"123","status":0,"value":%s}`, b)
+				return true
+			}
+
+			for _, tc := range []struct {
+				by   WindowSortKey
+				want []string
+			}{
+				{WindowsByOpenOrder, []string{"c", "a", "b", "new1"}},
+				{WindowsByTitle, []string{"a", "b", "c", "new1"}},
+				{WindowsByURL, []string{"a", "b", "c", "new1"}},
+			} {
+				for i := 0; i < 3; i++ {
+					infos, err := wd.WindowsSorted(tc.by)
+					if err != nil {
+						t.Fatalf("wd.WindowsSorted(%d) returned error: %v", tc.by, err)
+					}
+					var got []string
+					for _, info := range infos {
+						got = append(got, info.Handle)
+					}
+					if !reflect.DeepEqual(got, tc.want) {
+						t.Errorf("wd.WindowsSorted(%d) returned windows %v, want %v", tc.by, got, tc.want)
+					}
+				}
+			}
+			if ws.current != "a" {
+				t.Errorf("after wd.WindowsSorted(), the current window is %q, want %q", ws.current, "a")
+			}
+
+			handles, err := wd.WindowHandles()
+			if err != nil {
+				t.Fatalf("wd.WindowHandles() returned error: %v", err)
+			}
+			if len(handles) != 4 {
+				t.Errorf("wd.WindowHandles() = %v, want 4 distinct handles", handles)
+			}
+
+			if _, err := wd.WindowsSorted(WindowSortKey(42)); err == nil {
+				t.Error("wd.WindowsSorted() with an invalid key returned nil error")
+			}
+		})
+	}
+}
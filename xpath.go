@@ -0,0 +1,63 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RewriteRelativeXPath controls the behavior of the relative XPath check
+// enabled by WebDriver.SetRelativeXPathCheck. If false, element-scoped XPath
+// queries that start with "/" return an error. If true, those that start with
+// "//" are rewritten to search the element's descendants by replacing the
+// leading slashes with ".//". Absolute location paths, which start with a
+// single "/" such as "/html/body", still return an error, as no relative
+// expression selects the same nodes.
+var RewriteRelativeXPath = false
+
+func (wd *remoteWD) SetRelativeXPathCheck(enabled bool) {
+	wd.relativeXPathCheck = enabled
+}
+
+// checkRelativeXPath returns the XPath expression to use when searching the
+// subtree of an element with value. Per the XPath specification, expressions
+// starting with "/" select nodes relative to the document root, not to the
+// element, which is rarely what is intended.
+func checkRelativeXPath(value string, rewrite bool) (string, error) {
+	trimmed := strings.TrimLeft(value, " \t\r\n")
+	if !strings.HasPrefix(trimmed, "/") {
+		return value, nil
+	}
+	if !strings.HasPrefix(trimmed, "//") {
+		return "", fmt.Errorf("XPath expression %q is an absolute location path and would search from the document root rather than the element; use an expression relative to the element, starting with %q", value, "./")
+	}
+	if rewrite {
+		return ".//" + strings.TrimLeft(trimmed, "/"), nil
+	}
+	return "", fmt.Errorf("XPath expression %q starts with %q and would search the whole document rather than the element's subtree; use %q instead", value, "/", ".//"+strings.TrimLeft(trimmed, "/"))
+}
+
+// findFrom finds elements within the subtree of elem.
+func (elem *remoteWE) findFrom(by, value, suffix string) ([]byte, error) {
+	if err := elem.checkID(); err != nil {
+		return nil, err
+	}
+	wd := elem.parent
+	query := value
+	if strategy, err := LocatorStrategyOf(by); err == nil && strategy == ByXPATH && wd.relativeXPathCheck {
+		if query, err = checkRelativeXPath(value, RewriteRelativeXPath); err != nil {
+			return nil, err
+		}
+	}
+	url := fmt.Sprintf("/session/%%s/element/%s/element", elem.id)
+	response, err := wd.find(by, query, suffix, url)
+	if err != nil {
+		elem.noteError(err)
+		return nil, &ElementError{Op: "find", Locator: Locator{by, value}, Element: elem.provenance(), Err: err}
+	}
+	return response, nil
+}
+
+func (elem *remoteWE) FindElementRaw(by, value string) (json.RawMessage, error) {
+	return elem.findFrom(by, value, "")
+}
@@ -0,0 +1,92 @@
+package selenium
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckRelativeXPath(t *testing.T) {
+	tests := []struct {
+		value, rewritten string
+		rejected         bool
+		// absolute is set for absolute location paths, which are rejected
+		// even if rewriting is enabled.
+		absolute bool
+	}{
+		{value: ".//div", rewritten: ".//div"},
+		{value: "./div", rewritten: "./div"},
+		{value: "div/span", rewritten: "div/span"},
+		{value: "//div", rewritten: ".//div", rejected: true},
+		{value: " //div[@id='x']", rewritten: ".//div[@id='x']", rejected: true},
+		{value: "/html/body/div", rejected: true, absolute: true},
+		{value: "/html/body", rejected: true, absolute: true},
+	}
+	for _, tc := range tests {
+		got, err := checkRelativeXPath(tc.value, true)
+		if tc.absolute {
+			if err == nil {
+				t.Errorf("checkRelativeXPath(%q, true) = %q, want an error", tc.value, got)
+			}
+		} else if err != nil {
+			t.Errorf("checkRelativeXPath(%q, true) returned error: %v", tc.value, err)
+		} else if got != tc.rewritten {
+			t.Errorf("checkRelativeXPath(%q, true) = %q, want %q", tc.value, got, tc.rewritten)
+		}
+
+		got, err = checkRelativeXPath(tc.value, false)
+		if tc.rejected {
+			if err == nil {
+				t.Errorf("checkRelativeXPath(%q, false) = %q, want an error", tc.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.value {
+			t.Errorf("checkRelativeXPath(%q, false) = %q, %v, want %q, nil", tc.value, got, err, tc.value)
+		}
+	}
+}
+
+func TestRelativeXPathCheck(t *testing.T) {
+	var queries []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		queries = append(queries, string(body))
+		w.Header().Set("Content-Type", JSONType)
+		w.Write([]byte(`{"value":{"element-6066-11e4-a52e-4f735466cecf":"child","vendor:extra":"1"}}`))
+	}))
+	defer s.Close()
+	wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true}
+	elem := &remoteWE{parent: wd, id: "parent"}
+
+	// Without the check, the expression is passed through unchanged.
+	if _, err := elem.FindElement(ByXPATH, "//div"); err != nil {
+		t.Fatalf("elem.FindElement() returned error: %v", err)
+	}
+
+	wd.SetRelativeXPathCheck(true)
+	if _, err := elem.FindElement(ByXPATH, "//div"); err == nil {
+		t.Fatalf("elem.FindElement() with the check enabled returned nil error")
+	}
+	if len(queries) != 1 {
+		t.Fatalf("a rejected query made a request; got %d requests, want 1", len(queries))
+	}
+
+	defer func(old bool) { RewriteRelativeXPath = old }(RewriteRelativeXPath)
+	RewriteRelativeXPath = true
+	raw, err := elem.FindElementRaw(ByXPATH, "//div")
+	if err != nil {
+		t.Fatalf("elem.FindElementRaw() returned error: %v", err)
+	}
+	if !strings.Contains(queries[1], `".//div"`) {
+		t.Errorf("elem.FindElementRaw() sent %s, want the rewritten expression .//div", queries[1])
+	}
+	if !strings.Contains(string(raw), "vendor:extra") {
+		t.Errorf("elem.FindElementRaw() = %s, want the undecoded response", raw)
+	}
+	if _, err := wd.DecodeElement(raw); err != nil {
+		t.Errorf("wd.DecodeElement(raw) returned error: %v", err)
+	}
+}
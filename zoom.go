@@ -0,0 +1,179 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ZoomMethod identifies how SetPageZoom zooms the page.
+type ZoomMethod int
+
+const (
+	// ZoomNone means the page has not been zoomed in this session.
+	ZoomNone ZoomMethod = iota
+	// ZoomCDP sets the page scale factor through the Chrome DevTools Protocol,
+	// on Chromium-based browsers.
+	ZoomCDP
+	// ZoomFirefoxChrome sets the full zoom of the current tab from Firefox's
+	// chrome context, as the zoom menu does. It requires a remote end that
+	// permits switching to the chrome context.
+	ZoomFirefoxChrome
+	// ZoomCSSTransform scales the body element with a CSS transform. It is
+	// used when neither of the other methods is available, and only
+	// approximates zooming: the page is not laid out again at the smaller
+	// viewport width, fixed-position elements and viewport units are not
+	// scaled, and the zoom is lost when the page is navigated away from or
+	// reloaded.
+	ZoomCSSTransform
+)
+
+// String implements the fmt.Stringer interface.
+func (m ZoomMethod) String() string {
+	switch m {
+	case ZoomNone:
+		return "none"
+	case ZoomCDP:
+		return "Chrome DevTools Protocol page scale factor"
+	case ZoomFirefoxChrome:
+		return "Firefox full zoom"
+	case ZoomCSSTransform:
+		return "CSS transform"
+	}
+	return fmt.Sprintf("ZoomMethod(%d)", int(m))
+}
+
+// Emulated returns whether the method only approximates the browser's zoom.
+// See ZoomCSSTransform for the limitations.
+func (m ZoomMethod) Emulated() bool {
+	return m == ZoomCSSTransform
+}
+
+const (
+	firefoxSetZoomScript = `gBrowser.selectedBrowser.fullZoom = arguments[0];`
+	firefoxGetZoomScript = `return gBrowser.selectedBrowser.fullZoom;`
+
+	cssSetZoomScript = `
+var body = document.body, factor = arguments[0];
+body.style.transformOrigin = '0 0';
+body.style.transform = factor === 1 ? '' : 'scale(' + factor + ')';
+body.setAttribute('data-selenium-zoom', factor);`
+	cssGetZoomScript = `
+var zoom = document.body && document.body.getAttribute('data-selenium-zoom');
+return zoom ? parseFloat(zoom) : 1;`
+
+	cdpGetZoomScript = `return window.visualViewport ? window.visualViewport.scale : 1;`
+)
+
+// browserName returns the lower-cased name of the session's browser, as
+// negotiated or, failing that, as requested.
+func (wd *remoteWD) browserName() string {
+	for _, caps := range []Capabilities{wd.negotiated, wd.capabilities} {
+		if name, ok := caps["browserName"].(string); ok && name != "" {
+			return strings.ToLower(name)
+		}
+	}
+	return ""
+}
+
+// isChromium returns whether the session's browser is based on Chromium.
+func (wd *remoteWD) isChromium() bool {
+	switch wd.browserName() {
+	case "chrome", "chromium", "msedge", "microsoftedge":
+		return true
+	}
+	return false
+}
+
+// executeCDP sends a Chrome DevTools Protocol command on Chromium-based
+// browsers.
+func (wd *remoteWD) executeCDP(cmd string, params map[string]interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(map[string]interface{}{"cmd": cmd, "params": params})
+	if err != nil {
+		return nil, err
+	}
+	return wd.execute("POST", wd.requestURL("/session/%s/goog/cdp/execute", wd.id), data)
+}
+
+// inFirefoxChromeContext runs f with the session switched to Firefox's chrome
+// context, and switches back to the content context afterwards.
+func (wd *remoteWD) inFirefoxChromeContext(f func() error) error {
+	if err := wd.voidCommand("/session/%s/moz/context", map[string]string{"context": "chrome"}); err != nil {
+		return err
+	}
+	err := f()
+	if restoreErr := wd.voidCommand("/session/%s/moz/context", map[string]string{"context": "content"}); err == nil {
+		err = restoreErr
+	}
+	return err
+}
+
+func (wd *remoteWD) SetPageZoom(factor float64) error {
+	if factor <= 0 {
+		return fmt.Errorf("invalid zoom factor %v: must be positive", factor)
+	}
+	switch {
+	case wd.zoomMethod == ZoomCDP || (wd.zoomMethod == ZoomNone && wd.isChromium()):
+		_, err := wd.executeCDP("Emulation.setPageScaleFactor", map[string]interface{}{"pageScaleFactor": factor})
+		if err == nil {
+			wd.zoomMethod = ZoomCDP
+			return nil
+		}
+		if !isUnknownCommand(err) {
+			return err
+		}
+	case wd.zoomMethod == ZoomFirefoxChrome || (wd.zoomMethod == ZoomNone && wd.browserName() == "firefox"):
+		err := wd.inFirefoxChromeContext(func() error {
+			_, err := wd.ExecuteScript(firefoxSetZoomScript, []interface{}{factor})
+			return err
+		})
+		if err == nil {
+			wd.zoomMethod = ZoomFirefoxChrome
+			return nil
+		}
+		// The chrome context is only available if the remote end permits
+		// system access.
+		debugLog("zooming in the chrome context failed, falling back to a CSS transform: %v", err)
+	}
+	if _, err := wd.ExecuteScript(cssSetZoomScript, []interface{}{factor}); err != nil {
+		return err
+	}
+	wd.zoomMethod = ZoomCSSTransform
+	return nil
+}
+
+func (wd *remoteWD) PageZoom() (float64, ZoomMethod, error) {
+	var script string
+	switch wd.zoomMethod {
+	case ZoomNone:
+		return 1, ZoomNone, nil
+	case ZoomCDP:
+		script = cdpGetZoomScript
+	case ZoomFirefoxChrome:
+		var zoom interface{}
+		err := wd.inFirefoxChromeContext(func() error {
+			var err error
+			zoom, err = wd.ExecuteScript(firefoxGetZoomScript, nil)
+			return err
+		})
+		if err != nil {
+			return 0, wd.zoomMethod, err
+		}
+		return zoomValue(zoom, wd.zoomMethod)
+	default:
+		script = cssGetZoomScript
+	}
+	zoom, err := wd.ExecuteScript(script, nil)
+	if err != nil {
+		return 0, wd.zoomMethod, err
+	}
+	return zoomValue(zoom, wd.zoomMethod)
+}
+
+func zoomValue(v interface{}, method ZoomMethod) (float64, ZoomMethod, error) {
+	zoom, ok := v.(float64)
+	if !ok {
+		return 0, method, fmt.Errorf("unexpected zoom value %v", v)
+	}
+	return zoom, method, nil
+}
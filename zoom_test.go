@@ -0,0 +1,134 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// zoomServer records the commands sent to zoom the page. Commands listed in
+// unknown fail with an unknown command error.
+func zoomServer(t *testing.T, requests *[]string, unknown ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", JSONType)
+		path := strings.TrimPrefix(r.URL.Path, "/session/123")
+		body, _ := ioutil.ReadAll(r.Body)
+		var params struct {
+			Cmd     string
+			Context string
+			Script  string
+		}
+		json.Unmarshal(body, &params)
+		request := path + " " + params.Cmd + params.Context
+		switch {
+		case strings.Contains(params.Script, "gBrowser"):
+			request += "firefox"
+		case strings.Contains(params.Script, "data-selenium-zoom"):
+			request += "css"
+		}
+		*requests = append(*requests, strings.TrimSpace(request))
+		for _, u := range unknown {
+			if path == u {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"value":{"error":"unknown command","message":"not implemented"}}`)
+				return
+			}
+		}
+		if path == "/execute/sync" {
+			fmt.Fprint(w, `{"value":2}`)
+			return
+		}
+		fmt.Fprint(w, `{"value":null}`)
+	}))
+}
+
+func TestSetPageZoom(t *testing.T) {
+	tests := []struct {
+		name, browser string
+		unknown       []string
+		wantMethod    ZoomMethod
+		wantSet       []string
+		wantGet       []string
+	}{
+		{
+			name:       "Chrome",
+			browser:    "chrome",
+			wantMethod: ZoomCDP,
+			wantSet:    []string{"/goog/cdp/execute Emulation.setPageScaleFactor"},
+			wantGet:    []string{"/execute/sync"},
+		},
+		{
+			name:       "Firefox",
+			browser:    "firefox",
+			wantMethod: ZoomFirefoxChrome,
+			wantSet:    []string{"/moz/context chrome", "/execute/sync firefox", "/moz/context content"},
+			wantGet:    []string{"/moz/context chrome", "/execute/sync firefox", "/moz/context content"},
+		},
+		{
+			name:       "FirefoxWithoutChromeContext",
+			browser:    "firefox",
+			unknown:    []string{"/moz/context"},
+			wantMethod: ZoomCSSTransform,
+			wantSet:    []string{"/moz/context chrome", "/execute/sync css"},
+			wantGet:    []string{"/execute/sync css"},
+		},
+		{
+			name:       "ChromeWithoutCDP",
+			browser:    "chrome",
+			unknown:    []string{"/goog/cdp/execute"},
+			wantMethod: ZoomCSSTransform,
+			wantSet:    []string{"/goog/cdp/execute Emulation.setPageScaleFactor", "/execute/sync css"},
+			wantGet:    []string{"/execute/sync css"},
+		},
+		{
+			name:       "Safari",
+			browser:    "safari",
+			wantMethod: ZoomCSSTransform,
+			wantSet:    []string{"/execute/sync css"},
+			wantGet:    []string{"/execute/sync css"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+			s := zoomServer(t, &requests, tc.unknown...)
+			defer s.Close()
+			wd := &remoteWD{id: "123", urlPrefix: s.URL, w3cCompatible: true,
+				negotiated: Capabilities{"browserName": tc.browser}}
+
+			if zoom, method, err := wd.PageZoom(); err != nil || zoom != 1 || method != ZoomNone {
+				t.Errorf("PageZoom() before SetPageZoom() = %v, %v, %v; want 1, none, nil", zoom, method, err)
+			}
+			if err := wd.SetPageZoom(2); err != nil {
+				t.Fatalf("SetPageZoom(2) returned error: %v", err)
+			}
+			if strings.Join(requests, ", ") != strings.Join(tc.wantSet, ", ") {
+				t.Errorf("SetPageZoom(2) sent %q, want %q", requests, tc.wantSet)
+			}
+			requests = nil
+			zoom, method, err := wd.PageZoom()
+			if err != nil || zoom != 2 || method != tc.wantMethod {
+				t.Errorf("PageZoom() = %v, %v, %v; want 2, %v, nil", zoom, method, err, tc.wantMethod)
+			}
+			if strings.Join(requests, ", ") != strings.Join(tc.wantGet, ", ") {
+				t.Errorf("PageZoom() sent %q, want %q", requests, tc.wantGet)
+			}
+			if method.Emulated() != (tc.wantMethod == ZoomCSSTransform) {
+				t.Errorf("%v.Emulated() = %t", method, method.Emulated())
+			}
+		})
+	}
+}
+
+func TestSetPageZoomInvalidFactor(t *testing.T) {
+	wd := &remoteWD{id: "123", urlPrefix: "http://127.0.0.1:0", w3cCompatible: true}
+	for _, factor := range []float64{0, -1} {
+		if err := wd.SetPageZoom(factor); err == nil {
+			t.Errorf("SetPageZoom(%v) returned nil error", factor)
+		}
+	}
+}